@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// taskFiles are the definition files task runners read; a command running
+// one is re-checked when one of them appears or changes.
+var taskFiles = map[string][]string{
+	"make":  {"GNUmakefile", "makefile", "Makefile"},
+	"gmake": {"GNUmakefile", "makefile", "Makefile"},
+	"just":  {"justfile", "Justfile", ".justfile"},
+	"npm":   {"package.json"},
+	"yarn":  {"package.json"},
+	"pnpm":  {"package.json"},
+	"bun":   {"package.json"},
+}
+
+// decide checks a tool call like decideWithApprovals. A Bash command made
+// only of allow_cache.commands that was allowed before in the same
+// directory, under the same config and permission mode, is allowed without
+// running the checks while the files it names are unchanged. Only a plain
+// allow is cached: never an ask or deny, an allow granted by an exception,
+// an approval or a failed check, or a rewritten call.
+func decide(ctx context.Context, hookInput HookInput, cfg *config.SecurityConfig, logger *log.Logger) *checks.CheckResult {
+	ac := cfg.AllowCache
+	ttl, err := time.ParseDuration(ac.TTL)
+	if !ac.Enabled || err != nil || ttl <= 0 || hookInput.ToolName != "Bash" || config.IsUnattended(cfg) {
+		return decideWithApprovals(ctx, hookInput, cfg, logger)
+	}
+	call := approvalCall(hookInput, cfg)
+	files, ok := cacheableCommand(hookInput, cfg)
+	if !ok {
+		return decideWithApprovals(ctx, hookInput, cfg, logger)
+	}
+
+	key := strings.Join([]string{cfg.Version, hookInput.PermissionMode, call.Dir, call.Command}, "\x00")
+	cache := state.LoadAllowCache(allowCachePath(cfg), ttl)
+	if cache.Get(key) {
+		if cfg.Logging.LogAllCalls {
+			logger.Printf("[CACHED] Bash: %s", call.Command)
+		}
+		return checks.Allow("allow_cache")
+	}
+
+	result := decideWithApprovals(ctx, hookInput, cfg, logger)
+	if result.Status != checks.StatusAllow || result.RuleID != "" || result.UpdatedInput != nil || len(result.Errors) > 0 ||
+		result.CheckName == "exception_codes" || result.CheckName == "remember_approvals" {
+		return result
+	}
+	cache.Put(key, files, ac.MaxEntries)
+	if err := cache.Save(); err != nil {
+		logger.Printf("Failed to save allow cache: %v", err)
+	}
+	return result
+}
+
+// cacheableCommand reports whether every command of a Bash call matches
+// allow_cache.commands, and returns the files its decision depends on: the
+// paths it names, the script it runs, and for task runners their
+// definition files and the paths their recipes name, through nested
+// runners ($(MAKE) -C sub) as deep as the Bash handler checks them. A
+// call whose recipes can't all be resolved isn't cached.
+func cacheableCommand(hookInput HookInput, cfg *config.SecurityConfig) ([]string, bool) {
+	parsed := parsers.ParseBashCommand(handlers.GetString(hookInput.ToolInput, "command"))
+	if len(parsed) == 0 {
+		return nil, false
+	}
+	cwd := hookInput.Cwd
+	if !filepath.IsAbs(cwd) {
+		cwd = projectPath(cfg, "")
+	}
+
+	for _, cmd := range parsed {
+		if cmd.VariableAsCommand || len(cmd.Redirects) > 0 || !matchesCachedCommand(cmd, cfg.AllowCache.Commands) {
+			return nil, false
+		}
+	}
+	deps := &cacheDeps{seen: make(map[string]bool)}
+	if !deps.addCommands(cwd, parsed, 0) {
+		return nil, false
+	}
+	return deps.files, true
+}
+
+// cacheDeps collects the files a cached decision depends on.
+type cacheDeps struct {
+	files []string
+	seen  map[string]bool
+}
+
+// add records path, relative to dir.
+func (d *cacheDeps) add(dir, path string) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if path = filepath.Clean(path); !d.seen[path] {
+		d.seen[path] = true
+		d.files = append(d.files, path)
+	}
+}
+
+// addCommands records the files of cmds run from cwd, depth levels of
+// recipes down, and of the recipes they run. It returns false if a make or
+// just recipe can't be resolved, or recipes nest deeper than the handler
+// resolves them (handlers.MaxRecipeDepth).
+func (d *cacheDeps) addCommands(cwd string, cmds []*parsers.ParsedCommand, depth int) bool {
+	for _, cmd := range cmds {
+		dir := cwd
+		if cmd.Dir != "" {
+			dir = parsers.ResolvePath(cmd.Dir, cwd)
+		}
+		if parsers.IsPathLike(cmd.Command) {
+			d.add(dir, cmd.Command)
+		}
+		for _, path := range parsers.ExtractPathsFromCommand(cmd) {
+			d.add(dir, path)
+		}
+
+		runner := filepath.Base(cmd.Command)
+		for _, name := range taskFiles[runner] {
+			d.add(dir, name)
+		}
+		recipes := parsers.ResolveTaskRecipes(cmd, dir)
+		if len(recipes) == 0 {
+			// make and just always run a recipe: none found means
+			// one the cache can't watch (make -C missing/)
+			if runner == "make" || runner == "gmake" || runner == "just" {
+				return false
+			}
+			continue
+		}
+		if depth >= handlers.MaxRecipeDepth {
+			return false
+		}
+		for _, recipe := range recipes {
+			d.add(dir, recipe.Source)
+			for _, line := range recipe.Commands {
+				// The handler checks recipe lines from the call's cwd too
+				if !d.addCommands(cwd, parsers.ParseBashCommand(line), depth+1) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// matchesCachedCommand reports whether cmd is one of entries: its name
+// followed by the entry's arguments, in order.
+func matchesCachedCommand(cmd *parsers.ParsedCommand, entries []string) bool {
+	name := filepath.Base(cmd.Command)
+	for _, entry := range entries {
+		words := strings.Fields(entry)
+		if len(words) == 0 || words[0] != name || len(words)-1 > len(cmd.Args) {
+			continue
+		}
+		matched := true
+		for i, w := range words[1:] {
+			if cmd.Args[i] != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// allowCachePath returns where allowed commands are kept: allow_cache.store
+// relative to the project, or by default the project's directory in
+// state_directory.
+func allowCachePath(cfg *config.SecurityConfig) string {
+	if store := cfg.AllowCache.Store; store != "" {
+		return projectPath(cfg, store)
+	}
+	return filepath.Join(state.ProjectDir(cfg.StateDirectory, projectPath(cfg, "")), "allow_cache.json")
+}
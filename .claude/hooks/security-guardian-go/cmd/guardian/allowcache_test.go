@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// TestAllowCacheNestedMake checks that editing the Makefile a recipe's
+// $(MAKE) -C runs drops the cached allow.
+func TestAllowCacheNestedMake(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	t.Setenv("UNATTENDED", "")
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Makefile", "all:\n\t$(MAKE) -C sub\n")
+	write("sub/Makefile", "all:\n\techo hello\n")
+
+	cfg := config.DefaultConfig()
+	cfg.StateDirectory = t.TempDir()
+	logger := log.New(io.Discard, "", 0)
+	input := HookInput{
+		HookEventName: "PreToolUse",
+		ToolName:      "Bash",
+		ToolInput:     map[string]interface{}{"command": "make"},
+		Cwd:           root,
+	}
+
+	files, ok := cacheableCommand(input, cfg)
+	if !ok {
+		t.Fatal("make is not cacheable")
+	}
+	if !containsRule(files, filepath.Join(root, "sub", "Makefile")) {
+		t.Errorf("files = %q, want sub/Makefile", files)
+	}
+
+	if result := decide(context.Background(), input, cfg, logger); !result.IsAllowed() {
+		t.Fatalf("first run: %s %s", result.PermissionDecisionValue(), result.Reason)
+	}
+	if result := decide(context.Background(), input, cfg, logger); result.CheckName != "allow_cache" {
+		t.Fatalf("second run not cached: %s", result.CheckName)
+	}
+
+	// Same size, later mtime: only the stamp tells the edit
+	write("sub/Makefile", "all:\n\trm -rf ~;:\n")
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(filepath.Join(root, "sub", "Makefile"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	result := decide(context.Background(), input, cfg, logger)
+	if result.PermissionDecisionValue() != checks.DecisionDeny || result.RuleID != checks.RuleDeletionCritical {
+		t.Fatalf("after edit: %s %s (%s)", result.PermissionDecisionValue(), result.RuleID, result.Reason)
+	}
+}
+
+// TestAllowCacheRecipeDepth checks that recipes nested deeper than the
+// handler resolves them aren't cached.
+func TestAllowCacheRecipeDepth(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	for _, dir := range []string{"", "a", "a/b", "a/b/c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	makefiles := map[string]string{
+		"Makefile":       "all:\n\t$(MAKE) -C a\n",
+		"a/Makefile":     "all:\n\t$(MAKE) -C a/b\n",
+		"a/b/Makefile":   "all:\n\t$(MAKE) -C a/b/c\n",
+		"a/b/c/Makefile": "all:\n\techo deep\n",
+	}
+	for path, content := range makefiles {
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Recipe lines are checked from the call's cwd, as the handler does
+	cfg := config.DefaultConfig()
+	input := HookInput{ToolName: "Bash", ToolInput: map[string]interface{}{"command": "make"}, Cwd: root}
+	if _, ok := cacheableCommand(input, cfg); ok {
+		t.Error("make nesting beyond the recipe depth is cacheable")
+	}
+	input.ToolInput["command"] = "make -C a"
+	if _, ok := cacheableCommand(input, cfg); !ok {
+		t.Error("make nesting within the recipe depth is not cacheable")
+	}
+
+	input.ToolInput["command"] = "make -C missing"
+	if _, ok := cacheableCommand(input, cfg); ok {
+		t.Error("make without a Makefile is cacheable")
+	}
+}
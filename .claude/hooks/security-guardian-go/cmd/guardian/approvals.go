@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+	"github.com/artwist-polyakov/security-guardian/pkg/shparse"
+)
+
+// maxPendingApprovals bounds the asks a session waits to see approved.
+const maxPendingApprovals = 20
+
+// decideWithApprovals checks a tool call like processHookInput. A Bash
+// command that only asks for rules in remember_approvals is allowed if the
+// user approved it before in the same directory; otherwise it is kept
+// pending until PostToolUse shows it ran. A call the user granted an
+// exception for with `guardian allow` is allowed until it expires; a denied
+// call is offered a new exception code.
+func decideWithApprovals(ctx context.Context, hookInput HookInput, cfg *config.SecurityConfig, logger *log.Logger) *checks.CheckResult {
+	ra := cfg.RememberApprovals
+	remember := ra.Enabled && hookInput.ToolName == "Bash"
+	if !remember && !cfg.ExceptionCodes.Enabled {
+		return processHookInput(ctx, hookInput, cfg, nil, logger)
+	}
+
+	listed := make(map[string]bool)
+	for _, rule := range ra.Rules {
+		listed[rule] = true
+	}
+	var asked, denied []string
+	rememberable := true
+	tracer := func(step handlers.TraceStep) {
+		switch step.Decision {
+		case checks.DecisionDeny:
+			rememberable = false
+			denied = appendUnique(denied, step.Result.RuleID)
+		case checks.DecisionAsk:
+			if !listed[step.Result.RuleID] {
+				rememberable = false
+			}
+			asked = appendUnique(asked, step.Result.RuleID)
+		}
+	}
+	result := processHookInput(ctx, hookInput, cfg, tracer, logger)
+	if result.IsAllowed() {
+		return result
+	}
+
+	if cfg.ExceptionCodes.Enabled {
+		if excepted := applyException(hookInput, result, append(denied, asked...), cfg, logger); excepted != nil {
+			return excepted
+		}
+	}
+
+	// A timeout or internal error is decided without a traced check
+	if !remember || !rememberable || len(asked) == 0 || result.PermissionDecisionValue() != checks.DecisionAsk || !listed[result.RuleID] {
+		return result
+	}
+
+	call := approvalCall(hookInput, cfg)
+	call.Rules = asked
+	if state.LoadApprovals(approvalsPath(cfg)).Covers(call) {
+		logger.Printf("[REMEMBERED] Bash: %s (rules: %s)", call.Command, strings.Join(asked, ", "))
+		return checks.Allow("remember_approvals")
+	}
+
+	s := loadSession(cfg, hookInput.SessionID)
+	s.AddPending(call, maxPendingApprovals)
+	if err := s.Save(); err != nil {
+		logger.Printf("Failed to save session: %v", err)
+	}
+	return result
+}
+
+// confirmApproval remembers a pending Bash command once it ran, which
+// means the user approved the ask.
+func confirmApproval(hookInput HookInput, cfg *config.SecurityConfig, logger *log.Logger) {
+	if !cfg.RememberApprovals.Enabled || hookInput.ToolName != "Bash" {
+		return
+	}
+	s := loadSession(cfg, hookInput.SessionID)
+	approval, ok := s.TakePending(approvalCall(hookInput, cfg))
+	if !ok {
+		return
+	}
+	if err := s.Save(); err != nil {
+		logger.Printf("Failed to save session: %v", err)
+	}
+
+	approvals := state.LoadApprovals(approvalsPath(cfg))
+	approval.Time = time.Now()
+	approvals.Add(approval)
+	if err := approvals.Save(); err != nil {
+		logger.Printf("Failed to save approvals: %v", err)
+		return
+	}
+	logger.Printf("[APPROVED] Bash: %s (rules: %s)", approval.Command, strings.Join(approval.Rules, ", "))
+}
+
+// approvalCall identifies a Bash call: its normalized command and the
+// directory it runs in, relative to the project root.
+func approvalCall(hookInput HookInput, cfg *config.SecurityConfig) state.Approval {
+	call := state.Approval{
+		Command: shparse.Normalize(handlers.GetString(hookInput.ToolInput, "command")),
+		Dir:     ".",
+		Time:    time.Now(),
+	}
+	root := projectPath(cfg, "")
+	if filepath.IsAbs(hookInput.Cwd) {
+		if rel, err := filepath.Rel(root, hookInput.Cwd); err == nil {
+			call.Dir = filepath.ToSlash(rel)
+		} else {
+			call.Dir = hookInput.Cwd
+		}
+	}
+	return call
+}
+
+// approvalsPath returns where approvals are kept: remember_approvals.store
+// relative to the project, or by default the project's directory in
+// state_directory, shared by its worktrees.
+func approvalsPath(cfg *config.SecurityConfig) string {
+	if store := cfg.RememberApprovals.Store; store != "" {
+		return projectPath(cfg, store)
+	}
+	return filepath.Join(state.ProjectDir(cfg.StateDirectory, projectPath(cfg, "")), "approvals.json")
+}
+
+// appendUnique appends s to list unless it is already there.
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// benchCorpus is the corpus `guardian bench` runs without --corpus.
+//
+//go:embed bench_corpus.txt
+var benchCorpus []byte
+
+// benchReport is the result of `guardian bench`, also printed with --json.
+type benchReport struct {
+	Calls         int          `json:"calls"`
+	Evaluations   int          `json:"evaluations"`
+	P50Ms         float64      `json:"p50_ms"`
+	P95Ms         float64      `json:"p95_ms"`
+	P99Ms         float64      `json:"p99_ms"`
+	MaxMs         float64      `json:"max_ms"`
+	AllocsPerEval uint64       `json:"allocs_per_eval"`
+	BytesPerEval  uint64       `json:"bytes_per_eval"`
+	BudgetP95Ms   float64      `json:"budget_p95_ms"`
+	WithinBudget  bool         `json:"within_budget"`
+	Slowest       []benchCall  `json:"slowest"`
+	Large         []benchLarge `json:"large,omitempty"`
+}
+
+// benchLarge is the cost of a Write of a generated bundle of SizeMB.
+type benchLarge struct {
+	SizeMB        int     `json:"size_mb"`
+	P50Ms         float64 `json:"p50_ms"`
+	MaxMs         float64 `json:"max_ms"`
+	AllocsPerEval uint64  `json:"allocs_per_eval"`
+	BytesPerEval  uint64  `json:"bytes_per_eval"`
+}
+
+// benchCall is the p95 latency of one corpus call.
+type benchCall struct {
+	Call  string  `json:"call"`
+	P95Ms float64 `json:"p95_ms"`
+}
+
+// runBench implements `guardian bench`: it runs a corpus of tool calls
+// through all checks, reports latency percentiles and allocations per
+// evaluation, and exits 1 if the p95 is over performance.budget_p95_ms.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	corpusPath := fs.String("corpus", "", "Bash commands or hook inputs, one per line (default: the bundled corpus)")
+	rounds := fs.Int("n", 20, "times each call is evaluated")
+	budget := fs.Float64("budget", -1, "p95 budget in ms (default performance.budget_p95_ms, 0: report only)")
+	jsonOut := fs.Bool("json", false, "print the report as JSON")
+	large := fs.String("large", "", "also time Writes of generated JS bundles of these sizes in MB, e.g. 10,50 (not part of the budget)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *rounds < 1 {
+		fmt.Fprintln(os.Stderr, "guardian bench: -n must be at least 1")
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	if *budget < 0 {
+		*budget = cfg.Performance.BudgetP95Ms
+	}
+
+	var r io.Reader = bytes.NewReader(benchCorpus)
+	if *corpusPath != "" {
+		f, err := os.Open(*corpusPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian bench: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+	cases, err := readCorpus(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian bench: %v\n", err)
+		return 1
+	}
+	if len(cases) == 0 {
+		fmt.Fprintln(os.Stderr, "guardian bench: empty corpus")
+		return 1
+	}
+
+	// Side effects are off as in a replay; timeouts stay as configured
+	benchCfg, cleanup := replayConfig(cfg, "")
+	defer cleanup()
+	benchCfg.Performance = cfg.Performance
+	logger := log.New(io.Discard, "", 0)
+	evaluate := func(c crosscheckCase) {
+		processHookInput(context.Background(), c.input, benchCfg, nil, logger)
+	}
+
+	// One untimed round fills the probe cache and the OS page cache
+	for _, c := range cases {
+		evaluate(c)
+	}
+
+	all := make([]time.Duration, 0, len(cases)*(*rounds))
+	perCall := make([][]time.Duration, len(cases))
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < *rounds; i++ {
+		for j, c := range cases {
+			start := time.Now()
+			evaluate(c)
+			d := time.Since(start)
+			all = append(all, d)
+			perCall[j] = append(perCall[j], d)
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	report := benchReport{
+		Calls:         len(cases),
+		Evaluations:   len(all),
+		P50Ms:         percentileMs(all, 50),
+		P95Ms:         percentileMs(all, 95),
+		P99Ms:         percentileMs(all, 99),
+		MaxMs:         percentileMs(all, 100),
+		AllocsPerEval: (after.Mallocs - before.Mallocs) / uint64(len(all)),
+		BytesPerEval:  (after.TotalAlloc - before.TotalAlloc) / uint64(len(all)),
+		BudgetP95Ms:   *budget,
+	}
+	report.WithinBudget = *budget <= 0 || report.P95Ms <= *budget
+	for j, c := range cases {
+		report.Slowest = append(report.Slowest, benchCall{Call: crosscheckSummary(c.input), P95Ms: percentileMs(perCall[j], 95)})
+	}
+	sort.SliceStable(report.Slowest, func(a, b int) bool { return report.Slowest[a].P95Ms > report.Slowest[b].P95Ms })
+	if len(report.Slowest) > 5 {
+		report.Slowest = report.Slowest[:5]
+	}
+
+	for _, field := range strings.Split(*large, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		mb, err := strconv.Atoi(field)
+		if err != nil || mb < 1 {
+			fmt.Fprintf(os.Stderr, "guardian bench: invalid --large size %q\n", field)
+			return 2
+		}
+		report.Large = append(report.Large, benchLargeWrite(mb, *rounds, evaluate))
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printBenchReport(report)
+	}
+	if !report.WithinBudget {
+		return 1
+	}
+	return 0
+}
+
+// benchLargeWrite times a Write of a generated JS bundle of mb megabytes,
+// at most 3 rounds: what content checks cost on generated files.
+func benchLargeWrite(mb, rounds int, evaluate func(crosscheckCase)) benchLarge {
+	if rounds > 3 {
+		rounds = 3
+	}
+	line := "var m%d=function(e,t){return fetch(\"/api/items/\"+e).then(function(r){return r.json()}).catch(t)};\n"
+	var b strings.Builder
+	b.Grow(mb << 20)
+	for i := 0; b.Len() < mb<<20; i++ {
+		fmt.Fprintf(&b, line, i)
+	}
+	c := crosscheckCase{input: HookInput{
+		HookEventName: "PreToolUse",
+		ToolName:      "Write",
+		ToolInput:     map[string]interface{}{"file_path": fmt.Sprintf("dist/bundle-%dmb.js", mb), "content": b.String()},
+	}}
+
+	var durations []time.Duration
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < rounds; i++ {
+		start := time.Now()
+		evaluate(c)
+		durations = append(durations, time.Since(start))
+	}
+	runtime.ReadMemStats(&after)
+	return benchLarge{
+		SizeMB:        mb,
+		P50Ms:         percentileMs(durations, 50),
+		MaxMs:         percentileMs(durations, 100),
+		AllocsPerEval: (after.Mallocs - before.Mallocs) / uint64(rounds),
+		BytesPerEval:  (after.TotalAlloc - before.TotalAlloc) / uint64(rounds),
+	}
+}
+
+// printBenchReport prints the report of `guardian bench`.
+func printBenchReport(r benchReport) {
+	fmt.Printf("%d calls x %d rounds = %d evaluations\n\n", r.Calls, r.Evaluations/r.Calls, r.Evaluations)
+	fmt.Printf("p50   %8.2f ms\n", r.P50Ms)
+	fmt.Printf("p95   %8.2f ms\n", r.P95Ms)
+	fmt.Printf("p99   %8.2f ms\n", r.P99Ms)
+	fmt.Printf("max   %8.2f ms\n", r.MaxMs)
+	fmt.Printf("allocs/eval  %d (%d bytes)\n", r.AllocsPerEval, r.BytesPerEval)
+
+	fmt.Printf("\nSlowest calls (p95):\n")
+	for _, c := range r.Slowest {
+		fmt.Printf("  %8.2f ms  %s\n", c.P95Ms, c.Call)
+	}
+
+	if len(r.Large) > 0 {
+		fmt.Printf("\nLarge Writes (not part of the budget):\n")
+	}
+	for _, l := range r.Large {
+		fmt.Printf("  %4d MB  p50 %8.2f ms  max %8.2f ms  allocs/eval %d (%d bytes)\n", l.SizeMB, l.P50Ms, l.MaxMs, l.AllocsPerEval, l.BytesPerEval)
+	}
+
+	switch {
+	case r.BudgetP95Ms <= 0:
+		fmt.Printf("\nNo budget set\n")
+	case r.WithinBudget:
+		fmt.Printf("\nWithin budget: p95 %.2f ms <= %.2f ms\n", r.P95Ms, r.BudgetP95Ms)
+	default:
+		fmt.Printf("\nOVER BUDGET: p95 %.2f ms > %.2f ms\n", r.P95Ms, r.BudgetP95Ms)
+	}
+}
+
+// percentileMs returns the p-th percentile of durations in milliseconds
+// (nearest rank). p = 100 is the maximum.
+func percentileMs(durations []time.Duration, p int) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return float64(sorted[i]) / float64(time.Millisecond)
+}
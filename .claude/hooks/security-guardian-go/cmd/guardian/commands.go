@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a CLI subcommand (guardian <name> ...).
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists subcommands. Without arguments the binary runs as a hook.
+var commands = []command{
+	{"version", "print the version, commit, build date and config fingerprint (--json)", runVersion},
+	{"trash", "list, restore or purge files moved to trash instead of deleted", runTrash},
+	{"git-backups", "list or prune working tree snapshots taken before destructive git ops", runGitBackups},
+	{"trust", "record reviewed scripts by sha256 so content checks skip them", runTrust},
+	{"allow", "grant the exception code of a deny message for a limited time (--ttl 30m, --list)", runAllow},
+	{"self-update", "replace this binary with the signed release for this platform (--check)", runSelfUpdate},
+	{"decoy", "install or list honeypot .env files whose access is denied and reported", runDecoy},
+	{"policy", "print a summary of the active policy (--markdown for CLAUDE.md)", runPolicy},
+	{"explain", "replay a recorded ask/deny and show which checks and patterns decided it", runExplain},
+	{"rules", "list active rules with their decision and the config file/line they come from", runRules},
+	{"migrate-config", "convert a Python guardian config to this version's security_config.yaml", runMigrateConfig},
+	{"scan", "check staged files (--staged) or paths for secrets and dangerous code; exit 1 on violations", runScan},
+	{"serve", "evaluate tool calls over local HTTP (POST /v1/evaluate) with an API key", runServe},
+	{"doctor", "report invalid config, patterns the checks skip and internal errors of recent sessions", runDoctor},
+	{"config", "diff OLD NEW --corpus FILES: decisions a new config changes; print-default [--comments]; docs: key reference", runConfig},
+	{"bench", "time all checks on a corpus of everyday tool calls (p50/p95, allocations); exit 1 over performance.budget_p95_ms", runBench},
+	{"crosscheck", "run a corpus through the Go and Python guardians and report decision mismatches", runCrosscheck},
+}
+
+// runCommand dispatches a subcommand and returns the exit code.
+func runCommand(args []string) int {
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+
+	if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
+	}
+	printUsage()
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		return 0
+	}
+	return 2
+}
+
+// printUsage prints the subcommand list.
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: guardian                  run as Claude Code hook (reads JSON from stdin)")
+	fmt.Fprintln(os.Stderr, "       guardian <command> [args]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", c.name, c.summary)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// runConfig implements `guardian config <subcommand>`.
+func runConfig(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "diff":
+			return runConfigDiff(args[1:])
+		case "print-default":
+			return runConfigPrintDefault(args[1:])
+		case "docs":
+			return runConfigDocs(args[1:])
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Usage: guardian config diff OLD.yaml NEW.yaml --corpus FILE...")
+	fmt.Fprintln(os.Stderr, "       guardian config print-default [--comments]")
+	fmt.Fprintln(os.Stderr, "       guardian config docs [--json]")
+	return 2
+}
+
+// runConfigPrintDefault implements `guardian config print-default
+// [--comments]`: the built-in defaults as YAML, a starting point for a
+// config that sets every key.
+func runConfigPrintDefault(args []string) int {
+	fs := flag.NewFlagSet("config print-default", flag.ContinueOnError)
+	comments := fs.Bool("comments", false, "document each key with its comment from the schema")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	data, err := config.DefaultYAML(*comments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian config print-default: %v\n", err)
+		return 1
+	}
+	os.Stdout.Write(data)
+	return 0
+}
+
+// runConfigDocs implements `guardian config docs [--json]`: a reference of
+// every config key with its type, default and description, as a Markdown
+// table.
+func runConfigDocs(args []string) int {
+	fs := flag.NewFlagSet("config docs", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	docs := config.Reference()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(docs)
+		return 0
+	}
+
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	fmt.Println("# Configuration reference")
+	fmt.Println()
+	fmt.Println("Generated by `guardian config docs` from the config schema. Keys left out of `security_config.yaml` take the default.")
+	fmt.Println()
+	fmt.Println("| Key | Type | Default | Description |")
+	fmt.Println("|-----|------|---------|-------------|")
+	for _, d := range docs {
+		def := ""
+		if d.Default != "" {
+			def = "`" + cell.Replace(d.Default) + "`"
+		}
+		fmt.Printf("| `%s` | %s | %s | %s |\n", d.Key, cell.Replace(d.Type), def, cell.Replace(d.Doc))
+	}
+	return 0
+}
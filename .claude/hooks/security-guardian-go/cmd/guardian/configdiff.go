@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// diffCase is a decision to replay: a hook input, where it came from and
+// the decision recorded with it, if any.
+type diffCase struct {
+	source   string
+	input    HookInput
+	session  string
+	recorded string
+}
+
+// runConfigDiff implements `guardian config diff OLD NEW --corpus FILE...`.
+// It replays a corpus of decisions under both configs and reports the
+// ones the new config decides differently, so a config change can be
+// previewed before it is rolled out.
+func runConfigDiff(args []string) int {
+	fs := flag.NewFlagSet("config diff", flag.ContinueOnError)
+	var corpus []string
+	fs.Func("corpus", "decision records (explain.store), hook inputs or Bash commands, one per line; glob patterns allowed; repeatable", func(v string) error {
+		corpus = append(corpus, v)
+		return nil
+	})
+	verbose := fs.Bool("v", false, "print unchanged decisions too")
+	// Flags may follow the configs: config diff old.yaml new.yaml --corpus ...
+	var positional []string
+	for rest := args; ; {
+		if err := fs.Parse(rest); err != nil {
+			return 2
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if len(positional) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: guardian config diff OLD.yaml NEW.yaml --corpus FILE...")
+		return 2
+	}
+	// The shell expands --corpus decisions-*.jsonl into further arguments
+	corpus = append(corpus, positional[2:]...)
+	if len(corpus) == 0 {
+		corpus = []string{projectPath(config.DefaultConfig(), config.DefaultConfig().Explain.Store)}
+	}
+
+	oldCfg, oldData, err := loadDiffConfig(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian config diff: %v\n", err)
+		return 1
+	}
+	newCfg, newData, err := loadDiffConfig(positional[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian config diff: %v\n", err)
+		return 1
+	}
+
+	var cases []diffCase
+	for _, pattern := range corpus {
+		files, _ := filepath.Glob(pattern)
+		if len(files) == 0 {
+			files = []string{pattern}
+		}
+		for _, file := range files {
+			c, err := readDiffCorpus(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "guardian config diff: %s: %v\n", file, err)
+				return 1
+			}
+			cases = append(cases, c...)
+		}
+	}
+
+	if keys := changedKeys(oldData, newData); len(keys) > 0 {
+		fmt.Printf("Changed settings: %s\n\n", strings.Join(keys, ", "))
+	} else {
+		fmt.Printf("No settings changed\n\n")
+	}
+
+	var stricter, looser, reruled int
+	for _, c := range cases {
+		before := replayDecision(c, oldCfg)
+		after := replayDecision(c, newCfg)
+		summary := crosscheckSummary(c.input)
+
+		switch {
+		case before.decision == after.decision && before.rule == after.rule:
+			if *verbose {
+				fmt.Printf("same      %s  %-5s %s\n", c.source, after.decision, summary)
+			}
+			continue
+		case decisionRank[after.decision] > decisionRank[before.decision]:
+			stricter++
+			fmt.Printf("STRICTER  %s  %s -> %s  %s\n", c.source, before.decision, after.decision, summary)
+		case decisionRank[after.decision] < decisionRank[before.decision]:
+			looser++
+			fmt.Printf("LOOSER    %s  %s -> %s  %s\n", c.source, before.decision, after.decision, summary)
+		default:
+			reruled++
+			fmt.Printf("RULE      %s  %s  %s\n", c.source, after.decision, summary)
+		}
+		if before.reason != "" {
+			fmt.Printf("          old: %s (rule: %s)\n", before.reason, before.rule)
+		}
+		if after.reason != "" {
+			fmt.Printf("          new: %s (rule: %s)\n", after.reason, after.rule)
+		}
+		if c.recorded != "" && c.recorded != before.decision {
+			fmt.Printf("          recorded as %s: files or session counters changed since\n", c.recorded)
+		}
+	}
+
+	fmt.Printf("\n%d decisions: %d unchanged, %d stricter, %d looser, %d decided by another rule\n",
+		len(cases), len(cases)-stricter-looser-reruled, stricter, looser, reruled)
+	if stricter+looser+reruled > 0 {
+		return 1
+	}
+	return 0
+}
+
+// diffVerdict is the decision of one config.
+type diffVerdict struct {
+	decision string
+	rule     string
+	reason   string
+}
+
+// replayDecision decides a corpus case under cfg without side effects.
+func replayDecision(c diffCase, cfg *config.SecurityConfig) diffVerdict {
+	replayCfg, cleanup := replayConfig(cfg, c.session)
+	defer cleanup()
+	result := processHookInput(context.Background(), c.input, replayCfg, nil, log.New(io.Discard, "", 0))
+	return diffVerdict{decision: string(result.PermissionDecisionValue()), rule: result.RuleID, reason: result.Reason}
+}
+
+// loadDiffConfig loads a config to compare and its YAML. Unlike the hook,
+// a missing file is an error rather than the defaults.
+func loadDiffConfig(path string) (*config.SecurityConfig, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.LoadConfigFromBytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return cfg, data, nil
+}
+
+// readDiffCorpus reads a corpus file: decision records as explain stores
+// them, hook inputs, or Bash commands, one per line.
+func readDiffCorpus(path string) ([]diffCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []diffCase
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		source := fmt.Sprintf("%s:%d", filepath.Base(path), n)
+
+		var record state.DecisionRecord
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &record) == nil && record.Tool != "" {
+			cases = append(cases, diffCase{
+				source: source,
+				input: HookInput{
+					HookEventName:  "PreToolUse",
+					ToolName:       record.Tool,
+					ToolInput:      record.Input,
+					PermissionMode: record.PermissionMode,
+					Cwd:            record.Cwd,
+				},
+				session:  record.SessionID,
+				recorded: record.Decision,
+			})
+			continue
+		}
+		parsed, err := readCorpus(strings.NewReader(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		for _, c := range parsed {
+			cases = append(cases, diffCase{source: source, input: c.input, session: c.input.SessionID})
+		}
+	}
+	return cases, scanner.Err()
+}
+
+// changedKeys returns the dotted keys whose values differ between two
+// YAML configs.
+func changedKeys(oldData, newData []byte) []string {
+	var before, after map[string]interface{}
+	yaml.Unmarshal(oldData, &before)
+	yaml.Unmarshal(newData, &after)
+
+	flatBefore := make(map[string]interface{})
+	flatAfter := make(map[string]interface{})
+	flattenYAML("", before, flatBefore)
+	flattenYAML("", after, flatAfter)
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, flat := range []map[string]interface{}{flatBefore, flatAfter} {
+		for k := range flat {
+			if !seen[k] && !reflect.DeepEqual(flatBefore[k], flatAfter[k]) {
+				keys = append(keys, k)
+			}
+			seen[k] = true
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flattenYAML collects the values of nested mappings by dotted key. Lists
+// are compared whole.
+func flattenYAML(prefix string, v interface{}, out map[string]interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		if prefix != "" {
+			out[prefix] = v
+		}
+		return
+	}
+	for k, child := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flattenYAML(key, child, out)
+	}
+}
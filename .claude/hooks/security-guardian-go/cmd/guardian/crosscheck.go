@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// crosscheckCase is one corpus entry: a hook input and where it came from.
+type crosscheckCase struct {
+	line  int
+	input HookInput
+	raw   []byte
+}
+
+// crosscheckVerdict is the decision of one implementation.
+type crosscheckVerdict struct {
+	decision string
+	reason   string
+}
+
+// decisionRank orders decisions from least to most strict.
+var decisionRank = map[string]int{"allow": 0, "ask": 1, "deny": 2}
+
+// runCrosscheck implements `guardian crosscheck [--corpus file] [--python cmd] [-v]`.
+// It feeds a corpus through this binary's checks and the Python guardian
+// and reports inputs they decide differently.
+func runCrosscheck(args []string) int {
+	fs := flag.NewFlagSet("crosscheck", flag.ContinueOnError)
+	corpusPath := fs.String("corpus", "-", "corpus file: one hook input JSON or Bash command per line (- for stdin)")
+	python := fs.String("python", "python3 ../security-guardian/main.py", "command that runs the Python guardian")
+	verbose := fs.Bool("v", false, "print every input and both reasons, not only mismatches")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	pythonArgs := strings.Fields(*python)
+	if len(pythonArgs) == 0 {
+		fmt.Fprintln(os.Stderr, "guardian crosscheck: --python is empty")
+		return 2
+	}
+
+	var r io.Reader = os.Stdin
+	if *corpusPath != "-" {
+		f, err := os.Open(*corpusPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian crosscheck: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+	cases, err := readCorpus(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian crosscheck: %s: %v\n", *corpusPath, err)
+		return 1
+	}
+
+	configPath := config.FindConfigPath()
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian crosscheck: config %s: %v (using defaults)\n", configPath, err)
+		cfg = config.DefaultConfig()
+	}
+	replayCfg, cleanup := replayConfig(cfg, "")
+	defer cleanup()
+
+	var stricter, looser, failed int
+	for _, c := range cases {
+		result := processHookInput(context.Background(), c.input, replayCfg, nil, log.New(io.Discard, "", 0))
+		goVerdict := crosscheckVerdict{decision: string(result.PermissionDecisionValue()), reason: result.Reason}
+		pyVerdict, err := runPythonGuardian(pythonArgs, c.raw)
+		summary := crosscheckSummary(c.input)
+
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("ERROR     line %d  %s\n          python: %v\n", c.line, summary, err)
+			continue
+		case goVerdict.decision == pyVerdict.decision:
+			if *verbose {
+				fmt.Printf("ok        line %d  %-5s %s\n", c.line, goVerdict.decision, summary)
+			}
+			continue
+		case decisionRank[goVerdict.decision] > decisionRank[pyVerdict.decision]:
+			stricter++
+			fmt.Printf("STRICTER  line %d  go=%s python=%s  %s\n", c.line, goVerdict.decision, pyVerdict.decision, summary)
+		default:
+			looser++
+			fmt.Printf("LOOSER    line %d  go=%s python=%s  %s\n", c.line, goVerdict.decision, pyVerdict.decision, summary)
+		}
+		if goVerdict.reason != "" {
+			fmt.Printf("          go:     %s\n", goVerdict.reason)
+		}
+		if pyVerdict.reason != "" {
+			fmt.Printf("          python: %s\n", pyVerdict.reason)
+		}
+	}
+
+	fmt.Printf("\n%d inputs: %d match, %d stricter in Go, %d looser in Go, %d errors\n",
+		len(cases), len(cases)-stricter-looser-failed, stricter, looser, failed)
+	if stricter+looser+failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readCorpus reads corpus lines. A line starting with { is a hook input;
+// any other line is a Bash command. Blank lines and # comments are skipped.
+func readCorpus(r io.Reader) ([]crosscheckCase, error) {
+	var cases []crosscheckCase
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var input HookInput
+		if strings.HasPrefix(line, "{") {
+			if err := json.Unmarshal([]byte(line), &input); err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+		} else {
+			input.ToolName = "Bash"
+			input.ToolInput = map[string]interface{}{"command": line}
+		}
+		if input.HookEventName == "" {
+			input.HookEventName = "PreToolUse"
+		}
+		raw, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		cases = append(cases, crosscheckCase{line: n, input: input, raw: raw})
+	}
+	return cases, scanner.Err()
+}
+
+// runPythonGuardian runs the Python guardian on one hook input. It prints
+// nothing to allow and a permissionDecision object otherwise.
+func runPythonGuardian(args []string, input []byte) (crosscheckVerdict, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// A traceback ends with the exception
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return crosscheckVerdict{}, fmt.Errorf("%v: %s", err, lines[len(lines)-1])
+	}
+
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return crosscheckVerdict{decision: string(checks.DecisionAllow)}, nil
+	}
+	var output HookOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return crosscheckVerdict{}, fmt.Errorf("unexpected output %q", out)
+	}
+	if _, ok := decisionRank[output.PermissionDecision]; !ok {
+		return crosscheckVerdict{}, fmt.Errorf("unknown decision %q", output.PermissionDecision)
+	}
+	reason, _, _ := strings.Cut(output.Message, "\n")
+	return crosscheckVerdict{decision: output.PermissionDecision, reason: reason}, nil
+}
+
+// crosscheckSummary shows an input on one line: the command for Bash, the
+// path for file tools.
+func crosscheckSummary(input HookInput) string {
+	for _, key := range []string{"command", "file_path", "notebook_path", "path", "pattern"} {
+		if v, ok := input.ToolInput[key].(string); ok {
+			v = strings.ReplaceAll(v, "\n", `\n`)
+			if len(v) > 100 {
+				v = v[:100] + "..."
+			}
+			return fmt.Sprintf("%s: %s", input.ToolName, v)
+		}
+	}
+	return input.ToolName
+}
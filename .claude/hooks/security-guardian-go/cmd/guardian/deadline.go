@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// errCheckTimeout is returned by runHandler when the checks run out of time.
+var errCheckTimeout = errors.New("checks timed out")
+
+// checkPanic is a panic contained by runHandler.
+type checkPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *checkPanic) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// checkTimeout returns the time the checks of a tool call get, 0 for no
+// limit.
+func checkTimeout(cfg *config.SecurityConfig, toolName string) time.Duration {
+	ms := cfg.Performance.CheckTimeoutMs
+	if toolMs, ok := cfg.Performance.ToolTimeoutsMs[toolName]; ok {
+		ms = toolMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// checkContext returns the context the checks of a tool call run under:
+// parent, limited to timeout (no limit if timeout <= 0). Subprocesses the
+// checks start are killed when it is done.
+func checkContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// runHandler runs handle, containing a panic and giving up when ctx is
+// done: errCheckTimeout past its deadline, ctx.Err() when it was
+// cancelled (daemon shutdown, client gone). A handler given up on keeps
+// running in the background, but its subprocesses are killed with ctx.
+func runHandler(ctx context.Context, handle func() *checks.CheckResult) (*checks.CheckResult, error) {
+	type outcome struct {
+		result *checks.CheckResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: &checkPanic{value: r, stack: debug.Stack()}}
+			}
+		}()
+		done <- outcome{result: handle()}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, errCheckTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// logCheckFailure logs a timeout or panic with the checks that finished
+// before it.
+func logCheckFailure(logger *log.Logger, toolName string, timeout time.Duration, engine *checks.Engine, err error) {
+	var finished []string
+	for _, t := range engine.Timings() {
+		finished = append(finished, fmt.Sprintf("%s %dms", t.Check, t.Elapsed.Milliseconds()))
+	}
+	if len(finished) == 0 {
+		finished = append(finished, "none")
+	}
+
+	var p *checkPanic
+	if errors.As(err, &p) {
+		logger.Printf("[PANIC] %s: %v (finished: %s)\n%s", toolName, p.value, strings.Join(finished, ", "), p.stack)
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		logger.Printf("[CANCELLED] %s: checks were cancelled (finished: %s)", toolName, strings.Join(finished, ", "))
+		return
+	}
+	logger.Printf("[TIMEOUT] %s: checks did not finish in %dms (finished: %s)", toolName, timeout.Milliseconds(), strings.Join(finished, ", "))
+}
+
+// checkFailure decides a tool call whose checks timed out or were
+// cancelled, by performance.on_timeout, or panicked, by on_internal_error.
+func checkFailure(toolName string, timeout time.Duration, err error, cfg *config.SecurityConfig) *checks.CheckResult {
+	var p *checkPanic
+	if errors.As(err, &p) {
+		return internalError(fmt.Sprintf("Security checks for %s failed with an internal error", toolName), checks.RuleInternalPanic, cfg)
+	}
+	return failResult(cfg.Performance.OnTimeout, "performance", checks.RuleInternalTimeout,
+		fmt.Sprintf("Security checks for %s did not finish in %dms", toolName, timeout.Milliseconds()),
+		"The call could not be checked. Tell the user what you are running, or try a simpler call.",
+		cfg)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/decoy"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+const defaultDecoyPath = ".env.production"
+
+// runDecoy implements `guardian decoy install [PATH]` and `guardian decoy list`.
+func runDecoy(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: guardian decoy install [PATH]  |  guardian decoy list")
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	projectRoot := cfg.Directories.ProjectRoot
+	if projectRoot == "" {
+		projectRoot = parsers.GetProjectRoot()
+	}
+
+	registry, err := decoy.Load(decoy.RegistryPath(cfg, projectRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian decoy: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "list":
+		if len(registry.Decoys) == 0 {
+			fmt.Println("No decoys installed")
+			return 0
+		}
+		for _, d := range registry.Decoys {
+			state := "ok"
+			if _, err := os.Stat(filepath.Join(projectRoot, d.Path)); err != nil {
+				state = "MISSING"
+			}
+			fmt.Printf("%-8s %s (installed %s)\n", state, d.Path, d.Installed)
+		}
+		return 0
+
+	case "install":
+		rel := defaultDecoyPath
+		if len(args) > 1 {
+			rel = args[1]
+		}
+		if filepath.IsAbs(rel) {
+			r, err := filepath.Rel(projectRoot, rel)
+			if err != nil || strings.HasPrefix(r, "..") {
+				fmt.Fprintf(os.Stderr, "guardian decoy: %s is outside the project\n", rel)
+				return 1
+			}
+			rel = r
+		}
+
+		d, err := registry.Install(projectRoot, rel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian decoy: %v\n", err)
+			return 1
+		}
+		if err := registry.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "guardian decoy: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Installed decoy %s with %d canary values\n", d.Path, len(d.Canaries))
+		fmt.Println("Keep it out of git (add it to .gitignore) and don't mention it to the agent.")
+		if !cfg.Decoys.Enabled {
+			fmt.Println("Note: decoys.enabled is false in the config, access is not checked.")
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "guardian decoy: unknown subcommand %q\n", args[0])
+	return 2
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/hardening"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// doctorTools are the tools whose handlers doctor builds to find the
+// config entries checks skip.
+var doctorTools = []string{"Bash", "Read", "Write", "Edit", "NotebookEdit", "Glob", "Grep", "WebFetch", "Task", "WebSearch", "SlashCommand", "BashOutput"}
+
+// doctorReport is what `guardian doctor --json` prints.
+type doctorReport struct {
+	ConfigPath    string                 `json:"config_path,omitempty"`
+	ConfigVersion string                 `json:"config_version"`
+	Invalid       []string               `json:"invalid,omitempty"`  // config.Validate
+	Skipped       []checks.InternalError `json:"skipped,omitempty"`  // entries the checks skip
+	Warnings      []string               `json:"warnings,omitempty"` // session setup
+	Errors        map[string]int         `json:"errors,omitempty"`   // internal errors in recorded sessions, by kind
+	Sessions      int                    `json:"sessions"`
+	LastErrors    []string               `json:"last_errors,omitempty"`
+}
+
+// healthy reports whether doctor found nothing to fix.
+func (r *doctorReport) healthy() bool {
+	return len(r.Invalid) == 0 && len(r.Skipped) == 0 && len(r.Warnings) == 0 && len(r.Errors) == 0
+}
+
+// runDoctor implements `guardian doctor [--json]`: config problems, config
+// entries the checks skip (regexps that don't compile, invalid custom
+// rules), setup warnings, and the internal errors recorded sessions ran
+// into. Exit 1 when there is anything to fix.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	report := buildDoctorReport(config.FindConfigPath())
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printDoctor(report)
+	}
+	if !report.healthy() {
+		return 1
+	}
+	return 0
+}
+
+// buildDoctorReport inspects the config at configPath (see
+// config.FindConfigPath) and the sessions recorded for its project. A
+// config that fails to load is named among the warnings, and the defaults
+// it leaves in effect are checked.
+func buildDoctorReport(configPath string) *doctorReport {
+	cfg, loadErr := config.LoadConfig(configPath)
+	report := &doctorReport{ConfigPath: configPath, ConfigVersion: cfg.Version}
+
+	if err := config.Validate(cfg); err != nil {
+		report.Invalid = strings.Split(err.Error(), "\n")
+	}
+
+	// Building the handlers compiles every pattern they use
+	engine := checks.NewEngine(cfg)
+	for _, tool := range doctorTools {
+		getHandler(tool, engine)
+	}
+	// Patterns that don't compile are among the setup warnings, with their key
+	for _, e := range engine.Errors() {
+		if e.Kind != checks.ErrorPattern {
+			report.Skipped = append(report.Skipped, e)
+		}
+	}
+
+	projectRoot := projectPath(cfg, ".")
+	report.Warnings = hardening.Inspect(cfg, configPath, loadErr, projectRoot).Warnings()
+
+	// Sessions in progress, then finished ones
+	report.Errors = make(map[string]int)
+	for _, s := range state.ListSessions(projectPath(cfg, cfg.MassModification.StateFile)) {
+		report.Sessions++
+		for kind, n := range s.Errors {
+			report.Errors[kind] += n
+		}
+		for _, e := range s.LastErrors {
+			report.LastErrors = appendUnique(report.LastErrors, e)
+		}
+	}
+	summaries, err := state.LoadSummaries(projectPath(cfg, cfg.SessionSummary.Store))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian doctor: %v\n", err)
+	}
+	for _, s := range summaries {
+		report.Sessions++
+		for kind, n := range s.Errors {
+			report.Errors[kind] += n
+		}
+	}
+	if len(report.Errors) == 0 {
+		report.Errors = nil
+	}
+	return report
+}
+
+// printDoctor prints the report as text.
+func printDoctor(r *doctorReport) {
+	path := r.ConfigPath
+	if path == "" {
+		path = "built-in defaults"
+	}
+	fmt.Printf("config: %s (%s)\n", path, r.ConfigVersion)
+	for _, line := range r.Invalid {
+		fmt.Printf("  invalid: %s\n", line)
+	}
+	for _, e := range r.Skipped {
+		fmt.Printf("  skipped by the checks: %s\n", e)
+	}
+	for _, w := range r.Warnings {
+		fmt.Printf("  warning: %s\n", w)
+	}
+
+	fmt.Printf("\nInternal errors in %d recorded sessions:", r.Sessions)
+	if len(r.Errors) == 0 {
+		fmt.Println(" none")
+	} else {
+		kinds := make([]string, 0, len(r.Errors))
+		for kind := range r.Errors {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Printf(" %s x%d", kind, r.Errors[kind])
+		}
+		fmt.Println()
+		for _, e := range r.LastErrors {
+			fmt.Printf("  %s\n", e)
+		}
+	}
+
+	if r.healthy() {
+		fmt.Println("\nNo problems found.")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+func TestDoctorConfigLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		warned  bool
+	}{
+		{"malformed", "a: [\n", true},
+		{"wrong type", "strict_config: [1]\n", true},
+		{"valid", "strict_config: false\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("CLAUDE_PROJECT_DIR", root)
+			path := filepath.Join(root, "security_config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			report := buildDoctorReport(path)
+			warned := false
+			for _, w := range report.Warnings {
+				if strings.Contains(w, "failed to load") {
+					warned = true
+				}
+			}
+			if warned != tt.warned {
+				t.Errorf("failed to load warning = %v, want %v (warnings: %q)", warned, tt.warned, report.Warnings)
+			}
+			if tt.warned && report.healthy() {
+				t.Error("report is healthy with a config that failed to load")
+			}
+		})
+	}
+}
+
+// TestStrictConfigFailure checks that strict_config fails closed on a
+// config that doesn't load, read from the file itself.
+func TestStrictConfigFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    checks.PermissionDecision // "": no failure
+	}{
+		{"broken strict", "strict_config: true\na: [\n", checks.DecisionDeny},
+		{"broken lax", "strict_config: false\na: [\n", ""},
+		{"broken unset", "a: [\n", ""},
+		{"strict bad pattern", "strict_config: true\nsensitive_files:\n  code_patterns:\n    - pattern: 'open\\((.*'\n", checks.DecisionDeny},
+		{"strict fine", "strict_config: true\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "security_config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadConfig(path)
+			result := strictConfigFailure(cfg, path, err, config.PatternErrors(cfg))
+			switch {
+			case tt.want == "" && result != nil:
+				t.Errorf("failure = %s (%s), want none", result.PermissionDecisionValue(), result.Reason)
+			case tt.want != "" && result == nil:
+				t.Errorf("no failure, want %s", tt.want)
+			case result != nil && (result.PermissionDecisionValue() != tt.want || result.RuleID != checks.RuleInternalConfig):
+				t.Errorf("failure = %s (%s), want %s (%s)", result.PermissionDecisionValue(), result.RuleID, tt.want, checks.RuleInternalConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// maxOfferedExceptions bounds the exception codes waiting to be granted.
+const maxOfferedExceptions = 20
+
+// exceptionPathKeys are the tool input fields naming what a tool other
+// than Bash works on.
+var exceptionPathKeys = []string{"file_path", "notebook_path", "path", "url"}
+
+// exceptionCall identifies a call for an exception: the normalized command
+// and directory of Bash, the tool and path or URL of other tools. It is
+// false for a call with neither.
+func exceptionCall(hookInput HookInput, cfg *config.SecurityConfig) (state.Approval, bool) {
+	if hookInput.ToolName == "Bash" {
+		call := approvalCall(hookInput, cfg)
+		return call, call.Command != ""
+	}
+	call := state.Approval{Tool: hookInput.ToolName, Dir: ".", Time: time.Now()}
+	for _, key := range exceptionPathKeys {
+		if v := handlers.GetString(hookInput.ToolInput, key); v != "" {
+			call.Path = v
+			return call, true
+		}
+	}
+	return call, false
+}
+
+// applyException allows a call the user granted an exception for that
+// covers all the rules that asked or denied. Otherwise a denied call is
+// offered a new code and nil is returned.
+func applyException(hookInput HookInput, result *checks.CheckResult, rules []string, cfg *config.SecurityConfig, logger *log.Logger) *checks.CheckResult {
+	call, ok := exceptionCall(hookInput, cfg)
+	// A timeout or internal error is decided without a traced check
+	if !ok || !containsRule(rules, result.RuleID) {
+		return nil
+	}
+	for _, rule := range rules {
+		if exceptionExcluded(cfg, rule) {
+			return nil
+		}
+	}
+	call.Rules = rules
+
+	approvals := state.LoadApprovals(approvalsPath(cfg))
+	if e, ok := approvals.Excepted(call); ok {
+		logger.Printf("[EXCEPTION] %s: %s (rules: %s, code: %s, until %s)", hookInput.ToolName, exceptionTarget(call), strings.Join(rules, ", "), e.Code, e.Expires.Format(time.RFC3339))
+		return checks.Allow("exception_codes")
+	}
+	if result.PermissionDecisionValue() != checks.DecisionDeny {
+		return nil
+	}
+
+	if call.Code = newExceptionCode(); call.Code == "" {
+		return nil
+	}
+	approvals.Offer(call, maxOfferedExceptions)
+	if err := approvals.Save(); err != nil {
+		logger.Printf("Failed to save approvals: %v", err)
+	}
+	return nil
+}
+
+// offeredException returns the exception code offered for a denied call,
+// or "".
+func offeredException(hookInput HookInput, cfg *config.SecurityConfig) string {
+	call, ok := exceptionCall(hookInput, cfg)
+	if !ok {
+		return ""
+	}
+	return state.LoadApprovals(approvalsPath(cfg)).OfferedCode(call)
+}
+
+// exceptionExcluded reports whether denials of rule get no exception code
+// (exception_codes.excluded_rules, exact or "prefix.*").
+func exceptionExcluded(cfg *config.SecurityConfig, rule string) bool {
+	for _, excluded := range cfg.ExceptionCodes.ExcludedRules {
+		if excluded == rule || strings.HasSuffix(excluded, ".*") && strings.HasPrefix(rule, strings.TrimSuffix(excluded, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// newExceptionCode returns a random 8-character code, or "" if the
+// system has no randomness to give.
+func newExceptionCode() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// exceptionTarget describes the call of an exception for logs and listings.
+func exceptionTarget(call state.Approval) string {
+	if call.Command != "" {
+		return fmt.Sprintf("`%s` in %s", call.Command, call.Dir)
+	}
+	return fmt.Sprintf("%s %s", call.Tool, call.Path)
+}
+
+// containsRule reports whether rules contains rule.
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// runAllow implements `guardian allow CODE [--ttl 30m]` and
+// `guardian allow --list`.
+func runAllow(args []string) int {
+	fs := flag.NewFlagSet("allow", flag.ContinueOnError)
+	list := fs.Bool("list", false, "show granted exceptions and offered codes")
+	ttlFlag := fs.String("ttl", "", "how long the exception lasts (default exception_codes.default_ttl)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// Flags may follow the code: guardian allow 3f9a1c2e --ttl 30m
+	code := fs.Arg(0)
+	if fs.NArg() > 1 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return 2
+		}
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	approvals := state.LoadApprovals(approvalsPath(cfg))
+
+	if *list {
+		now := time.Now()
+		for _, e := range approvals.Entries {
+			if e.Code != "" && now.Before(e.Expires) {
+				fmt.Printf("granted  %s  until %s  %s (%s)\n", e.Code, e.Expires.Format("2006-01-02 15:04"), exceptionTarget(e), strings.Join(e.Rules, ", "))
+			}
+		}
+		for _, o := range approvals.Offered {
+			fmt.Printf("offered  %s  %s  %s (%s)\n", o.Code, o.Time.Format("2006-01-02 15:04"), exceptionTarget(o), strings.Join(o.Rules, ", "))
+		}
+		return 0
+	}
+
+	if code == "" {
+		fmt.Fprintln(os.Stderr, "Usage: guardian allow CODE [--ttl 30m]  |  guardian allow --list")
+		return 2
+	}
+	if !cfg.ExceptionCodes.Enabled {
+		fmt.Fprintln(os.Stderr, "guardian allow: exception_codes is disabled")
+		return 1
+	}
+
+	ttl := *ttlFlag
+	if ttl == "" {
+		ttl = cfg.ExceptionCodes.DefaultTTL
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		fmt.Fprintf(os.Stderr, "guardian allow: invalid --ttl %q\n", ttl)
+		return 2
+	}
+	if max, err := time.ParseDuration(cfg.ExceptionCodes.MaxTTL); err == nil && max > 0 && d > max {
+		fmt.Fprintf(os.Stderr, "guardian allow: --ttl %s is longer than exception_codes.max_ttl (%s)\n", ttl, cfg.ExceptionCodes.MaxTTL)
+		return 2
+	}
+
+	granted, ok := approvals.Grant(code, time.Now().Add(d))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "guardian allow: no pending exception with code %s (codes work once)\n", code)
+		return 1
+	}
+	if err := approvals.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "guardian allow: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Allowed until %s: %s (%s)\n", granted.Expires.Format("15:04"), exceptionTarget(granted), strings.Join(granted.Rules, ", "))
+	return 0
+}
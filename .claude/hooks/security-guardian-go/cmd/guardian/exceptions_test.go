@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+func TestExceptionExcludedDefaults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	for _, rule := range []string{
+		checks.RuleDeletionCritical,
+		checks.RuleDeletionMoveProjectRoot,
+		checks.RuleGitHardBlocked,
+		checks.RuleBypassHardBlocked,
+		checks.RuleBypassSelfException,
+		"tamper.config_write",
+	} {
+		if !exceptionExcluded(cfg, rule) {
+			t.Errorf("%s gets an exception code", rule)
+		}
+	}
+	for _, rule := range []string{checks.RuleDirectoryOutside, checks.RuleSecretsRead} {
+		if exceptionExcluded(cfg, rule) {
+			t.Errorf("%s gets no exception code", rule)
+		}
+	}
+}
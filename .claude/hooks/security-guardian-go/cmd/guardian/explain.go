@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/messages"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// recordForExplain stores an ask/deny with its input and returns the
+// decision ID, or "" if it couldn't be stored.
+func recordForExplain(hookInput HookInput, result *checks.CheckResult, cfg *config.SecurityConfig, logger *log.Logger) string {
+	input, truncated := state.LimitInput(hookInput.ToolInput, cfg.Explain.MaxContentBytes)
+	record := state.DecisionRecord{
+		ID:              state.NewDecisionID(),
+		Time:            time.Now().UTC(),
+		SessionID:       hookInput.SessionID,
+		Tool:            hookInput.ToolName,
+		Input:           input,
+		Truncated:       truncated,
+		Cwd:             hookInput.Cwd,
+		PermissionMode:  hookInput.PermissionMode,
+		Decision:        string(result.PermissionDecisionValue()),
+		GuardianVersion: guardianVersion(),
+		ConfigVersion:   cfg.Version,
+		// Decoy hits are stored as the rule they pose as
+		RuleID: messages.BuildPayload(result, hookInput.ToolInput).RuleID,
+		Reason: result.Reason,
+	}
+	for _, e := range result.Errors {
+		record.Errors = append(record.Errors, e.String())
+	}
+	if err := state.AppendRecord(projectPath(cfg, cfg.Explain.Store), record, cfg.Explain.MaxRecords); err != nil {
+		logger.Printf("Failed to record decision: %v", err)
+		return ""
+	}
+	return record.ID
+}
+
+// runExplain implements `guardian explain --last | --list | <id>`.
+func runExplain(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: guardian explain --last  |  guardian explain <decision-id>  |  guardian explain --list")
+		return 2
+	}
+
+	configPath := config.FindConfigPath()
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian explain: config %s: %v (using defaults)\n", configPath, err)
+		cfg = config.DefaultConfig()
+	}
+
+	records, err := state.LoadRecords(projectPath(cfg, cfg.Explain.Store))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian explain: %v\n", err)
+		return 1
+	}
+	if len(records) == 0 {
+		fmt.Println("No recorded decisions (explain.enabled must be on when the operation is blocked)")
+		return 0
+	}
+
+	var record *state.DecisionRecord
+	switch args[0] {
+	case "--list":
+		for _, r := range records {
+			fmt.Printf("%s  %s  %-8s  %-5s %-10s %s\n", r.ID, r.Time.Local().Format("2006-01-02 15:04:05"), shortSessionID(r.SessionID), r.Decision, r.Tool, r.Reason)
+		}
+		return 0
+	case "--last":
+		record = &records[len(records)-1]
+	default:
+		for i := range records {
+			if records[i].ID == args[0] {
+				record = &records[i]
+			}
+		}
+		if record == nil {
+			fmt.Fprintf(os.Stderr, "guardian explain: no decision %q (see guardian explain --list)\n", args[0])
+			return 1
+		}
+	}
+
+	explainRecord(record, cfg, configPath)
+	return 0
+}
+
+// explainRecord prints a recorded decision and a trace of its replay.
+func explainRecord(record *state.DecisionRecord, cfg *config.SecurityConfig, configPath string) {
+	fmt.Printf("Decision %s  %s\n", record.ID, record.Time.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  %s: %s (rule: %s)\n", record.Decision, record.Reason, record.RuleID)
+	fmt.Printf("  tool: %s  cwd: %s  permission mode: %s\n", record.Tool, record.Cwd, record.PermissionMode)
+	if record.ConfigVersion != "" {
+		fmt.Printf("  guardian: %s  config: %s\n", record.GuardianVersion, record.ConfigVersion)
+	}
+	if record.SessionID != "" {
+		fmt.Printf("  session: %s\n", record.SessionID)
+	}
+	for _, e := range record.Errors {
+		fmt.Printf("  internal error: %s\n", e)
+	}
+
+	keys := make([]string, 0, len(record.Input))
+	for k := range record.Input {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Println("\nInput:")
+	for _, k := range keys {
+		value := fmt.Sprintf("%v", record.Input[k])
+		if lines := strings.Count(value, "\n"); lines > 0 {
+			value = fmt.Sprintf("(%d lines, %d bytes)", lines+1, len(value))
+		}
+		fmt.Printf("  %s: %s\n", k, value)
+	}
+
+	replayCfg, cleanup := replayConfig(cfg, record.SessionID)
+	defer cleanup()
+
+	var steps []handlers.TraceStep
+	// No session ID: the checks read the copied counters as they are
+	hookInput := HookInput{
+		HookEventName:  "PreToolUse",
+		ToolName:       record.Tool,
+		ToolInput:      record.Input,
+		PermissionMode: record.PermissionMode,
+		Cwd:            record.Cwd,
+	}
+	result := processHookInput(context.Background(), hookInput, replayCfg, func(step handlers.TraceStep) {
+		steps = append(steps, step)
+	}, log.New(io.Discard, "", 0))
+
+	fmt.Printf("\nReplay with %s (config %s):\n", configPath, cfg.Version)
+	if len(steps) == 0 {
+		fmt.Println("  no checks ran (whitelisted command, or nothing to check)")
+	}
+	for i, step := range steps {
+		r := step.Result
+		check, ruleID := r.CheckName, r.RuleID
+		// Decoys stay indistinguishable from real secrets files
+		if ruleID == checks.RuleDecoyAccess {
+			check, ruleID = "secrets_check", checks.RuleSecretsRead
+		}
+
+		fmt.Printf("  %2d. %-24s %s", i+1, check, r.PermissionDecisionValue())
+		if ruleID != "" {
+			fmt.Printf("  %s", ruleID)
+			if rule, ok := checks.LookupRule(ruleID); ok {
+				fmt.Printf(" (%s)", rule.Description)
+			}
+		}
+		fmt.Println()
+		if r.IsAllowed() {
+			continue
+		}
+		fmt.Printf("        reason:   %s\n", r.Reason)
+		if r.Pattern != "" {
+			fmt.Printf("        matched:  %s\n", r.Pattern)
+		}
+		if r.Guidance != "" {
+			fmt.Printf("        guidance: %s\n", strings.ReplaceAll(r.Guidance, "\n", "\n                  "))
+		}
+		if step.Decision != r.PermissionDecisionValue() {
+			fmt.Printf("        override: decisions.%s: %s\n", ruleID, step.Decision)
+		}
+	}
+
+	// What the checks decided before the permission mode was applied
+	decided := checks.DecisionAllow
+	for _, step := range steps {
+		if step.Decision == checks.DecisionDeny {
+			decided = checks.DecisionDeny
+			break
+		}
+		if step.Decision == checks.DecisionAsk {
+			decided = checks.DecisionAsk
+		}
+	}
+
+	final := result.PermissionDecisionValue()
+	if decided == checks.DecisionAsk && final == checks.DecisionDeny {
+		fmt.Printf("\nPermission mode %s runs without prompts (yolo_mode: %s): ask is elevated to deny.\n", record.PermissionMode, cfg.YoloMode)
+	}
+	fmt.Printf("\nResult: %s", final)
+	if !result.IsAllowed() {
+		fmt.Printf(" - %s", result.Reason)
+	}
+	fmt.Println()
+
+	if len(record.Truncated) > 0 {
+		fmt.Printf("Note: %s cut to %d bytes when recorded; content checks saw only that part.\n",
+			strings.Join(record.Truncated, ", "), cfg.Explain.MaxContentBytes)
+	}
+	if string(final) != record.Decision {
+		fmt.Println("Note: the replay differs from the recorded decision - the config, files or session counters changed since.")
+	}
+}
+
+// replayConfig returns a copy of cfg for replaying a decision without side
+// effects: no git backups or trash rewrites, no download tracking, and
+// the counters of the session in a temporary copy.
+func replayConfig(cfg *config.SecurityConfig, sessionID string) (*config.SecurityConfig, func()) {
+	c := *cfg
+	c.Git.BackupBeforeDestructive = false
+	c.Trash.Enabled = false
+	c.DownloadProtection.TrackDownloadedExecutables = false
+	// The trace is read after the replay returns, so it must run to the end
+	c.Performance.CheckTimeoutMs = 0
+	c.Performance.ToolTimeoutsMs = nil
+
+	dir, err := os.MkdirTemp("", "guardian-explain-")
+	if err != nil {
+		return &c, func() {}
+	}
+	session := filepath.Join(dir, "session.json")
+	if data, err := os.ReadFile(state.SessionPath(projectPath(cfg, cfg.MassModification.StateFile), sessionID)); err == nil {
+		os.WriteFile(session, data, 0600)
+	}
+	c.MassModification.StateFile = session
+	return &c, func() { os.RemoveAll(dir) }
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/messages"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+)
+
+// failResult is the result for a call that couldn't be checked:
+// decision is allow, ask or deny (anything else asks), then decisions:
+// overrides of rule apply.
+func failResult(decision, checkName, rule, reason, guidance string, cfg *config.SecurityConfig) *checks.CheckResult {
+	var result *checks.CheckResult
+	switch decision {
+	case config.FailAllow:
+		return checks.Allow(checkName)
+	case config.FailDeny:
+		result = checks.Deny(checkName, reason, guidance)
+	default:
+		result = checks.Ask(checkName, reason, guidance)
+	}
+	return policy.ApplyOverrides(result.WithRule(rule), cfg)
+}
+
+// internalError decides a call the hook failed on by on_internal_error.
+// Failing closed names the failure, so the user knows the guardian, not
+// a rule, stopped the call.
+func internalError(reason, rule string, cfg *config.SecurityConfig) *checks.CheckResult {
+	return failResult(cfg.OnInternalError, "internal_error", rule, reason,
+		"Security Guardian failed and could not check this call. Tell the user what you are running; details are in the guardian log.",
+		cfg)
+}
+
+// invalidPatterns denies a call while strict_config is on and the config
+// has patterns that don't compile (errs, see config.PatternErrors).
+func invalidPatterns(errs []error, cfg *config.SecurityConfig) *checks.CheckResult {
+	return failResult(config.FailDeny, "internal_error", checks.RuleInternalConfig,
+		fmt.Sprintf("Security Guardian config has %d patterns that don't compile (strict_config)", len(errs)),
+		"The guardian checks no calls until its config is fixed. Tell the user to run `guardian doctor`; the guardian log names each pattern.",
+		cfg)
+}
+
+// brokenConfig denies a call while strict_config is on and the config
+// fails to load (err, see config.StrictConfigSet).
+func brokenConfig(err error, cfg *config.SecurityConfig) *checks.CheckResult {
+	return failResult(config.FailDeny, "internal_error", checks.RuleInternalConfig,
+		fmt.Sprintf("Security Guardian config failed to load (strict_config): %v", err),
+		"The guardian checks no calls until its config is fixed. Tell the user to run `guardian doctor`; the guardian log has the error.",
+		cfg)
+}
+
+// strictConfigFailure returns the denial strict_config calls for when the
+// config at configPath failed to load (configErr) or has patterns that
+// don't compile, or nil. A config that failed to load is read for
+// strict_config itself: cfg holds the defaults then.
+func strictConfigFailure(cfg *config.SecurityConfig, configPath string, configErr error, patternErrs []error) *checks.CheckResult {
+	switch {
+	case configErr != nil && config.StrictConfigSet(configPath):
+		return brokenConfig(configErr, cfg)
+	case cfg.StrictConfig && len(patternErrs) > 0:
+		return invalidPatterns(patternErrs, cfg)
+	}
+	return nil
+}
+
+// logPatternErrors logs each regexp and glob of cfg that doesn't compile,
+// with its config key, and returns them.
+func logPatternErrors(logger *log.Logger, cfg *config.SecurityConfig) []error {
+	errs := config.PatternErrors(cfg)
+	for _, err := range errs {
+		logger.Printf("[CONFIG] pattern skipped by the checks: %v", err)
+	}
+	return errs
+}
+
+// logInternalErrors logs the internal errors the checks of a call worked
+// around. They are logged whatever logging.log_blocked says: each one
+// means a call was checked less than configured.
+func logInternalErrors(logger *log.Logger, toolName string, errs []checks.InternalError) {
+	for _, e := range errs {
+		logger.Printf("[INTERNAL] %s: %s", toolName, e)
+	}
+}
+
+// emitFailure writes the decision for a call the hook failed on outside
+// the checks (a panic in main) and returns the exit code.
+func emitFailure(result *checks.CheckResult, permissionMode string, cfg *config.SecurityConfig, format *inputFormat) int {
+	result = policy.Resolve(result, cfg, permissionMode)
+	output := HookOutput{PermissionDecision: string(result.PermissionDecisionValue())}
+	switch result.PermissionDecisionValue() {
+	case checks.DecisionDeny:
+		output.Message = messages.FormatBlockMessage(result)
+	case checks.DecisionAsk:
+		output.Message = messages.FormatConfirmMessage(result)
+	}
+	writeOutput(format, output)
+	return 0
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/messages"
+)
+
+// inputFormat is the hook protocol of an agent: how its pre-tool-call
+// payload maps onto HookInput and how a decision is written back.
+// Without --format-in the binary speaks Claude Code's protocol.
+type inputFormat struct {
+	name string
+	// decode maps a payload onto HookInput.
+	decode func(data []byte) (HookInput, error)
+	// encode writes the response for a decision; allow has no message.
+	encode func(w io.Writer, output HookOutput)
+}
+
+// inputFormats lists the protocols of other agents (--format-in).
+var inputFormats = []inputFormat{
+	{"cursor", decodeCursor, encodeCursor},
+	{"generic", decodeGeneric, encodeGeneric},
+}
+
+// parseFormatIn takes a leading --format-in NAME (or --format-in=NAME) off
+// args. format is nil for Claude Code.
+func parseFormatIn(args []string) (format *inputFormat, rest []string, err error) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "--format-in") {
+		return nil, args, nil
+	}
+	name, ok := strings.CutPrefix(args[0], "--format-in=")
+	rest = args[1:]
+	if !ok {
+		if args[0] != "--format-in" || len(args) < 2 {
+			return nil, nil, fmt.Errorf("usage: guardian --format-in cursor|generic|claude")
+		}
+		name, rest = args[1], args[2:]
+	}
+	if name == "claude" {
+		return nil, rest, nil
+	}
+	for i := range inputFormats {
+		if inputFormats[i].name == name {
+			return &inputFormats[i], rest, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unknown input format %q (cursor, generic, claude)", name)
+}
+
+// Cursor hooks (.cursor/hooks.json): beforeShellExecution and
+// beforeReadFile carry the call; beforeMCPExecution an MCP tool.
+type cursorInput struct {
+	HookEventName  string          `json:"hook_event_name"`
+	ConversationID string          `json:"conversation_id"`
+	WorkspaceRoots []string        `json:"workspace_roots"`
+	Command        string          `json:"command"`
+	Cwd            string          `json:"cwd"`
+	FilePath       string          `json:"file_path"`
+	ToolName       string          `json:"tool_name"`
+	ToolInput      json.RawMessage `json:"tool_input"`
+}
+
+type cursorOutput struct {
+	Permission   string `json:"permission"`
+	UserMessage  string `json:"userMessage,omitempty"`
+	AgentMessage string `json:"agentMessage,omitempty"`
+}
+
+// decodeCursor maps a Cursor hook payload. Events that carry no tool call
+// (afterFileEdit, stop, ...) map to a tool no handler checks, so they are
+// allowed.
+func decodeCursor(data []byte) (HookInput, error) {
+	var in cursorInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		return HookInput{}, err
+	}
+	hookInput := HookInput{
+		HookEventName:  "PreToolUse",
+		SessionID:      in.ConversationID,
+		Cwd:            in.Cwd,
+		PermissionMode: "default",
+	}
+	// The first workspace root is the project, as CLAUDE_PROJECT_DIR is
+	// for Claude Code
+	if len(in.WorkspaceRoots) > 0 {
+		if os.Getenv("CLAUDE_PROJECT_DIR") == "" {
+			os.Setenv("CLAUDE_PROJECT_DIR", in.WorkspaceRoots[0])
+		}
+		if hookInput.Cwd == "" {
+			hookInput.Cwd = in.WorkspaceRoots[0]
+		}
+	}
+
+	switch in.HookEventName {
+	case "beforeShellExecution":
+		hookInput.ToolName = "Bash"
+		hookInput.ToolInput = map[string]interface{}{"command": in.Command}
+	case "beforeReadFile":
+		hookInput.ToolName = "Read"
+		hookInput.ToolInput = map[string]interface{}{"file_path": in.FilePath}
+	case "beforeMCPExecution":
+		hookInput.ToolName = "mcp__" + in.ToolName
+		// tool_input is an object or a JSON string holding one
+		var raw interface{}
+		json.Unmarshal(in.ToolInput, &raw)
+		if s, ok := raw.(string); ok {
+			json.Unmarshal([]byte(s), &raw)
+		}
+		hookInput.ToolInput, _ = raw.(map[string]interface{})
+	default:
+		hookInput.ToolName = in.HookEventName
+	}
+	return hookInput, nil
+}
+
+// encodeCursor writes Cursor's permission response: the user sees the
+// reason, the agent the full message with guidance.
+func encodeCursor(w io.Writer, output HookOutput) {
+	out := cursorOutput{Permission: output.PermissionDecision}
+	if output.Message != "" {
+		out.UserMessage, _, _ = strings.Cut(output.Message, "\n")
+		out.AgentMessage = output.Message
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+// Generic JSON for wrapper scripts and agents without their own format:
+// {"tool": "Bash", "input": {"command": "..."}, "cwd": "...", "session_id": "..."}
+// with Claude Code's tool names and input keys.
+type genericInput struct {
+	Tool           string                 `json:"tool"`
+	Input          map[string]interface{} `json:"input"`
+	Cwd            string                 `json:"cwd"`
+	SessionID      string                 `json:"session_id"`
+	PermissionMode string                 `json:"permission_mode"`
+}
+
+type genericOutput struct {
+	Decision string            `json:"decision"`
+	Message  string            `json:"message,omitempty"`
+	Payload  *messages.Payload `json:"payload,omitempty"`
+	// ConfigVersion identifies the config that decided (guardian serve).
+	ConfigVersion string `json:"config_version,omitempty"`
+}
+
+// decodeGeneric maps a generic payload; every call is a PreToolUse.
+func decodeGeneric(data []byte) (HookInput, error) {
+	var in genericInput
+	if err := json.Unmarshal(data, &in); err != nil {
+		return HookInput{}, err
+	}
+	if in.Tool == "" {
+		return HookInput{}, fmt.Errorf("no tool in the input")
+	}
+	return HookInput{
+		HookEventName:  "PreToolUse",
+		ToolName:       in.Tool,
+		ToolInput:      in.Input,
+		Cwd:            in.Cwd,
+		SessionID:      in.SessionID,
+		PermissionMode: in.PermissionMode,
+	}, nil
+}
+
+// encodeGeneric writes the decision, message and structured payload.
+func encodeGeneric(w io.Writer, output HookOutput) {
+	json.NewEncoder(w).Encode(genericOutput{
+		Decision: output.PermissionDecision,
+		Message:  output.Message,
+		Payload:  output.Payload,
+	})
+}
+
+// writeOutput writes a decision in the protocol of format. Claude Code
+// takes no output as allow.
+func writeOutput(format *inputFormat, output HookOutput) {
+	if format != nil {
+		format.encode(os.Stdout, output)
+		return
+	}
+	if output.PermissionDecision == "allow" {
+		return
+	}
+	json.NewEncoder(os.Stdout).Encode(output)
+}
+
+// rewriteUnsupported asks about a call allowed with rewritten input (rm
+// moved to trash) for callers that can only take it as it is.
+func rewriteUnsupported() HookOutput {
+	return HookOutput{
+		PermissionDecision: "ask",
+		Message:            "CONFIRM: Security Guardian would move this deletion to trash, which this agent doesn't support. Run it as is?",
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/gitbackup"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// runGitBackups implements `guardian git-backups [--prune <age>]`.
+func runGitBackups(args []string) int {
+	fs := flag.NewFlagSet("git-backups", flag.ContinueOnError)
+	dir := fs.String("C", "", "repository directory (default: project root)")
+	prune := fs.Duration("prune", 0, "delete backups older than this (e.g. 168h)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *dir == "" {
+		*dir = parsers.GetProjectRoot()
+	}
+
+	if *prune > 0 {
+		removed, err := gitbackup.Prune(*dir, *prune)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian git-backups: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Deleted %d backup ref(s)\n", removed)
+		return 0
+	}
+
+	backups, err := gitbackup.List(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian git-backups: %v\n", err)
+		return 1
+	}
+	if len(backups) == 0 {
+		fmt.Println("No git backups")
+		return 0
+	}
+	for _, b := range backups {
+		fmt.Printf("%s  %s  %s\n", b.Ref, b.Date.Local().Format(time.RFC3339), b.Subject)
+	}
+	fmt.Println("\nRestore files: git checkout <ref> -- .   Inspect: git diff <ref>")
+	return 0
+}
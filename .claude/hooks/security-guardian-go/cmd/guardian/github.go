@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// writeGitHubAnnotations writes violations as GitHub Actions workflow
+// commands, shown as annotations on the changed lines of a pull request:
+// denies as errors, asks as warnings.
+func writeGitHubAnnotations(w io.Writer, violations []scanViolation) {
+	for _, v := range violations {
+		level := "warning"
+		if v.result.PermissionDecisionValue() == checks.DecisionDeny {
+			level = "error"
+		}
+		props := []string{"file=" + escapeGitHubProperty(v.file)}
+		if len(v.result.Details) > 0 {
+			f := v.result.Details[0]
+			props = append(props, fmt.Sprintf("line=%d", f.Line), fmt.Sprintf("col=%d", f.Column))
+		}
+		props = append(props, "title="+escapeGitHubProperty("security-guardian: "+v.result.RuleID))
+
+		message := v.result.Reason
+		if len(v.result.Details) > 1 {
+			message += fmt.Sprintf(" (%d matches)", len(v.result.Details))
+		}
+		fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), escapeGitHubData(message))
+	}
+}
+
+// writeGitHubSummary appends a Markdown table of violations by rule to
+// $GITHUB_STEP_SUMMARY, shown on the workflow run page. Outside Actions
+// it does nothing.
+func writeGitHubSummary(violations []scanViolation, files int) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	b.WriteString("### Security Guardian scan\n\n")
+	if len(violations) == 0 {
+		fmt.Fprintf(&b, "%d files scanned, no violations.\n", files)
+		_, err := f.WriteString(b.String())
+		return err
+	}
+
+	type ruleRow struct {
+		id, decision string
+		files        []string
+	}
+	rows := make(map[string]*ruleRow)
+	for _, v := range violations {
+		row, ok := rows[v.result.RuleID]
+		if !ok {
+			row = &ruleRow{id: v.result.RuleID, decision: string(v.result.PermissionDecisionValue())}
+			rows[v.result.RuleID] = row
+		}
+		row.files = append(row.files, v.file)
+	}
+	ids := make([]string, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintf(&b, "%d files scanned, %d violations.\n\n", files, len(violations))
+	b.WriteString("| Rule | Decision | Files |\n|---|---|---|\n")
+	for _, id := range ids {
+		row := rows[id]
+		shown := row.files
+		if len(shown) > 5 {
+			shown = append(shown[:5:5], fmt.Sprintf("and %d more", len(row.files)-5))
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", id, row.decision, strings.ReplaceAll(strings.Join(shown, ", "), "|", `\|`))
+	}
+	_, err = f.WriteString(b.String())
+	return err
+}
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
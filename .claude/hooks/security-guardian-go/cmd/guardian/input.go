@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// Header fields of an input too large to parse. Claude Code writes them
+// before tool_input.
+var inputFieldPattern = regexp.MustCompile(`"(hook_event_name|tool_name|permission_mode|cwd|session_id)"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+
+// sniffLimit is how much of an oversized input is searched for its header.
+const sniffLimit = 64 * 1024
+
+// readHookInput reads at most limit bytes of hook input (no limit if
+// limit <= 0). oversized reports that there was more.
+func readHookInput(r io.Reader, limit int) (data []byte, oversized bool, err error) {
+	if limit <= 0 {
+		data, err = io.ReadAll(r)
+		return data, false, err
+	}
+	data, err = io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if len(data) > limit {
+		return data[:limit], true, err
+	}
+	return data, false, err
+}
+
+// sniffHookInput recovers the event, tool and session fields of an input
+// cut at the size limit; tool_input is lost.
+func sniffHookInput(data []byte) HookInput {
+	if len(data) > sniffLimit {
+		data = data[:sniffLimit]
+	}
+	var input HookInput
+	for _, m := range inputFieldPattern.FindAllSubmatch(data, -1) {
+		value := string(m[2])
+		switch string(m[1]) {
+		case "hook_event_name":
+			input.HookEventName = value
+		case "tool_name":
+			input.ToolName = value
+		case "permission_mode":
+			input.PermissionMode = value
+		case "cwd":
+			input.Cwd = value
+		case "session_id":
+			input.SessionID = value
+		}
+	}
+	return input
+}
+
+// oversizedInput decides a tool call larger than input_limits.max_input_bytes,
+// which the checks never saw.
+func oversizedInput(hookInput HookInput, cfg *config.SecurityConfig) *checks.CheckResult {
+	limits := cfg.InputLimits
+	if limits.OnOversized == config.OversizedAllow {
+		return checks.Allow("input_limits")
+	}
+	tool := hookInput.ToolName
+	if tool == "" {
+		tool = "tool"
+	}
+	return checks.Ask("input_limits",
+		fmt.Sprintf("%s call larger than %d bytes was not checked", tool, limits.MaxInputBytes),
+		"The input is too large for the security checks. Tell the user what you are writing or running, or split it into smaller calls.",
+	).WithRule(checks.RuleInputOversized)
+}
@@ -0,0 +1,502 @@
+// Package main provides the CLI entry point for Security Guardian.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/decoy"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/messages"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+)
+
+// HookInput represents the input from Claude Code hooks.
+type HookInput struct {
+	HookEventName  string                 `json:"hook_event_name"`
+	ToolName       string                 `json:"tool_name"`
+	ToolInput      map[string]interface{} `json:"tool_input"`
+	ToolResponse   interface{}            `json:"tool_response"`
+	PermissionMode string                 `json:"permission_mode"`
+	Cwd            string                 `json:"cwd"`
+	SessionID      string                 `json:"session_id"`
+	Reason         string                 `json:"reason"` // SessionEnd
+	Source         string                 `json:"source"` // SessionStart
+}
+
+// HookOutput represents the output for Claude Code hooks.
+type HookOutput struct {
+	PermissionDecision string            `json:"permissionDecision"`
+	Message            string            `json:"message,omitempty"`
+	Details            []checks.Finding  `json:"details,omitempty"`
+	Payload            *messages.Payload `json:"payload,omitempty"`
+}
+
+// PostToolUseOutput feeds a warning about tool output back to Claude.
+type PostToolUseOutput struct {
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// UpdatedInputOutput allows a tool call with rewritten input.
+type UpdatedInputOutput struct {
+	HookSpecificOutput struct {
+		HookEventName            string                 `json:"hookEventName"`
+		PermissionDecision       string                 `json:"permissionDecision"`
+		PermissionDecisionReason string                 `json:"permissionDecisionReason,omitempty"`
+		UpdatedInput             map[string]interface{} `json:"updatedInput"`
+	} `json:"hookSpecificOutput"`
+}
+
+func main() {
+	// Other agents' hook protocols (guardian --format-in cursor)
+	format, args, err := parseFormatIn(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Subcommands (guardian trash ...); without arguments run as a hook
+	if len(args) > 0 {
+		os.Exit(runCommand(args))
+	}
+
+	// Load configuration
+	configPath := config.FindConfigPath()
+	// A config that fails to load leaves the defaults in effect
+	cfg, configErr := config.LoadConfig(configPath)
+
+	messages.SetLanguage(cfg.Messages.Language)
+
+	// Setup logging
+	logger := setupLogging(cfg)
+	if configErr != nil {
+		logger.Printf("[CONFIG] %s failed to load, running with built-in defaults: %v", configPath, configErr)
+	}
+
+	// A bug outside the checks is decided like any internal error
+	var hookInput HookInput
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Printf("[PANIC] %v (on_internal_error: %s)\n%s", r, cfg.OnInternalError, debug.Stack())
+			flushLogs()
+			os.Exit(emitFailure(internalError(fmt.Sprintf("Security Guardian failed: %v", r), checks.RuleInternalPanic, cfg), hookInput.PermissionMode, cfg, format))
+		}
+	}()
+
+	// Read hook input from stdin, bounded so a huge Write isn't buffered whole
+	inputData, oversized, err := readHookInput(os.Stdin, cfg.InputLimits.MaxInputBytes)
+	var inputErr error
+	if err != nil {
+		inputErr = fmt.Errorf("could not read the hook input: %w", err)
+	} else if oversized {
+		hookInput = sniffHookInput(inputData)
+		logger.Printf("[OVERSIZED] %s input over %d bytes (on_oversized: %s)", hookInput.ToolName, cfg.InputLimits.MaxInputBytes, cfg.InputLimits.OnOversized)
+	} else if format != nil {
+		if hookInput, err = format.decode(inputData); err != nil {
+			inputErr = fmt.Errorf("could not parse the %s hook input: %w", format.name, err)
+		}
+	} else if err := json.Unmarshal(inputData, &hookInput); err != nil {
+		inputErr = fmt.Errorf("could not parse the hook input: %w", err)
+	}
+	if inputErr != nil {
+		logger.Printf("[ERROR] %v (on_internal_error: %s)", inputErr, cfg.OnInternalError)
+		// Only a tool call can be held back; other events carry no decision
+		hookInput = sniffHookInput(inputData)
+		if cfg.OnInternalError == config.FailAllow || hookInput.HookEventName != "" && hookInput.HookEventName != "PreToolUse" {
+			writeOutput(format, HookOutput{PermissionDecision: "allow"})
+			os.Exit(0)
+		}
+	}
+
+	// Log lines name the config that decided and tell concurrent
+	// sessions apart
+	logger.SetPrefix(logPrefix(cfg.Version, hookInput.SessionID))
+	logger.SetFlags(logger.Flags() | log.Lmsgprefix)
+
+	// A pattern that doesn't compile leaves its rule off
+	patternErrs := logPatternErrors(logger, cfg)
+
+	// Session lifecycle events carry no tool
+	switch hookInput.HookEventName {
+	case "SessionStart":
+		os.Exit(processSessionStart(hookInput, cfg, configPath, configErr, logger))
+	case "Stop", "SessionEnd":
+		os.Exit(processSessionEvent(hookInput, cfg, logger))
+	}
+
+	// Log all tool calls if enabled (helps diagnose model behavior, e.g. GLM/zclaude)
+	if cfg.Logging.LogAllCalls {
+		logger.Printf("[CALL] %s %s", hookInput.ToolName, sanitizeToolInput(hookInput))
+	}
+
+	// Background shells are audited even when all-calls logging is off
+	if cfg.BackgroundShells.Audit && !cfg.Logging.LogAllCalls &&
+		(hookInput.ToolName == "BashOutput" || hookInput.ToolName == "KillShell") {
+		logger.Printf("[AUDIT] %s %s", hookInput.ToolName, sanitizeToolInput(hookInput))
+	}
+
+	// Tool output screening runs after the tool; output too large to read
+	// can only be logged
+	if hookInput.HookEventName == "PostToolUse" {
+		if oversized {
+			os.Exit(0)
+		}
+		// A pending ask that ran was approved by the user
+		confirmApproval(hookInput, cfg, logger)
+		os.Exit(processPostToolUse(context.Background(), hookInput, cfg, logger))
+	}
+
+	// Process input
+	strictFailure := strictConfigFailure(cfg, configPath, configErr, patternErrs)
+	var result *checks.CheckResult
+	switch {
+	case inputErr != nil:
+		result = policy.Resolve(internalError(fmt.Sprintf("Security Guardian %v", inputErr), checks.RuleInternalError, cfg), cfg, hookInput.PermissionMode)
+	case oversized:
+		result = policy.Resolve(oversizedInput(hookInput, cfg), cfg, hookInput.PermissionMode)
+	case strictFailure != nil:
+		result = policy.Resolve(strictFailure, cfg, hookInput.PermissionMode)
+	default:
+		result = decide(context.Background(), hookInput, cfg, logger)
+	}
+
+	decisionID := recordResult(hookInput, result, cfg, logger)
+
+	switch result.PermissionDecisionValue() {
+	case checks.DecisionDeny, checks.DecisionAsk:
+		writeOutput(format, decisionOutput(hookInput, result, decisionID, cfg))
+		os.Exit(0) // exit 0 so Claude Code processes JSON
+
+	default:
+		// ALLOW with rewritten input (rm -> guardian trash put, dry runs)
+		if result.UpdatedInput != nil && format != nil {
+			// Other agents can't take a rewritten call; the user decides
+			writeOutput(format, rewriteUnsupported())
+			os.Exit(0)
+		}
+		if result.UpdatedInput != nil {
+			var output UpdatedInputOutput
+			output.HookSpecificOutput.HookEventName = "PreToolUse"
+			output.HookSpecificOutput.PermissionDecision = "allow"
+			output.HookSpecificOutput.PermissionDecisionReason = result.Reason
+			if result.Reason == "" {
+				output.HookSpecificOutput.PermissionDecisionReason = "Security Guardian: deletion moved to trash (restore with `guardian trash restore`)"
+			}
+			output.HookSpecificOutput.UpdatedInput = result.UpdatedInput
+			json.NewEncoder(os.Stdout).Encode(output)
+		}
+		// ALLOW - exit 0 (no output unless input was rewritten)
+		writeOutput(format, HookOutput{PermissionDecision: "allow"})
+		os.Exit(0)
+	}
+}
+
+// alertMarkers are the log markers of rules reported through
+// decoys.notify_command besides decoy hits.
+var alertMarkers = map[string]string{
+	checks.RuleCanaryReuse: "CANARY",
+	checks.RuleTamperProbe: "TAMPER",
+}
+
+// recordResult keeps what a decision leaves behind: the explain record,
+// the log line, the session counters, the denied command for background
+// shell output and decoy notifications. It returns the explain ID.
+func recordResult(hookInput HookInput, result *checks.CheckResult, cfg *config.SecurityConfig, logger *log.Logger) string {
+	// Keep asks/denies replayable by `guardian explain`
+	decisionID := ""
+	if !result.IsAllowed() && cfg.Explain.Enabled {
+		decisionID = recordForExplain(hookInput, result, cfg, logger)
+	}
+
+	// Log blocked/denied if enabled
+	if cfg.Logging.LogBlocked && !result.IsAllowed() {
+		if decisionID != "" {
+			logger.Printf("[%s] %s: %s (rule: %s, id: %s)", result.PermissionDecisionValue(), hookInput.ToolName, result.Reason, result.RuleID, decisionID)
+		} else {
+			logger.Printf("[%s] %s: %s (rule: %s)", result.PermissionDecisionValue(), hookInput.ToolName, result.Reason, result.RuleID)
+		}
+	}
+
+	recordDecision(cfg, hookInput.SessionID, result, logger)
+
+	// Remember denied commands to spot them later in background shell output
+	if hookInput.ToolName == "Bash" && result.PermissionDecisionValue() == checks.DecisionDeny {
+		command := handlers.GetString(hookInput.ToolInput, "command")
+		engine := checks.NewEngine(cfg)
+		engine.SessionID = hookInput.SessionID
+		if err := checks.NewBackgroundShellCheck(engine).RecordDenied(command); err != nil {
+			logger.Printf("Failed to record denied command: %v", err)
+		}
+	}
+
+	// Decoy hits are logged and reported regardless of log_blocked
+	if result.RuleID == checks.RuleDecoyAccess {
+		logger.Printf("[DECOY] %s: %s %s", hookInput.ToolName, result.Reason, sanitizeToolInput(hookInput))
+		if err := decoy.Notify(cfg, hookInput.ToolName, result.Reason); err != nil {
+			logger.Printf("[DECOY] notify_command failed: %v", err)
+		}
+	}
+
+	// So are canary hits (guardian text fed back as instructions) and
+	// probes of the guardian itself
+	if marker := alertMarkers[result.RuleID]; marker != "" {
+		logger.Printf("[%s] %s: %s %s", marker, hookInput.ToolName, result.Reason, sanitizeToolInput(hookInput))
+		if err := decoy.Notify(cfg, hookInput.ToolName, result.Reason); err != nil {
+			logger.Printf("[%s] notify_command failed: %v", marker, err)
+		}
+	}
+
+	return decisionID
+}
+
+// decisionOutput builds the message and decision data of an ask or deny.
+func decisionOutput(hookInput HookInput, result *checks.CheckResult, decisionID string, cfg *config.SecurityConfig) HookOutput {
+	if result.PermissionDecisionValue() == checks.DecisionAllow {
+		return HookOutput{PermissionDecision: string(checks.DecisionAllow)}
+	}
+	payload := messages.BuildPayload(result, hookInput.ToolInput)
+	payload.DecisionID = decisionID
+	payload.GuardianVersion = guardianVersion()
+	payload.ConfigVersion = cfg.Version
+	shown := messages.ApplyOverride(result, payload, cfg, hookInput.ToolInput, projectPath(cfg, ""))
+	output := HookOutput{
+		PermissionDecision: string(result.PermissionDecisionValue()),
+		Details:            result.Details,
+		Payload:            payload,
+	}
+	switch result.PermissionDecisionValue() {
+	case checks.DecisionDeny:
+		output.Message = messages.FormatBlockMessage(shown)
+		if cfg.Messages.Remediation {
+			if remedy := messages.Remediation(result, payload, hookInput.ToolInput); remedy != "" {
+				output.Message += "\n" + messages.Translate(remedy)
+			}
+		}
+		if cfg.ExceptionCodes.Enabled {
+			if code := offeredException(hookInput, cfg); code != "" {
+				output.Message += "\n" + messages.Translate(fmt.Sprintf("Exception code: %s. Only if the user wants exactly this allowed, they can run `guardian allow %s --ttl %s`.", code, code, cfg.ExceptionCodes.DefaultTTL))
+			}
+		}
+		if cfg.CanaryTokens.Enabled {
+			if token := canaryToken(cfg, hookInput.SessionID); token != "" {
+				output.Message += "\n" + messages.Translate(fmt.Sprintf("Guardian reference: %s", token))
+			}
+		}
+	case checks.DecisionAsk:
+		output.Message = messages.FormatConfirmMessage(shown)
+	}
+	if cfg.Messages.Structured {
+		output.Message += "\n" + messages.FormatPayload(output.Payload)
+	}
+	return output
+}
+
+// processHookInput processes hook input and returns check result.
+// tracer, if not nil, sees every check result (guardian explain).
+// Checks that panic or overrun performance.check_timeout_ms are logged
+// and decided by performance.on_timeout; cancelling ctx stops them the
+// same way.
+func processHookInput(ctx context.Context, hookInput HookInput, cfg *config.SecurityConfig, tracer handlers.Tracer, logger *log.Logger) *checks.CheckResult {
+	engine := checks.NewEngine(cfg)
+	engine.SessionID = hookInput.SessionID
+
+	// Canary tokens are looked for in the input of every tool, handled or not
+	if canary := checks.NewCanaryCheck(engine).CheckInput(hookInput.ToolInput); !canary.IsAllowed() {
+		base := &handlers.BaseHandler{ToolName: hookInput.ToolName, Config: cfg, Engine: engine, Tracer: tracer}
+		return policy.Resolve(base.Resolve(canary), cfg, hookInput.PermissionMode)
+	}
+
+	handler := getHandler(hookInput.ToolName, engine)
+	if handler == nil {
+		// Tool not handled, allow by default
+		return checks.Allow("unknown")
+	}
+
+	// Relative paths are relative to the session's cwd, which differs
+	// from project root after Claude cd-s into a subdirectory
+	if filepath.IsAbs(hookInput.Cwd) {
+		handler.SetWorkDir(hookInput.Cwd)
+	}
+	if tracer != nil {
+		handler.SetTracer(tracer)
+	}
+
+	timeout := checkTimeout(cfg, hookInput.ToolName)
+	ctx, cancel := checkContext(ctx, timeout)
+	defer cancel()
+	engine.SetContext(ctx)
+	result, err := runHandler(ctx, func() *checks.CheckResult {
+		return handler.Handle(hookInput.ToolInput)
+	})
+	if err != nil {
+		logCheckFailure(logger, hookInput.ToolName, timeout, engine, err)
+		result = checkFailure(hookInput.ToolName, timeout, err, cfg)
+	}
+	internal := engine.Errors()
+	logInternalErrors(logger, hookInput.ToolName, internal)
+
+	// Run the preview of a command that needs confirmation
+	if bash, ok := handler.(*handlers.BashHandler); ok && result.NeedsConfirmation() && cfg.DryRun.Enabled &&
+		(!cfg.DryRun.OnlyWhenDenied || config.IsYoloMode(cfg.YoloMode, hookInput.PermissionMode) || config.IsUnattended(cfg)) {
+		if preview := bash.DryRun(hookInput.ToolInput, result); preview != nil {
+			logger.Printf("[DRY-RUN] Bash: %s (rule: %s)", preview.UpdatedInput["command"], result.RuleID)
+			preview.Errors = internal
+			return preview
+		}
+	}
+
+	result = policy.Resolve(result, cfg, hookInput.PermissionMode)
+	result.Errors = internal
+	return result
+}
+
+// processPostToolUse checks tool output and returns the exit code.
+// The tool already ran, so a finding can only warn: "block" shows the
+// reason to Claude alongside the result.
+func processPostToolUse(ctx context.Context, hookInput HookInput, cfg *config.SecurityConfig, logger *log.Logger) int {
+	engine := checks.NewEngine(cfg)
+	engine.SessionID = hookInput.SessionID
+	handler, ok := getHandler(hookInput.ToolName, engine).(handlers.ResponseHandler)
+	if !ok {
+		return 0
+	}
+	if filepath.IsAbs(hookInput.Cwd) {
+		handler.SetWorkDir(hookInput.Cwd)
+	}
+
+	timeout := checkTimeout(cfg, hookInput.ToolName)
+	ctx, cancel := checkContext(ctx, timeout)
+	defer cancel()
+	engine.SetContext(ctx)
+	result, err := runHandler(ctx, func() *checks.CheckResult {
+		return handler.HandleResponse(hookInput.ToolInput, hookInput.ToolResponse)
+	})
+	if err != nil {
+		// The output is already in the session; nothing to decide
+		logCheckFailure(logger, hookInput.ToolName, timeout, engine, err)
+		return 0
+	}
+	logInternalErrors(logger, hookInput.ToolName, engine.Errors())
+	if result.IsAllowed() {
+		return 0
+	}
+
+	if cfg.Logging.LogBlocked {
+		logger.Printf("[post] %s: %s (rule: %s)", hookInput.ToolName, result.Reason, result.RuleID)
+	}
+	payload := messages.BuildPayload(result, hookInput.ToolInput)
+	json.NewEncoder(os.Stdout).Encode(PostToolUseOutput{
+		Decision: "block",
+		Reason:   messages.FormatWarningMessage(messages.ApplyOverride(result, payload, cfg, hookInput.ToolInput, projectPath(cfg, ""))),
+	})
+	return 0
+}
+
+// getHandler returns appropriate handler for tool. Its checks share engine.
+func getHandler(toolName string, engine *checks.Engine) handlers.ToolHandler {
+	switch toolName {
+	case "Bash":
+		return handlers.NewBashHandler(engine)
+	case "Read":
+		return handlers.NewReadHandler(engine)
+	case "Write":
+		return handlers.NewWriteHandler(engine)
+	case "Edit":
+		return handlers.NewEditHandler(engine)
+	case "NotebookEdit":
+		return handlers.NewNotebookEditHandler(engine)
+	case "Glob":
+		return handlers.NewGlobGrepHandler(engine)
+	case "Grep":
+		return handlers.NewGrepHandler(engine)
+	case "WebFetch":
+		return handlers.NewWebFetchHandler(engine)
+	case "Task":
+		return handlers.NewTaskHandler(engine)
+	case "WebSearch":
+		return handlers.NewWebSearchHandler(engine)
+	case "SlashCommand":
+		return handlers.NewSlashCommandHandler(engine)
+	case "BashOutput", "KillShell":
+		return handlers.NewBackgroundShellHandler(engine, toolName)
+	default:
+		return nil
+	}
+}
+
+// sanitizeToolInput returns a short, safe representation of tool input for logging.
+// Truncates long values (file content) and masks sensitive patterns.
+func sanitizeToolInput(input HookInput) string {
+	parts := make([]string, 0, len(input.ToolInput))
+	for k, v := range input.ToolInput {
+		s := fmt.Sprintf("%v", v)
+		// Truncate long values (e.g. file content in Write tool)
+		if len(s) > 200 {
+			s = s[:200] + "..."
+		}
+		parts = append(parts, fmt.Sprintf("%s=%q", k, s))
+	}
+	if len(parts) == 0 {
+		return "{}"
+	}
+	return "{" + fmt.Sprintf("%s", joinStrings(parts, ", ")) + "}"
+}
+
+// joinStrings joins strings with separator (avoids importing strings package).
+func joinStrings(ss []string, sep string) string {
+	result := ""
+	for i, s := range ss {
+		if i > 0 {
+			result += sep
+		}
+		result += s
+	}
+	return result
+}
+
+// logOutput is the open log file, synced on Stop/SessionEnd.
+var logOutput *os.File
+
+// flushLogs syncs the log file to disk.
+func flushLogs() {
+	if logOutput != nil {
+		logOutput.Sync()
+	}
+}
+
+// setupLogging sets up logging based on configuration.
+func setupLogging(cfg *config.SecurityConfig) *log.Logger {
+	logger := log.New(io.Discard, "", 0)
+
+	if !cfg.Logging.Enabled {
+		return logger
+	}
+
+	// Expand log directory path
+	logDir := os.ExpandEnv(cfg.Logging.LogDirectory)
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return logger
+	}
+
+	// Create log file with date
+	logFile := filepath.Join(logDir, fmt.Sprintf("security-guardian-%s.log", time.Now().Format("2006-01-02")))
+
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return logger
+	}
+
+	logOutput = f
+	logger = log.New(f, "", log.LstdFlags)
+	return logger
+}
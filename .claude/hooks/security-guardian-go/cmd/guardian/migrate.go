@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// runMigrateConfig implements `guardian migrate-config <old.yaml> [-o file]`.
+func runMigrateConfig(args []string) int {
+	fs := flag.NewFlagSet("migrate-config", flag.ContinueOnError)
+	output := fs.String("o", "", "write the new config to this file instead of stdout")
+	force := fs.Bool("force", false, "overwrite the -o file if it exists")
+	// Allow flags after the path
+	var path string
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		path, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if path == "" && fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: guardian migrate-config <old.yaml> [-o security_config.yaml] [--force]")
+		fmt.Fprintln(os.Stderr, "  old.yaml is the Python guardian config, usually .claude/hooks/security-guardian/config/security_config.yaml")
+		return 2
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian migrate-config: %v\n", err)
+		return 1
+	}
+	migrated, warnings, err := config.MigratePython(data)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian migrate-config: %s: %v\n", path, err)
+		return 1
+	}
+
+	if *output == "" {
+		os.Stdout.Write(migrated)
+		return 0
+	}
+	if _, err := os.Stat(*output); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "guardian migrate-config: %s exists (use --force to overwrite)\n", *output)
+		return 1
+	}
+	if err := os.WriteFile(*output, migrated, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "guardian migrate-config: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	return 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+)
+
+// runPolicy implements `guardian policy [--markdown]`.
+func runPolicy(args []string) int {
+	fs := flag.NewFlagSet("policy", flag.ContinueOnError)
+	markdown := fs.Bool("markdown", false, "render as a Markdown section for CLAUDE.md")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	yolo := config.IsYoloMode(cfg.YoloMode, "default")
+	digest := policy.BuildDigest(cfg, projectPath(cfg, "."), yolo)
+	if *markdown {
+		fmt.Print(digest.Markdown())
+	} else {
+		fmt.Println(digest.Text())
+	}
+	return 0
+}
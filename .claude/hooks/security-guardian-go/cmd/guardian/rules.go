@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+)
+
+// runRules implements `guardian rules [list] [--all] [--json]`.
+func runRules(args []string) int {
+	if len(args) > 0 && args[0] == "list" {
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include rules that are switched off")
+	asJSON := fs.Bool("json", false, "print as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	configPath := config.FindConfigPath()
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian rules: config %s: %v (showing defaults)\n", configPath, err)
+		cfg = config.DefaultConfig()
+	}
+	sources, err := config.LoadSources(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian rules: %v\n", err)
+	}
+
+	rules := policy.EffectiveRules(cfg, sources)
+	if !*all {
+		active := rules[:0]
+		for _, r := range rules {
+			if r.Active {
+				active = append(active, r)
+			}
+		}
+		rules = active
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rules)
+		return 0
+	}
+
+	fmt.Printf("Config: %s (keys not set there use built-in defaults)\n", configPath)
+	if config.IsYoloMode(cfg.YoloMode, "bypassPermissions") {
+		fmt.Printf("yolo_mode: %s - with --dangerously-skip-permissions every ask is a deny\n", cfg.YoloMode)
+	}
+
+	for _, r := range rules {
+		state := string(r.Decision)
+		if !r.Active {
+			state = "off"
+		}
+		fmt.Printf("\n%-40s %-5s %s\n", r.ID, state, r.Description)
+		if r.Source != "built-in" {
+			fmt.Printf("    decision: %s (built-in %s) from %s\n", r.Decision, r.BuiltIn, r.Source)
+		}
+		if r.Switch != "" {
+			fmt.Printf("    switch:   %s\n", r.Switch)
+		}
+		for _, e := range r.Entries {
+			fmt.Printf("    %s: %s  (%s)\n", e.Key, e.Value, e.Source)
+		}
+	}
+
+	// Overrides for rule IDs that don't exist never apply
+	custom := make(map[string]bool)
+	for _, r := range cfg.CustomRules {
+		custom[checks.CustomRuleID(r.ID)] = true
+	}
+	var unknown []string
+	for id := range cfg.Decisions {
+		if _, ok := checks.LookupRule(id); !ok && !custom[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		fmt.Printf("\nWarning: decisions.%s (%s) is not a rule ID and has no effect\n", id, sources.Describe("decisions."+id))
+	}
+
+	errs := checks.CustomRuleErrors(cfg)
+	for i := range cfg.CustomRules {
+		if err, ok := errs[i]; ok {
+			key := fmt.Sprintf("custom_rules[%d]", i)
+			fmt.Printf("\nWarning: %s (%s) is skipped: %v\n", key, sources.Describe(key), err)
+		}
+	}
+	return 0
+}
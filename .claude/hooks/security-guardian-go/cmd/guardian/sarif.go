@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// SARIF 2.1.0, the subset GitHub code scanning and editors read.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleDefault  `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+type sarifRuleDefault struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel maps a decision to a SARIF level: denies are errors, asks
+// warnings.
+func sarifLevel(decision checks.PermissionDecision) string {
+	if decision == checks.DecisionDeny {
+		return "error"
+	}
+	return "warning"
+}
+
+// writeSARIF writes violations as a SARIF log. Rules are identified by
+// their rule ID, the same as in decisions: and guardian rules, and
+// described from the rule registry; each finding of a violation is one of
+// its locations.
+func writeSARIF(w io.Writer, violations []scanViolation) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "security-guardian",
+			InformationURI: "https://github.com/artwist-polyakov/polyakov-claude-skills",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, v := range violations {
+		id := v.result.RuleID
+		index, ok := ruleIndex[id]
+		if !ok {
+			rule := sarifRule{ID: id, ShortDescription: sarifMessage{Text: v.result.Reason}}
+			rule.DefaultConfiguration.Level = sarifLevel(v.result.PermissionDecisionValue())
+			if r, ok := checks.LookupRule(id); ok {
+				rule.ShortDescription.Text = r.Description
+				rule.DefaultConfiguration.Level = sarifLevel(r.Decision)
+				rule.Properties = map[string]string{"check": r.Check}
+			}
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		result := sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     sarifLevel(v.result.PermissionDecisionValue()),
+			Message:   sarifMessage{Text: v.result.Reason},
+		}
+		artifact := sarifArtifactLocation{URI: (&url.URL{Path: v.file}).String(), URIBaseID: "%SRCROOT%"}
+		for _, f := range v.result.Details {
+			result.Locations = append(result.Locations, sarifLocation{sarifPhysicalLocation{
+				ArtifactLocation: artifact,
+				Region:           &sarifRegion{StartLine: f.Line, StartColumn: f.Column},
+			}})
+		}
+		if len(result.Locations) == 0 {
+			result.Locations = []sarifLocation{{sarifPhysicalLocation{ArtifactLocation: artifact}}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/handlers"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+)
+
+// scanViolation is a file that a content or secrets rule asks or denies.
+type scanViolation struct {
+	file   string
+	result *checks.CheckResult
+}
+
+// line returns the line of the first finding, 0 if the rule matched the
+// file as a whole.
+func (v scanViolation) line() int {
+	if len(v.result.Details) > 0 {
+		return v.result.Details[0].Line
+	}
+	return 0
+}
+
+// scanner runs the secrets and code content checks on files outside the
+// hook flow.
+type scanner struct {
+	cfg     *config.SecurityConfig
+	root    string
+	secrets *checks.SecretsCheck
+	code    *checks.CodeContentCheck
+	trusted *trust.Manifest // nil when scanning staged content
+}
+
+// runScan implements `guardian scan --staged` and `guardian scan PATH...`.
+// It applies the rules the hook applies to reads and writes of the agent
+// (secrets files, high-entropy content, dangerous code patterns) to files a
+// person is about to commit, and exits 1 when any of them asks or denies.
+func runScan(args []string) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	staged := fs.Bool("staged", false, "scan the files staged for commit, as staged")
+	format := fs.String("format", "text", "output format: text, sarif or github")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *staged == (fs.NArg() > 0) {
+		fmt.Fprintln(os.Stderr, "Usage: guardian scan [--format FORMAT] --staged  |  guardian scan [--format FORMAT] PATH...")
+		return 2
+	}
+	if *format != "text" && *format != "sarif" && *format != "github" {
+		fmt.Fprintf(os.Stderr, "guardian scan: unknown format %q (text, sarif, github)\n", *format)
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian scan: config: %v (using defaults)\n", err)
+		cfg = config.DefaultConfig()
+	}
+	s := newScanner(cfg)
+
+	var violations []scanViolation
+	var files int
+	if *staged {
+		violations, files, err = s.scanStaged()
+	} else {
+		violations, files, err = s.scanPaths(fs.Args())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian scan: %v\n", err)
+		return 2
+	}
+
+	switch *format {
+	case "sarif":
+		if err := writeSARIF(os.Stdout, violations); err != nil {
+			fmt.Fprintf(os.Stderr, "guardian scan: %v\n", err)
+			return 2
+		}
+	case "github":
+		writeGitHubAnnotations(os.Stdout, violations)
+		if err := writeGitHubSummary(violations, files); err != nil {
+			fmt.Fprintf(os.Stderr, "guardian scan: step summary: %v\n", err)
+		}
+	default:
+		printScanText(violations, files)
+	}
+	if len(violations) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printScanText prints a line per violation and the totals.
+func printScanText(violations []scanViolation, files int) {
+	for _, v := range violations {
+		location := v.file
+		if line := v.line(); line > 0 {
+			location = fmt.Sprintf("%s:%d", v.file, line)
+		}
+		fmt.Printf("%-5s %s: %s [%s]\n", strings.ToUpper(string(v.result.PermissionDecisionValue())), location, v.result.Reason, v.result.RuleID)
+	}
+	fmt.Printf("%d files scanned, %d violations\n", files, len(violations))
+}
+
+// newScanner creates the checks for the project of the current directory.
+// Every match is reported, with its line.
+func newScanner(cfg *config.SecurityConfig) *scanner {
+	scanCfg := *cfg
+	scanCfg.DangerousOperations.ReportAllMatches = true
+	engine := checks.NewEngine(&scanCfg)
+	s := &scanner{
+		cfg:     &scanCfg,
+		root:    engine.BoundaryRoot,
+		secrets: checks.NewSecretsCheck(engine),
+		code:    checks.NewCodeContentCheck(engine),
+		trusted: engine.Trusted(),
+	}
+	s.secrets.SetWorkDir(s.root)
+	return s
+}
+
+// scanStaged scans the index versions of added, copied, modified and
+// renamed files.
+func (s *scanner) scanStaged() ([]scanViolation, int, error) {
+	out, err := exec.Command("git", "-C", s.root, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR").Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("git diff --cached: %w", err)
+	}
+	// Reviewed scripts are trusted as they are on disk, not as staged
+	s.trusted = nil
+
+	var violations []scanViolation
+	var files int
+	for _, name := range strings.Split(string(out), "\x00") {
+		if name == "" {
+			continue
+		}
+		content, err := exec.Command("git", "-C", s.root, "cat-file", "blob", ":"+name).Output()
+		if err != nil {
+			// Submodules and the like have no blob
+			continue
+		}
+		files++
+		violations = append(violations, s.scanFile(name, content)...)
+	}
+	return violations, files, nil
+}
+
+// scanPaths scans files and, recursively, directories. .git directories
+// and symlinks are skipped.
+func (s *scanner) scanPaths(paths []string) ([]scanViolation, int, error) {
+	var violations []scanViolation
+	var files int
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files++
+			violations = append(violations, s.scanFile(s.relative(p), content)...)
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return violations, files, nil
+}
+
+// relative returns path relative to the project root when it is inside.
+func (s *scanner) relative(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(s.root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// scanFile checks one file: its name against the secrets patterns, its
+// content for credential-like tokens and, for scripts, dangerous code.
+func (s *scanner) scanFile(name string, content []byte) []scanViolation {
+	var violations []scanViolation
+	add := func(result *checks.CheckResult) {
+		if result = policy.ApplyOverrides(result, s.cfg); !result.IsAllowed() {
+			violations = append(violations, scanViolation{file: name, result: result})
+		}
+	}
+
+	add(s.secrets.CheckPath(name, "read"))
+	add(s.secrets.CheckContentSecrets(name, content))
+
+	if bytes.IndexByte(content, 0) >= 0 {
+		return violations
+	}
+	if !handlers.IsScriptFile(name) && !bytes.HasPrefix(content, []byte("#!")) {
+		return violations
+	}
+	if s.trusted != nil && s.trusted.Lookup(s.root, filepath.Join(s.root, name)) == trust.Trusted {
+		return violations
+	}
+	add(s.code.CheckContent(string(content), name))
+	return violations
+}
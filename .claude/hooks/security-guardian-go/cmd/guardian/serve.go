@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/messages"
+)
+
+// evaluateServer answers POST /v1/evaluate with the decision the hook
+// would make for a tool call.
+type evaluateServer struct {
+	configPath string
+	key        string
+	logger     *log.Logger
+
+	mu     sync.RWMutex
+	policy *servedConfig
+}
+
+// servedConfig is a loaded config and its version (config.Fingerprint,
+// "default" without a file).
+type servedConfig struct {
+	cfg     *config.SecurityConfig
+	version string
+	modTime time.Time
+	size    int64
+}
+
+// runServe implements `guardian serve [--listen addr] [--key-file file]`:
+// the checks as a local HTTP service, for editor extensions and wrapper
+// scripts that can't run a hook process per call.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8765", "address to listen on")
+	keyFile := fs.String("key-file", "", "API key file, created if missing (default: api_key in state_directory)")
+	reloadInterval := fs.Duration("reload-interval", 2*time.Second, "how often the config file is checked for changes, 0: only on SIGHUP")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	configPath := config.FindConfigPath()
+	policy, err := loadServedConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian serve: config %s: %v\n", configPath, err)
+		return 1
+	}
+	cfg := policy.cfg
+	messages.SetLanguage(cfg.Messages.Language)
+
+	if *keyFile == "" {
+		*keyFile = filepath.Join(cfg.StateDirectory, "api_key")
+	}
+	key, err := loadAPIKey(*keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian serve: API key: %v\n", err)
+		return 1
+	}
+
+	host, _, err := net.SplitHostPort(*listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian serve: --listen: %v\n", err)
+		return 2
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		fmt.Fprintf(os.Stderr, "guardian serve: warning: %s is reachable from other hosts\n", *listen)
+	}
+
+	s := &evaluateServer{configPath: configPath, key: key, logger: setupLogging(cfg), policy: policy}
+	s.logger.Printf("[CONFIG] serving %s (config %s)", configPath, policy.version)
+	logPatternErrors(s.logger, cfg)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/evaluate", s.handleEvaluate)
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	go s.watchConfig(*reloadInterval)
+
+	fmt.Fprintf(os.Stderr, "guardian serve: listening on %s (API key in %s, config %s)\n", *listen, *keyFile, policy.version)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "guardian serve: %v\n", err)
+		return 1
+	}
+	flushLogs()
+	return 0
+}
+
+// loadServedConfig loads and validates the config at path. Unlike the hook,
+// which falls back to defaults, a config that doesn't parse is an error.
+func loadServedConfig(path string) (*servedConfig, error) {
+	info, err := os.Stat(path)
+	if path == "" || os.IsNotExist(err) {
+		return &servedConfig{cfg: config.DefaultConfig(), version: "default"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfigFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.StrictConfig {
+		if err := errors.Join(config.PatternErrors(cfg)...); err != nil {
+			return nil, err
+		}
+	}
+	return &servedConfig{
+		cfg:     cfg,
+		version: cfg.Version,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}, nil
+}
+
+// watchConfig reloads the config when its file changes (checked every
+// interval) or on SIGHUP. A config that fails to load or validate is
+// rejected and the served one kept; calls in flight finish with the
+// config they started with.
+func (s *evaluateServer) watchConfig(interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	var tick <-chan time.Time
+	if interval > 0 && s.configPath != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-hup:
+			s.reloadConfig()
+		case <-tick:
+			current := s.current()
+			info, err := os.Stat(s.configPath)
+			if err != nil || info.ModTime().Equal(current.modTime) && info.Size() == current.size {
+				continue
+			}
+			s.reloadConfig()
+		}
+	}
+}
+
+// reloadConfig swaps in the config file if it is valid and changed.
+func (s *evaluateServer) reloadConfig() {
+	current := s.current()
+	next, err := loadServedConfig(s.configPath)
+	if err != nil {
+		s.logger.Printf("[CONFIG] rejected %s, keeping config %s: %v", s.configPath, current.version, err)
+		// Don't retry the same file every tick
+		if info, statErr := os.Stat(s.configPath); statErr == nil {
+			s.mu.Lock()
+			s.policy = &servedConfig{cfg: current.cfg, version: current.version, modTime: info.ModTime(), size: info.Size()}
+			s.mu.Unlock()
+		}
+		return
+	}
+	if next.version == current.version {
+		s.mu.Lock()
+		s.policy = next
+		s.mu.Unlock()
+		return
+	}
+	if next.cfg.Messages.Language != current.cfg.Messages.Language {
+		s.logger.Printf("[CONFIG] messages.language changes on restart")
+	}
+	s.mu.Lock()
+	s.policy = next
+	s.mu.Unlock()
+	s.logger.Printf("[CONFIG] reloaded %s: config %s -> %s", s.configPath, current.version, next.version)
+	logPatternErrors(s.logger, next.cfg)
+}
+
+// current returns the config calls are decided with.
+func (s *evaluateServer) current() *servedConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// loadAPIKey returns GUARDIAN_API_KEY, or the key in path, which is
+// created with a random key readable only by the user.
+func loadAPIKey(path string) (string, error) {
+	if key := os.Getenv("GUARDIAN_API_KEY"); key != "" {
+		return key, nil
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		if key := strings.TrimSpace(string(data)); key != "" {
+			return key, nil
+		}
+		return "", fmt.Errorf("%s is empty", path)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// handleEvaluate decides one tool call. The body is a hook input
+// (?format=cursor or generic for other agents); the response is the
+// decision, message and decision data, as with --format-in generic.
+func (s *evaluateServer) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		httpError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	if !s.authorized(r) {
+		httpError(w, http.StatusUnauthorized, "missing or wrong API key")
+		return
+	}
+
+	var format *inputFormat
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		if format, _, err = parseFormatIn([]string{"--format-in", name}); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	policy := s.current()
+	cfg := policy.cfg
+
+	body := io.Reader(r.Body)
+	if limit := cfg.InputLimits.MaxInputBytes; limit > 0 {
+		body = http.MaxBytesReader(w, r.Body, int64(limit))
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		httpError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	var hookInput HookInput
+	if format != nil {
+		hookInput, err = format.decode(data)
+	} else {
+		err = json.Unmarshal(data, &hookInput)
+	}
+	if err != nil {
+		httpError(w, http.StatusBadRequest, fmt.Sprintf("could not parse the tool call: %v", err))
+		return
+	}
+	if hookInput.HookEventName != "" && hookInput.HookEventName != "PreToolUse" {
+		httpError(w, http.StatusBadRequest, "only PreToolUse calls are evaluated")
+		return
+	}
+
+	// Log lines name the config version that decided the call
+	logger := log.New(s.logger.Writer(), logPrefix(policy.version, hookInput.SessionID), s.logger.Flags()|log.Lmsgprefix)
+	if cfg.Logging.LogAllCalls {
+		logger.Printf("[CALL] %s %s (http)", hookInput.ToolName, sanitizeToolInput(hookInput))
+	}
+
+	result := decide(r.Context(), hookInput, cfg, logger)
+	decisionID := recordResult(hookInput, result, cfg, logger)
+	output := decisionOutput(hookInput, result, decisionID, cfg)
+	if result.UpdatedInput != nil {
+		output = rewriteUnsupported()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(genericOutput{
+		Decision:      output.PermissionDecision,
+		Message:       output.Message,
+		Payload:       output.Payload,
+		ConfigVersion: policy.version,
+	})
+}
+
+// authorized checks the API key: Authorization: Bearer KEY or X-Guardian-Key.
+func (s *evaluateServer) authorized(r *http.Request) bool {
+	key := r.Header.Get("X-Guardian-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = bearer
+	}
+	return key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.key)) == 1
+}
+
+// httpError writes an error as {"error": message}.
+func httpError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
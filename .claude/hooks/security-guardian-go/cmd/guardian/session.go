@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/hardening"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// StopOutput shows a message to the user when Claude finishes a turn.
+type StopOutput struct {
+	SystemMessage string `json:"systemMessage,omitempty"`
+}
+
+// projectPath resolves a config path relative to the project root.
+func projectPath(cfg *config.SecurityConfig, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	root := cfg.Directories.ProjectRoot
+	if root == "" {
+		root = parsers.GetProjectRoot()
+	}
+	return filepath.Join(root, path)
+}
+
+// loadSession loads the state of a session, shared with the mass
+// modification counters.
+func loadSession(cfg *config.SecurityConfig, sessionID string) *state.Session {
+	mm := cfg.MassModification
+	path := state.SessionPath(projectPath(cfg, mm.StateFile), sessionID)
+	return state.LoadSession(path, time.Duration(mm.SessionIdleMinutes)*time.Minute)
+}
+
+// canaryToken returns the canary token of a session, creating and saving
+// it on first use. It is "" if the session can't be saved: a token the
+// canary check won't find is no use.
+func canaryToken(cfg *config.SecurityConfig, sessionID string) string {
+	s := loadSession(cfg, sessionID)
+	token := s.Canary()
+	if err := s.Save(); err != nil {
+		return ""
+	}
+	return token
+}
+
+// logPrefix starts the log lines of a call: the config version and the
+// session.
+func logPrefix(configVersion, sessionID string) string {
+	prefix := fmt.Sprintf("[config %s] ", configVersion)
+	if sessionID != "" {
+		prefix += fmt.Sprintf("[session %s] ", shortSessionID(sessionID))
+	}
+	return prefix
+}
+
+// shortSessionID shortens a session ID for log lines and listings.
+func shortSessionID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// recordDecision counts a PreToolUse decision and the internal errors of
+// its checks in the session.
+func recordDecision(cfg *config.SecurityConfig, sessionID string, result *checks.CheckResult, logger *log.Logger) {
+	if !cfg.SessionSummary.Enabled {
+		return
+	}
+	s := loadSession(cfg, sessionID)
+	s.Record(string(result.PermissionDecisionValue()), result.RuleID)
+	for _, e := range result.Errors {
+		s.RecordError(e.Kind, e.Message)
+	}
+	if err := s.Save(); err != nil {
+		logger.Printf("Failed to save session: %v", err)
+	}
+}
+
+// processSessionEvent handles Stop and SessionEnd and returns the exit code.
+func processSessionEvent(hookInput HookInput, cfg *config.SecurityConfig, logger *log.Logger) int {
+	defer flushLogs()
+
+	if !cfg.SessionSummary.Enabled {
+		return 0
+	}
+	s := loadSession(cfg, hookInput.SessionID)
+
+	switch hookInput.HookEventName {
+	case "SessionEnd":
+		summary := s.Summary(hookInput.Reason)
+		summary.SessionID = hookInput.SessionID
+		if err := state.AppendSummary(projectPath(cfg, cfg.SessionSummary.Store), summary); err != nil {
+			logger.Printf("Failed to write session summary: %v", err)
+			return 0
+		}
+		logger.Printf("[SESSION] %s", formatSummary(s, cfg.SessionSummary.TopRules))
+		// A session's own file goes with it; the shared one is reused
+		if hookInput.SessionID != "" {
+			if err := s.Remove(); err != nil {
+				logger.Printf("Failed to remove session: %v", err)
+			}
+			break
+		}
+		s.Reset()
+		if err := s.Save(); err != nil {
+			logger.Printf("Failed to reset session: %v", err)
+		}
+
+	case "Stop":
+		// Only speak up when something new was asked or denied this turn
+		if !cfg.SessionSummary.PrintOnStop || s.Flagged() <= s.Reported {
+			return 0
+		}
+		s.Reported = s.Flagged()
+		if err := s.Save(); err != nil {
+			logger.Printf("Failed to save session: %v", err)
+		}
+		json.NewEncoder(os.Stdout).Encode(StopOutput{
+			SystemMessage: "Security Guardian: " + formatSummary(s, cfg.SessionSummary.TopRules),
+		})
+	}
+	return 0
+}
+
+// formatSummary renders session totals on one line.
+func formatSummary(s *state.Session, topRules int) string {
+	summary := s.Summary("")
+	line := fmt.Sprintf("%d allowed, %d confirmations, %d denied this session", summary.Allowed, summary.Asked, summary.Denied)
+
+	var rules []string
+	for _, r := range s.TopRules(topRules) {
+		rules = append(rules, fmt.Sprintf("%s x%d", r.Rule, r.Count))
+	}
+	if len(rules) > 0 {
+		line += " (" + strings.Join(rules, ", ") + ")"
+	}
+	return line
+}
+
+// SessionStartOutput reports warnings to the user and the active policy to Claude.
+type SessionStartOutput struct {
+	SystemMessage      string `json:"systemMessage,omitempty"`
+	HookSpecificOutput struct {
+		HookEventName     string `json:"hookEventName"`
+		AdditionalContext string `json:"additionalContext,omitempty"`
+	} `json:"hookSpecificOutput"`
+}
+
+// processSessionStart checks the session environment and returns the exit code.
+func processSessionStart(hookInput HookInput, cfg *config.SecurityConfig, configPath string, configErr error, logger *log.Logger) int {
+	// Sessions that ended without SessionEnd leave their counters behind
+	mm := cfg.MassModification
+	state.PruneSessions(projectPath(cfg, mm.StateFile), time.Duration(mm.SessionIdleMinutes)*time.Minute)
+
+	if !cfg.SessionStart.Enabled {
+		return 0
+	}
+
+	projectRoot := projectPath(cfg, ".")
+	report := hardening.Inspect(cfg, configPath, configErr, projectRoot)
+	for _, f := range report.Findings {
+		logger.Printf("[SESSION] %s (%s): %s", f.Level, hookInput.Source, f.Message)
+	}
+
+	var output SessionStartOutput
+	output.HookSpecificOutput.HookEventName = "SessionStart"
+	if warnings := report.Warnings(); cfg.SessionStart.ShowWarnings && len(warnings) > 0 {
+		output.SystemMessage = "Security Guardian:\n- " + strings.Join(warnings, "\n- ")
+	}
+	if cfg.SessionStart.InjectContext {
+		output.HookSpecificOutput.AdditionalContext = policy.BuildDigest(cfg, projectRoot, report.Yolo).Text()
+	}
+	if output.SystemMessage == "" && output.HookSpecificOutput.AdditionalContext == "" {
+		return 0
+	}
+	json.NewEncoder(os.Stdout).Encode(output)
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trash"
+)
+
+// runTrash implements `guardian trash put|list|restore|purge`.
+func runTrash(args []string) int {
+	if len(args) == 0 {
+		trashUsage()
+		return 2
+	}
+
+	fs := flag.NewFlagSet("trash "+args[0], flag.ContinueOnError)
+	dir := fs.String("dir", "", "trash directory (default: from config)")
+	force := fs.Bool("f", false, "put: ignore missing paths")
+	olderThan := fs.Duration("older-than", 0, "purge: only batches older than this (e.g. 168h)")
+	all := fs.Bool("all", false, "purge: remove all batches")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *dir == "" {
+		*dir = defaultTrashRoot()
+	}
+
+	switch args[0] {
+	case "put":
+		// Used by rewritten rm commands
+		batch, err := trash.Put(*dir, fs.Args(), *force)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trash: %v\n", err)
+			return 1
+		}
+		if len(batch.Entries) > 0 {
+			fmt.Fprintf(os.Stderr, "Moved %d item(s) to trash (%s). Restore: guardian trash restore %s\n",
+				len(batch.Entries), batch.ID, batch.ID)
+		}
+		return 0
+
+	case "list":
+		batches, err := trash.List(*dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trash: %v\n", err)
+			return 1
+		}
+		if len(batches) == 0 {
+			fmt.Println("Trash is empty")
+			return 0
+		}
+		for _, b := range batches {
+			fmt.Printf("%s  %s\n", b.ID, b.CreatedAt.Local().Format(time.RFC3339))
+			for _, e := range b.Entries {
+				fmt.Printf("    %s\n", e.Original)
+			}
+		}
+		return 0
+
+	case "restore":
+		batch, err := trash.Restore(*dir, fs.Arg(0))
+		if batch != nil {
+			fmt.Printf("Restored batch %s\n", batch.ID)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trash: %v\n", err)
+			return 1
+		}
+		return 0
+
+	case "purge":
+		if !*all && *olderThan == 0 {
+			fmt.Fprintln(os.Stderr, "guardian trash purge: specify --older-than <duration> or --all")
+			return 2
+		}
+		n, err := trash.Purge(*dir, *olderThan)
+		fmt.Printf("Purged %d batch(es)\n", n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trash: %v\n", err)
+			return 1
+		}
+		return 0
+
+	default:
+		trashUsage()
+		return 2
+	}
+}
+
+// defaultTrashRoot returns the trash directory from config.
+func defaultTrashRoot() string {
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	projectRoot := cfg.Directories.ProjectRoot
+	if projectRoot == "" {
+		projectRoot = parsers.GetProjectRoot()
+	}
+	return trash.Root(cfg, projectRoot)
+}
+
+// trashUsage prints trash subcommand usage.
+func trashUsage() {
+	fmt.Fprintln(os.Stderr, `Usage: guardian trash <subcommand> [--dir DIR]
+  list                          show trashed batches
+  restore [ID]                  restore a batch (latest by default)
+  purge --older-than 168h|--all permanently delete batches
+  put [-f] -- PATH...           move paths to trash (used by rewritten rm)`)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+)
+
+// runTrust implements `guardian trust [--list] [--remove] FILE...`.
+func runTrust(args []string) int {
+	fs := flag.NewFlagSet("trust", flag.ContinueOnError)
+	list := fs.Bool("list", false, "show trusted scripts and whether they changed")
+	remove := fs.Bool("remove", false, "stop trusting the given files")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(config.FindConfigPath())
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	projectRoot := cfg.Directories.ProjectRoot
+	if projectRoot == "" {
+		projectRoot = parsers.GetProjectRoot()
+	}
+
+	manifest, err := trust.Load(trust.ManifestPath(cfg, projectRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian trust: %v\n", err)
+		return 1
+	}
+
+	if *list {
+		paths := manifest.Paths()
+		if len(paths) == 0 {
+			fmt.Println("No trusted scripts")
+			return 0
+		}
+		for _, rel := range paths {
+			state := "ok"
+			if manifest.Lookup(projectRoot, filepath.Join(projectRoot, rel)) != trust.Trusted {
+				state = "CHANGED"
+			}
+			fmt.Printf("%-8s %s\n", state, rel)
+		}
+		return 0
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: guardian trust [--remove] FILE...  |  guardian trust --list")
+		return 2
+	}
+
+	for _, file := range fs.Args() {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trust: %v\n", err)
+			return 1
+		}
+		if *remove {
+			if !manifest.Remove(projectRoot, abs) {
+				fmt.Fprintf(os.Stderr, "guardian trust: %s is not trusted\n", file)
+			}
+			continue
+		}
+		rel, hash, err := manifest.Add(projectRoot, abs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "guardian trust: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Trusted %s (sha256 %s)\n", rel, hash[:12])
+	}
+
+	if err := manifest.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "guardian trust: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Where `guardian self-update` gets releases and the ed25519 public key
+// their signatures are checked with, set by release builds:
+// -ldflags "-X main.UpdateURL=... -X main.UpdateKey=<base64>". They are
+// pinned in the binary rather than read from the config, so an edited
+// config can't point the update at another binary.
+var (
+	UpdateURL = "https://github.com/artwist-polyakov/polyakov-claude-skills/releases/latest/download"
+	UpdateKey = ""
+)
+
+// maxUpdateBytes bounds a downloaded binary.
+const maxUpdateBytes = 100 << 20
+
+// releaseManifest is the signed description of a release (release.json):
+// its version and the SHA-256 of each binary. Its detached signature
+// (release.json.sig) covers the version, so an older signed release can't
+// be passed off as the latest, and through the hashes every binary.
+type releaseManifest struct {
+	Version string            `json:"version"`
+	Assets  map[string]string `json:"assets"` // asset name: hex SHA-256
+}
+
+// runSelfUpdate implements `guardian self-update [--check] [--force]`: it
+// downloads the signed release manifest, verifies it with the pinned key,
+// and unless the release is older than the running version downloads the
+// binary for this platform, checks it against the manifest and replaces
+// the running binary in one rename. --check only reads the manifest.
+func runSelfUpdate(args []string) int {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether a newer release is available")
+	force := fs.Bool("force", false, "reinstall the release if it is the running version")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	key, err := base64.StdEncoding.DecodeString(UpdateKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		fmt.Fprintln(os.Stderr, "guardian self-update: this binary has no release key (built from source); install a release or rebuild it")
+		return 1
+	}
+	manifest, err := fetchManifest(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian self-update: %v\n", err)
+		return 1
+	}
+
+	current := currentBuild()
+	fmt.Printf("running: %s\nrelease: %s\n", describeBuild(current), manifest.Version)
+	cmp, ok := compareVersions(manifest.Version, current.Version)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "guardian self-update: can't compare release %q with the running %q; nothing installed\n", manifest.Version, current.Version)
+		return 1
+	}
+	switch {
+	case *check:
+		switch {
+		case cmp > 0:
+			fmt.Println("An update is available: guardian self-update")
+		case cmp == 0:
+			fmt.Println("Up to date.")
+		default:
+			fmt.Println("The running version is newer than the release.")
+		}
+		return 0
+	case cmp < 0:
+		// Older releases are signed too: installing one would bring back
+		// what later releases fixed
+		fmt.Fprintf(os.Stderr, "guardian self-update: release %s is older than the running %s; nothing installed\n", manifest.Version, current.Version)
+		return 1
+	case cmp == 0 && !*force:
+		fmt.Println("Up to date.")
+		return 0
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian self-update: %v\n", err)
+		return 1
+	}
+	asset := releaseAsset()
+	binary, err := fetchAsset(manifest, asset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian self-update: %v; nothing installed\n", err)
+		return 1
+	}
+
+	// The new binary is written next to the old one, so the swap is a
+	// rename within one file system
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".guardian-update-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian self-update: %v\n", err)
+		return 1
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(binary)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0755)
+	}
+	if err == nil {
+		err = replaceBinary(tmp.Name(), exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guardian self-update: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Updated %s to %s\n", exe, manifest.Version)
+	return 0
+}
+
+// releaseURL returns the URL of a release asset.
+func releaseURL(name string) string {
+	return strings.TrimSuffix(UpdateURL, "/") + "/" + name
+}
+
+// fetchManifest downloads release.json and its signature and returns the
+// manifest if the signature verifies with key.
+func fetchManifest(key ed25519.PublicKey) (releaseManifest, error) {
+	var manifest releaseManifest
+	url := releaseURL("release.json")
+	data, err := download(url)
+	if err != nil {
+		return manifest, err
+	}
+	sig, err := download(url + ".sig")
+	if err != nil {
+		return manifest, err
+	}
+	if !verifyRelease(key, data, sig) {
+		return manifest, errors.New("signature of release.json does not verify; nothing installed")
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("release.json: %v", err)
+	}
+	if manifest.Version == "" {
+		return manifest, errors.New("release.json: no version")
+	}
+	return manifest, nil
+}
+
+// fetchAsset downloads a release binary and checks it against the
+// SHA-256 the manifest lists for it.
+func fetchAsset(manifest releaseManifest, asset string) ([]byte, error) {
+	want, ok := manifest.Assets[asset]
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", manifest.Version, asset)
+	}
+	binary, err := download(releaseURL(asset))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(binary)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), want) {
+		return nil, fmt.Errorf("%s doesn't match the SHA-256 in release.json", asset)
+	}
+	return binary, nil
+}
+
+// compareVersions compares release versions (1.2.0, v1.2.0, 1.3.0-rc.1):
+// numeric dot-separated parts, then a release above its pre-releases. ok
+// is false if either isn't such a version ("dev").
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, prea, oka := parseVersion(a)
+	pb, preb, okb := parseVersion(b)
+	if !oka || !okb {
+		return 0, false
+	}
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	switch {
+	case prea == preb:
+		return 0, true
+	case prea == "":
+		return 1, true
+	case preb == "":
+		return -1, true
+	}
+	return comparePreRelease(prea, preb), true
+}
+
+// comparePreRelease compares pre-release suffixes field by field: numbers
+// numerically (rc.2 before rc.10), other fields as strings.
+func comparePreRelease(a, b string) int {
+	fa, fb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(fa) && i < len(fb); i++ {
+		x, errx := strconv.Atoi(fa[i])
+		y, erry := strconv.Atoi(fb[i])
+		switch {
+		case errx == nil && erry == nil && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		case (errx != nil || erry != nil) && fa[i] != fb[i]:
+			return strings.Compare(fa[i], fb[i])
+		}
+	}
+	switch {
+	case len(fa) < len(fb):
+		return -1
+	case len(fa) > len(fb):
+		return 1
+	}
+	return 0
+}
+
+// parseVersion splits a version into its numbers and pre-release suffix.
+// Build metadata (+...) is ignored.
+func parseVersion(v string) (parts []int, pre string, ok bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre, true
+}
+
+// releaseAsset is the name of the release binary for this platform, as
+// .goreleaser.yaml names it.
+func releaseAsset() string {
+	name := fmt.Sprintf("guardian-%s-%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// download fetches url, at most maxUpdateBytes of it.
+func download(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxUpdateBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxUpdateBytes {
+		return nil, fmt.Errorf("%s: larger than %d bytes", url, maxUpdateBytes)
+	}
+	return data, nil
+}
+
+// verifyRelease checks a detached ed25519 signature of release data, raw
+// (openssl pkeyutl -sign -rawin) or base64.
+func verifyRelease(key ed25519.PublicKey, data, sig []byte) bool {
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return false
+		}
+		sig = decoded
+	}
+	return len(sig) == ed25519.SignatureSize && ed25519.Verify(key, data, sig)
+}
+
+// describeBuild shows a version with its commit and build date.
+func describeBuild(info buildInfo) string {
+	s := info.Version
+	if info.Commit != "" {
+		s += " (" + info.Commit
+		if info.BuildDate != "" {
+			s += ", " + info.BuildDate
+		}
+		s += ")"
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		cmp  int
+		ok   bool
+	}{
+		{"1.2.0", "1.2.0", 0, true},
+		{"v1.2.0", "1.2.0", 0, true},
+		{"1.2.1", "1.2.0", 1, true},
+		{"1.10.0", "1.9.0", 1, true},
+		{"1.2", "1.2.0", 0, true},
+		{"2.0.0", "10.0.0", -1, true},
+		{"1.3.0-rc.1", "1.3.0", -1, true},
+		{"1.3.0", "1.3.0-rc.1", 1, true},
+		{"1.3.0-rc.10", "1.3.0-rc.2", 1, true},
+		{"1.3.0-beta", "1.3.0-alpha", 1, true},
+		{"1.2.0+abc", "1.2.0", 0, true},
+		{"dev", "1.2.0", 0, false},
+		{"1.2.0", "", 0, false},
+		{"1.x", "1.2", 0, false},
+	}
+	for _, tt := range tests {
+		cmp, ok := compareVersions(tt.a, tt.b)
+		if cmp != tt.cmp || ok != tt.ok {
+			t.Errorf("compareVersions(%q, %q) = %d, %v; want %d, %v", tt.a, tt.b, cmp, ok, tt.cmp, tt.ok)
+		}
+	}
+}
+
+// releaseServer serves a signed release of version with a binary for this
+// platform and records the paths requested.
+type releaseServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requested []string
+}
+
+func newReleaseServer(t *testing.T, version string, binary []byte, tamper func(manifest []byte) []byte) *releaseServer {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(binary)
+	manifest, _ := json.Marshal(releaseManifest{
+		Version: version,
+		Assets:  map[string]string{releaseAsset(): hex.EncodeToString(sum[:])},
+	})
+	sig := ed25519.Sign(priv, manifest)
+	if tamper != nil {
+		manifest = tamper(manifest)
+	}
+
+	rs := &releaseServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		rs.requested = append(rs.requested, r.URL.Path)
+		rs.mu.Unlock()
+		switch r.URL.Path {
+		case "/release.json":
+			w.Write(manifest)
+		case "/release.json.sig":
+			w.Write([]byte(base64.StdEncoding.EncodeToString(sig)))
+		case "/" + releaseAsset():
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(rs.Close)
+
+	oldURL, oldKey, oldVersion := UpdateURL, UpdateKey, Version
+	UpdateURL, UpdateKey, Version = rs.URL, base64.StdEncoding.EncodeToString(pub), "1.2.0"
+	t.Cleanup(func() { UpdateURL, UpdateKey, Version = oldURL, oldKey, oldVersion })
+	return rs
+}
+
+// fetchedBinary reports whether the release binary was downloaded.
+func (rs *releaseServer) fetchedBinary() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, path := range rs.requested {
+		if path == "/"+releaseAsset() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSelfUpdateCheckReadsManifestOnly(t *testing.T) {
+	rs := newReleaseServer(t, "1.3.0", []byte("new binary"), nil)
+	if code := runSelfUpdate([]string{"--check"}); code != 0 {
+		t.Fatalf("--check exit code %d", code)
+	}
+	if rs.fetchedBinary() {
+		t.Error("--check downloaded the release binary")
+	}
+}
+
+func TestSelfUpdateRefusesDowngrade(t *testing.T) {
+	for _, args := range [][]string{nil, {"--force"}} {
+		rs := newReleaseServer(t, "1.1.0", []byte("old signed binary"), nil)
+		if code := runSelfUpdate(args); code != 1 {
+			t.Errorf("%v: exit code %d, want 1", args, code)
+		}
+		if rs.fetchedBinary() {
+			t.Errorf("%v: downloaded an older release", args)
+		}
+	}
+}
+
+func TestSelfUpdateRejectsTamperedVersion(t *testing.T) {
+	// The version is signed: raising it breaks the signature
+	rs := newReleaseServer(t, "1.1.0", []byte("old signed binary"), func(manifest []byte) []byte {
+		var m releaseManifest
+		json.Unmarshal(manifest, &m)
+		m.Version = "9.9.9"
+		data, _ := json.Marshal(m)
+		return data
+	})
+	if code := runSelfUpdate(nil); code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	if rs.fetchedBinary() {
+		t.Error("downloaded the binary of a manifest that doesn't verify")
+	}
+}
+
+func TestFetchAssetChecksSum(t *testing.T) {
+	newReleaseServer(t, "1.3.0", []byte("new binary"), nil)
+	key, _ := base64.StdEncoding.DecodeString(UpdateKey)
+	manifest, err := fetchManifest(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fetchAsset(manifest, releaseAsset()); err != nil {
+		t.Errorf("fetchAsset: %v", err)
+	}
+	manifest.Assets[releaseAsset()] = hex.EncodeToString(make([]byte, sha256.Size))
+	if _, err := fetchAsset(manifest, releaseAsset()); err == nil {
+		t.Error("fetchAsset accepted a binary not matching the manifest")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// replaceBinary moves the verified binary at tmp over exe. A rename
+// replaces the file atomically: a hook starting meanwhile runs either the
+// old binary or the new one.
+func replaceBinary(tmp, exe string) error {
+	return os.Rename(tmp, exe)
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// replaceBinary moves the verified binary at tmp over exe. Windows doesn't
+// replace a running executable, but renames it: the old binary is moved
+// aside to exe.old first, and moved back if the new one can't take its
+// place. The next update removes it.
+func replaceBinary(tmp, exe string) error {
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// Build information, set by the Makefile:
+// -ldflags "-X main.Version=... -X main.Commit=... -X main.BuildDate=...".
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// buildInfo describes the binary and the config it runs with.
+type buildInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit,omitempty"`
+	BuildDate     string `json:"build_date,omitempty"`
+	GoVersion     string `json:"go_version"`
+	ConfigPath    string `json:"config_path,omitempty"`
+	ConfigVersion string `json:"config_version"`
+}
+
+// currentBuild returns the build information. Without ldflags (go build,
+// go install) the commit and date come from the VCS stamp Go embeds.
+func currentBuild() buildInfo {
+	info := buildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+				if len(info.Commit) > 12 {
+					info.Commit = info.Commit[:12]
+				}
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// guardianVersion is the version and commit in one string, as recorded
+// with decisions.
+func guardianVersion() string {
+	info := currentBuild()
+	if info.Commit == "" {
+		return info.Version
+	}
+	return info.Version + "+" + info.Commit
+}
+
+// runVersion implements `guardian version [--json]`.
+func runVersion(args []string) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	info := currentBuild()
+	info.ConfigPath = config.FindConfigPath()
+	cfg, err := config.LoadConfig(info.ConfigPath)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	info.ConfigVersion = cfg.Version
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(info)
+		return 0
+	}
+	fmt.Printf("guardian %s\n", info.Version)
+	if info.Commit != "" {
+		fmt.Printf("commit:  %s\n", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf("built:   %s\n", info.BuildDate)
+	}
+	fmt.Printf("go:      %s\n", info.GoVersion)
+	fmt.Printf("config:  %s (%s)\n", info.ConfigPath, info.ConfigVersion)
+	return 0
+}
@@ -0,0 +1,335 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// AutoloadCheck asks before files the project runs without being invoked
+// (git hooks, direnv .envrc, VS Code folderOpen tasks) are created or
+// changed. Nothing runs at write time, so every other check passes; the
+// code runs on the user's next commit, cd or folder open instead.
+// Shell activation files (.envrc, conda activate.d) and editor task and run
+// configurations also have their content screened, so the confirmation
+// says what the file would do.
+type AutoloadCheck struct {
+	BaseCheck
+	projectRoot string
+	config      *config.SecurityConfig
+	engine      *Engine
+
+	network       []*regexp.Regexp
+	sensitive     []*regexp.Regexp
+	dynamic       []*regexp.Regexp
+	pathChanges   []*regexp.Regexp
+	secretExports []*regexp.Regexp
+}
+
+// screenGroup is a category of lines the screened files are checked for.
+type screenGroup struct {
+	category string
+	patterns []*regexp.Regexp
+}
+
+// ideCommandKeys are the VS Code task and launch configuration keys whose
+// values are run.
+var ideCommandKeys = map[string]bool{
+	"command": true, "args": true, "program": true, "script": true,
+	"runtimeExecutable": true, "runtimeArgs": true,
+}
+
+// ideOptionValue matches the option values of a JetBrains run configuration
+// (SCRIPT_TEXT, PROGRAM_PARAMETERS, ...).
+var ideOptionValue = regexp.MustCompile(`\bvalue="([^"]*)"`)
+
+// autoloadRemoveCommands remove or create directories; neither leaves
+// code behind to run.
+var autoloadRemoveCommands = map[string]bool{
+	"rm": true, "rmdir": true, "unlink": true, "shred": true, "mkdir": true,
+}
+
+// NewAutoloadCheck creates a new AutoloadCheck instance.
+func NewAutoloadCheck(e *Engine) *AutoloadCheck {
+	ops := &e.Config.DangerousOperations
+	activation := &e.Config.Autoload.Activation
+	join := func(a, b []string) []string {
+		return append(append([]string{}, a...), b...)
+	}
+	return &AutoloadCheck{
+		BaseCheck:     BaseCheck{CheckName: "autoload_check"},
+		projectRoot:   e.ProjectRoot,
+		config:        e.Config,
+		engine:        e,
+		network:       e.compileAll(join(ops.Network, ops.Shell.Network)),
+		sensitive:     e.compileAll(join(ops.SensitiveAccess, ops.Shell.SensitiveAccess)),
+		dynamic:       e.compileAll(join(ops.DynamicExecution, ops.Shell.DynamicExecution)),
+		pathChanges:   e.compileAll(activation.PathChanges),
+		secretExports: e.compileAll(activation.SecretExports),
+	}
+}
+
+// CheckWrite checks a Write or Edit of path. content is the new file, or
+// the replacement text of an Edit (partial), in which case the file on disk
+// is also looked at: editing a task that already runs on folderOpen counts.
+func (c *AutoloadCheck) CheckWrite(path, content string, partial bool) *CheckResult {
+	if !c.config.Autoload.Enabled {
+		return c.Allow()
+	}
+	entry := c.entryFor(path)
+	if result := c.checkIDE(path, content); !result.IsAllowed() {
+		return result
+	}
+	if result := c.checkActivation(path, content, entry); !result.IsAllowed() {
+		return result
+	}
+	if entry == nil {
+		return c.Allow()
+	}
+	if entry.Contains != "" {
+		re := c.engine.compile(entry.Contains)
+		if re == nil {
+			return c.Allow()
+		}
+		runs := re.MatchString(content)
+		if !runs && partial {
+			if data, err := os.ReadFile(parsers.ResolvePath(path, c.baseDir(c.projectRoot))); err == nil {
+				runs = re.Match(data)
+			}
+		}
+		if !runs {
+			return c.Allow()
+		}
+	}
+	return c.ask(path, entry)
+}
+
+// CheckCommand checks the files a command writes, copies or moves into
+// place. The content isn't known, so entries with contains ask as well.
+func (c *AutoloadCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if !c.config.Autoload.Enabled {
+		return c.Allow()
+	}
+	for _, cmd := range parsedCommands {
+		if autoloadRemoveCommands[cmd.Command] {
+			continue
+		}
+		for _, op := range parsers.ClassifyOperands(cmd) {
+			if op.Role != parsers.RoleDestination || op.Value == "" {
+				continue
+			}
+			path := parsers.JoinDir(cmd.Dir, op.Value)
+			if entry := c.entryFor(path); entry != nil {
+				return c.ask(path, entry).WithOrigin(cmd)
+			}
+		}
+	}
+	return c.Allow()
+}
+
+// checkActivation screens content written to a shell activation file for
+// network calls, PATH changes and exported secrets.
+func (c *AutoloadCheck) checkActivation(path, content string, entry *config.AutoloadPath) *CheckResult {
+	rel, ok := c.relPath(path)
+	if !ok || content == "" {
+		return c.Allow()
+	}
+	if _, ok := globs.MatchList(c.config.Autoload.Activation.Files, rel); !ok {
+		return c.Allow()
+	}
+
+	findings, kinds := screen(path, content, []screenGroup{
+		{"network call", c.network},
+		{"PATH change", c.pathChanges},
+		{"exported secret", c.secretExports},
+	})
+	if len(findings) == 0 {
+		return c.Allow()
+	}
+
+	runs := "It is run or read when the user enters the directory or activates the environment"
+	if entry != nil && entry.Runs != "" {
+		runs = strings.ToUpper(entry.Runs[:1]) + entry.Runs[1:]
+	}
+	var lines []string
+	for _, f := range findings {
+		lines = append(lines, fmt.Sprintf("line %d: %s", f.Line, f.Match))
+	}
+	return c.Ask(
+		fmt.Sprintf("Shell activation file with %s: %s", strings.Join(kinds, ", "), path),
+		fmt.Sprintf("%s. Flagged lines: %s. Show the user these lines and let them decide.", runs, strings.Join(lines, "; ")),
+	).WithRule(RuleAutoloadActivation).WithPaths(path).WithDetails(findings)
+}
+
+// checkIDE screens the commands of an editor task or run configuration for
+// network calls, sensitive file access and dynamic execution.
+func (c *AutoloadCheck) checkIDE(path, content string) *CheckResult {
+	rel, ok := c.relPath(path)
+	if !ok || content == "" {
+		return c.Allow()
+	}
+	if _, ok := globs.MatchList(c.config.Autoload.IDE.Files, rel); !ok {
+		return c.Allow()
+	}
+
+	groups := []screenGroup{
+		{"network call", c.network},
+		{"sensitive access", c.sensitive},
+		{"dynamic execution", c.dynamic},
+	}
+	var findings []Finding
+	var kinds, flagged []string
+	for _, command := range ideCommands(path, content) {
+		found, k := screen(path, command, groups)
+		if len(found) == 0 {
+			continue
+		}
+		for i := range found {
+			found[i].Description = truncate(command, 120)
+		}
+		findings = append(findings, found...)
+		flagged = append(flagged, truncate(command, 120))
+		for _, kind := range k {
+			kinds = appendKind(kinds, kind)
+		}
+	}
+	if len(findings) == 0 {
+		return c.Allow()
+	}
+
+	runs := "It runs when the user starts the task or run configuration"
+	if strings.Contains(content, "folderOpen") {
+		runs = "It runs when the folder is opened (runOn: folderOpen)"
+	}
+	return c.Ask(
+		fmt.Sprintf("Editor task or run configuration with %s: %s", strings.Join(kinds, ", "), path),
+		fmt.Sprintf("%s. Flagged commands: %s. Show the user these commands and let them decide.", runs, strings.Join(flagged, "; ")),
+	).WithRule(RuleAutoloadIDECommand).WithPaths(path).WithDetails(findings)
+}
+
+// ideCommands returns the commands of an editor configuration: option
+// values of JetBrains XML, values under ideCommandKeys of VS Code JSON.
+// JSON with comments or an Edit fragment is returned whole.
+func ideCommands(path, content string) []string {
+	var commands []string
+	if strings.HasSuffix(path, ".xml") {
+		for _, m := range ideOptionValue.FindAllStringSubmatch(content, -1) {
+			commands = append(commands, html.UnescapeString(m[1]))
+		}
+		return commands
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return []string{content}
+	}
+	collectIDECommands(doc, false, &commands)
+	return commands
+}
+
+// collectIDECommands appends the strings under ideCommandKeys in v.
+func collectIDECommands(v interface{}, command bool, out *[]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectIDECommands(v[k], command || ideCommandKeys[k], out)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectIDECommands(item, command, out)
+		}
+	case string:
+		if command {
+			*out = append(*out, v)
+		}
+	}
+}
+
+// screen returns the matches of each group in content, ordered by line,
+// and the categories that matched.
+func screen(path, content string, groups []screenGroup) ([]Finding, []string) {
+	var findings []Finding
+	var kinds []string
+	for _, group := range groups {
+		n := len(findings)
+		for _, re := range group.patterns {
+			for _, loc := range re.FindAllStringIndex(content, -1) {
+				line, col := lineColumn(content, loc[0])
+				findings = append(findings, Finding{
+					Category: group.category,
+					File:     path,
+					Line:     line,
+					Column:   col,
+					Match:    strings.TrimSpace(content[loc[0]:loc[1]]),
+				})
+			}
+		}
+		if len(findings) > n {
+			kinds = append(kinds, group.category)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings, kinds
+}
+
+// appendKind appends kind unless it is already in kinds.
+func appendKind(kinds []string, kind string) []string {
+	for _, k := range kinds {
+		if k == kind {
+			return kinds
+		}
+	}
+	return append(kinds, kind)
+}
+
+// relPath returns path relative to the project root, or false outside it.
+func (c *AutoloadCheck) relPath(path string) (string, bool) {
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	rel, err := filepath.Rel(c.projectRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// entryFor returns the autoload entry covering path, or nil. Paths outside
+// the project are left to the directory and dotfile rules.
+func (c *AutoloadCheck) entryFor(path string) *config.AutoloadPath {
+	rel, ok := c.relPath(path)
+	if !ok {
+		return nil
+	}
+	for i := range c.config.Autoload.Paths {
+		if globs.Match(c.config.Autoload.Paths[i].Path, rel) {
+			return &c.config.Autoload.Paths[i]
+		}
+	}
+	return nil
+}
+
+// ask builds the confirmation for a write to an autoload location.
+func (c *AutoloadCheck) ask(path string, entry *config.AutoloadPath) *CheckResult {
+	runs := entry.Runs
+	if runs == "" {
+		runs = "The project runs it automatically"
+	} else {
+		runs = strings.ToUpper(runs[:1]) + runs[1:]
+	}
+	return c.Ask(
+		fmt.Sprintf("Write to a file that runs automatically: %s", path),
+		fmt.Sprintf("%s, so this is code execution on the user's next action. Show the user the content and let them decide.", runs),
+	).WithRule(RuleAutoloadWrite).WithPaths(path).WithPattern(entry.Path)
+}
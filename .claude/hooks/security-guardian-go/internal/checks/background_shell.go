@@ -0,0 +1,75 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// BackgroundShellCheck watches output of background shells. The guardian
+// sees only the command that started a shell; a script it launched can
+// still run what was denied earlier in the session.
+type BackgroundShellCheck struct {
+	BaseCheck
+	sessionFile string
+	config      *config.SecurityConfig
+}
+
+// Denied commands shorter than this match too much unrelated output
+const minTrackedCommandLength = 8
+
+// NewBackgroundShellCheck creates a new BackgroundShellCheck instance.
+func NewBackgroundShellCheck(e *Engine) *BackgroundShellCheck {
+	return &BackgroundShellCheck{
+		BaseCheck:   BaseCheck{CheckName: "background_shell_check"},
+		sessionFile: e.SessionFile(),
+		config:      e.Config,
+	}
+}
+
+// CheckCommand is not used - see CheckOutput.
+func (c *BackgroundShellCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.Allow()
+}
+
+// CheckOutput checks background shell output for commands denied earlier
+// in the session.
+func (c *BackgroundShellCheck) CheckOutput(output string) *CheckResult {
+	if !c.config.BackgroundShells.FlagDeniedOutput || output == "" {
+		return c.Allow()
+	}
+
+	for _, command := range c.loadSession().DeniedCommands {
+		if len(command) < minTrackedCommandLength {
+			continue
+		}
+		if strings.Contains(output, command) {
+			return c.Ask(
+				fmt.Sprintf("Background shell output contains a command denied earlier: %s", truncate(command, 120)),
+				"A background process may be running what the guardian blocked. Stop it with KillShell and tell the user.",
+			).WithRule(RuleShellOutputDenied)
+		}
+	}
+
+	return c.Allow()
+}
+
+// RecordDenied remembers a denied Bash command for the session.
+func (c *BackgroundShellCheck) RecordDenied(command string) error {
+	command = strings.TrimSpace(command)
+	if !c.config.BackgroundShells.FlagDeniedOutput || len(command) < minTrackedCommandLength {
+		return nil
+	}
+	s := c.loadSession()
+	s.AddDenied(command, c.config.BackgroundShells.MaxTracked)
+	return s.Save()
+}
+
+// loadSession loads the session shared with the mass modification counters.
+func (c *BackgroundShellCheck) loadSession() *state.Session {
+	mm := c.config.MassModification
+	return state.LoadSession(c.sessionFile, time.Duration(mm.SessionIdleMinutes)*time.Minute)
+}
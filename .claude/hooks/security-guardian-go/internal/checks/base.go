@@ -1,6 +1,12 @@
 // Package checks provides security check implementations.
 package checks
 
+import (
+	"fmt"
+
+	"github.com/artwist-polyakov/security-guardian/internal/model"
+)
+
 // CheckStatus represents the result status of a security check.
 type CheckStatus string
 
@@ -32,6 +38,65 @@ type CheckResult struct {
 	Guidance  string             `json:"guidance"`
 	CheckName string             `json:"check_name"`
 	Decision  PermissionDecision `json:"decision,omitempty"`
+	RuleID    string             `json:"rule_id,omitempty"`
+	// UpdatedInput replaces the tool input of an allowed call
+	// (e.g. rm rewritten into a move to trash).
+	UpdatedInput map[string]interface{} `json:"updated_input,omitempty"`
+	// Details lists every flagged location (code content checks).
+	Details []Finding `json:"details,omitempty"`
+	// Paths are the offending paths, Suggestion a safer command to offer
+	// (see messages.BuildPayload).
+	Paths      []string `json:"paths,omitempty"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	// Pattern is the config entry, glob or regexp that matched
+	// (shown by `guardian explain`).
+	Pattern string `json:"pattern,omitempty"`
+	// Errors are the internal errors the checks of the call worked
+	// around (see Engine.Errors); they don't change the decision.
+	Errors []InternalError `json:"errors,omitempty"`
+}
+
+// Finding is a pattern match in checked content.
+type Finding struct {
+	Category    string `json:"category"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Match       string `json:"match"`
+	Description string `json:"description,omitempty"`
+}
+
+// WithDetails attaches findings to the result.
+func (r *CheckResult) WithDetails(details []Finding) *CheckResult {
+	r.Details = details
+	return r
+}
+
+// WithPaths records the offending paths.
+func (r *CheckResult) WithPaths(paths ...string) *CheckResult {
+	r.Paths = append(r.Paths, paths...)
+	return r
+}
+
+// WithPattern records the config entry, glob or regexp that matched.
+func (r *CheckResult) WithPattern(pattern string) *CheckResult {
+	r.Pattern = pattern
+	return r
+}
+
+// WithSuggestion records a safer command to offer instead.
+func (r *CheckResult) WithSuggestion(command string) *CheckResult {
+	r.Suggestion = command
+	return r
+}
+
+// WithOrigin notes in the reason where cmd runs when it is nested in
+// another command's substitution (see ParsedCommand.Origin).
+func (r *CheckResult) WithOrigin(cmd *ParsedCommand) *CheckResult {
+	if origin := cmd.Origin(); origin != "" && !r.IsAllowed() {
+		r.Reason = fmt.Sprintf("%s (%s)", r.Reason, origin)
+	}
+	return r
 }
 
 // IsAllowed returns true if the result allows the operation.
@@ -73,6 +138,8 @@ func (r *CheckResult) ToMap() map[string]interface{} {
 		"guidance":  r.Guidance,
 		"check_name": r.CheckName,
 		"decision":  string(r.PermissionDecisionValue()),
+		"rule_id":   r.RuleID,
+		"details":   r.Details,
 	}
 }
 
@@ -105,46 +172,38 @@ func Deny(checkName, reason, guidance string) *CheckResult {
 	}
 }
 
-// Ask creates a deny result with guidance to run the command manually.
+// Ask creates an ask result (user confirmation required).
 // In YOLO mode (--dangerously-skip-permissions) ASK is auto-approved,
-// which makes it equivalent to ALLOW. Since this hook is designed
-// specifically for YOLO mode, all ASK decisions are elevated to DENY
-// with a clear instruction for the user to run the command themselves.
+// which makes it equivalent to ALLOW, so the caller elevates it to DENY
+// via ElevateAsk with a clear instruction for the user to run the command themselves.
 func Ask(checkName, reason, guidance string) *CheckResult {
 	return &CheckResult{
-		Status:    StatusBlock,
+		Status:    StatusConfirm,
 		Reason:    reason,
 		Guidance:  guidance,
 		CheckName: checkName,
-		Decision:  DecisionDeny,
+		Decision:  DecisionAsk,
 	}
 }
 
-// Confirm creates a deny result (elevated from ASK for YOLO mode).
-// Same as Ask() — in YOLO mode, all confirmations become hard denies.
+// Confirm creates an ask result. Same as Ask().
 func Confirm(checkName, reason, guidance string) *CheckResult {
-	return &CheckResult{
-		Status:    StatusBlock,
-		Reason:    reason,
-		Guidance:  guidance,
-		CheckName: checkName,
-		Decision:  DecisionDeny,
-	}
+	return Ask(checkName, reason, guidance)
 }
 
-// ParsedCommand represents a parsed bash command (imported from parsers).
-// This is a forward declaration to avoid circular imports.
-type ParsedCommand struct {
-	Command           string
-	Args              []string
-	Flags             []string
-	PipesTo           *ParsedCommand
-	Redirects         []string
-	Subcommands       []*ParsedCommand
-	VariableAsCommand bool
-	Raw               string
+// ElevateAsk turns an ASK result into a hard DENY.
+// Used in YOLO mode where interactive permission prompts are skipped.
+func (r *CheckResult) ElevateAsk() *CheckResult {
+	if r.PermissionDecisionValue() == DecisionAsk {
+		r.Status = StatusBlock
+		r.Decision = DecisionDeny
+	}
+	return r
 }
 
+// ParsedCommand represents a parsed bash command (see model.ParsedCommand).
+type ParsedCommand = model.ParsedCommand
+
 // SecurityCheck is the interface for all security checks.
 type SecurityCheck interface {
 	// Name returns the check name.
@@ -153,12 +212,17 @@ type SecurityCheck interface {
 	CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult
 	// CheckPath checks a path for security issues.
 	CheckPath(path string, operation string) *CheckResult
+	// SetWorkDir sets the directory relative paths are resolved against.
+	SetWorkDir(dir string)
 }
 
 // BaseCheck provides common functionality for security checks.
 type BaseCheck struct {
 	CheckName string
 	Config    interface{}
+	// WorkDir is the session working directory (hook's cwd).
+	// Empty means relative paths resolve against project root.
+	WorkDir string
 }
 
 // Name returns the check name.
@@ -166,6 +230,20 @@ func (b *BaseCheck) Name() string {
 	return b.CheckName
 }
 
+// SetWorkDir sets the directory relative paths are resolved against.
+func (b *BaseCheck) SetWorkDir(dir string) {
+	b.WorkDir = dir
+}
+
+// baseDir returns the directory relative paths resolve against:
+// the working directory if known, otherwise projectRoot.
+func (b *BaseCheck) baseDir(projectRoot string) string {
+	if b.WorkDir != "" {
+		return b.WorkDir
+	}
+	return projectRoot
+}
+
 // Allow creates an allow result for this check.
 func (b *BaseCheck) Allow() *CheckResult {
 	return Allow(b.CheckName)
@@ -181,12 +259,12 @@ func (b *BaseCheck) Deny(reason, guidance string) *CheckResult {
 	return Deny(b.CheckName, reason, guidance)
 }
 
-// Ask creates a deny result for this check (elevated from ASK for YOLO mode).
+// Ask creates an ask result for this check.
 func (b *BaseCheck) Ask(reason, guidance string) *CheckResult {
 	return Ask(b.CheckName, reason, guidance)
 }
 
-// Confirm creates a deny result for this check (elevated from ASK for YOLO mode).
+// Confirm creates an ask result for this check.
 func (b *BaseCheck) Confirm(reason, guidance string) *CheckResult {
 	return Confirm(b.CheckName, reason, guidance)
 }
@@ -2,6 +2,9 @@ package checks
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
@@ -11,14 +14,16 @@ import (
 // BypassCheck checks for attempts to bypass security measures.
 type BypassCheck struct {
 	BaseCheck
-	config *config.SecurityConfig
+	projectRoot string
+	config      *config.SecurityConfig
 }
 
 // NewBypassCheck creates a new BypassCheck instance.
-func NewBypassCheck(cfg *config.SecurityConfig) *BypassCheck {
+func NewBypassCheck(e *Engine) *BypassCheck {
 	return &BypassCheck{
-		BaseCheck: BaseCheck{CheckName: "bypass_check"},
-		config:    cfg,
+		BaseCheck:   BaseCheck{CheckName: "bypass_check"},
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
 	}
 }
 
@@ -34,11 +39,26 @@ func (c *BypassCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCo
 		return result
 	}
 
+	// Check for shell <(cmd) and source <(cmd)
+	if result := c.checkSubstitutedShell(parsedCommands); !result.IsAllowed() {
+		return result
+	}
+
 	// Check for pipe to shell
 	if result := c.checkPipeToShell(parsedCommands); !result.IsAllowed() {
 		return result
 	}
 
+	// The agent must not approve its own scripts
+	if result := c.checkSelfTrust(parsedCommands); !result.IsAllowed() {
+		return result
+	}
+
+	// Check for >(cmd) and named pipes feeding a shell or the network
+	if result := c.checkHiddenPipes(parsedCommands); !result.IsAllowed() {
+		return result
+	}
+
 	// Check for shell -c execution
 	if result := c.checkShellExec(rawCommand, parsedCommands); !result.IsAllowed() {
 		return result
@@ -60,15 +80,7 @@ func (c *BypassCheck) checkHardBlocked(rawCommand string, parsedCommands []*Pars
 				return c.Deny(
 					fmt.Sprintf("Command '%s' is blocked (potential bypass)", blocked),
 					"Use explicit commands instead of eval/exec.",
-				)
-			}
-		}
-
-		// Check piped commands
-		if cmd.PipesTo != nil {
-			result := c.checkHardBlocked(rawCommand, []*ParsedCommand{cmd.PipesTo})
-			if !result.IsAllowed() {
-				return result
+				).WithRule(RuleBypassHardBlocked).WithPattern(blocked).WithOrigin(cmd)
 			}
 		}
 	}
@@ -87,7 +99,7 @@ func (c *BypassCheck) checkVariableAsCommand(parsedCommands []*ParsedCommand) *C
 			return c.Deny(
 				"Variable used as command (potential bypass)",
 				"Use explicit commands. Variable expansion as command is blocked.",
-			)
+			).WithRule(RuleBypassVariableAsCommand)
 		}
 	}
 
@@ -98,22 +110,169 @@ func (c *BypassCheck) checkVariableAsCommand(parsedCommands []*ParsedCommand) *C
 func (c *BypassCheck) checkPipeToShell(parsedCommands []*ParsedCommand) *CheckResult {
 	shellTargets := c.config.BypassPrevention.BlockShellPipeTargets
 
-	// Convert to parsers.ParsedCommand for the helper function
-	parserCmds := make([]*parsers.ParsedCommand, len(parsedCommands))
-	for i, cmd := range parsedCommands {
-		parserCmds[i] = convertParsedCommand(cmd)
-	}
-
-	if parsers.IsPipeToShell(parserCmds, shellTargets) {
+	if parsers.IsPipeToShell(parsedCommands, shellTargets) {
 		return c.Deny(
 			"Piping to shell detected (dangerous pattern)",
 			"Cannot pipe to shell. Download file first, review, then execute.",
-		)
+		).WithRule(RuleBypassPipeToShell)
+	}
+
+	return c.Allow()
+}
+
+// checkSubstitutedShell checks for a shell or source reading a process
+// substitution, which runs its output like a pipe to shell.
+func (c *BypassCheck) checkSubstitutedShell(parsedCommands []*ParsedCommand) *CheckResult {
+	shellTargets := c.config.BypassPrevention.BlockShellPipeTargets
+
+	for _, cmd := range parsedCommands {
+		if parsers.FeedsShell(cmd, shellTargets) {
+			return c.Deny(
+				fmt.Sprintf("Output of %s executed by %s through process substitution", cmd.Command, cmd.Parent.Command),
+				"Cannot feed command output to a shell. Write it to a file, review, then execute.",
+			).WithRule(RuleBypassPipeToShell)
+		}
 	}
 
 	return c.Allow()
 }
 
+// checkSelfTrust blocks `guardian trust FILE`, `guardian allow CODE` and
+// `guardian self-update`: trusting a script, granting an exception and
+// replacing the binary that checks the agent are the user's decisions,
+// not the agent's.
+func (c *BypassCheck) checkSelfTrust(parsedCommands []*ParsedCommand) *CheckResult {
+	self := ""
+	if exe, err := os.Executable(); err == nil {
+		self = filepath.Base(exe)
+	}
+
+	for _, cmd := range parsedCommands {
+		guardian, subcommand, rest := guardianSubcommand(cmd, self)
+		if subcommand == "trust" && !containsFlag(rest, "--list") {
+			return c.Deny(
+				"Trusting scripts for the guardian is reserved for the user",
+				fmt.Sprintf("Ask the user to review the script and run `%s` themselves.", strings.Join(append([]string{guardian.Command, "trust"}, rest...), " ")),
+			).WithRule(RuleBypassSelfTrust)
+		}
+		if subcommand == "allow" && !containsFlag(rest, "--list") {
+			return c.Deny(
+				"Granting guardian exceptions is reserved for the user",
+				"Tell the user what the denied operation is for; they decide whether to run `guardian allow` with its code.",
+			).WithRule(RuleBypassSelfException)
+		}
+		if subcommand == "self-update" && !containsFlag(rest, "--check") {
+			return c.Deny(
+				"Updating the guardian is reserved for the user",
+				"Tell the user an update may be available; they run `guardian self-update` themselves.",
+			).WithRule(RuleBypassSelfUpdate)
+		}
+	}
+
+	return c.Allow()
+}
+
+// guardianGlobalOptions are the options before a guardian subcommand that
+// take the next word as their value.
+var guardianGlobalOptions = map[string]bool{"--format-in": true, "--config": true}
+
+// guardianSubcommand returns the guardian command cmd runs, through
+// wrappers (env, sudo, timeout, ...), with its subcommand after the global
+// options and the words after that. self is the name of the running
+// binary, which may not be "guardian". The command is nil if cmd doesn't
+// run guardian.
+func guardianSubcommand(cmd *ParsedCommand, self string) (guardian *ParsedCommand, subcommand string, rest []string) {
+	cmd = parsers.UnwrapCommand(cmd)
+	if name := filepath.Base(cmd.Command); name != "guardian" && name != self {
+		return nil, "", nil
+	}
+	if len(cmd.Words) == 0 {
+		// Commands decoded from JSON without words
+		if len(cmd.Args) == 0 {
+			return cmd, "", nil
+		}
+		return cmd, cmd.Args[0], append(append([]string{}, cmd.Flags...), cmd.Args[1:]...)
+	}
+
+	i := 0
+	for ; i < len(cmd.Words) && strings.HasPrefix(cmd.Words[i], "-"); i++ {
+		if guardianGlobalOptions[cmd.Words[i]] {
+			i++
+		}
+	}
+	if i >= len(cmd.Words) {
+		return cmd, "", nil
+	}
+	return cmd, cmd.Words[i], cmd.Words[i+1:]
+}
+
+// Commands that send their input over the network
+var networkSinkCommands = map[string]bool{
+	"curl": true, "wget": true, "nc": true, "ncat": true, "netcat": true,
+	"socat": true, "ssh": true, "scp": true, "sftp": true, "ftp": true,
+	"telnet": true, "openssl": true,
+}
+
+// checkHiddenPipes checks commands fed through process substitution
+// (tee >(curl -T - url)) or a named pipe (mkfifo p; ... < p), which a
+// plain | check doesn't see.
+func (c *BypassCheck) checkHiddenPipes(parsedCommands []*ParsedCommand) *CheckResult {
+	shellTargets := c.config.BypassPrevention.BlockShellPipeTargets
+
+	for _, cmd := range parsedCommands {
+		via := cmd.PipeVia
+		if via == "" && c.readsNamedPipe(cmd) {
+			via = parsers.PipeViaFifo
+		}
+		if via == "" {
+			continue
+		}
+
+		// Follow the rest of the pipeline: `cat p | nc host 80`
+		for sink := cmd; sink != nil; sink = sink.PipesTo {
+			for _, shell := range shellTargets {
+				if sink.Command == shell || strings.HasSuffix(sink.Command, "/"+shell) {
+					return c.Deny(
+						fmt.Sprintf("Output fed to shell through %s", pipeViaLabel(via)),
+						"Cannot feed data to a shell. Write it to a file, review, then execute.",
+					).WithRule(RuleBypassPipeToShell)
+				}
+			}
+			if networkSinkCommands[sink.Command] {
+				return c.Ask(
+					fmt.Sprintf("Data sent to %s through %s", sink.Command, pipeViaLabel(via)),
+					"Process substitution and named pipes can move local data to the network unseen. Show the user the command and let them run it.",
+				).WithRule(RuleBypassHiddenPipeNetwork)
+			}
+		}
+	}
+
+	return c.Allow()
+}
+
+// pipeViaLabel describes a PipeVia value for messages.
+func pipeViaLabel(via string) string {
+	if via == parsers.PipeViaFifo {
+		return "a named pipe"
+	}
+	return "process substitution"
+}
+
+// readsNamedPipe reports whether cmd reads an existing FIFO on disk
+// (created by an earlier command).
+func (c *BypassCheck) readsNamedPipe(cmd *ParsedCommand) bool {
+	for _, op := range parsers.ClassifyOperands(cmd) {
+		if op.Role != parsers.RoleSource || op.Value == "" || strings.HasPrefix(op.Value, "-") {
+			continue
+		}
+		path := parsers.ResolvePath(parsers.JoinDir(cmd.Dir, op.Value), c.baseDir(c.projectRoot))
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // checkShellExec checks for shell -c execution patterns.
 func (c *BypassCheck) checkShellExec(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
 	for _, pattern := range c.config.BypassPrevention.BlockShellExecPatterns {
@@ -121,7 +280,7 @@ func (c *BypassCheck) checkShellExec(rawCommand string, parsedCommands []*Parsed
 			return c.Deny(
 				fmt.Sprintf("Shell exec pattern detected: %s", pattern),
 				"Direct shell execution with -c is blocked. Run commands directly.",
-			)
+			).WithRule(RuleBypassShellExec)
 		}
 	}
 
@@ -133,7 +292,7 @@ func (c *BypassCheck) checkShellExec(rawCommand string, parsedCommands []*Parsed
 				return c.Deny(
 					fmt.Sprintf("Shell exec detected: %s -c", cmd.Command),
 					"Direct shell execution is blocked. Run the inner command directly.",
-				)
+				).WithRule(RuleBypassShellExec)
 			}
 		case "env":
 			// Check for env -i bash/sh
@@ -142,7 +301,7 @@ func (c *BypassCheck) checkShellExec(rawCommand string, parsedCommands []*Parsed
 					return c.Deny(
 						"env shell execution detected",
 						"Shell execution via env is blocked.",
-					)
+					).WithRule(RuleBypassShellExec)
 				}
 			}
 		case "busybox":
@@ -151,7 +310,7 @@ func (c *BypassCheck) checkShellExec(rawCommand string, parsedCommands []*Parsed
 				return c.Deny(
 					"busybox shell execution detected",
 					"Shell execution via busybox is blocked.",
-				)
+				).WithRule(RuleBypassShellExec)
 			}
 		}
 	}
@@ -203,32 +362,90 @@ func (c *BypassCheck) checkInterpreterNetwork(rawCommand string) *CheckResult {
 		}
 	}
 
+	// Calls only to allowlisted hosts (localhost, the project's dev API)
+	if hasNetwork && c.onlyAllowedHosts(rawCommand) {
+		hasNetwork = false
+	}
+
 	// Determine action based on patterns found
 	if hasNetwork {
 		return c.Confirm(
 			"Inline interpreter code with network calls detected",
 			"This code makes network calls. Verify it's safe before allowing.",
-		)
+		).WithRule(RuleBypassInlineNetwork)
 	}
 
 	if hasObfuscation {
 		return c.Confirm(
 			"Inline interpreter code with potential obfuscation detected",
 			"This code uses import obfuscation. Verify it's safe.",
-		)
+		).WithRule(RuleBypassInlineObfuscation)
 	}
 
 	if hasRCE && hasNetwork {
 		return c.Confirm(
 			"Potential RCE pattern with network access detected",
 			"This code pattern could execute remote code. Verify carefully.",
-		)
+		).WithRule(RuleBypassInlineRCE)
 	}
 
 	// Allow plain inline code without network
 	return c.Allow()
 }
 
+// Hosts referenced by inline code: URLs and (host, port) socket tuples
+var (
+	inlineURLHost   = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://(\[[^\]]+\]|[^/:\s'"?#]+)(?::(\d+))?`)
+	inlineHostTuple = regexp.MustCompile(`\(\s*['"]([^'"]+)['"]\s*,\s*(\d+)\s*\)`)
+)
+
+// onlyAllowedHosts reports whether inline code references at least one host
+// and every referenced host is in inline_network_allowed_hosts.
+func (c *BypassCheck) onlyAllowedHosts(rawCommand string) bool {
+	allowed := c.config.BypassPrevention.InlineNetworkAllowedHosts
+	if len(allowed) == 0 {
+		return false
+	}
+
+	var matches [][]string
+	matches = append(matches, inlineURLHost.FindAllStringSubmatch(rawCommand, -1)...)
+	matches = append(matches, inlineHostTuple.FindAllStringSubmatch(rawCommand, -1)...)
+	if len(matches) == 0 {
+		// Can't tell where the code connects to
+		return false
+	}
+
+	for _, m := range matches {
+		host := strings.Trim(strings.ToLower(m[1]), "[]")
+		if !hostAllowed(host, m[2], allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+// hostAllowed matches host (and port) against allowlist entries.
+func hostAllowed(host string, port string, allowed []string) bool {
+	for _, entry := range allowed {
+		entry = strings.ToLower(entry)
+		entryPort := ""
+		// host:port, but not a bare IPv6 address
+		if i := strings.LastIndex(entry, ":"); i > 0 && strings.Count(entry, ":") == 1 {
+			entry, entryPort = entry[:i], entry[i+1:]
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+		if entry == host {
+			return true
+		}
+		if strings.HasPrefix(entry, "*.") && strings.HasSuffix(host, entry[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
 // containsFlag checks if a flag is in the list.
 func containsFlag(flags []string, target string) bool {
 	for _, f := range flags {
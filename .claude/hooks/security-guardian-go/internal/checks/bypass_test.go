@@ -0,0 +1,69 @@
+package checks
+
+import "testing"
+
+// TestBypassCheckSelfTrust checks that guardian commands reserved for the
+// user are denied however guardian is started.
+func TestBypassCheckSelfTrust(t *testing.T) {
+	tests := []struct {
+		command string
+		want    PermissionDecision
+		rule    string
+	}{
+		{"guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"env guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"env -i PATH=/usr/bin guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"command guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"nice -n 5 guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"nohup guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"timeout 10 guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"exec guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"sudo -u root guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"./bin/guardian allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"guardian --format-in generic allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"guardian --format-in=generic allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"guardian --config guardian.yaml allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"env guardian --format-in cursor allow ABC123", DecisionDeny, RuleBypassSelfException},
+		{"guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"env guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"env A=1 guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"command guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"nice guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"nohup guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"timeout 5 guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"exec guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"sudo guardian trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"guardian --format-in generic trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"guardian --config x.yaml trust ./evil.sh", DecisionDeny, RuleBypassSelfTrust},
+		{"guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"env guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"command guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"nice -n 19 guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"nohup guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"timeout 60 guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"exec guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"sudo guardian self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"guardian --format-in generic self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"guardian --config x.yaml self-update", DecisionDeny, RuleBypassSelfUpdate},
+		{"guardian self-update --check", DecisionAllow, ""},
+		{"env guardian --format-in generic self-update --check", DecisionAllow, ""},
+		{"guardian trust --list", DecisionAllow, ""},
+		{"env guardian trust --list", DecisionAllow, ""},
+		{"guardian allow --list", DecisionAllow, ""},
+		{"env guardian allow --list", DecisionAllow, ""},
+		{"guardian --format-in allow", DecisionAllow, ""},
+		{"echo guardian allow ABC123", DecisionAllow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			check := NewBypassCheck(newTestEngine(t))
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if result.RuleID != tt.rule {
+				t.Errorf("rule = %q, want %q", result.RuleID, tt.rule)
+			}
+		})
+	}
+}
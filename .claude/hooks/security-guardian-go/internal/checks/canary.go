@@ -0,0 +1,72 @@
+package checks
+
+import (
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// CanaryCheck denies tool inputs that carry the session's canary token.
+// The token only ever appears in deny messages, so an input repeating it
+// is guardian text being fed back: written to a file for a later session,
+// sent over the network or handed to a sub-agent as instructions.
+type CanaryCheck struct {
+	BaseCheck
+	token string
+}
+
+// NewCanaryCheck creates a new CanaryCheck instance. It has nothing to
+// look for until a deny message has given out the session's token.
+func NewCanaryCheck(e *Engine) *CanaryCheck {
+	c := &CanaryCheck{BaseCheck: BaseCheck{CheckName: "canary_check"}}
+	if e.Config.CanaryTokens.Enabled {
+		idle := time.Duration(e.Config.MassModification.SessionIdleMinutes) * time.Minute
+		c.token = state.LoadSession(e.SessionFile(), idle).CanaryToken
+	}
+	return c
+}
+
+// CheckCommand checks the raw command for the token.
+func (c *CanaryCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.CheckContent(rawCommand)
+}
+
+// CheckContent checks text for the token.
+func (c *CanaryCheck) CheckContent(content string) *CheckResult {
+	if c.token == "" || !strings.Contains(content, c.token) {
+		return c.Allow()
+	}
+	return c.Deny(
+		"Tool input contains the reference of an earlier guardian message",
+		"Guardian messages are for you, not for files, commands or other agents. Leave the reference out and continue with the task.",
+	).WithRule(RuleCanaryReuse)
+}
+
+// CheckInput checks every string in a tool input, whatever the tool.
+func (c *CanaryCheck) CheckInput(toolInput map[string]interface{}) *CheckResult {
+	if c.token == "" {
+		return c.Allow()
+	}
+	return c.checkValue(toolInput)
+}
+
+func (c *CanaryCheck) checkValue(v interface{}) *CheckResult {
+	switch x := v.(type) {
+	case string:
+		return c.CheckContent(x)
+	case map[string]interface{}:
+		for _, item := range x {
+			if result := c.checkValue(item); !result.IsAllowed() {
+				return result
+			}
+		}
+	case []interface{}:
+		for _, item := range x {
+			if result := c.checkValue(item); !result.IsAllowed() {
+				return result
+			}
+		}
+	}
+	return c.Allow()
+}
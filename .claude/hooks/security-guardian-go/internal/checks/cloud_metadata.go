@@ -0,0 +1,102 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// CloudMetadataCheck denies requests to cloud instance metadata services.
+// They hand out live credentials of the machine's role to anything that
+// asks, which makes them the first target of an injected prompt. The
+// endpoints are looked for in the raw text, so curl, wget and inline
+// python -c / node -e code are covered alike.
+type CloudMetadataCheck struct {
+	BaseCheck
+	config *config.SecurityConfig
+}
+
+// NewCloudMetadataCheck creates a new CloudMetadataCheck instance.
+func NewCloudMetadataCheck(e *Engine) *CloudMetadataCheck {
+	return &CloudMetadataCheck{
+		BaseCheck: BaseCheck{CheckName: "cloud_metadata_check"},
+		config:    e.Config,
+	}
+}
+
+// CheckCommand checks the raw command, inline code included.
+func (c *CloudMetadataCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.CheckText(rawCommand)
+}
+
+// CheckText checks a command, URL or code for a metadata endpoint that is
+// not in cloud_metadata.allowed.
+func (c *CloudMetadataCheck) CheckText(text string) *CheckResult {
+	cm := c.config.CloudMetadata
+	if !cm.Enabled {
+		return c.Allow()
+	}
+
+	// ASCII only, so offsets in lower are offsets in text
+	lower := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, text)
+	for _, endpoint := range cm.Endpoints {
+		endpoint = strings.ToLower(endpoint)
+		if endpoint == "" {
+			continue
+		}
+		for from := 0; ; {
+			i := strings.Index(lower[from:], endpoint)
+			if i < 0 {
+				break
+			}
+			i += from
+			from = i + len(endpoint)
+			// 10.169.254.169.254 or a longer host name is something else
+			if i > 0 && isHostChar(lower[i-1]) {
+				continue
+			}
+			target := metadataTarget(text[i:])
+			if c.isAllowed(target) {
+				continue
+			}
+			return c.Deny(
+				fmt.Sprintf("Request to cloud metadata endpoint: %s", target),
+				"Instance metadata services hand out the machine's cloud credentials. Do not query them; ask the user for the value you need.",
+			).WithRule(RuleCloudMetadata).WithPattern(endpoint)
+		}
+	}
+
+	return c.Allow()
+}
+
+// isAllowed reports whether target (host and path) starts with an entry
+// of cloud_metadata.allowed.
+func (c *CloudMetadataCheck) isAllowed(target string) bool {
+	target = strings.ToLower(target)
+	for _, allowed := range c.config.CloudMetadata.Allowed {
+		if allowed != "" && strings.HasPrefix(target, strings.ToLower(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// metadataTarget returns the host and path starting text, up to the end
+// of the word. The bracket closing an IPv6 host is dropped.
+func metadataTarget(text string) string {
+	if end := strings.IndexAny(text, " \t\r\n'\"`;|&()<>,"); end >= 0 {
+		text = text[:end]
+	}
+	return strings.Replace(text, "]", "", 1)
+}
+
+// isHostChar reports whether b can be part of a host name or address.
+func isHostChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '.' || b == '-' || b == ':'
+}
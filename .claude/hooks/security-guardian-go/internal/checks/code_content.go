@@ -9,6 +9,7 @@ import (
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
 )
 
 // CodeContentCheck checks script content for dangerous patterns.
@@ -16,15 +17,35 @@ type CodeContentCheck struct {
 	BaseCheck
 	projectRoot string
 	config      *config.SecurityConfig
+	trusted     *trust.Manifest
+	engine      *Engine
+
+	// Compiled patterns: common ones plus per-language sections
+	common         patternSet
+	languages      map[string]patternSet
+	codePatterns   []codePatternItem
+	envVarPatterns []*regexp.Regexp
+	notebookMeta   []codePatternItem
+}
+
+// patternSet holds compiled dangerous_operations patterns by category.
+type patternSet struct {
+	network   []*regexp.Regexp
+	sensitive []*regexp.Regexp
+	scanning  []*regexp.Regexp
+	recon     []*regexp.Regexp
+	dynamic   []*regexp.Regexp
+}
 
-	// Compiled patterns
-	networkPatterns   []*regexp.Regexp
-	sensitivePatterns []*regexp.Regexp
-	scanningPatterns  []*regexp.Regexp
-	reconPatterns     []*regexp.Regexp
-	dynamicPatterns   []*regexp.Regexp
-	codePatterns      []codePatternItem
-	envVarPatterns    []*regexp.Regexp
+// Languages with their own dangerous_operations section
+var patternLanguages = []string{"python", "javascript", "shell"}
+
+// Extensions mapped to a language section
+var languageExtensions = map[string]string{
+	".py": "python", ".pyw": "python", ".ipynb": "python",
+	".js": "javascript", ".mjs": "javascript", ".cjs": "javascript",
+	".ts": "javascript", ".jsx": "javascript", ".tsx": "javascript",
+	".sh": "shell", ".bash": "shell", ".zsh": "shell", ".ksh": "shell",
 }
 
 type codePatternItem struct {
@@ -33,29 +54,31 @@ type codePatternItem struct {
 }
 
 // NewCodeContentCheck creates a new CodeContentCheck instance.
-func NewCodeContentCheck(cfg *config.SecurityConfig) *CodeContentCheck {
+func NewCodeContentCheck(e *Engine) *CodeContentCheck {
 	c := &CodeContentCheck{
 		BaseCheck:   BaseCheck{CheckName: "code_content_check"},
-		projectRoot: parsers.GetProjectRoot(),
-		config:      cfg,
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
+		engine:      e,
 	}
-	c.compilePatterns()
+	c.trusted = e.Trusted()
+	c.compilePatterns(e)
 	return c
 }
 
 // compilePatterns compiles regex patterns from config.
-func (c *CodeContentCheck) compilePatterns() {
-	ops := c.config.DangerousOperations
+func (c *CodeContentCheck) compilePatterns(e *Engine) {
+	ops := &c.config.DangerousOperations
 
-	c.networkPatterns = compilePatterns(ops.Network)
-	c.sensitivePatterns = compilePatterns(ops.SensitiveAccess)
-	c.scanningPatterns = compilePatterns(ops.SecretScanning)
-	c.reconPatterns = compilePatterns(ops.SystemRecon)
-	c.dynamicPatterns = compilePatterns(ops.DynamicExecution)
+	c.common = compilePatternSet(e, &ops.LanguagePatterns)
+	c.languages = make(map[string]patternSet)
+	for _, lang := range patternLanguages {
+		c.languages[lang] = compilePatternSet(e, ops.ForLanguage(lang))
+	}
 
 	// Compile code patterns from sensitive_files config
 	for _, item := range c.config.SensitiveFiles.CodePatterns {
-		if re := compilePattern(item.Pattern); re != nil {
+		if re := e.compile(item.Pattern); re != nil {
 			c.codePatterns = append(c.codePatterns, codePatternItem{
 				pattern:     re,
 				description: item.Description,
@@ -65,7 +88,7 @@ func (c *CodeContentCheck) compilePatterns() {
 
 	// Custom patterns
 	for _, item := range c.config.SensitiveFiles.CustomPatterns {
-		if re := compilePattern(item.Pattern); re != nil {
+		if re := e.compile(item.Pattern); re != nil {
 			c.codePatterns = append(c.codePatterns, codePatternItem{
 				pattern:     re,
 				description: item.Description,
@@ -73,33 +96,91 @@ func (c *CodeContentCheck) compilePatterns() {
 		}
 	}
 
+	for _, item := range c.config.DangerousOperations.NotebookMetadata {
+		if re := e.compile(item.Pattern); re != nil {
+			c.notebookMeta = append(c.notebookMeta, codePatternItem{
+				pattern:     re,
+				description: item.Description,
+			})
+		}
+	}
+
 	// Secret env var patterns
 	for _, varName := range c.config.SensitiveFiles.SecretEnvVars {
 		pattern := fmt.Sprintf(`(getenv|environ)\s*[\[\(]['"]?%s['"]?[\]\)]`, regexp.QuoteMeta(varName))
-		if re := compilePattern(pattern); re != nil {
+		if re := e.compile(pattern); re != nil {
 			c.envVarPatterns = append(c.envVarPatterns, re)
 		}
 	}
 }
 
-// compilePatterns compiles a list of pattern strings.
-func compilePatterns(patterns []string) []*regexp.Regexp {
-	var result []*regexp.Regexp
-	for _, p := range patterns {
-		if re := compilePattern(p); re != nil {
-			result = append(result, re)
-		}
+// compilePatternSet compiles one dangerous_operations section.
+func compilePatternSet(e *Engine, lp *config.LanguagePatterns) patternSet {
+	return patternSet{
+		network:   e.compileAll(lp.Network),
+		sensitive: e.compileAll(lp.SensitiveAccess),
+		scanning:  e.compileAll(lp.SecretScanning),
+		recon:     e.compileAll(lp.SystemRecon),
+		dynamic:   e.compileAll(lp.DynamicExecution),
 	}
-	return result
 }
 
-// compilePattern compiles a single pattern string.
-func compilePattern(pattern string) *regexp.Regexp {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil
+// patternsFor returns the common patterns plus those of the given language.
+func (c *CodeContentCheck) patternsFor(lang string) patternSet {
+	set := c.common
+	extra, ok := c.languages[lang]
+	if !ok {
+		return set
+	}
+	join := func(a, b []*regexp.Regexp) []*regexp.Regexp {
+		return append(append([]*regexp.Regexp{}, a...), b...)
+	}
+	return patternSet{
+		network:   join(set.network, extra.network),
+		sensitive: join(set.sensitive, extra.sensitive),
+		scanning:  join(set.scanning, extra.scanning),
+		recon:     join(set.recon, extra.recon),
+		dynamic:   join(set.dynamic, extra.dynamic),
 	}
-	return re
+}
+
+// detectLanguage guesses the language of content from the file extension,
+// falling back to the shebang line. Returns "" if unknown.
+func detectLanguage(filePath, content string) string {
+	// Notebook cells are reported as "nb.ipynb (cell)" or "nb.ipynb (code cells)"
+	name := strings.TrimSuffix(strings.TrimSuffix(filePath, " (cell)"), " (code cells)")
+	if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return lang
+	}
+
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line := content
+	if idx := strings.Index(line, "\n"); idx >= 0 {
+		line = line[:idx]
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" && len(fields) > 1 {
+		interp = fields[1]
+		// #!/usr/bin/env -S node --flags
+		if interp == "-S" && len(fields) > 2 {
+			interp = fields[2]
+		}
+	}
+	switch {
+	case strings.HasPrefix(interp, "python"):
+		return "python"
+	case interp == "node" || interp == "nodejs" || interp == "deno" || interp == "bun":
+		return "javascript"
+	case interp == "sh" || interp == "bash" || interp == "zsh" || interp == "ksh" || interp == "dash":
+		return "shell"
+	}
+	return ""
 }
 
 // CheckCommand is not used for content check - use CheckContent instead.
@@ -118,70 +199,42 @@ func (c *CodeContentCheck) CheckContent(content string, filePath string) *CheckR
 		fileName = "script"
 	}
 
-	// Track found patterns
-	var networkFound []string
-	var sensitiveFound []string
-	var scanningFound []string
-	var reconFound []string
-	var dynamicFound []string
-	var codePatternFound []codePatternMatch
-	var envVarFound []string
-
-	// Check network patterns
-	for _, re := range c.networkPatterns {
-		if match := re.FindString(content); match != "" {
-			networkFound = append(networkFound, c.findLineContext(content, match))
-		}
-	}
-
-	// Check sensitive access patterns
-	for _, re := range c.sensitivePatterns {
-		if match := re.FindString(content); match != "" {
-			sensitiveFound = append(sensitiveFound, c.findLineContext(content, match))
-		}
-	}
-
-	// Check secret scanning patterns
-	for _, re := range c.scanningPatterns {
-		if match := re.FindString(content); match != "" {
-			scanningFound = append(scanningFound, c.findLineContext(content, match))
-		}
-	}
-
-	// Check system recon patterns
-	for _, re := range c.reconPatterns {
-		if match := re.FindString(content); match != "" {
-			reconFound = append(reconFound, c.findLineContext(content, match))
+	lang := detectLanguage(filePath, content)
+	patterns := c.patternsFor(lang)
+
+	// Oversized content is scanned in sampled windows (input_limits).
+	// Commented-out code and docstrings don't run; blanking them keeps
+	// offsets and lines, so only the scanned windows are copied
+	windows := scanWindows(content, c.config.InputLimits)
+	texts := make([]string, len(windows))
+	for i, w := range windows {
+		texts[i] = content[w[0]:w[1]]
+		if !c.config.DangerousOperations.Strict {
+			texts[i] = stripComments(texts[i], lang)
 		}
 	}
 
-	// Check dynamic execution patterns
-	for _, re := range c.dynamicPatterns {
-		if match := re.FindString(content); match != "" {
-			dynamicFound = append(dynamicFound, c.findLineContext(content, match))
-		}
-	}
+	// Track found patterns
+	networkFound := c.scan(content, windows, texts, filePath, "network", patterns.network)
+	sensitiveFound := c.scan(content, windows, texts, filePath, "sensitive_access", patterns.sensitive)
+	scanningFound := c.scan(content, windows, texts, filePath, "secret_scanning", patterns.scanning)
+	reconFound := c.scan(content, windows, texts, filePath, "system_recon", patterns.recon)
+	dynamicFound := c.scan(content, windows, texts, filePath, "dynamic_execution", patterns.dynamic)
+	envVarFound := c.scan(content, windows, texts, filePath, "secret_env_var", c.envVarPatterns)
 
 	// Check code patterns from config
+	var codePatternFound []Finding
 	for _, item := range c.codePatterns {
-		if match := item.pattern.FindString(content); match != "" {
-			codePatternFound = append(codePatternFound, codePatternMatch{
-				match:       match,
-				description: item.description,
-			})
-		}
-	}
-
-	// Check secret env var patterns
-	for _, re := range c.envVarPatterns {
-		if match := re.FindString(content); match != "" {
-			envVarFound = append(envVarFound, match)
+		for _, f := range c.scan(content, windows, texts, filePath, "code_pattern", []*regexp.Regexp{item.pattern}) {
+			f.Description = item.description
+			codePatternFound = append(codePatternFound, f)
 		}
 	}
 
 	// EXFILTRATION RISK: network + sensitive access
 	if len(networkFound) > 0 && (len(sensitiveFound) > 0 || len(codePatternFound) > 0 || len(envVarFound) > 0) {
-		return c.buildExfiltrationWarning(fileName, networkFound, sensitiveFound, codePatternFound, envVarFound)
+		return c.buildExfiltrationWarning(fileName, networkFound, sensitiveFound, codePatternFound, envVarFound).
+			WithDetails(c.details(networkFound, sensitiveFound, codePatternFound, envVarFound))
 	}
 
 	// SECRET SCANNING: dangerous by itself
@@ -189,7 +242,7 @@ func (c *CodeContentCheck) CheckContent(content string, filePath string) *CheckR
 		return c.Ask(
 			fmt.Sprintf("Script %s contains secret scanning patterns", fileName),
 			c.formatScanningWarning(scanningFound),
-		)
+		).WithRule(RuleCodeSecretScan).WithDetails(c.details(scanningFound))
 	}
 
 	// DYNAMIC EXECUTION: dangerous by itself
@@ -197,7 +250,7 @@ func (c *CodeContentCheck) CheckContent(content string, filePath string) *CheckR
 		return c.Ask(
 			fmt.Sprintf("Script %s uses dynamic code execution", fileName),
 			c.formatDynamicWarning(dynamicFound),
-		)
+		).WithRule(RuleCodeDynamicExec).WithDetails(c.details(dynamicFound))
 	}
 
 	// SYSTEM RECON + NETWORK: could be data gathering
@@ -205,12 +258,73 @@ func (c *CodeContentCheck) CheckContent(content string, filePath string) *CheckR
 		return c.Ask(
 			fmt.Sprintf("Script %s gathers system info with network access", fileName),
 			c.formatReconWarning(networkFound, reconFound),
-		)
+		).WithRule(RuleCodeSystemRecon).WithDetails(c.details(networkFound, reconFound))
 	}
 
 	return c.Allow()
 }
 
+// scan returns the matches of patterns in the scanned windows of content,
+// texts being the windows with comments blanked: the first match of each
+// pattern, or up to input_limits.max_pattern_matches when
+// report_all_matches is enabled.
+func (c *CodeContentCheck) scan(content string, windows [][2]int, texts []string, filePath, category string, patterns []*regexp.Regexp) []Finding {
+	limit := 1
+	if c.config.DangerousOperations.ReportAllMatches {
+		limit = c.config.InputLimits.MaxPatternMatches
+	}
+
+	lines := &lineCounter{text: content}
+	var found []Finding
+	for _, re := range patterns {
+		n := 0
+		for i, w := range windows {
+			max := 0
+			if limit > 0 {
+				if n >= limit {
+					break
+				}
+				max = limit - n
+			}
+			text := texts[i]
+			matchChunks(c.engine.Context(), re, text, c.config.InputLimits, max, func(start, end int) bool {
+				line, col := lines.lineColumn(w[0] + start)
+				found = append(found, Finding{
+					Category: category,
+					File:     filePath,
+					Line:     line,
+					Column:   col,
+					Match:    text[start:end],
+				})
+				n++
+				return true
+			})
+		}
+	}
+	return found
+}
+
+// details joins findings for the structured result when report_all_matches
+// is enabled.
+func (c *CodeContentCheck) details(groups ...[]Finding) []Finding {
+	if !c.config.DangerousOperations.ReportAllMatches {
+		return nil
+	}
+	var all []Finding
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// listLimit returns how many entries of a warning list to show.
+func (c *CodeContentCheck) listLimit(n int) int {
+	if c.config.DangerousOperations.ReportAllMatches {
+		return -1
+	}
+	return n
+}
+
 // CheckFile checks a file for dangerous patterns.
 // The filePath is resolved against project root to ensure correct file access
 // regardless of the hook's working directory.
@@ -222,74 +336,84 @@ func (c *CodeContentCheck) CheckFile(filePath string) *CheckResult {
 		return c.Allow()
 	}
 
-	// Resolve path against project root so relative paths work
-	// even when the hook is invoked from a different cwd
-	resolved := parsers.ResolvePath(filePath, c.projectRoot)
+	// Resolve path against the session working directory (falls back to
+	// project root) so relative paths match where the script actually is
+	resolved := parsers.ResolvePath(filePath, c.baseDir(c.projectRoot))
+
+	// Reviewed scripts skip heuristics while unchanged
+	if c.trusted != nil {
+		switch c.trusted.Lookup(c.projectRoot, resolved) {
+		case trust.Trusted:
+			return c.Allow()
+		case trust.Changed:
+			return c.Ask(
+				fmt.Sprintf("Trusted script changed since it was reviewed: %s", filePath),
+				fmt.Sprintf("Show the user the diff. If it is fine, they can run `guardian trust %s` again.", filePath),
+			).WithRule(RuleCodeTrustedChanged)
+		}
+	}
 
 	content, err := os.ReadFile(resolved)
 	if err != nil {
+		// A script that doesn't exist yet has nothing to check
+		if !os.IsNotExist(err) {
+			c.engine.Errorf(ErrorRead, "%v", err)
+		}
 		return c.Allow()
 	}
 
 	return c.CheckContent(string(content), filePath)
 }
 
-type codePatternMatch struct {
-	match       string
-	description string
+// lineColumn returns the 1-based line and column of offset in content.
+func lineColumn(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	col := offset - strings.LastIndex(content[:offset], "\n")
+	return line, col
+}
+
+// formatFinding formats a finding as "match (line N, col M)".
+func formatFinding(f Finding) string {
+	return fmt.Sprintf("%s (line %d, col %d)", f.Match, f.Line, f.Column)
 }
 
-// findLineContext finds the line number and context for a match.
-func (c *CodeContentCheck) findLineContext(content string, match string) string {
-	idx := strings.Index(content, match)
-	if idx < 0 {
-		return match
+// appendFindings appends up to limit findings (all if limit < 0) as list items.
+func appendFindings(lines []string, indent string, found []Finding, limit int) []string {
+	for i, f := range found {
+		if limit >= 0 && i >= limit {
+			lines = append(lines, fmt.Sprintf("%s... and %d more", indent, len(found)-limit))
+			break
+		}
+		item := formatFinding(f)
+		if f.Description != "" {
+			item = fmt.Sprintf("%s: %s", f.Description, item)
+		}
+		lines = append(lines, indent+"- "+item)
 	}
-	lineNum := strings.Count(content[:idx], "\n") + 1
-	return fmt.Sprintf("%s (line %d)", match, lineNum)
+	return lines
 }
 
 // buildExfiltrationWarning builds exfiltration risk warning.
-func (c *CodeContentCheck) buildExfiltrationWarning(fileName string, network []string, sensitive []string, codePatterns []codePatternMatch, envVars []string) *CheckResult {
+func (c *CodeContentCheck) buildExfiltrationWarning(fileName string, network []Finding, sensitive []Finding, codePatterns []Finding, envVars []Finding) *CheckResult {
 	var parts []string
 	parts = append(parts, fmt.Sprintf("EXFILTRATION RISK: %s contains:", fileName))
 
 	parts = append(parts, "  Network calls:")
-	for i, n := range network {
-		if i >= 3 {
-			break
-		}
-		parts = append(parts, fmt.Sprintf("    - %s", n))
-	}
+	parts = appendFindings(parts, "    ", network, c.listLimit(3))
 
 	if len(sensitive) > 0 {
 		parts = append(parts, "  Sensitive file access:")
-		for i, s := range sensitive {
-			if i >= 3 {
-				break
-			}
-			parts = append(parts, fmt.Sprintf("    - %s", s))
-		}
+		parts = appendFindings(parts, "    ", sensitive, c.listLimit(3))
 	}
 
 	if len(codePatterns) > 0 {
 		parts = append(parts, "  Secret access patterns:")
-		for i, p := range codePatterns {
-			if i >= 3 {
-				break
-			}
-			parts = append(parts, fmt.Sprintf("    - %s: %s", p.description, p.match))
-		}
+		parts = appendFindings(parts, "    ", codePatterns, c.listLimit(3))
 	}
 
 	if len(envVars) > 0 {
 		parts = append(parts, "  Secret env vars:")
-		for i, e := range envVars {
-			if i >= 3 {
-				break
-			}
-			parts = append(parts, fmt.Sprintf("    - %s", e))
-		}
+		parts = appendFindings(parts, "    ", envVars, c.listLimit(3))
 	}
 
 	parts = append(parts, "\nThis could be an attempt to send your secrets externally.")
@@ -297,52 +421,32 @@ func (c *CodeContentCheck) buildExfiltrationWarning(fileName string, network []s
 	return c.Ask(
 		fmt.Sprintf("Script %s has network + sensitive data access (exfiltration risk)", fileName),
 		strings.Join(parts, "\n"),
-	)
+	).WithRule(RuleCodeExfiltration)
 }
 
 // formatScanningWarning formats secret scanning warning.
-func (c *CodeContentCheck) formatScanningWarning(patterns []string) string {
+func (c *CodeContentCheck) formatScanningWarning(patterns []Finding) string {
 	lines := []string{"Script searches for secrets/passwords:"}
-	for i, p := range patterns {
-		if i >= 5 {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("  - %s", p))
-	}
+	lines = appendFindings(lines, "  ", patterns, c.listLimit(5))
 	lines = append(lines, "\nThis could be attempting to find and collect credentials.")
 	return strings.Join(lines, "\n")
 }
 
 // formatDynamicWarning formats dynamic execution warning.
-func (c *CodeContentCheck) formatDynamicWarning(patterns []string) string {
+func (c *CodeContentCheck) formatDynamicWarning(patterns []Finding) string {
 	lines := []string{"Script uses dynamic code execution:"}
-	for i, p := range patterns {
-		if i >= 5 {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("  - %s", p))
-	}
+	lines = appendFindings(lines, "  ", patterns, c.listLimit(5))
 	lines = append(lines, "\nexec/eval/compile can hide malicious code.")
 	return strings.Join(lines, "\n")
 }
 
 // formatReconWarning formats reconnaissance warning.
-func (c *CodeContentCheck) formatReconWarning(network []string, recon []string) string {
+func (c *CodeContentCheck) formatReconWarning(network []Finding, recon []Finding) string {
 	lines := []string{"Script gathers system info with network access:"}
 	lines = append(lines, "  Network:")
-	for i, n := range network {
-		if i >= 3 {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("    - %s", n))
-	}
+	lines = appendFindings(lines, "    ", network, c.listLimit(3))
 	lines = append(lines, "  System info:")
-	for i, r := range recon {
-		if i >= 3 {
-			break
-		}
-		lines = append(lines, fmt.Sprintf("    - %s", r))
-	}
+	lines = appendFindings(lines, "    ", recon, c.listLimit(3))
 	lines = append(lines, "\nCould be fingerprinting your system.")
 	return strings.Join(lines, "\n")
 }
@@ -0,0 +1,158 @@
+package checks
+
+import "strings"
+
+// stripComments blanks comments (and Python docstrings) in content so
+// patterns don't fire on commented-out code or documentation. Ordinary
+// string literals are kept: paths like ".env" and commands like "whoami"
+// live in them. Blanked runs are replaced with spaces and newlines are
+// kept, so line numbers of the remaining matches don't change.
+// Unknown languages are returned unchanged.
+func stripComments(content, lang string) string {
+	switch lang {
+	case "python":
+		return stripPython(content)
+	case "javascript":
+		return stripJavaScript(content)
+	case "shell":
+		return stripShell(content)
+	}
+	return content
+}
+
+// blank replaces b[from:to] with spaces, keeping newlines.
+func blank(b []byte, from, to int) {
+	for i := from; i < to && i < len(b); i++ {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
+
+// lineEnd returns the index of the newline ending the line at i (or len).
+func lineEnd(s string, i int) int {
+	if idx := strings.IndexByte(s[i:], '\n'); idx >= 0 {
+		return i + idx
+	}
+	return len(s)
+}
+
+// skipQuoted returns the index just past the string literal starting at i
+// (s[i] is the quote). Backslash escapes are honoured unless raw is set.
+func skipQuoted(s string, i int, quote byte, raw bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if !raw {
+				j++
+			}
+		case quote:
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// stripPython blanks # comments and triple-quoted strings that stand alone
+// as a statement (docstrings).
+func stripPython(s string) string {
+	b := []byte(s)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '#':
+			end := lineEnd(s, i)
+			blank(b, i, end)
+			i = end
+		case strings.HasPrefix(s[i:], `"""`) || strings.HasPrefix(s[i:], `'''`):
+			quote := s[i : i+3]
+			end := strings.Index(s[i+3:], quote)
+			if end < 0 {
+				end = len(s)
+			} else {
+				end += i + 6
+			}
+			if isStatementStart(s, i) {
+				blank(b, i, end)
+			}
+			i = end
+		case c == '"' || c == '\'':
+			end := skipQuoted(s, i, c, false)
+			// Python strings don't span lines without a backslash
+			if nl := strings.IndexByte(s[i:end], '\n'); nl >= 0 {
+				end = i + nl
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return string(b)
+}
+
+// isStatementStart reports whether only whitespace (or a string prefix like
+// r/b/f) precedes position i on its line.
+func isStatementStart(s string, i int) bool {
+	start := strings.LastIndexByte(s[:i], '\n') + 1
+	prefix := strings.TrimSpace(s[start:i])
+	return strings.Trim(strings.ToLower(prefix), "rbuf") == "" && len(prefix) <= 2
+}
+
+// stripJavaScript blanks // and /* */ comments. String and template
+// literals are skipped so comment markers inside them are left alone.
+func stripJavaScript(s string) string {
+	b := []byte(s)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case strings.HasPrefix(s[i:], "//") && commentMayStart(s, i):
+			end := lineEnd(s, i)
+			blank(b, i, end)
+			i = end
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				end = len(s)
+			} else {
+				end += i + 4
+			}
+			blank(b, i, end)
+			i = end
+		case c == '"' || c == '\'' || c == '`':
+			i = skipQuoted(s, i, c, false)
+		default:
+			i++
+		}
+	}
+	return string(b)
+}
+
+// commentMayStart reports whether // at i can open a comment rather than
+// being part of a regex literal or URL (e.g. /a\/\//, http://).
+func commentMayStart(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	return strings.IndexByte(" \t\n;{}(),", s[i-1]) >= 0
+}
+
+// stripShell blanks # comments. A # only opens a comment at the start of a
+// word and outside quotes ($#, ${#var} and a#b are not comments).
+func stripShell(s string) string {
+	b := []byte(s)
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\':
+			i += 2
+		case c == '\'':
+			i = skipQuoted(s, i, c, true)
+		case c == '"':
+			i = skipQuoted(s, i, c, false)
+		case c == '#' && (i == 0 || strings.IndexByte(" \t\n;&|()", s[i-1]) >= 0):
+			end := lineEnd(s, i)
+			blank(b, i, end)
+			i = end
+		default:
+			i++
+		}
+	}
+	return string(b)
+}
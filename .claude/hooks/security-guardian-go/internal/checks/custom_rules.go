@@ -0,0 +1,382 @@
+package checks
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/expr"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+)
+
+// CustomRuleCheck applies custom_rules: boolean expressions over signals
+// of a command or file operation (see BashSignals and FileSignals). It
+// runs after the built-in checks, so it can only tighten what they allow.
+type CustomRuleCheck struct {
+	BaseCheck
+	projectRoot string
+	config      *config.SecurityConfig
+	trusted     *trust.Manifest
+	rules       []customRule
+}
+
+// customRule is a compiled custom_rules entry.
+type customRule struct {
+	config.CustomRule
+	ruleID string
+	when   *expr.Expr
+	tools  map[string]bool
+}
+
+// BashSignals are the signals of a Bash command a custom rule can use.
+var BashSignals = map[string]string{
+	"download":           "runs a download command (curl, wget, fetch, aria2c)",
+	"network":            "runs a command that talks to the network (downloads, ssh, scp, nc, ...)",
+	"pipe_to_shell":      "pipes output into a shell (bypass_prevention.block_shell_pipe_targets)",
+	"interpreter_inline": "runs inline interpreter code (bypass_prevention.confirm_interpreter_inline_with_network)",
+	"inline_network":     "contains a network pattern (bypass_prevention.network_patterns)",
+	"obfuscation":        "contains an obfuscation pattern (bypass_prevention.obfuscation_patterns, base64 -d, xxd -r)",
+	"outside_project":    "has a path operand outside the project root, allowed_paths included",
+	"secrets_path":       "has a path operand matching no_read_content or forbidden_read",
+	"writes":             "writes, moves or changes a path operand",
+	"delete":             "deletes files (rm, rmdir, unlink, shred)",
+	"git":                "runs git",
+	"sudo":               "runs sudo or doas",
+	"trusted_script":     "has an operand that is an unchanged trusted script (guardian trust)",
+	"ci":                 "runs in a CI environment",
+}
+
+// FileSignals are the signals of a Read, Write, Edit or NotebookEdit call.
+var FileSignals = map[string]string{
+	"read":            "the tool reads the file",
+	"write":           "the tool writes the file",
+	"outside_project": "the file is outside the project root, allowed_paths included",
+	"secrets_path":    "the file matches no_read_content or forbidden_read",
+	"trusted_script":  "the file is an unchanged trusted script (guardian trust)",
+	"ci":              "runs in a CI environment",
+}
+
+// customRuleTools are the tools custom rules can apply to.
+var customRuleTools = map[string]map[string]string{
+	"Bash":         BashSignals,
+	"Read":         FileSignals,
+	"Write":        FileSignals,
+	"Edit":         FileSignals,
+	"NotebookEdit": FileSignals,
+}
+
+// networkCommands talk to the network besides downloadCommands.
+var networkCommands = map[string]bool{
+	"ssh": true, "scp": true, "sftp": true, "rsync": true, "nc": true,
+	"ncat": true, "netcat": true, "socat": true, "telnet": true, "ftp": true,
+}
+
+// CustomRuleID returns the rule ID of a custom rule: its id with a
+// "custom." prefix.
+func CustomRuleID(id string) string {
+	if strings.HasPrefix(id, "custom.") {
+		return id
+	}
+	return "custom." + id
+}
+
+// NewCustomRuleCheck creates a new CustomRuleCheck instance. Rules with
+// errors (see CustomRuleErrors) are skipped.
+func NewCustomRuleCheck(e *Engine) *CustomRuleCheck {
+	rules, errs := compileCustomRules(e.Config)
+	for i, err := range errs {
+		e.Errorf(ErrorConfig, "custom_rules[%d]: %v", i, err)
+	}
+	c := &CustomRuleCheck{
+		BaseCheck:   BaseCheck{CheckName: "custom_rules"},
+		projectRoot: e.BoundaryRoot,
+		config:      e.Config,
+		rules:       rules,
+	}
+	if len(rules) > 0 {
+		c.trusted = e.Trusted()
+	}
+	return c
+}
+
+// CustomRuleErrors returns the problem of each invalid custom_rules
+// entry in cfg, by index.
+func CustomRuleErrors(cfg *config.SecurityConfig) map[int]error {
+	_, errs := compileCustomRules(cfg)
+	return errs
+}
+
+// compileCustomRules compiles the valid custom_rules of cfg.
+func compileCustomRules(cfg *config.SecurityConfig) ([]customRule, map[int]error) {
+	var rules []customRule
+	errs := make(map[int]error)
+	for i, r := range cfg.CustomRules {
+		rule, err := compileCustomRule(r)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errs
+}
+
+// compileCustomRule compiles one custom_rules entry.
+func compileCustomRule(r config.CustomRule) (customRule, error) {
+	if r.ID == "" {
+		return customRule{}, fmt.Errorf("id is required")
+	}
+	decision := PermissionDecision(strings.ToLower(r.Decision))
+	if decision != DecisionAsk && decision != DecisionDeny {
+		return customRule{}, fmt.Errorf("decision must be ask or deny, got %q", r.Decision)
+	}
+	when, err := expr.Parse(r.When)
+	if err != nil {
+		return customRule{}, fmt.Errorf("when: %v", err)
+	}
+
+	tools := r.Tools
+	if len(tools) == 0 {
+		tools = []string{"Bash"}
+	}
+	rule := customRule{CustomRule: r, ruleID: CustomRuleID(r.ID), when: when, tools: make(map[string]bool)}
+	var problems []string
+	for _, tool := range tools {
+		signals, ok := customRuleTools[tool]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown tool %q", tool))
+			continue
+		}
+		rule.tools[tool] = true
+		for _, signal := range when.Names() {
+			if _, ok := signals[signal]; !ok {
+				problems = append(problems, fmt.Sprintf("%s has no signal %q", tool, signal))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return customRule{}, fmt.Errorf("%s", strings.Join(problems, ", "))
+	}
+	return rule, nil
+}
+
+// CheckCommand evaluates the Bash custom rules against a command.
+func (c *CustomRuleCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if !c.applies("Bash") {
+		return c.Allow()
+	}
+	s := &commandSignals{check: c, raw: rawCommand, cmds: flattenCommands(parsedCommands), values: make(map[string]bool)}
+	return c.evaluate("Bash", s.value)
+}
+
+// CheckPath evaluates the custom rules of the file tool operation stands
+// for ("read" is Read, anything else Write).
+func (c *CustomRuleCheck) CheckPath(path string, operation string) *CheckResult {
+	tool := "Write"
+	if operation == "read" {
+		tool = "Read"
+	}
+	return c.CheckFile(tool, path)
+}
+
+// CheckFile evaluates the custom rules of a file tool against its path.
+func (c *CustomRuleCheck) CheckFile(tool string, path string) *CheckResult {
+	if !c.applies(tool) {
+		return c.Allow()
+	}
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	signal := func(name string) bool {
+		switch name {
+		case "read":
+			return tool == "Read"
+		case "write":
+			return tool != "Read"
+		case "outside_project":
+			return c.outsideProject(resolved)
+		case "secrets_path":
+			return c.secretsPath(resolved)
+		case "trusted_script":
+			return c.trustedScript(resolved)
+		case "ci":
+			return parsers.IsInCIEnvironment()
+		}
+		return false
+	}
+	return c.evaluate(tool, signal)
+}
+
+// applies reports whether any rule applies to tool.
+func (c *CustomRuleCheck) applies(tool string) bool {
+	for _, r := range c.rules {
+		if r.tools[tool] {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns the result of the first rule for tool that matches.
+func (c *CustomRuleCheck) evaluate(tool string, signal func(string) bool) *CheckResult {
+	for _, r := range c.rules {
+		if !r.tools[tool] || !r.when.Eval(signal) {
+			continue
+		}
+		reason := r.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("Custom rule %s matched: %s", r.ID, r.When)
+		}
+		guidance := r.Guidance
+		if guidance == "" {
+			guidance = "A custom rule in the guardian config forbids this. Explain to the user what you wanted to do and let them do it."
+		}
+
+		var result *CheckResult
+		if PermissionDecision(strings.ToLower(r.Decision)) == DecisionDeny {
+			result = c.Deny(reason, guidance)
+		} else {
+			result = c.Ask(reason, guidance)
+		}
+		return result.WithRule(r.ruleID).WithPattern(r.When)
+	}
+	return c.Allow()
+}
+
+// outsideProject reports whether a resolved path is outside the project
+// root. Unlike DirectoryCheck, allowed_paths count as outside.
+func (c *CustomRuleCheck) outsideProject(resolved string) bool {
+	return !parsers.IsPathWithinAllowed(resolved, c.projectRoot, nil)
+}
+
+// secretsPath reports whether a resolved path matches no_read_content or
+// forbidden_read. Paths outside the project match by file name.
+func (c *CustomRuleCheck) secretsPath(resolved string) bool {
+	rel, err := filepath.Rel(c.projectRoot, resolved)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = resolved
+	}
+	var patterns []string
+	patterns = append(patterns, c.config.SensitiveFiles.ForbiddenRead...)
+	patterns = append(patterns, c.config.ProtectedPaths.NoReadContent...)
+	filename := filepath.Base(rel)
+	_, ok := globs.MatchListFunc(patterns, func(p string) bool {
+		return matchPathOrName(p, rel, filename)
+	})
+	return ok
+}
+
+// trustedScript reports whether a resolved path is an unchanged trusted
+// script.
+func (c *CustomRuleCheck) trustedScript(resolved string) bool {
+	return c.trusted != nil && c.trusted.Lookup(c.projectRoot, resolved) == trust.Trusted
+}
+
+// commandSignals computes the signals of a command on first use.
+type commandSignals struct {
+	check  *CustomRuleCheck
+	raw    string
+	cmds   []*ParsedCommand
+	values map[string]bool
+}
+
+// value returns a signal, computing it once.
+func (s *commandSignals) value(name string) bool {
+	if v, ok := s.values[name]; ok {
+		return v
+	}
+	v := s.compute(name)
+	s.values[name] = v
+	return v
+}
+
+func (s *commandSignals) compute(name string) bool {
+	c := s.check
+	bp := c.config.BypassPrevention
+	switch name {
+	case "download":
+		return s.anyCommand(func(cmd *ParsedCommand) bool { return downloadCommands[cmd.Command] })
+	case "network":
+		return s.anyCommand(func(cmd *ParsedCommand) bool {
+			return downloadCommands[cmd.Command] || networkCommands[cmd.Command]
+		})
+	case "pipe_to_shell":
+		return parsers.IsPipeToShell(s.cmds, bp.BlockShellPipeTargets)
+	case "interpreter_inline":
+		return containsAny(s.raw, bp.ConfirmInterpreterInlineWithNetwork)
+	case "inline_network":
+		return containsAny(s.raw, bp.NetworkPatterns)
+	case "obfuscation":
+		return containsAny(s.raw, bp.ObfuscationPatterns) || s.anyCommand(func(cmd *ParsedCommand) bool {
+			return cmd.Command == "base64" && (containsFlag(cmd.Flags, "-d") || containsFlag(cmd.Flags, "--decode")) ||
+				cmd.Command == "xxd" && containsFlag(cmd.Flags, "-r")
+		})
+	case "outside_project":
+		return s.anyOperand(func(_ *ParsedCommand, _ parsers.Operand, resolved string) bool { return c.outsideProject(resolved) })
+	case "secrets_path":
+		return s.anyOperand(func(_ *ParsedCommand, _ parsers.Operand, resolved string) bool { return c.secretsPath(resolved) })
+	case "writes":
+		return s.anyOperand(func(_ *ParsedCommand, op parsers.Operand, _ string) bool { return op.Role == parsers.RoleDestination })
+	case "delete":
+		return s.anyCommand(func(cmd *ParsedCommand) bool { return deleteCommands[cmd.Command] })
+	case "git":
+		return s.anyCommand(func(cmd *ParsedCommand) bool { return cmd.Command == "git" })
+	case "sudo":
+		return s.anyCommand(func(cmd *ParsedCommand) bool { return cmd.Command == "sudo" || cmd.Command == "doas" })
+	case "trusted_script":
+		return s.anyOperand(func(_ *ParsedCommand, _ parsers.Operand, resolved string) bool { return c.trustedScript(resolved) })
+	case "ci":
+		return parsers.IsInCIEnvironment()
+	}
+	return false
+}
+
+// anyCommand reports whether match holds for any command.
+func (s *commandSignals) anyCommand(match func(cmd *ParsedCommand) bool) bool {
+	for _, cmd := range s.cmds {
+		if match(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyOperand reports whether match holds for any path operand.
+func (s *commandSignals) anyOperand(match func(cmd *ParsedCommand, op parsers.Operand, resolved string) bool) bool {
+	base := s.check.baseDir(s.check.projectRoot)
+	for _, cmd := range s.cmds {
+		for _, op := range parsers.ClassifyOperands(cmd) {
+			if !isPathOperand(cmd, op) {
+				continue
+			}
+			if match(cmd, op, parsers.ResolvePath(parsers.JoinDir(cmd.Dir, op.Value), base)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// flattenCommands returns the commands with the commands they pipe to.
+func flattenCommands(cmds []*ParsedCommand) []*ParsedCommand {
+	var all []*ParsedCommand
+	for _, cmd := range cmds {
+		for c := cmd; c != nil; c = c.PipesTo {
+			all = append(all, c)
+		}
+	}
+	return all
+}
+
+// containsAny reports whether s contains any of the substrings.
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if sub != "" && strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
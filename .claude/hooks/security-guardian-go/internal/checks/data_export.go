@@ -0,0 +1,189 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// DataExportCheck screens database dumps (dangerous_operations.data_export):
+// a dump written outside the project or piped to a network command takes
+// the database with it, and a connection string with a password leaves the
+// credentials in shell history and process lists.
+type DataExportCheck struct {
+	BaseCheck
+	projectRoot string
+	config      *config.SecurityConfig
+	credentials []*regexp.Regexp
+}
+
+// dumpOutputFlags are the options naming the output file of dump clients.
+var dumpOutputFlags = map[string][]string{
+	"pg_dump":      {"-f", "--file"},
+	"pg_dumpall":   {"-f", "--file"},
+	"mysqldump":    {"-r", "--result-file"},
+	"mariadb-dump": {"-r", "--result-file"},
+	"mongodump":    {"-o", "--out", "--archive"},
+	"mongoexport":  {"-o", "--out"},
+	"redis-cli":    {"--rdb"},
+}
+
+// NewDataExportCheck creates a new DataExportCheck instance.
+func NewDataExportCheck(e *Engine) *DataExportCheck {
+	var patterns []string
+	for _, p := range e.Config.DangerousOperations.DataExport.Credentials {
+		patterns = append(patterns, p.Pattern)
+	}
+	return &DataExportCheck{
+		BaseCheck:   BaseCheck{CheckName: "data_export_check"},
+		projectRoot: e.BoundaryRoot,
+		config:      e.Config,
+		credentials: e.compileAll(patterns),
+	}
+}
+
+// CheckCommand checks dump output destinations and credentials on the
+// command line.
+func (c *DataExportCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	cfg := &c.config.DangerousOperations.DataExport
+	if !cfg.Enabled {
+		return c.Allow()
+	}
+
+	for _, cmd := range parsedCommands {
+		if !matchesCommandEntry(cmd, cfg.Commands) {
+			continue
+		}
+		for sink := cmd.PipesTo; sink != nil; sink = sink.PipesTo {
+			if isNetworkSink(sink, c.config.Pipelines.NetworkSinks) {
+				return c.Ask(
+					fmt.Sprintf("Database dump piped to a network command: %s | %s", cmd.Command, sink.Command),
+					"The dump leaves the machine with every row in it. Show the user the command and let them run it if this export is intended.",
+				).WithRule(RuleDataExportNetwork).WithOrigin(cmd)
+			}
+		}
+		for _, out := range dumpOutputs(cmd) {
+			path := parsers.JoinDir(cmd.Dir, out)
+			if !c.outsideProject(path) {
+				continue
+			}
+			return c.Ask(
+				fmt.Sprintf("Database dump written outside the project: %s", out),
+				"Dumps belong in the project, where the user sees them. Write it inside the project, or show the user the command and let them run it.",
+			).WithRule(RuleDataExportOutside).WithPaths(path).WithOrigin(cmd)
+		}
+	}
+
+	for i, re := range c.credentials {
+		if re == nil || !re.MatchString(rawCommand) {
+			continue
+		}
+		return c.Ask(
+			fmt.Sprintf("Credentials on the command line: %s", cfg.Credentials[i].Description),
+			"Passwords in arguments end up in shell history, process lists and logs. Use ~/.pgpass, a MySQL option file or a variable the user sets instead, or let the user run the command.",
+		).WithRule(RuleDataExportCredentials).WithPattern(cfg.Credentials[i].Pattern)
+	}
+
+	return c.Allow()
+}
+
+// CheckDumpSize warns about dumps the commands wrote inside the project
+// that are larger than max_dump_bytes. Called from PostToolUse, when the
+// files exist.
+func (c *DataExportCheck) CheckDumpSize(parsedCommands []*ParsedCommand) *CheckResult {
+	cfg := &c.config.DangerousOperations.DataExport
+	if !cfg.Enabled || cfg.MaxDumpBytes <= 0 {
+		return c.Allow()
+	}
+
+	for _, cmd := range parsedCommands {
+		if !matchesCommandEntry(cmd, cfg.Commands) {
+			continue
+		}
+		outputs := dumpOutputs(cmd)
+		if len(outputs) == 0 && filepath.Base(cmd.Command) == "mongodump" {
+			outputs = []string{"dump"}
+		}
+		for _, out := range outputs {
+			path := parsers.JoinDir(cmd.Dir, out)
+			if c.outsideProject(path) {
+				continue
+			}
+			size := pathSize(parsers.ResolvePath(path, c.baseDir(c.projectRoot)))
+			if size <= cfg.MaxDumpBytes {
+				continue
+			}
+			return c.Ask(
+				fmt.Sprintf("Database dump of %d bytes in %s exceeds limit of %d bytes", size, out, cfg.MaxDumpBytes),
+				"That much data in one place is what an export starts with. Tell the user the dump is there and why it is needed; don't copy or send it anywhere without asking.",
+			).WithRule(RuleDataExportLargeDump).WithPaths(path).WithOrigin(cmd)
+		}
+	}
+
+	return c.Allow()
+}
+
+// pathSize returns the size of a file, or of the files in a directory.
+func pathSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// dumpOutputs returns the files a dump command writes: its output option
+// and write redirections. Args and flags are split apart in ParsedCommand,
+// so which arg an option takes is read from the command line itself.
+func dumpOutputs(cmd *ParsedCommand) []string {
+	var outputs []string
+	if segment := commandSegment(cmd); segment != "" {
+		for _, name := range dumpOutputFlags[filepath.Base(cmd.Command)] {
+			sep := `(?:=|\s+)`
+			if !strings.HasPrefix(name, "--") {
+				sep = `\s*`
+			}
+			re := regexp.MustCompile(`(?:^|\s)` + regexp.QuoteMeta(name) + sep + `(\S+)`)
+			for _, m := range re.FindAllStringSubmatch(segment, -1) {
+				if value := strings.Trim(m[1], `"'`); value != "" && value != "-" {
+					outputs = append(outputs, value)
+				}
+			}
+		}
+	}
+	for _, r := range cmd.Redirections {
+		if r.IsWrite() {
+			outputs = append(outputs, r.Target)
+		}
+	}
+	return outputs
+}
+
+// commandSegment returns the part of the command line from cmd's name to
+// the next pipe or list operator.
+func commandSegment(cmd *ParsedCommand) string {
+	i := strings.Index(cmd.Raw, cmd.Command)
+	if i < 0 {
+		return ""
+	}
+	segment := cmd.Raw[i+len(cmd.Command):]
+	if end := strings.IndexAny(segment, "|;&\n"); end >= 0 {
+		segment = segment[:end]
+	}
+	return segment
+}
+
+// outsideProject reports whether path resolves outside the project.
+func (c *DataExportCheck) outsideProject(path string) bool {
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	rel, err := filepath.Rel(c.projectRoot, resolved)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, "../")
+}
@@ -0,0 +1,103 @@
+package checks
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/decoy"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// DecoyCheck denies any access to installed decoy files and any use of
+// their canary values. Nothing legitimate touches a decoy, so a hit means
+// something is hunting for secrets.
+type DecoyCheck struct {
+	BaseCheck
+	projectRoot string
+	config      *config.SecurityConfig
+	registry    *decoy.Registry
+}
+
+// NewDecoyCheck creates a new DecoyCheck instance.
+func NewDecoyCheck(e *Engine) *DecoyCheck {
+	c := &DecoyCheck{
+		BaseCheck:   BaseCheck{CheckName: "decoy_check"},
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
+	}
+	if r := e.Decoys(); r != nil {
+		c.registry = r
+	}
+	return c
+}
+
+// CheckCommand checks command operands and the raw command for decoys.
+func (c *DecoyCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if c.registry == nil {
+		return c.Allow()
+	}
+
+	if c.registry.FindCanary(rawCommand) != nil {
+		return c.trip("Command contains a protected secret value")
+	}
+
+	// Piped commands are already in the slice, no need to follow PipesTo
+	for _, cmd := range parsedCommands {
+		// Listing or removing decoys would reveal them
+		if filepath.Base(cmd.Command) == "guardian" && len(cmd.Args) > 0 && cmd.Args[0] == "decoy" {
+			return c.Deny(
+				"Managing decoy files is reserved for the user",
+				"Do not run `guardian decoy`. Continue with the task.",
+			).WithRule(RuleDecoyAccess)
+		}
+
+		for _, op := range parsers.ClassifyOperands(cmd) {
+			if op.Role == parsers.RolePattern || op.Value == "" {
+				continue
+			}
+			if result := c.CheckPath(parsers.JoinDir(cmd.Dir, op.Value), "read"); !result.IsAllowed() {
+				return result
+			}
+		}
+	}
+
+	return c.Allow()
+}
+
+// CheckPath denies access to a decoy file.
+func (c *DecoyCheck) CheckPath(path string, operation string) *CheckResult {
+	if c.registry == nil {
+		return c.Allow()
+	}
+
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	// Through symlinks too: ln -s .env.production x && cat x
+	if real, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = real
+	}
+	if c.registry.Match(c.projectRoot, resolved) != nil {
+		return c.trip(fmt.Sprintf("Access to protected secrets file: %s", path))
+	}
+	return c.Allow()
+}
+
+// CheckContent denies content that carries a canary value.
+func (c *DecoyCheck) CheckContent(content string) *CheckResult {
+	if c.registry == nil {
+		return c.Allow()
+	}
+	if c.registry.FindCanary(content) != nil {
+		return c.trip("Content contains a protected secret value")
+	}
+	return c.Allow()
+}
+
+// trip builds the deny result for a decoy hit. The message doesn't reveal
+// the decoy; the rule ID marks it for logging and notification.
+func (c *DecoyCheck) trip(reason string) *CheckResult {
+	return c.Deny(
+		reason,
+		"This is not needed for the task. Stop searching for credentials and ask the user for any value you need.",
+	).WithRule(RuleDecoyAccess)
+}
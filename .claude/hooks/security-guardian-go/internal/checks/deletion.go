@@ -2,10 +2,12 @@ package checks
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
 )
 
@@ -37,30 +39,30 @@ var dangerousRmFlags = map[string]bool{
 }
 
 // NewDeletionCheck creates a new DeletionCheck instance.
-func NewDeletionCheck(cfg *config.SecurityConfig) *DeletionCheck {
+func NewDeletionCheck(e *Engine) *DeletionCheck {
 	return &DeletionCheck{
 		BaseCheck:    BaseCheck{CheckName: "deletion_check"},
-		projectRoot:  parsers.GetProjectRoot(),
-		allowedPaths: cfg.Directories.AllowedPaths,
-		config:       cfg,
+		projectRoot:  e.ProjectRoot,
+		allowedPaths: e.Config.Directories.AllowedPaths,
+		config:       e.Config,
 	}
 }
 
 // CheckCommand checks deletion commands for safety.
 func (c *DeletionCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	// Piped commands are already in the slice, no need to follow PipesTo
 	for _, cmd := range parsedCommands {
 		if deleteCommands[cmd.Command] {
 			result := c.checkDeletion(cmd)
 			if !result.IsAllowed() {
-				return result
+				return result.WithOrigin(cmd)
 			}
 		}
 
-		// Check piped commands
-		if cmd.PipesTo != nil {
-			result := c.CheckCommand(rawCommand, []*ParsedCommand{cmd.PipesTo})
+		if cmd.Command == "mv" {
+			result := c.checkMove(cmd)
 			if !result.IsAllowed() {
-				return result
+				return result.WithOrigin(cmd)
 			}
 		}
 	}
@@ -70,9 +72,23 @@ func (c *DeletionCheck) CheckCommand(rawCommand string, parsedCommands []*Parsed
 
 // checkDeletion checks a single deletion command.
 func (c *DeletionCheck) checkDeletion(cmd *ParsedCommand) *CheckResult {
-	paths := parsers.ExtractPathsFromCommand(convertParsedCommand(cmd))
+	paths := parsers.ExtractPathsFromCommand(cmd)
 	hasRecursive := c.hasDangerousFlags(cmd.Flags)
 
+	// Catastrophic targets first - DENY (no confirmation offered)
+	if hasRecursive {
+		for _, arg := range cmd.Args {
+			if c.isCriticalPath(c.resolveArg(cmd, arg)) {
+				return c.Deny(
+					fmt.Sprintf("Recursive deletion of critical directory: %s", arg),
+					"Deleting /, the home directory or a directory containing the project "+
+						"destroys data far beyond this project and cannot be undone, so confirmation is not offered. "+
+						"If this is really intended, the user must run it in their own terminal.",
+				).WithRule(RuleDeletionCritical).WithPaths(arg)
+			}
+		}
+	}
+
 	// Check for glob patterns in args that ExtractPathsFromCommand may have filtered out.
 	// Commands like "rm -rf *" are dangerous even though "*" isn't a path-like string.
 	if hasRecursive && len(paths) == 0 {
@@ -82,20 +98,20 @@ func (c *DeletionCheck) checkDeletion(cmd *ParsedCommand) *CheckResult {
 					fmt.Sprintf("Recursive deletion with glob pattern: %s %s", cmd.Command, arg),
 					fmt.Sprintf("Glob-based recursive deletion is dangerous. Give user the command: `%s %s %s`",
 						cmd.Command, strings.Join(cmd.Flags, " "), strings.Join(cmd.Args, " ")),
-				)
+				).WithRule(RuleDeletionRecursiveGlob).WithPaths(arg)
 			}
 		}
 	}
 
 	for _, pathStr := range paths {
-		resolved := parsers.ResolvePath(pathStr, c.projectRoot)
+		resolved := parsers.ResolvePath(parsers.JoinDir(cmd.Dir, pathStr), c.baseDir(c.projectRoot))
 
 		// Check if path is outside project - ASK (user can confirm)
 		if !parsers.IsPathWithinAllowed(resolved, c.projectRoot, c.allowedPaths) {
 			return c.Ask(
 				fmt.Sprintf("Cannot delete files outside project: %s", pathStr),
 				fmt.Sprintf("Give user the command: `rm %s %s`", strings.Join(cmd.Flags, " "), pathStr),
-			)
+			).WithRule(RuleDeletionOutside).WithPaths(pathStr)
 		}
 
 		// Check for dangerous recursive deletion of important paths
@@ -110,6 +126,65 @@ func (c *DeletionCheck) checkDeletion(cmd *ParsedCommand) *CheckResult {
 	return c.Allow()
 }
 
+// checkMove checks mv for renaming the project root or one of its ancestors.
+func (c *DeletionCheck) checkMove(cmd *ParsedCommand) *CheckResult {
+	sources := cmd.Args
+	hasTarget := false
+	for _, f := range cmd.Flags {
+		if f == "-t" {
+			// -t <dir>: first arg is the destination
+			hasTarget = true
+			if len(sources) > 0 {
+				sources = sources[1:]
+			}
+		} else if strings.HasPrefix(f, "--target-directory") {
+			hasTarget = true
+		}
+	}
+	if !hasTarget && len(sources) > 0 {
+		sources = sources[:len(sources)-1]
+	}
+
+	for _, arg := range sources {
+		resolved := c.resolveArg(cmd, arg)
+		rel, err := relPath(resolved, c.projectRoot)
+		if err == nil && !strings.HasPrefix(rel, "..") || c.isCriticalPath(resolved) {
+			return c.Deny(
+				fmt.Sprintf("Moving the project root or a directory containing it: %s", arg),
+				"Renaming the project root pulls the working directory out from under this session "+
+					"and every later path check, so confirmation is not offered. "+
+					"If this is really intended, the user must run it in their own terminal.",
+			).WithRule(RuleDeletionMoveProjectRoot)
+		}
+	}
+
+	return c.Allow()
+}
+
+// resolveArg resolves a command argument, treating a trailing /* as the
+// directory itself (rm -rf /* empties / just like rm -rf /).
+func (c *DeletionCheck) resolveArg(cmd *ParsedCommand, arg string) string {
+	if strings.HasSuffix(arg, "/*") {
+		arg = strings.TrimSuffix(arg, "*")
+	}
+	return parsers.ResolvePath(parsers.JoinDir(cmd.Dir, arg), c.baseDir(c.projectRoot))
+}
+
+// isCriticalPath reports whether path is /, the home directory or a strict
+// ancestor of the project root.
+func (c *DeletionCheck) isCriticalPath(path string) bool {
+	if path == "/" {
+		return true
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := relPath(home, path); err == nil && rel == "." {
+			return true
+		}
+	}
+	rel, err := relPath(path, c.projectRoot)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
 // containsGlob checks if a string contains shell glob characters.
 func containsGlob(s string) bool {
 	return strings.ContainsAny(s, "*?[")
@@ -118,6 +193,11 @@ func containsGlob(s string) bool {
 // hasDangerousFlags checks if any dangerous flags are present.
 // Handles both exact matches (-r, -rf) and combined short flags (-rfv, -Rfi).
 func (c *DeletionCheck) hasDangerousFlags(flags []string) bool {
+	return hasRecursiveFlag(flags)
+}
+
+// hasRecursiveFlag checks rm flags for recursive deletion.
+func hasRecursiveFlag(flags []string) bool {
 	for _, f := range flags {
 		// Exact match first
 		if dangerousRmFlags[f] {
@@ -147,19 +227,23 @@ func (c *DeletionCheck) checkDangerousRecursiveDelete(resolved string, originalP
 	// Check protected directories - ASK (user can confirm)
 	protected := c.getProtectedDirectories()
 	for _, protectedPath := range protected {
-		// Block deleting protected path or its children
+		// Block deleting protected path or its children, unless a no_modify
+		// exception ("!.claude/notes/**") lifts them
 		if relStr == protectedPath || strings.HasPrefix(relStr, protectedPath+"/") {
+			if last, ok := globs.MatchList(c.config.ProtectedPaths.NoModify, relStr); !ok && strings.HasPrefix(last, "!") {
+				continue
+			}
 			return c.Ask(
 				fmt.Sprintf("Cannot recursively delete protected path: %s", originalPath),
 				fmt.Sprintf("Path '%s' is protected. Give user the command if needed.", originalPath),
-			)
+			).WithRule(RuleDeletionProtected).WithPaths(originalPath).WithPattern(protectedPath)
 		}
 		// Block deleting ancestor directories that contain protected paths
 		if strings.HasPrefix(protectedPath, relStr+"/") {
 			return c.Ask(
 				fmt.Sprintf("Cannot recursively delete directory containing protected path: %s", originalPath),
 				fmt.Sprintf("Path '%s' contains protected content '%s'. Give user the command if needed.", originalPath, protectedPath),
-			)
+			).WithRule(RuleDeletionProtectedAncestor).WithPaths(originalPath).WithPattern(protectedPath)
 		}
 	}
 
@@ -168,7 +252,7 @@ func (c *DeletionCheck) checkDangerousRecursiveDelete(resolved string, originalP
 		return c.Ask(
 			"Cannot recursively delete project root",
 			"Deleting entire project is blocked. Be more specific about what to delete.",
-		)
+		).WithRule(RuleDeletionProjectRoot)
 	}
 
 	return c.Allow()
@@ -179,6 +263,9 @@ func (c *DeletionCheck) getProtectedDirectories() []string {
 	var protected []string
 
 	for _, pattern := range c.config.ProtectedPaths.NoModify {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
 		// Remove glob wildcards to get base path
 		base := strings.Split(pattern, "*")[0]
 		base = strings.TrimSuffix(base, "/")
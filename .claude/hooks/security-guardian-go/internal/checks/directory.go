@@ -15,111 +15,87 @@ type DirectoryCheck struct {
 	projectRoot  string
 	allowedPaths []string
 	config       *config.SecurityConfig
+	zones        *Zones
 }
 
 // NewDirectoryCheck creates a new DirectoryCheck instance.
-func NewDirectoryCheck(cfg *config.SecurityConfig) *DirectoryCheck {
-	projectRoot := cfg.Directories.ProjectRoot
-	if projectRoot == "" {
-		projectRoot = parsers.GetProjectRoot()
-	} else {
-		projectRoot = parsers.ResolvePath(projectRoot, "")
-	}
+func NewDirectoryCheck(e *Engine) *DirectoryCheck {
+	projectRoot := e.BoundaryRoot
 
 	return &DirectoryCheck{
 		BaseCheck:    BaseCheck{CheckName: "directory_check"},
 		projectRoot:  projectRoot,
-		allowedPaths: cfg.Directories.AllowedPaths,
-		config:       cfg,
+		allowedPaths: e.Config.Directories.AllowedPaths,
+		config:       e.Config,
+		zones:        e.Zones(projectRoot),
 	}
 }
 
+// ZoneFor returns the policy zone of a path inside the project.
+func (c *DirectoryCheck) ZoneFor(path string) string {
+	return c.zones.PolicyFor(path, c.baseDir(c.projectRoot))
+}
+
 // CheckCommand checks if command accesses paths outside allowed boundaries.
 func (c *DirectoryCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	// An unresolved path asks, unless another path is denied
+	var pending *CheckResult
+
+	// Piped commands are already in the slice, no need to follow PipesTo
 	for _, cmd := range parsedCommands {
-		// For commands that never take file path arguments (echo, printf, etc.),
-		// still check redirects and pipes — they can write outside project.
-		if nonPathCommands[cmd.Command] {
-			// Check redirect targets (echo hi > /etc/passwd)
-			for _, redir := range cmd.Redirects {
-				result := c.CheckPath(redir, cmd.Command)
-				if !result.IsAllowed() {
-					return result
-				}
+		// Patterns (grep/sed scripts, echo text, chmod modes) are skipped;
+		// redirects are checked even for commands that take no paths.
+		for _, op := range parsers.ClassifyOperands(cmd) {
+			if !isPathOperand(cmd, op) {
+				continue
 			}
-			// Check piped commands
-			if cmd.PipesTo != nil {
-				result := c.CheckCommand(rawCommand, []*ParsedCommand{cmd.PipesTo})
-				if !result.IsAllowed() {
-					return result
-				}
-			}
-			continue
-		}
-
-		paths := parsers.ExtractPathsFromCommand((*parsers.ParsedCommand)(convertParsedCommand(cmd)))
-
-		// For commands with a pattern first arg (grep, sed, awk),
-		// skip the first arg which is a pattern, not a path.
-		skipFirstArg := patternFirstArgCommands[cmd.Command]
-		firstArgSkipped := false
-
-		for _, pathStr := range paths {
-			if skipFirstArg && !firstArgSkipped {
-				if len(cmd.Args) > 0 && pathStr == cmd.Args[0] {
-					firstArgSkipped = true
-					continue
-				}
+			// A relative path after cd $X is as unknown as $X/path
+			path := parsers.JoinDir(cmd.Dir, op.Value)
+			if pending == nil && cmd.IsUnresolved(path) {
+				pending = c.Ask(
+					fmt.Sprintf("Path is only known when the command runs: %s", path),
+					"The path depends on a variable or shell expansion ($VAR, $(...), ${VAR/a/b}, a cd into one, CDPATH) the guardian can't resolve. Use a literal path, or show the user the command and let them run it. Variables can be added to directories.path_variables.",
+				).WithRule(RuleDirectoryUnresolved).WithPaths(path).WithOrigin(cmd)
 			}
-			result := c.CheckPath(pathStr, cmd.Command)
-			if !result.IsAllowed() {
-				return result
-			}
-		}
-
-		// For file-operating commands, also check bare args that ExtractPathsFromCommand
-		// may have filtered out (e.g. bare symlink names without /, ., or ~ characters).
-		// Only for commands known to take file paths as positional args,
-		// to avoid false positives with pattern-based commands (grep, echo, etc.).
-		if fileArgCommands[cmd.Command] {
-			for _, arg := range cmd.Args {
-				if strings.HasPrefix(arg, "-") {
-					continue
-				}
-				// Skip args already covered by ExtractPathsFromCommand
-				if strings.Contains(arg, "/") || strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~") || strings.Contains(arg, ".") {
-					continue
-				}
-				result := c.CheckPath(arg, cmd.Command)
-				if !result.IsAllowed() {
-					return result
-				}
-			}
-		}
-
-		// Recursively check piped commands
-		if cmd.PipesTo != nil {
-			result := c.CheckCommand(rawCommand, []*ParsedCommand{cmd.PipesTo})
+			result := c.CheckPath(path, redirectOperation(cmd.Command, op.Op))
 			if !result.IsAllowed() {
 				return result
 			}
 		}
 	}
 
+	if pending != nil {
+		return pending
+	}
 	return c.Allow()
 }
 
+// redirectOperation names the operation for guidance: the redirect kind for
+// redirect targets, otherwise the command.
+func redirectOperation(command string, op string) string {
+	switch {
+	case op == "":
+		return command
+	case op == "<":
+		return "read"
+	case strings.HasSuffix(op, ">>"):
+		return ">>"
+	default:
+		return ">"
+	}
+}
+
 // CheckPath checks if a path is within allowed boundaries.
 func (c *DirectoryCheck) CheckPath(path string, operation string) *CheckResult {
-	// Resolve path relative to project root
-	resolved := parsers.ResolvePath(path, c.projectRoot)
+	// Resolve path relative to the working directory
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
 
 	// Check for symlink escape - HARD DENY (security bypass)
-	if parsers.IsSymlinkEscape(path, c.projectRoot, c.projectRoot) {
+	if parsers.IsSymlinkEscape(path, c.projectRoot, c.baseDir(c.projectRoot)) {
 		return c.Deny(
 			fmt.Sprintf("Symlink escape detected: '%s' resolves to '%s' outside project", path, resolved),
 			"Symlink points outside project boundaries. This is a security bypass attempt.",
-		)
+		).WithRule(RuleDirectorySymlinkEscape).WithPaths(path)
 	}
 
 	// Check if within allowed paths
@@ -131,7 +107,7 @@ func (c *DirectoryCheck) CheckPath(path string, operation string) *CheckResult {
 		return c.Deny(
 			fmt.Sprintf("Path '%s' is outside project boundaries", resolved),
 			c.getGuidanceForOperation(operation, path),
-		)
+		).WithRule(RuleDirectoryOutside).WithPaths(resolved)
 	}
 
 	return c.Allow()
@@ -149,28 +125,9 @@ func (c *DirectoryCheck) getGuidanceForOperation(operation string, path string)
 		return fmt.Sprintf("Cannot copy/move files outside project. Give user the command: `%s %s`", operation, path)
 	case "find", "ls":
 		return fmt.Sprintf("Cannot search outside project. Give user the command: `%s %s`", operation, path)
-	case "echo", "tee", "write", ">", ">>":
+	case "echo", "tee", "dd", "sponge", "install", "write", ">", ">>":
 		return fmt.Sprintf("Cannot write outside project. Give user the command for writing to %s", path)
 	default:
 		return fmt.Sprintf("Operation '%s' blocked outside project. Give user the command or add path to allowed_paths in config.", operation)
 	}
 }
-
-// convertParsedCommand converts checks.ParsedCommand to parsers.ParsedCommand.
-func convertParsedCommand(cmd *ParsedCommand) *parsers.ParsedCommand {
-	if cmd == nil {
-		return nil
-	}
-	result := &parsers.ParsedCommand{
-		Command:           cmd.Command,
-		Args:              cmd.Args,
-		Flags:             cmd.Flags,
-		Redirects:         cmd.Redirects,
-		VariableAsCommand: cmd.VariableAsCommand,
-		Raw:               cmd.Raw,
-	}
-	if cmd.PipesTo != nil {
-		result.PipesTo = convertParsedCommand(cmd.PipesTo)
-	}
-	return result
-}
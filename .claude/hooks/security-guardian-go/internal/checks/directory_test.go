@@ -0,0 +1,38 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+func TestDirectoryCheckUnresolved(t *testing.T) {
+	tests := []struct {
+		command string
+		want    PermissionDecision
+		rule    string
+	}{
+		{`X=/etc; cd $X && cat passwd`, DecisionAsk, RuleDirectoryUnresolved},
+		{`cd "$X" && rm -rf data`, DecisionAsk, RuleDirectoryUnresolved},
+		{`D=/; cd $D && rm -rf home`, DecisionAsk, RuleDirectoryUnresolved},
+		{`CDPATH=/ cd etc && cat passwd`, DecisionAsk, RuleDirectoryUnresolved},
+		{`cd $(pwd) && cat passwd`, DecisionAsk, RuleDirectoryUnresolved},
+		{`cd - && cat passwd`, DecisionAsk, RuleDirectoryUnresolved},
+		{`cat $X/passwd`, DecisionAsk, RuleDirectoryUnresolved},
+		{`cd $X && cat /etc/passwd`, DecisionDeny, RuleDirectoryOutside},
+		{`cd sub && cat file.txt`, DecisionAllow, ""},
+		{`cd sub && cd - && cat file.txt`, DecisionAllow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			check := NewDirectoryCheck(newTestEngine(t))
+			result := check.CheckCommand(tt.command, parsers.ParseBashCommand(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if result.RuleID != tt.rule {
+				t.Errorf("rule = %q, want %q", result.RuleID, tt.rule)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
 )
 
 // DownloadCheck checks for dangerous download operations.
@@ -50,11 +51,11 @@ var binaryExtensions = map[string]bool{
 }
 
 // NewDownloadCheck creates a new DownloadCheck instance.
-func NewDownloadCheck(cfg *config.SecurityConfig) *DownloadCheck {
+func NewDownloadCheck(e *Engine) *DownloadCheck {
 	return &DownloadCheck{
 		BaseCheck:   BaseCheck{CheckName: "download_check"},
-		projectRoot: parsers.GetProjectRoot(),
-		config:      cfg,
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
 	}
 }
 
@@ -62,23 +63,18 @@ func NewDownloadCheck(cfg *config.SecurityConfig) *DownloadCheck {
 func (c *DownloadCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
 	// First check for pipe to shell (always HARD DENY)
 	shellTargets := c.config.BypassPrevention.BlockShellPipeTargets
-	parserCmds := make([]*parsers.ParsedCommand, len(parsedCommands))
-	for i, cmd := range parsedCommands {
-		parserCmds[i] = convertParsedCommand(cmd)
-	}
-
-	if parsers.IsPipeToShell(parserCmds, shellTargets) {
+	if parsers.IsPipeToShell(parsedCommands, shellTargets) {
 		return c.Deny(
 			"Downloading and piping to shell detected",
 			"Cannot pipe downloads to shell. Download file, review, then run.",
-		)
+		).WithRule(RuleDownloadPipeToShell)
 	}
 
 	for _, cmd := range parsedCommands {
 		if downloadCommands[cmd.Command] {
 			result := c.checkDownload(cmd)
 			if !result.IsAllowed() {
-				return result
+				return result.WithOrigin(cmd)
 			}
 		}
 	}
@@ -103,7 +99,7 @@ func (c *DownloadCheck) checkDownload(cmd *ParsedCommand) *CheckResult {
 		for scriptExt := range scriptExtensions {
 			if strings.HasSuffix(extension, scriptExt) {
 				if c.config.DownloadProtection.TrackDownloadedExecutables {
-					c.trackDownloadedFile(url, outputPath)
+					c.trackDownloadedFile(url, outputPath, cmd.Dir)
 				}
 				return c.Allow()
 			}
@@ -118,7 +114,7 @@ func (c *DownloadCheck) checkDownload(cmd *ParsedCommand) *CheckResult {
 					fmt.Sprintf("Download of binary executable: *%s", extension),
 					fmt.Sprintf("Binary files cannot be content-checked. Give user the command: `%s %s %s`",
 						cmd.Command, strings.Join(cmd.Flags, " "), strings.Join(cmd.Args, " ")),
-				)
+				).WithRule(RuleDownloadBinary)
 			}
 		}
 	}
@@ -139,7 +135,7 @@ func (c *DownloadCheck) checkDownload(cmd *ParsedCommand) *CheckResult {
 
 	// Unknown extension - allow but track for execution check
 	if c.config.DownloadProtection.TrackDownloadedExecutables {
-		c.trackDownloadedFile(url, outputPath)
+		c.trackDownloadedFile(url, outputPath, cmd.Dir)
 	}
 
 	return c.Allow()
@@ -269,7 +265,8 @@ func (c *DownloadCheck) getExtension(url string, outputPath string) string {
 }
 
 // trackDownloadedFile tracks a downloaded file for later execution check.
-func (c *DownloadCheck) trackDownloadedFile(url string, outputPath string) {
+// dir is the directory the download command runs in (see ParsedCommand.Dir).
+func (c *DownloadCheck) trackDownloadedFile(url string, outputPath string, dir string) {
 	if !c.config.DownloadProtection.TrackDownloadedExecutables {
 		return
 	}
@@ -278,14 +275,14 @@ func (c *DownloadCheck) trackDownloadedFile(url string, outputPath string) {
 
 	var resolved string
 	if outputPath != "" {
-		resolved = parsers.ResolvePath(outputPath, c.projectRoot)
+		resolved = parsers.ResolvePath(parsers.JoinDir(dir, outputPath), c.baseDir(c.projectRoot))
 	} else {
 		// Extract filename from URL
 		filename := filepath.Base(strings.Split(url, "?")[0])
-		resolved = parsers.ResolvePath(filename, c.projectRoot)
+		resolved = parsers.ResolvePath(parsers.JoinDir(dir, filename), c.baseDir(c.projectRoot))
 	}
 
-	files[resolved] = map[string]interface{}{
+	files[c.downloadKey(resolved)] = map[string]interface{}{
 		"url":            url,
 		"downloaded_at":  time.Now().UTC().Format(time.RFC3339),
 		"checked_binary": false,
@@ -301,8 +298,7 @@ func (c *DownloadCheck) loadDownloadedFiles() map[string]interface{} {
 		return c.downloadedFiles
 	}
 
-	metadataPath := filepath.Join(c.projectRoot, c.config.DownloadProtection.DownloadedFilesMetadata)
-	data, err := os.ReadFile(metadataPath)
+	data, err := os.ReadFile(c.metadataPath())
 	if err != nil {
 		c.downloadedFiles = make(map[string]interface{})
 		return c.downloadedFiles
@@ -324,24 +320,119 @@ func (c *DownloadCheck) saveDownloadedFiles() {
 		return
 	}
 
-	metadataPath := filepath.Join(c.projectRoot, c.config.DownloadProtection.DownloadedFilesMetadata)
+	metadataPath := c.metadataPath()
 
 	// Ensure parent directory exists
 	dir := filepath.Dir(metadataPath)
-	os.MkdirAll(dir, 0755)
+	os.MkdirAll(dir, 0700)
 
 	data, err := json.MarshalIndent(c.downloadedFiles, "", "  ")
 	if err != nil {
 		return
 	}
 
-	os.WriteFile(metadataPath, data, 0644)
+	os.WriteFile(metadataPath, data, 0600)
+}
+
+// metadataPath returns where downloads are recorded: downloaded_files_metadata
+// relative to the project, or by default the project's directory in
+// state_directory, shared by its worktrees.
+func (c *DownloadCheck) metadataPath() string {
+	if path := c.config.DownloadProtection.DownloadedFilesMetadata; path != "" {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(c.projectRoot, path)
+	}
+	return filepath.Join(state.ProjectDir(c.config.StateDirectory, c.projectRoot), "downloaded.json")
+}
+
+// downloadKey records a file inside the project by its path relative to the
+// project root, so worktrees sharing the record see each other's
+// downloads. Files elsewhere keep their absolute path.
+func (c *DownloadCheck) downloadKey(resolved string) string {
+	root := parsers.ResolvePath(c.projectRoot, "")
+	if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return rel
+	}
+	return resolved
+}
+
+// downloadRecord returns the record of a downloaded file, or nil.
+func (c *DownloadCheck) downloadRecord(path string) map[string]interface{} {
+	files := c.loadDownloadedFiles()
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	// Records written before keys were relative use the absolute path
+	for _, key := range []string{c.downloadKey(resolved), resolved} {
+		if record, ok := files[key].(map[string]interface{}); ok {
+			return record
+		}
+	}
+	return nil
+}
+
+// FirstRun returns where a downloaded or extracted file that hasn't been
+// run yet came from ("downloaded from <url>", "extracted from <archive>").
+func (c *DownloadCheck) FirstRun(path string) (string, bool) {
+	record := c.downloadRecord(path)
+	if record == nil || record["executed_at"] != nil {
+		return "", false
+	}
+	url, _ := record["url"].(string)
+	if archive, ok := record["extracted_from"].(string); ok {
+		if url != "" {
+			return fmt.Sprintf("extracted from %s (downloaded from %s)", archive, url), true
+		}
+		return "extracted from " + archive, true
+	}
+	if url == "" {
+		url = "the internet"
+	}
+	return "downloaded from " + url, true
+}
+
+// TrackExtracted records files extracted from archive. They keep the URL
+// of the archive when it was downloaded.
+func (c *DownloadCheck) TrackExtracted(files []string, archive string) {
+	if len(files) == 0 {
+		return
+	}
+	records := c.loadDownloadedFiles()
+	url := ""
+	if record := c.downloadRecord(archive); record != nil {
+		url, _ = record["url"].(string)
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, file := range files {
+		records[c.downloadKey(file)] = map[string]interface{}{
+			"url":            url,
+			"extracted_from": archive,
+			"downloaded_at":  now,
+			"checked_binary": false,
+		}
+	}
+	c.downloadedFiles = records
+	c.saveDownloadedFiles()
+}
+
+// MarkExecuted records that a downloaded file ran.
+func (c *DownloadCheck) MarkExecuted(path string) {
+	record := c.downloadRecord(path)
+	if record == nil || record["executed_at"] != nil {
+		return
+	}
+	record["executed_at"] = time.Now().UTC().Format(time.RFC3339)
+	c.saveDownloadedFiles()
 }
 
 // IsDownloadedFile checks if a file was previously downloaded.
 func (c *DownloadCheck) IsDownloadedFile(path string) bool {
 	files := c.loadDownloadedFiles()
-	resolved := parsers.ResolvePath(path, c.projectRoot)
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	if _, ok := files[c.downloadKey(resolved)]; ok {
+		return true
+	}
+	// Records written before keys were relative
 	_, ok := files[resolved]
 	return ok
 }
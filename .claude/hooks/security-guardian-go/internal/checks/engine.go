@@ -0,0 +1,282 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/decoy"
+	"github.com/artwist-polyakov/security-guardian/internal/gitstate"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+)
+
+// Engine holds what the checks of one hook invocation share: the project
+// root, compiled config patterns, the trusted scripts manifest, the decoy
+// registry, git index snapshots and subprocess probe results. Build it
+// once per invocation with NewEngine and pass it to the check
+// constructors.
+type Engine struct {
+	Config *config.SecurityConfig
+	// ProjectRoot is the detected project root (CLAUDE_PROJECT_DIR or the
+	// nearest .git); BoundaryRoot is directories.project_root when set,
+	// otherwise ProjectRoot.
+	ProjectRoot  string
+	BoundaryRoot string
+	// SessionID is the Claude session of the hook input; session counters
+	// are kept per session. Set it before creating checks.
+	SessionID string
+
+	ctx      context.Context
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp // nil value: invalid pattern
+	indexes  map[string]*gitstate.Index
+	zones    map[string]*Zones
+
+	started  time.Time
+	finished []CheckTiming
+
+	trustedOnce sync.Once
+	trusted     *trust.Manifest
+	decoysOnce  sync.Once
+	decoys      *decoy.Registry
+	probesOnce  sync.Once
+	probeCache  *state.ProbeCache // nil: performance.probe_cache_seconds is 0
+	probed      map[string]probeResult
+
+	errMu          sync.Mutex
+	internalErrors []InternalError
+}
+
+// probeResult is a probe run during this invocation.
+type probeResult struct {
+	result string
+	ok     bool
+}
+
+// NewEngine creates the shared state for checks running with cfg.
+func NewEngine(cfg *config.SecurityConfig) *Engine {
+	parsers.SetPathVariables(cfg.Directories.PathVariables)
+	root := parsers.GetProjectRoot()
+	boundary := root
+	if cfg.Directories.ProjectRoot != "" {
+		boundary = parsers.ResolvePath(cfg.Directories.ProjectRoot, "")
+	}
+	return &Engine{
+		Config:       cfg,
+		ProjectRoot:  root,
+		BoundaryRoot: boundary,
+		patterns:     make(map[string]*regexp.Regexp),
+		indexes:      make(map[string]*gitstate.Index),
+		zones:        make(map[string]*Zones),
+		probed:       make(map[string]probeResult),
+		started:      time.Now(),
+	}
+}
+
+// SetContext sets the context the checks run under: subprocesses they
+// start are killed, and checks not yet started are skipped, once it is
+// done. Set it before running checks.
+func (e *Engine) SetContext(ctx context.Context) {
+	e.ctx = ctx
+}
+
+// Context returns the context the checks run under, Background if none
+// was set.
+func (e *Engine) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// SessionFile returns the state file of the session: mass_modification.state_file
+// relative to the project root, one per session ID (see state.SessionPath).
+func (e *Engine) SessionFile() string {
+	path := e.Config.MassModification.StateFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.ProjectRoot, path)
+	}
+	return state.SessionPath(path, e.SessionID)
+}
+
+// CheckTiming is a check that finished and when, counted from NewEngine.
+type CheckTiming struct {
+	Check   string
+	Elapsed time.Duration
+}
+
+// Finished records that a check returned a result.
+func (e *Engine) Finished(check string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.finished = append(e.finished, CheckTiming{Check: check, Elapsed: time.Since(e.started)})
+}
+
+// Timings returns the checks that finished, in the order they did.
+func (e *Engine) Timings() []CheckTiming {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]CheckTiming(nil), e.finished...)
+}
+
+// compile returns the compiled pattern, or nil if it doesn't compile.
+// Each pattern is compiled once per invocation.
+func (e *Engine) compile(pattern string) *regexp.Regexp {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if re, ok := e.patterns[pattern]; ok {
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+		e.Errorf(ErrorPattern, "%v", err)
+	}
+	e.patterns[pattern] = re
+	return re
+}
+
+// compileAll compiles a list of patterns, skipping invalid ones.
+func (e *Engine) compileAll(patterns []string) []*regexp.Regexp {
+	var result []*regexp.Regexp
+	for _, p := range patterns {
+		if re := e.compile(p); re != nil {
+			result = append(result, re)
+		}
+	}
+	return result
+}
+
+// Zones returns the zones of the config relative to root.
+func (e *Engine) Zones(root string) *Zones {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if z, ok := e.zones[root]; ok {
+		return z
+	}
+	z := NewZones(e.Config, root)
+	e.zones[root] = z
+	return z
+}
+
+// Trusted returns the trusted scripts manifest, or nil.
+func (e *Engine) Trusted() *trust.Manifest {
+	e.trustedOnce.Do(func() {
+		e.trusted = loadTrustedScripts(e)
+	})
+	return e.trusted
+}
+
+// Decoys returns the decoy registry, or nil when decoys are off or none
+// are installed.
+func (e *Engine) Decoys() *decoy.Registry {
+	e.decoysOnce.Do(func() {
+		if !e.Config.Decoys.Enabled {
+			return
+		}
+		if r, err := decoy.Load(decoy.RegistryPath(e.Config, e.ProjectRoot)); err == nil && len(r.Decoys) > 0 {
+			e.decoys = r
+		}
+	})
+	return e.decoys
+}
+
+// IsGitTracked reports whether path is tracked by git. The index of each
+// repository is read once per invocation; an error means it couldn't be
+// read.
+func (e *Engine) IsGitTracked(path string) (bool, error) {
+	dir := filepath.Dir(path)
+
+	e.mu.Lock()
+	idx, ok := e.indexes[dir]
+	e.mu.Unlock()
+	if !ok {
+		var err error
+		if idx, err = gitstate.ReadIndex(dir); err != nil {
+			return false, err
+		}
+		e.mu.Lock()
+		e.indexes[dir] = idx
+		e.mu.Unlock()
+	}
+	return idx.Tracked(path), nil
+}
+
+// Probe returns the result of run, a subprocess probing path (kind names
+// the probe: "file", "git-tracked"); ok is false if run failed, which is
+// recorded as an ErrorProbe. Each probe runs once per invocation; results
+// with ok are also kept in the state directory for
+// performance.probe_cache_seconds, and reused while what they depend on
+// (see probeStat) keeps its size and mtime.
+func (e *Engine) Probe(kind, path string, run func() (string, error)) (string, bool) {
+	key := kind + "\x00" + path
+	e.mu.Lock()
+	if p, ok := e.probed[key]; ok {
+		e.mu.Unlock()
+		return p.result, p.ok
+	}
+	e.mu.Unlock()
+
+	info, statErr := probeStat(kind, path)
+	cache := e.probes()
+	if cache != nil && statErr == nil {
+		e.mu.Lock()
+		result, ok := cache.Get(kind, path, info)
+		if ok {
+			e.probed[key] = probeResult{result, true}
+		}
+		e.mu.Unlock()
+		if ok {
+			return result, true
+		}
+	}
+
+	result, err := run()
+	ok := err == nil
+	if err != nil {
+		e.Errorf(ErrorProbe, "%s %s: %v", kind, path, err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.probed[key] = probeResult{result, ok}
+	if cache != nil && statErr == nil && ok {
+		cache.Put(kind, path, info, result)
+		cache.Save()
+	}
+	return result, ok
+}
+
+// probeStat stats what a cached probe of path depends on: the file, or
+// for "git-tracked" the repository's index, which `git add` and `git rm
+// --cached` rewrite while the file stays the same.
+func probeStat(kind, path string) (os.FileInfo, error) {
+	if kind == "git-tracked" {
+		index, err := gitstate.IndexFile(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		return os.Stat(index)
+	}
+	return os.Stat(path)
+}
+
+// probes returns the probe cache shared across invocations, or nil.
+func (e *Engine) probes() *state.ProbeCache {
+	e.probesOnce.Do(func() {
+		ttl := time.Duration(e.Config.Performance.ProbeCacheSeconds) * time.Second
+		if ttl <= 0 || e.Config.StateDirectory == "" {
+			return
+		}
+		path := filepath.Join(state.ProjectDir(e.Config.StateDirectory, e.ProjectRoot), "probes.json")
+		e.probeCache = state.LoadProbeCache(path, ttl)
+	})
+	return e.probeCache
+}
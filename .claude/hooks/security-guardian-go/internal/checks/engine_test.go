@@ -0,0 +1,164 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// newTestEngine returns an engine with the default config for a project
+// in a temporary directory.
+func newTestEngine(t testing.TB) *Engine {
+	t.Helper()
+	t.Setenv("CLAUDE_PROJECT_DIR", t.TempDir())
+	t.Setenv("CDPATH", "")
+	return NewEngine(config.DefaultConfig())
+}
+
+// probeFiles is how many files a probed command touches, "dozens" in the
+// cases that made per-path subprocesses slow.
+const probeFiles = 30
+
+// newProbeProject creates a git repository with probeFiles tracked files
+// in a temporary project and returns their paths.
+func newProbeProject(tb testing.TB) []string {
+	tb.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git not installed")
+	}
+	root := tb.TempDir()
+	tb.Setenv("CLAUDE_PROJECT_DIR", root)
+
+	var files []string
+	for i := 0; i < probeFiles; i++ {
+		path := filepath.Join(root, fmt.Sprintf("script%02d.sh", i))
+		if err := os.WriteFile(path, []byte("#!/bin/sh\necho hi\n"), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			tb.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return files
+}
+
+// newProbeEngine returns an engine keeping probes in a state directory
+// of its own for ttlSeconds (0: per call only).
+func newProbeEngine(stateDir string, ttlSeconds int) *Engine {
+	cfg := config.DefaultConfig()
+	cfg.StateDirectory = stateDir
+	cfg.Performance.ProbeCacheSeconds = ttlSeconds
+	return NewEngine(cfg)
+}
+
+// probeGitTracked runs the `git ls-files` probe on each file rounds times,
+// as several checks looking at the same paths would, and returns how many
+// subprocesses ran.
+func probeGitTracked(tb testing.TB, e *Engine, files []string, rounds int) int {
+	runs := 0
+	for r := 0; r < rounds; r++ {
+		for _, path := range files {
+			result, ok := e.Probe("git-tracked", path, func() (string, error) {
+				runs++
+				tracked, err := parsers.IsGitTracked(context.Background(), path, e.ProjectRoot)
+				return strconv.FormatBool(tracked), err
+			})
+			if !ok || result != "true" {
+				tb.Fatalf("probe %s = %q, %v", path, result, ok)
+			}
+		}
+	}
+	return runs
+}
+
+func TestProbeCache(t *testing.T) {
+	files := newProbeProject(t)
+	stateDir := t.TempDir()
+
+	// Within a call each path is probed once
+	if runs := probeGitTracked(t, newProbeEngine(stateDir, 0), files, 3); runs != probeFiles {
+		t.Errorf("per call: %d subprocesses, want %d", runs, probeFiles)
+	}
+	// The first call with the cache fills it, the next ones reuse it
+	if runs := probeGitTracked(t, newProbeEngine(stateDir, 300), files, 1); runs != probeFiles {
+		t.Errorf("cold cache: %d subprocesses, want %d", runs, probeFiles)
+	}
+	if runs := probeGitTracked(t, newProbeEngine(stateDir, 300), files, 1); runs != 0 {
+		t.Errorf("warm cache: %d subprocesses, want 0", runs)
+	}
+	// Editing a file leaves the index, and so the cache, alone
+	if err := os.WriteFile(files[0], []byte("#!/bin/sh\necho changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if runs := probeGitTracked(t, newProbeEngine(stateDir, 300), files, 1); runs != 0 {
+		t.Errorf("after an edit: %d subprocesses, want 0", runs)
+	}
+	// Untracking a file rewrites the index: every path is probed again
+	cmd := exec.Command("git", "rm", "-q", "-f", "--cached", files[0])
+	cmd.Dir = filepath.Dir(files[0])
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git rm --cached: %v\n%s", err, out)
+	}
+	e := newProbeEngine(stateDir, 300)
+	runs := 0
+	result, ok := e.Probe("git-tracked", files[0], func() (string, error) {
+		runs++
+		tracked, err := parsers.IsGitTracked(context.Background(), files[0], e.ProjectRoot)
+		return strconv.FormatBool(tracked), err
+	})
+	if !ok || result != "false" || runs != 1 {
+		t.Errorf("after git rm --cached: probe = %q, %v with %d subprocesses, want \"false\" with 1", result, ok, runs)
+	}
+	if runs := probeGitTracked(t, e, files[1:], 1); runs != probeFiles-1 {
+		t.Errorf("after git rm --cached: %d subprocesses, want %d", runs, probeFiles-1)
+	}
+}
+
+// BenchmarkProbeGitTracked measures one hook call probing probeFiles
+// paths three times each: "uncached" runs a subprocess per probe (the
+// behaviour before the cache), "per-call" once per path, "disk-cache" none
+// while the index is unchanged. The target is a warm call under 1ms
+// where the uncached one costs a git process per path.
+func BenchmarkProbeGitTracked(b *testing.B) {
+	files := newProbeProject(b)
+
+	b.Run("uncached", func(b *testing.B) {
+		runs := 0
+		for i := 0; i < b.N; i++ {
+			for r := 0; r < 3; r++ {
+				// A fresh engine per round forgets what the last one ran
+				runs += probeGitTracked(b, newProbeEngine(b.TempDir(), 0), files, 1)
+			}
+		}
+		b.ReportMetric(float64(runs)/float64(b.N), "subprocs/op")
+	})
+	b.Run("per-call", func(b *testing.B) {
+		runs := 0
+		for i := 0; i < b.N; i++ {
+			runs += probeGitTracked(b, newProbeEngine(b.TempDir(), 0), files, 3)
+		}
+		b.ReportMetric(float64(runs)/float64(b.N), "subprocs/op")
+	})
+	b.Run("disk-cache", func(b *testing.B) {
+		stateDir := b.TempDir()
+		probeGitTracked(b, newProbeEngine(stateDir, 300), files, 1)
+		b.ResetTimer()
+		runs := 0
+		for i := 0; i < b.N; i++ {
+			runs += probeGitTracked(b, newProbeEngine(stateDir, 300), files, 3)
+		}
+		b.ReportMetric(float64(runs)/float64(b.N), "subprocs/op")
+	})
+}
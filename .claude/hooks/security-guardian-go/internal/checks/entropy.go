@@ -0,0 +1,204 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// Token-like runs: base64/base64url alphabet (= only as trailing padding)
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_\-.]+=*`)
+
+// Hex tokens have at most 4 bits of entropy per char, so they get their
+// own threshold (and need a secret-ish name nearby: git SHAs are hex too).
+var (
+	hexPattern     = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	secretKeywords = regexp.MustCompile(`(?i)(key|token|secret|passw|auth|credential|bearer|signature)`)
+)
+
+const hexMinEntropy = 3.0
+
+// CheckReadContent samples a file allowed for Read and asks before loading it
+// into the context when it contains high-entropy tokens that look like
+// credentials, even though its name doesn't match forbidden_read.
+func (c *SecretsCheck) CheckReadContent(path string) *CheckResult {
+	scan := c.config.SensitiveFiles.ContentScan
+	if !scan.Enabled {
+		return c.Allow()
+	}
+
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	if c.skipContentScan(resolved) {
+		return c.Allow()
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil || !info.Mode().IsRegular() {
+		return c.Allow()
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		c.engine.Errorf(ErrorRead, "%v", err)
+		return c.Allow()
+	}
+	defer f.Close()
+
+	sample, err := io.ReadAll(io.LimitReader(f, int64(contentScanBytes(scan.MaxBytes))))
+	if err != nil {
+		c.engine.Errorf(ErrorRead, "%v", err)
+		return c.Allow()
+	}
+	return c.checkSample(path, sample)
+}
+
+// CheckContentSecrets is CheckReadContent for content that isn't read from
+// the working tree (a staged blob in `guardian scan --staged`).
+func (c *SecretsCheck) CheckContentSecrets(path string, content []byte) *CheckResult {
+	if !c.config.SensitiveFiles.ContentScan.Enabled {
+		return c.Allow()
+	}
+	if c.skipContentScan(parsers.ResolvePath(path, c.baseDir(c.projectRoot))) {
+		return c.Allow()
+	}
+	if max := contentScanBytes(c.config.SensitiveFiles.ContentScan.MaxBytes); len(content) > max {
+		content = content[:max]
+	}
+	return c.checkSample(path, content)
+}
+
+// skipContentScan reports whether a file is in content_scan.skip_files.
+func (c *SecretsCheck) skipContentScan(resolved string) bool {
+	rel, err := filepath.Rel(c.projectRoot, resolved)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = resolved
+	}
+	_, skip := globs.MatchListFunc(c.config.SensitiveFiles.ContentScan.SkipFiles, func(p string) bool {
+		return matchPathOrName(p, rel, filepath.Base(rel))
+	})
+	return skip
+}
+
+// contentScanBytes returns content_scan.max_bytes or its default.
+func contentScanBytes(maxBytes int) int {
+	if maxBytes <= 0 {
+		return 256 * 1024
+	}
+	return maxBytes
+}
+
+// checkSample asks when the first bytes of a file hold credential-like tokens.
+func (c *SecretsCheck) checkSample(path string, sample []byte) *CheckResult {
+	scan := c.config.SensitiveFiles.ContentScan
+	if bytes.IndexByte(sample, 0) >= 0 {
+		// Binary files are not loaded as text
+		return c.Allow()
+	}
+
+	found := findHighEntropyTokens(c.engine.Context(), string(sample), scan.MinLength, scan.MinEntropy, c.config.InputLimits)
+	if len(found) == 0 {
+		return c.Allow()
+	}
+
+	lines := []string{fmt.Sprintf("%s looks like it contains credentials:", path)}
+	for i, tok := range found {
+		if i >= 5 {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(found)-5))
+			break
+		}
+		lines = append(lines, "  - "+tok)
+	}
+	lines = append(lines, "\nReading it puts these values into the conversation. Ask the user whether it is safe, or read a redacted copy / example file instead.")
+
+	return c.Ask(
+		fmt.Sprintf("File may contain secrets (high-entropy tokens): %s", path),
+		strings.Join(lines, "\n"),
+	).WithRule(RuleSecretsReadHighEntropy)
+}
+
+// findHighEntropyTokens returns masked descriptions of credential-like tokens
+// in content ("line 3: sk-a…(40 chars)"), at most max_pattern_matches of
+// input_limits. Tokens are searched in its chunks, so a minified bundle on
+// one line isn't split into every word it has at once.
+func findHighEntropyTokens(ctx context.Context, content string, minLength int, minEntropy float64, limits config.InputLimitsConfig) []string {
+	if minLength <= 0 {
+		minLength = 20
+	}
+	if minEntropy <= 0 {
+		minEntropy = 4.5
+	}
+
+	var found []string
+	seen := make(map[string]bool)
+	lines := &lineCounter{text: content}
+	matchChunks(ctx, tokenPattern, content, limits, 0, func(start, end int) bool {
+		tok := strings.Trim(content[start:end], "=.-")
+		// The name nearby is looked for on the line, within lineSlack
+		line := content[lineStart(content, start):lineStop(content, end)]
+		if len(tok) < minLength || seen[tok] || !looksLikeSecret(tok, line, minEntropy) {
+			return true
+		}
+		seen[tok] = true
+		n, _ := lines.lineColumn(start)
+		found = append(found, fmt.Sprintf("line %d: %s…(%d chars)", n, tok[:4], len(tok)))
+		return limits.MaxPatternMatches <= 0 || len(found) < limits.MaxPatternMatches
+	})
+	return found
+}
+
+// looksLikeSecret reports whether tok is random enough to be a credential
+// rather than a word, path or identifier.
+func looksLikeSecret(tok, line string, minEntropy float64) bool {
+	// Paths and dotted names (a/b/c, com.example.app) are not keys
+	if strings.Count(tok, "/") > 2 || strings.Count(tok, ".") > 1 {
+		return false
+	}
+	if hexPattern.MatchString(tok) {
+		return len(tok) >= 32 && secretKeywords.MatchString(line) && shannonEntropy(tok) >= hexMinEntropy
+	}
+	digits, letters := 0, 0
+	for _, r := range tok {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+			letters++
+		}
+	}
+	// Random keys mix letters and digits; identifiers like
+	// getUserById2Response have a digit at most
+	if letters == 0 || digits == 0 || (digits < 2 && len(tok) < 32) {
+		return false
+	}
+	// A short string can't reach a high per-char entropy even when random
+	threshold := math.Min(minEntropy, 0.9*math.Log2(float64(len(tok))))
+	return shannonEntropy(tok) >= threshold
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,50 @@
+package checks
+
+import "fmt"
+
+// Kinds of internal errors (see Engine.Errorf).
+const (
+	// ErrorPattern is a configured regexp that doesn't compile.
+	ErrorPattern = "pattern"
+	// ErrorConfig is a config entry or state file a check had to skip
+	// (an invalid custom rule, an unreadable trusted scripts manifest).
+	ErrorConfig = "config"
+	// ErrorRead is a file to check that couldn't be read.
+	ErrorRead = "read"
+	// ErrorProbe is a subprocess probe (`file`, `git ls-files`) that
+	// failed or timed out.
+	ErrorProbe = "probe"
+)
+
+// InternalError is a problem a check worked around by checking less. It
+// doesn't change the decision, but protection is weaker than configured
+// until it is fixed, so it is logged and counted apart from decisions.
+type InternalError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (e InternalError) String() string {
+	return e.Kind + ": " + e.Message
+}
+
+// Errorf records an internal error of kind. The same error is recorded
+// once per invocation.
+func (e *Engine) Errorf(kind, format string, args ...interface{}) {
+	ie := InternalError{Kind: kind, Message: fmt.Sprintf(format, args...)}
+	e.errMu.Lock()
+	defer e.errMu.Unlock()
+	for _, existing := range e.internalErrors {
+		if existing == ie {
+			return
+		}
+	}
+	e.internalErrors = append(e.internalErrors, ie)
+}
+
+// Errors returns the internal errors recorded so far, in order.
+func (e *Engine) Errors() []InternalError {
+	e.errMu.Lock()
+	defer e.errMu.Unlock()
+	return append([]InternalError(nil), e.internalErrors...)
+}
@@ -2,24 +2,30 @@ package checks
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+	"github.com/gabriel-vasile/mimetype"
 )
 
-// Note: Using custom timeout handling instead of context.Context
-
-// ExecutionCheck checks for chmod +x on downloaded or suspicious files.
+// ExecutionCheck checks for chmod +x on downloaded or suspicious files, and
+// for the first run of a downloaded file.
 type ExecutionCheck struct {
 	BaseCheck
 	projectRoot   string
 	config        *config.SecurityConfig
 	downloadCheck *DownloadCheck
+	trusted       *trust.Manifest
+	engine        *Engine
 }
 
 // Binary magic bytes for detection
@@ -32,12 +38,27 @@ var binaryMagic = map[string][]byte{
 	"Script with shebang": {'#', '!'},
 }
 
+// runInterpreters run the script file given as their first argument (or
+// on stdin). Their inline code flags make the argument something else.
+var runInterpreters = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+	"python": true, "python2": true, "python3": true, "pypy": true, "pypy3": true,
+	"ruby": true, "perl": true, "node": true, "nodejs": true, "deno": true, "bun": true,
+	"php": true, "lua": true, "Rscript": true, "osascript": true, "tclsh": true,
+}
+
+// inlineCodeFlags take code or a module instead of a file.
+var inlineCodeFlags = map[string]bool{"-c": true, "-e": true, "-E": true, "-m": true, "-r": true, "--eval": true, "--print": true, "-p": true}
+
 // NewExecutionCheck creates a new ExecutionCheck instance.
-func NewExecutionCheck(cfg *config.SecurityConfig) *ExecutionCheck {
+func NewExecutionCheck(e *Engine) *ExecutionCheck {
+	projectRoot := e.ProjectRoot
 	return &ExecutionCheck{
 		BaseCheck:   BaseCheck{CheckName: "execution_check"},
-		projectRoot: parsers.GetProjectRoot(),
-		config:      cfg,
+		projectRoot: projectRoot,
+		config:      e.Config,
+		trusted:     e.Trusted(),
+		engine:      e,
 	}
 }
 
@@ -46,7 +67,7 @@ func (c *ExecutionCheck) SetDownloadCheck(dc *DownloadCheck) {
 	c.downloadCheck = dc
 }
 
-// CheckCommand checks chmod commands for safety.
+// CheckCommand checks chmod commands and files run by commands.
 func (c *ExecutionCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
 	for _, cmd := range parsedCommands {
 		if cmd.Command == "chmod" {
@@ -55,11 +76,90 @@ func (c *ExecutionCheck) CheckCommand(rawCommand string, parsedCommands []*Parse
 				return result
 			}
 		}
+		if target := ranFile(cmd); target != "" {
+			result := c.checkRun(cmd, target)
+			if !result.IsAllowed() {
+				return result
+			}
+		}
 	}
 
 	return c.Allow()
 }
 
+// RecordRuns marks the downloaded files the commands ran as executed, so
+// only their first run asks. Called from PostToolUse, when the command ran.
+func (c *ExecutionCheck) RecordRuns(parsedCommands []*ParsedCommand) {
+	if c.downloadCheck == nil {
+		return
+	}
+	for _, cmd := range parsedCommands {
+		if target := ranFile(cmd); target != "" {
+			c.downloadCheck.MarkExecuted(target)
+		}
+	}
+}
+
+// checkRun asks before a downloaded file runs for the first time.
+func (c *ExecutionCheck) checkRun(cmd *ParsedCommand, target string) *CheckResult {
+	if c.downloadCheck == nil || !c.config.DownloadProtection.ConfirmFirstRun {
+		return c.Allow()
+	}
+	origin, ok := c.downloadCheck.FirstRun(target)
+	if !ok {
+		return c.Allow()
+	}
+	if c.trusted != nil {
+		resolved := parsers.ResolvePath(target, c.baseDir(c.projectRoot))
+		if c.trusted.Lookup(c.projectRoot, resolved) == trust.Trusted {
+			return c.Allow()
+		}
+	}
+	return c.Confirm(
+		fmt.Sprintf("First run of downloaded or extracted file: %s", target),
+		fmt.Sprintf("%s was %s and has not run yet. Show the user the file and give them the command: `%s`", target, origin, cmd.Raw),
+	).WithRule(RuleExecutionRunDownloaded).WithPaths(target).WithOrigin(cmd)
+}
+
+// ranFile returns the file a command runs: the command itself when given
+// as a path (./payload), the file given to source, or the script of an
+// interpreter (python payload, sh payload, sh < payload). "" if none.
+func ranFile(cmd *ParsedCommand) string {
+	var target string
+	switch name := filepath.Base(cmd.Command); {
+	case strings.Contains(cmd.Command, "/"):
+		target = cmd.Command
+	case cmd.Command == "source" || cmd.Command == ".":
+		if len(cmd.Args) > 0 {
+			target = cmd.Args[0]
+		}
+	case runInterpreters[name]:
+		for _, f := range cmd.Flags {
+			if inlineCodeFlags[f] {
+				return ""
+			}
+		}
+		args := cmd.Args
+		// deno run x.ts, bun run x.ts
+		if (name == "deno" || name == "bun") && len(args) > 0 && args[0] == "run" {
+			args = args[1:]
+		}
+		if len(args) > 0 {
+			target = args[0]
+			break
+		}
+		for _, r := range cmd.Redirections {
+			if r.Op == "<" {
+				target = r.Target
+			}
+		}
+	}
+	if target == "" {
+		return ""
+	}
+	return parsers.JoinDir(cmd.Dir, target)
+}
+
 // checkChmod checks a chmod command for making downloaded files executable.
 func (c *ExecutionCheck) checkChmod(cmd *ParsedCommand) *CheckResult {
 	// Check if making executable (+x)
@@ -76,21 +176,34 @@ func (c *ExecutionCheck) checkChmod(cmd *ParsedCommand) *CheckResult {
 			continue
 		}
 
-		resolved := parsers.ResolvePath(pathStr, c.projectRoot)
+		// Relative to the directory the command runs in (after cd)
+		target := parsers.JoinDir(cmd.Dir, pathStr)
+		resolved := parsers.ResolvePath(target, c.baseDir(c.projectRoot))
 
-		// Check if git-tracked (allowed)
-		if c.config.DownloadProtection.GitTrackedAllow {
-			if parsers.IsGitTracked(resolved, c.projectRoot) {
+		// Reviewed scripts (guardian trust) are allowed while unchanged
+		if c.trusted != nil {
+			switch c.trusted.Lookup(c.projectRoot, resolved) {
+			case trust.Trusted:
 				continue
+			case trust.Changed:
+				return c.Confirm(
+					fmt.Sprintf("chmod +x on trusted script that changed since review: %s", pathStr),
+					fmt.Sprintf("Show the user the diff. If it is fine, they can run `guardian trust %s` again.", pathStr),
+				).WithRule(RuleExecutionTrustedChanged)
 			}
 		}
 
+		// Check if git-tracked (allowed)
+		if c.config.DownloadProtection.GitTrackedAllow && c.isGitTracked(resolved) {
+			continue
+		}
+
 		// Check if previously downloaded
-		if c.downloadCheck != nil && c.downloadCheck.IsDownloadedFile(pathStr) {
+		if c.downloadCheck != nil && c.downloadCheck.IsDownloadedFile(target) {
 			return c.Confirm(
 				fmt.Sprintf("chmod +x on downloaded file: %s", pathStr),
 				fmt.Sprintf("File was downloaded from internet. Give user: `chmod +x %s`", pathStr),
-			)
+			).WithRule(RuleExecutionChmodDownloaded)
 		}
 
 		// Check file type if enabled
@@ -128,62 +241,102 @@ func (c *ExecutionCheck) isMakingExecutable(cmd *ParsedCommand) bool {
 	return false
 }
 
-// checkBinaryType checks file type using file command or magic bytes.
+// isGitTracked reads the git index, falling back to `git ls-files` when
+// the index can't be read and subprocess_fallback is on.
+func (c *ExecutionCheck) isGitTracked(path string) bool {
+	tracked, err := c.engine.IsGitTracked(path)
+	if err != nil && c.config.DownloadProtection.SubprocessFallback {
+		result, _ := c.engine.Probe("git-tracked", path, func() (string, error) {
+			tracked, err := parsers.IsGitTracked(c.engine.Context(), path, c.projectRoot)
+			return strconv.FormatBool(tracked), err
+		})
+		return result == "true"
+	}
+	return tracked
+}
+
+// executableTypes maps content types (and their parents) to the file type
+// shown to the user.
+var executableTypes = map[string]string{
+	"application/x-elf":                             "ELF executable",
+	"application/x-mach-binary":                     "Mach-O binary",
+	"application/vnd.microsoft.portable-executable": "Windows PE",
+	"text/x-python":                                 "Python script",
+	"text/x-perl":                                   "Perl script",
+	"text/x-php":                                    "PHP script",
+	"text/x-lua":                                    "Lua script",
+	"text/x-tcl":                                    "Tcl script",
+	"application/javascript":                        "Node.js script",
+}
+
+// checkBinaryType checks file type by content, then by magic bytes, and
+// with subprocess_fallback by the `file` command for unknown content.
 func (c *ExecutionCheck) checkBinaryType(path string, originalPath string) *CheckResult {
 	info, err := os.Stat(path)
 	if err != nil || info.IsDir() {
 		return nil
 	}
 
-	// Try file command first with timeout
-	cmd := exec.Command("file", "-b", path)
-
-	done := make(chan []byte, 1)
-	errChan := make(chan error, 1)
-	go func() {
-		out, err := cmd.Output()
-		if err != nil {
-			errChan <- err
-		} else {
-			done <- out
+	mtype, err := mimetype.DetectFile(path)
+	if err == nil {
+		for m := mtype; m != nil; m = m.Parent() {
+			if fileType, ok := executableTypes[m.String()]; ok {
+				return c.binaryResult(fileType, originalPath)
+			}
 		}
-	}()
+	}
 
-	var output []byte
-	select {
-	case output = <-done:
-		err = nil
-	case err = <-errChan:
-	case <-time.After(5 * time.Second):
-		if cmd.Process != nil {
-			cmd.Process.Kill()
+	if c.config.DownloadProtection.FileCommandFallback {
+		if result := c.checkMagicBytes(path, originalPath); result != nil {
+			return result
 		}
-		err = fmt.Errorf("timeout")
 	}
 
-	if err == nil {
-		outputLower := strings.ToLower(string(output))
-		if strings.Contains(outputLower, "executable") ||
-			strings.Contains(outputLower, "script") ||
-			strings.Contains(outputLower, "elf") ||
-			strings.Contains(outputLower, "mach-o") ||
-			strings.Contains(outputLower, "pe32") {
-			return c.Confirm(
-				fmt.Sprintf("chmod +x on binary/script file: %s", originalPath),
-				fmt.Sprintf("File appears to be executable. Give user: `chmod +x %s`", originalPath),
-			)
-		}
-		return nil
+	if c.config.DownloadProtection.SubprocessFallback && (err != nil || mtype.Is("application/octet-stream")) {
+		return c.checkFileCommand(path, originalPath)
 	}
+	return nil
+}
 
-	// Fallback to magic bytes if file command unavailable
-	if c.config.DownloadProtection.FileCommandFallback {
-		return c.checkMagicBytes(path, originalPath)
+// checkFileCommand checks file type using the file command.
+func (c *ExecutionCheck) checkFileCommand(path string, originalPath string) *CheckResult {
+	output, ok := c.engine.Probe("file", path, func() (string, error) {
+		return runFileCommand(c.engine.Context(), path)
+	})
+	if !ok {
+		return nil
 	}
 
+	outputLower := strings.ToLower(output)
+	if strings.Contains(outputLower, "executable") ||
+		strings.Contains(outputLower, "script") ||
+		strings.Contains(outputLower, "elf") ||
+		strings.Contains(outputLower, "mach-o") ||
+		strings.Contains(outputLower, "pe32") {
+		return c.Confirm(
+			fmt.Sprintf("chmod +x on binary/script file: %s", originalPath),
+			fmt.Sprintf("File appears to be executable. Give user: `chmod +x %s`", originalPath),
+		).WithRule(RuleExecutionChmodBinary)
+	}
 	return nil
 }
 
+// runFileCommand returns the output of `file -b path`. It is killed when
+// ctx is done or after 5 seconds.
+func runFileCommand(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "file", "-b", path).Output()
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("file: %w", ctx.Err())
+	}
+	if err != nil {
+		return "", fmt.Errorf("file: %w", err)
+	}
+	return string(output), nil
+}
+
 // checkMagicBytes checks file type by reading magic bytes.
 func (c *ExecutionCheck) checkMagicBytes(path string, originalPath string) *CheckResult {
 	f, err := os.Open(path)
@@ -200,16 +353,21 @@ func (c *ExecutionCheck) checkMagicBytes(path string, originalPath string) *Chec
 
 	for fileType, magic := range binaryMagic {
 		if bytes.HasPrefix(header[:n], magic) {
-			return c.Confirm(
-				fmt.Sprintf("chmod +x on %s: %s", fileType, originalPath),
-				fmt.Sprintf("File is %s. Give user: `chmod +x %s`", fileType, originalPath),
-			)
+			return c.binaryResult(fileType, originalPath)
 		}
 	}
 
 	return nil
 }
 
+// binaryResult asks for confirmation of chmod +x on an executable file.
+func (c *ExecutionCheck) binaryResult(fileType string, originalPath string) *CheckResult {
+	return c.Confirm(
+		fmt.Sprintf("chmod +x on %s: %s", fileType, originalPath),
+		fmt.Sprintf("File is %s. Give user: `chmod +x %s`", fileType, originalPath),
+	).WithRule(RuleExecutionChmodBinary)
+}
+
 // isNumeric checks if a string is all digits.
 func isNumeric(s string) bool {
 	if s == "" {
@@ -0,0 +1,244 @@
+package checks
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// maxArchiveMembers bounds how many members of one archive are listed.
+const maxArchiveMembers = 10000
+
+// archiveMember is a regular file in an archive.
+type archiveMember struct {
+	name string
+	mode os.FileMode
+}
+
+// SetDownloadCheck sets the download record extracted files are added to.
+func (c *UnpackCheck) SetDownloadCheck(dc *DownloadCheck) {
+	c.downloadCheck = dc
+}
+
+// RecordExtracted records the scripts and binaries the unpack commands
+// extracted with the downloads, so their first run asks like a download's.
+// Called from PostToolUse, when the files exist.
+func (c *UnpackCheck) RecordExtracted(parsedCommands []*ParsedCommand) {
+	if c.downloadCheck == nil || !c.config.UnpackProtection.TrackExtractedExecutables {
+		return
+	}
+	for _, cmd := range parsedCommands {
+		if !isExtraction(cmd) {
+			continue
+		}
+		archive := archiveOperand(cmd)
+		if archive == "" {
+			continue
+		}
+		archive = parsers.JoinDir(cmd.Dir, archive)
+		members, err := listArchive(parsers.ResolvePath(archive, c.baseDir(c.projectRoot)))
+		if err != nil || len(members) == 0 {
+			continue
+		}
+
+		dir := c.extractTargetDirectory(cmd)
+		if dir == "" {
+			dir = "."
+		}
+		dir = parsers.ResolvePath(parsers.JoinDir(cmd.Dir, dir), c.baseDir(c.projectRoot))
+		strip := stripComponents(cmd)
+
+		var files []string
+		for _, m := range members {
+			name := m.name
+			if cmd.Command == "unzip" && containsFlag(cmd.Flags, "-j") {
+				name = path.Base(name)
+			} else if name = stripPath(name, strip); name == "" {
+				continue
+			}
+			file := filepath.Join(dir, filepath.FromSlash(name))
+			if isExecutableFile(file, m.mode, c.config.DownloadProtection.RequireUserDownload) {
+				files = append(files, file)
+			}
+		}
+		c.downloadCheck.TrackExtracted(files, archive)
+	}
+}
+
+// isExtraction reports whether an unpack command extracts files (not a
+// listing or test).
+func isExtraction(cmd *ParsedCommand) bool {
+	switch cmd.Command {
+	case "tar", "bsdtar":
+		for _, f := range cmd.Flags {
+			if f == "--extract" || f == "--get" || !strings.HasPrefix(f, "--") && strings.Contains(f, "x") {
+				return true
+			}
+		}
+		// tar xzf archive.tgz
+		return len(cmd.Args) > 0 && strings.HasPrefix(cmd.Args[0], "x")
+	case "unzip":
+		for _, f := range cmd.Flags {
+			if f == "-l" || f == "-t" || f == "-Z" || f == "-v" {
+				return false
+			}
+		}
+		return true
+	case "7z", "7za":
+		return len(cmd.Args) > 0 && (cmd.Args[0] == "x" || cmd.Args[0] == "e")
+	}
+	return false
+}
+
+// archiveOperand returns the archive an unpack command reads.
+func archiveOperand(cmd *ParsedCommand) string {
+	for _, f := range cmd.Flags {
+		if strings.HasPrefix(f, "--file=") {
+			return strings.TrimPrefix(f, "--file=")
+		}
+	}
+	for _, arg := range cmd.Args {
+		if archiveFormat(arg) != "" {
+			return arg
+		}
+	}
+	return ""
+}
+
+// archiveFormat returns the format of an archive by name: tar, tar.gz,
+// tar.bz2, zip, or other for formats that can't be listed in process.
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar.bz2"), strings.HasSuffix(lower, ".tbz2"):
+		return "tar.bz2"
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"), strings.HasSuffix(lower, ".whl"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.xz"), strings.HasSuffix(lower, ".txz"),
+		strings.HasSuffix(lower, ".7z"), strings.HasSuffix(lower, ".rar"):
+		return "other"
+	}
+	return ""
+}
+
+// listArchive returns the regular files of an archive.
+func listArchive(file string) ([]archiveMember, error) {
+	format := archiveFormat(file)
+	if format == "zip" {
+		r, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		var members []archiveMember
+		for _, f := range r.File {
+			if len(members) >= maxArchiveMembers {
+				break
+			}
+			if f.Mode().IsRegular() {
+				members = append(members, archiveMember{name: f.Name, mode: f.Mode()})
+			}
+		}
+		return members, nil
+	}
+	if format != "tar" && format != "tar.gz" && format != "tar.bz2" {
+		return nil, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	switch format {
+	case "tar.gz":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.bz2":
+		r = bzip2.NewReader(f)
+	}
+
+	var members []archiveMember
+	tr := tar.NewReader(r)
+	for len(members) < maxArchiveMembers {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return members, err
+		}
+		if h.Typeflag == tar.TypeReg {
+			members = append(members, archiveMember{name: h.Name, mode: h.FileInfo().Mode()})
+		}
+	}
+	return members, nil
+}
+
+// stripComponents returns the --strip-components value of a tar command.
+func stripComponents(cmd *ParsedCommand) int {
+	for _, f := range cmd.Flags {
+		if v, ok := strings.CutPrefix(f, "--strip-components="); ok {
+			n, _ := strconv.Atoi(v)
+			return n
+		}
+	}
+	return 0
+}
+
+// stripPath drops the first n elements of an archive member name, as tar
+// --strip-components does; "" if nothing is left.
+func stripPath(name string, n int) string {
+	parts := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+	if n >= len(parts) {
+		return ""
+	}
+	return path.Join(parts[n:]...)
+}
+
+// isExecutableFile reports whether an extracted file is a script or
+// binary: executable mode in the archive, a script or binary extension,
+// or a shebang or executable header on disk.
+func isExecutableFile(file string, mode os.FileMode, exts []string) bool {
+	if mode&0o111 != 0 {
+		return true
+	}
+	lower := strings.ToLower(file)
+	for _, ext := range exts {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, 8)
+	n, _ := f.Read(header)
+	for _, magic := range binaryMagic {
+		if bytes.HasPrefix(header[:n], magic) {
+			return true
+		}
+	}
+	return false
+}
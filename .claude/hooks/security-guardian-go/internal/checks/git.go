@@ -12,7 +12,10 @@ import (
 // GitCheck checks for destructive git operations.
 type GitCheck struct {
 	BaseCheck
-	config *config.SecurityConfig
+	projectRoot  string
+	allowedPaths []string
+	config       *config.SecurityConfig
+	aliasCache   map[string]map[string]string // git aliases by directory
 }
 
 // SaferAlternatives maps operation patterns to their safer alternatives.
@@ -26,41 +29,98 @@ var SaferAlternatives = map[string]string{
 }
 
 // NewGitCheck creates a new GitCheck instance.
-func NewGitCheck(cfg *config.SecurityConfig) *GitCheck {
+func NewGitCheck(e *Engine) *GitCheck {
 	return &GitCheck{
-		BaseCheck: BaseCheck{CheckName: "git_check"},
-		config:    cfg,
+		BaseCheck:    BaseCheck{CheckName: "git_check"},
+		projectRoot:  e.ProjectRoot,
+		allowedPaths: e.Config.Directories.AllowedPaths,
+		config:       e.Config,
 	}
 }
 
-// CheckCommand checks git command for destructive operations.
+// CheckCommand checks the git commands of a command line for destructive
+// operations. Flags are matched in canonical form (see
+// parsers.NormalizeGitArgs), so --delete matches -d and a +refspec matches
+// --force, after expanding git aliases. A denial wins over an ask.
 func (c *GitCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
-	// Convert to parsers.ParsedCommand
-	parserCmds := make([]*parsers.ParsedCommand, len(parsedCommands))
-	for i, cmd := range parsedCommands {
-		parserCmds[i] = convertParsedCommand(cmd)
+	defined := definedAliases(parsedCommands)
+	var asked *CheckResult
+	for _, cmd := range parsedCommands {
+		if cmd.Command != "git" {
+			continue
+		}
+		result := c.checkGitCommand(cmd, defined)
+		if result.IsBlocked() {
+			return result
+		}
+		if asked == nil && !result.IsAllowed() {
+			asked = result
+		}
 	}
+	if asked != nil {
+		return asked
+	}
+	return c.Allow()
+}
 
-	subcommand, flags := parsers.GetGitSubcommandAndFlags(parserCmds)
+// checkGitCommand checks one git command. defined are the aliases the
+// command line itself sets with git config.
+func (c *GitCheck) checkGitCommand(cmd *ParsedCommand, defined map[string]string) *CheckResult {
+	gitCmd, alias, shell := parsers.ExpandGitAlias(cmd, func(name string) (string, bool) {
+		if value, ok := defined[name]; ok {
+			return value, true
+		}
+		value, ok := c.aliases(cmd)[name]
+		return value, ok
+	})
+	if shell != "" {
+		return c.Confirm(
+			fmt.Sprintf("git %s is an alias that runs a shell command: %s", alias, shell),
+			fmt.Sprintf("Run what the alias runs directly, so it is checked. Give user the command: `%s`", cmd.Raw),
+		).WithRule(RuleGitShellAlias).WithPattern("alias." + alias)
+	}
 
-	if subcommand == "" {
+	inv := parsers.NormalizeGitArgs(gitCmd)
+	if inv == nil {
 		return c.Allow()
 	}
 
+	// Where clones and worktrees land, and which hosts code comes from
+	if result := c.checkDestination(gitCmd); !result.IsAllowed() {
+		return result
+	}
+	// Archives and bundles of the repository leaving the project
+	if result := c.checkExport(gitCmd); !result.IsAllowed() {
+		return result
+	}
+	configured := c.configuredForce(gitCmd, inv)
+	subcommand := inv.Subcommand
+	flags := gitFlagSet(subcommand, inv.Flags)
+
 	// Build operation string for matching
-	operation := c.buildOperationString(subcommand, flags)
+	operation := c.buildOperationString(subcommand, inv.Flags)
+	shown := operation
+	if alias != "" {
+		shown += fmt.Sprintf(" (git alias %s)", alias)
+	}
+	if configured != "" {
+		shown += fmt.Sprintf(" (%s)", configured)
+	}
 
 	// Check if explicitly allowed
 	if c.isAllowed(operation) {
 		return c.Allow()
 	}
 
+	// What the operation would do to the repository (git.repo_aware)
+	verdict := c.repoVerdict(gitCmd, subcommand, inv.Args, flags)
+
 	// Check if hard blocked - DENY (no confirmation possible)
-	if c.isHardBlocked(operation) {
+	if pattern := c.hardBlockedPattern(operation); pattern != "" && !verdict.safe {
 		return c.Deny(
-			fmt.Sprintf("Destructive git operation blocked: %s", operation),
-			c.getSaferAlternative(operation),
-		)
+			fmt.Sprintf("Destructive git operation blocked: %s", shown),
+			joinGuidance(verdict.note, c.getSaferAlternative(operation)),
+		).WithRule(RuleGitHardBlocked).WithPattern(pattern)
 	}
 
 	// Check if CI auto-allow
@@ -68,39 +128,40 @@ func (c *GitCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedComma
 		return c.Allow()
 	}
 
+	// Pushes that delete remote branches
+	if branches, pattern := c.remoteDeletion(gitCmd, subcommand, inv.Args, flags); branches != "" {
+		return c.Confirm(
+			fmt.Sprintf("git push would delete remote branches: %s", branches),
+			"Give user the command: `"+gitCmd.Raw+"`",
+		).WithRule(RuleGitRemoteBranchDelete).WithPattern(pattern)
+	}
+
 	// Check if confirmation required
-	if c.needsConfirmation(operation) {
+	if pattern := c.confirmPattern(operation); pattern != "" && !verdict.safe {
 		return c.Confirm(
-			fmt.Sprintf("Git operation requires confirmation: %s", operation),
-			c.getSaferAlternative(operation),
-		)
+			fmt.Sprintf("Git operation requires confirmation: %s", shown),
+			joinGuidance(verdict.note, c.getSaferAlternative(operation)),
+		).WithRule(RuleGitConfirmRequired).WithPattern(pattern)
 	}
 
 	return c.Allow()
 }
 
-// buildOperationString builds operation string from subcommand and flags.
-func (c *GitCheck) buildOperationString(subcommand string, flags []string) string {
-	// Normalize flags
-	var normalizedFlags []string
-	for _, flag := range flags {
-		if strings.HasPrefix(flag, "-") && !strings.HasPrefix(flag, "--") {
-			if len(flag) > 2 {
-				// Expand combined flags
-				for _, char := range flag[1:] {
-					normalizedFlags = append(normalizedFlags, fmt.Sprintf("-%c", char))
-				}
-			} else {
-				normalizedFlags = append(normalizedFlags, flag)
-			}
-		} else {
-			normalizedFlags = append(normalizedFlags, flag)
-		}
+// joinGuidance puts a repository note before the suggestion.
+func joinGuidance(note, suggestion string) string {
+	if note == "" {
+		return suggestion
 	}
+	return note + " " + suggestion
+}
 
-	sort.Strings(normalizedFlags)
-	if len(normalizedFlags) > 0 {
-		return subcommand + " " + strings.Join(normalizedFlags, " ")
+// buildOperationString builds operation string from subcommand and
+// canonical flags.
+func (c *GitCheck) buildOperationString(subcommand string, flags []string) string {
+	sorted := append([]string(nil), flags...)
+	sort.Strings(sorted)
+	if len(sorted) > 0 {
+		return subcommand + " " + strings.Join(sorted, " ")
 	}
 	return subcommand
 }
@@ -115,18 +176,18 @@ func (c *GitCheck) isAllowed(operation string) bool {
 	return false
 }
 
-// isHardBlocked checks if operation is hard blocked.
-func (c *GitCheck) isHardBlocked(operation string) bool {
+// hardBlockedPattern returns the hard_blocked entry matching operation, or "".
+func (c *GitCheck) hardBlockedPattern(operation string) string {
 	for _, pattern := range c.config.Git.HardBlocked {
 		if c.matchesPattern(operation, pattern) {
 			// But check if --force-with-lease is present (allowed)
 			if strings.Contains(operation, "--force-with-lease") {
-				return false
+				return ""
 			}
-			return true
+			return pattern
 		}
 	}
-	return false
+	return ""
 }
 
 // isCIAutoAllowed checks if operation is auto-allowed in CI.
@@ -139,14 +200,14 @@ func (c *GitCheck) isCIAutoAllowed(operation string) bool {
 	return false
 }
 
-// needsConfirmation checks if operation needs confirmation.
-func (c *GitCheck) needsConfirmation(operation string) bool {
+// confirmPattern returns the confirm_required entry matching operation, or "".
+func (c *GitCheck) confirmPattern(operation string) string {
 	for _, pattern := range c.config.Git.ConfirmRequired {
 		if c.matchesPattern(operation, pattern) {
-			return true
+			return pattern
 		}
 	}
-	return false
+	return ""
 }
 
 // matchesPattern checks if operation matches a pattern.
@@ -163,9 +224,9 @@ func (c *GitCheck) matchesPattern(operation string, pattern string) bool {
 		return false
 	}
 
-	// Expand combined short flags
-	patternFlags := expandFlags(patternParts[1:])
-	operationFlags := expandFlags(operationParts[1:])
+	// Patterns may spell flags any way git accepts
+	patternFlags := gitFlagSet(patternParts[0], patternParts[1:])
+	operationFlags := gitFlagSet(operationParts[0], operationParts[1:])
 
 	// Check if pattern flags are subset of operation flags
 	for pf := range patternFlags {
@@ -177,6 +238,17 @@ func (c *GitCheck) matchesPattern(operation string, pattern string) bool {
 	return true
 }
 
+// gitFlagSet returns the canonical flags of a git subcommand as a set.
+func gitFlagSet(subcommand string, flags []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, flag := range flags {
+		for _, f := range parsers.CanonicalGitFlags(subcommand, flag) {
+			result[f] = true
+		}
+	}
+	return result
+}
+
 // expandFlags expands combined short flags and returns as a set.
 func expandFlags(flags []string) map[string]bool {
 	result := make(map[string]bool)
@@ -0,0 +1,109 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// checkDestination asks for a clone, worktree add or submodule add whose
+// destination is outside the project and directories.allowed_paths, and
+// for a remote whose host is not in git.allowed_remotes. These look like
+// routine git commands but write a checkout (hooks included) wherever
+// they are told, or fetch code from anywhere.
+func (c *GitCheck) checkDestination(cmd *ParsedCommand) *CheckResult {
+	subcommand, operands := parsers.GitOperands(cmd)
+	dest, remote := gitDestination(subcommand, operands)
+
+	if dest != "" {
+		resolved := parsers.ResolvePath(dest, c.repoDir(cmd))
+		if !parsers.IsPathWithinAllowed(resolved, c.projectRoot, c.allowedPaths) {
+			return c.Ask(
+				fmt.Sprintf("git %s destination outside project: %s", subcommand, resolved),
+				fmt.Sprintf("Clone and add worktrees inside the project only. Give user: `%s`", cmd.Raw),
+			).WithRule(RuleGitDestinationOutside).WithPattern(subcommand)
+		}
+	}
+
+	if remote == "" || len(c.config.Git.AllowedRemotes) == 0 {
+		return c.Allow()
+	}
+	host := gitRemoteHost(remote)
+	if host == "" || hostAllowed(host, "", c.config.Git.AllowedRemotes) {
+		return c.Allow()
+	}
+	return c.Ask(
+		fmt.Sprintf("git remote host not in git.allowed_remotes: %s", host),
+		fmt.Sprintf("Use a repository on an allowed host. Give user: `%s`", cmd.Raw),
+	).WithRule(RuleGitRemoteNotAllowed).WithPattern(host)
+}
+
+// gitDestination returns the directory a git command creates and the
+// remote URL it names, as written ("" if none).
+func gitDestination(subcommand string, operands []string) (dest, remote string) {
+	switch subcommand {
+	case "clone":
+		// git clone <repo> [<dir>]
+		if len(operands) == 0 {
+			return "", ""
+		}
+		if len(operands) > 1 {
+			return operands[1], operands[0]
+		}
+		return cloneDirName(operands[0]), operands[0]
+	case "worktree":
+		// git worktree add <path> [<commit-ish>]
+		if len(operands) > 1 && operands[0] == "add" {
+			return operands[1], ""
+		}
+	case "submodule":
+		// git submodule add <repo> [<path>]
+		if len(operands) > 2 && operands[0] == "add" {
+			return operands[2], operands[1]
+		}
+		if len(operands) == 2 && operands[0] == "add" {
+			return cloneDirName(operands[1]), operands[1]
+		}
+	case "remote":
+		// git remote add <name> <url>, git remote set-url <name> <url>
+		if len(operands) > 2 && (operands[0] == "add" || operands[0] == "set-url") {
+			return "", operands[2]
+		}
+	case "fetch", "pull", "push", "ls-remote":
+		// a URL in place of a remote name
+		if len(operands) > 0 {
+			return "", operands[0]
+		}
+	}
+	return "", ""
+}
+
+// cloneDirName returns the directory git clone makes for a repository:
+// the last path component without "/.git" or ".git".
+func cloneDirName(repo string) string {
+	name := strings.TrimRight(repo, "/")
+	name = strings.TrimSuffix(name, "/.git")
+	name = strings.TrimSuffix(name, ".git")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// gitRemoteHost returns the host of a git URL (scheme://[user@]host/path
+// or scp-like [user@]host:path), or "" for a remote name or a local path.
+func gitRemoteHost(remote string) string {
+	if strings.Contains(remote, "://") {
+		if strings.HasPrefix(strings.ToLower(remote), "file://") {
+			return ""
+		}
+		return strings.ToLower(strings.Trim(urlHost(remote), "[]"))
+	}
+	// scp-like syntax: a colon before any slash
+	i := strings.Index(remote, ":")
+	if i <= 0 || strings.Contains(remote[:i], "/") {
+		return ""
+	}
+	return strings.ToLower(remoteHost(remote[:i]))
+}
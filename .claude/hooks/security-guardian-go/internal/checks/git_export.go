@@ -0,0 +1,63 @@
+package checks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// checkExport asks for git archive and git bundle create output piped to a
+// network command or written outside the project. Both package the whole
+// repository, unpushed work included, into one file that is easy to carry
+// off, like a database dump (see DataExportCheck).
+func (c *GitCheck) checkExport(cmd *ParsedCommand) *CheckResult {
+	subcommand, operands := parsers.GitOperands(cmd)
+	var outputs []string
+	switch subcommand {
+	case "archive":
+		outputs = parsers.GitOptionValues(cmd, "-o", "--output")
+	case "bundle":
+		// git bundle create <file> <rev-list-args>; "-" is stdout
+		if len(operands) < 2 || operands[0] != "create" {
+			return c.Allow()
+		}
+		if operands[1] != "-" {
+			outputs = []string{operands[1]}
+		}
+	default:
+		return c.Allow()
+	}
+	// Output options are relative to -C, redirections to the shell's cwd
+	var paths []string
+	for _, out := range outputs {
+		paths = append(paths, parsers.ResolvePath(out, c.repoDir(cmd)))
+	}
+	for _, r := range cmd.Redirections {
+		if r.IsWrite() {
+			outputs = append(outputs, r.Target)
+			paths = append(paths, parsers.ResolvePath(parsers.JoinDir(cmd.Dir, r.Target), c.baseDir(c.projectRoot)))
+		}
+	}
+
+	for sink := cmd.PipesTo; sink != nil; sink = sink.PipesTo {
+		if isNetworkSink(sink, c.config.Pipelines.NetworkSinks) {
+			return c.Ask(
+				fmt.Sprintf("Repository export piped to a network command: git %s | %s", subcommand, sink.Command),
+				"The archive leaves the machine with the whole repository in it, unpushed work included. Show the user the command and let them run it if this export is intended.",
+			).WithRule(RuleGitExportNetwork).WithOrigin(cmd)
+		}
+	}
+	for i, path := range paths {
+		rel, err := filepath.Rel(c.projectRoot, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			continue
+		}
+		return c.Ask(
+			fmt.Sprintf("Repository export written outside the project: %s", outputs[i]),
+			"Archives and bundles belong in the project, where the user sees them. Write it inside the project, or show the user the command and let them run it.",
+		).WithRule(RuleGitExportOutside).WithPaths(path).WithOrigin(cmd)
+	}
+	return c.Allow()
+}
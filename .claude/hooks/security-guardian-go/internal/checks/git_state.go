@@ -0,0 +1,303 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/gitbackup"
+	"github.com/artwist-polyakov/security-guardian/internal/gitstate"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// repoVerdict is what the repository state says about a git operation.
+type repoVerdict struct {
+	safe bool   // the operation loses nothing: skip hard_blocked/confirm_required
+	note string // why it isn't safe, prepended to the guidance
+}
+
+// pushTarget is a branch a push updates.
+type pushTarget struct {
+	src    string // local revision, "" for a deletion
+	remote string
+	branch string // remote branch
+}
+
+// definedAliases returns the aliases a command line sets with
+// `git config [set] alias.<name> <value>`, which take effect for the git
+// commands after them before the hook could read them from the config.
+func definedAliases(cmds []*ParsedCommand) map[string]string {
+	defined := make(map[string]string)
+	for _, cmd := range cmds {
+		inv := parsers.NormalizeGitArgs(cmd)
+		if inv == nil || inv.Subcommand != "config" {
+			continue
+		}
+		for i, arg := range inv.Args {
+			if key := strings.ToLower(arg); strings.HasPrefix(key, "alias.") && i+1 < len(inv.Args) {
+				defined[strings.TrimPrefix(key, "alias.")] = inv.Args[i+1]
+				break
+			}
+		}
+	}
+	return defined
+}
+
+// aliases returns the git aliases in effect where cmd runs.
+func (c *GitCheck) aliases(cmd *ParsedCommand) map[string]string {
+	dir := c.repoDir(cmd)
+	if c.aliasCache == nil {
+		c.aliasCache = make(map[string]map[string]string)
+	}
+	aliases, ok := c.aliasCache[dir]
+	if !ok {
+		aliases = gitstate.Aliases(dir)
+		c.aliasCache[dir] = aliases
+	}
+	return aliases
+}
+
+// configuredForce adds --force to a push without refspecs that the
+// repository config makes forced: remote.<name>.mirror, or a +refspec in
+// remote.<name>.push. It returns the config key, or "".
+func (c *GitCheck) configuredForce(cmd *ParsedCommand, inv *parsers.GitInvocation) string {
+	if inv.Subcommand != "push" || len(inv.Args) > 1 || inv.HasFlag("--force") || inv.HasFlag("--mirror") {
+		return ""
+	}
+	repo := c.openRepo(cmd)
+	if repo == nil {
+		return ""
+	}
+	remote := repo.PushRemote(repo.CurrentBranch())
+	if len(inv.Args) == 1 {
+		remote = inv.Args[0]
+	}
+	refspecs, mirror := repo.RemotePush(remote)
+	key := ""
+	if mirror {
+		key = "remote." + remote + ".mirror"
+		inv.Flags = append(inv.Flags, "--mirror")
+	}
+	for _, spec := range refspecs {
+		if strings.HasPrefix(spec, "+") && key == "" {
+			key = "remote." + remote + ".push " + spec
+		}
+	}
+	if key != "" {
+		inv.Flags = append(inv.Flags, "--force")
+	}
+	return key
+}
+
+// repoDir returns the directory cmd operates in.
+func (c *GitCheck) repoDir(cmd *ParsedCommand) string {
+	return parsers.ResolvePath(gitbackup.RepoDir(cmd), c.baseDir(c.projectRoot))
+}
+
+// openRepo opens the repository cmd operates on, or returns nil.
+func (c *GitCheck) openRepo(cmd *ParsedCommand) *gitstate.Repo {
+	repo, err := gitstate.Open(c.repoDir(cmd))
+	if err != nil {
+		return nil
+	}
+	return repo
+}
+
+// pushTargets resolves the remote branches `git push <args>` updates.
+// ok is false when they can't be told (--all, --tags, no upstream).
+func pushTargets(repo *gitstate.Repo, args []string, flags map[string]bool) (targets []pushTarget, ok bool) {
+	if flags["--all"] || flags["--branches"] || flags["--tags"] || flags["--mirror"] {
+		return nil, false
+	}
+
+	current := repo.CurrentBranch()
+	remote, upstream := repo.Upstream(current)
+	if len(args) > 0 {
+		remote = args[0]
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+	if !repo.HasRemote(remote) {
+		return nil, false
+	}
+
+	refspecs := args
+	if len(args) > 0 {
+		refspecs = args[1:]
+	}
+	if len(refspecs) == 0 {
+		if current == "" {
+			return nil, false
+		}
+		if upstream == "" {
+			upstream = current
+		}
+		return []pushTarget{{src: current, remote: remote, branch: upstream}}, true
+	}
+
+	for _, spec := range refspecs {
+		t := pushTarget{remote: remote}
+		spec = strings.TrimPrefix(spec, "+")
+		src, dst, found := strings.Cut(spec, ":")
+		if !found {
+			dst = src
+		}
+		if src == "HEAD" {
+			src = current
+		}
+		if flags["-d"] {
+			src, dst = "", spec
+		}
+		if dst == "HEAD" || dst == "" {
+			dst = current
+		}
+		t.src = src
+		t.branch = strings.TrimPrefix(dst, "refs/heads/")
+		if t.branch == "" || strings.HasPrefix(t.branch, "refs/") {
+			return nil, false
+		}
+		targets = append(targets, t)
+	}
+	return targets, true
+}
+
+// repoVerdict checks a force push or branch -D against the repository.
+// Operations it doesn't know get the zero verdict (decided by flags).
+func (c *GitCheck) repoVerdict(cmd *ParsedCommand, subcommand string, args []string, flags map[string]bool) repoVerdict {
+	if cmd == nil || !c.config.Git.RepoAware {
+		return repoVerdict{}
+	}
+
+	switch {
+	case subcommand == "push" && (flags["--force"] || flags["-f"]):
+		repo := c.openRepo(cmd)
+		if repo == nil {
+			return repoVerdict{}
+		}
+		targets, ok := pushTargets(repo, args, flags)
+		if !ok {
+			return repoVerdict{}
+		}
+		return c.forcePushVerdict(repo, targets)
+
+	case subcommand == "branch" && flags["-d"] && flags["-f"] && !flags["-r"]:
+		repo := c.openRepo(cmd)
+		if repo == nil || len(args) == 0 {
+			return repoVerdict{}
+		}
+		for _, branch := range args {
+			unmerged, err := repo.Unmerged(branch)
+			if err != nil {
+				return repoVerdict{}
+			}
+			if len(unmerged) > 0 {
+				return repoVerdict{note: fmt.Sprintf("%s has %d commits not merged into HEAD.", branch, len(unmerged))}
+			}
+		}
+		return repoVerdict{safe: true}
+	}
+	return repoVerdict{}
+}
+
+// forcePushVerdict is safe when no target is protected and every commit
+// the push would discard is the user's own.
+func (c *GitCheck) forcePushVerdict(repo *gitstate.Repo, targets []pushTarget) repoVerdict {
+	email := strings.ToLower(repo.UserEmail())
+	for _, t := range targets {
+		if t.src == "" {
+			continue
+		}
+		for _, pattern := range c.config.Git.ProtectedBranches {
+			if globs.Match(pattern, t.branch) {
+				return repoVerdict{note: fmt.Sprintf("%s is a protected branch (git.protected_branches).", t.branch)}
+			}
+		}
+
+		overwritten, err := repo.Overwritten(t.src, t.remote, t.branch)
+		if err != nil {
+			return repoVerdict{}
+		}
+		var others []string
+		for _, commit := range overwritten {
+			if email == "" || strings.ToLower(commit.Email) != email {
+				others = append(others, commit.Hash)
+			}
+		}
+		if len(others) > 0 {
+			return repoVerdict{note: fmt.Sprintf("Force push would discard %d commits by others on %s/%s: %s.",
+				len(others), t.remote, t.branch, strings.Join(limitList(others, 5), ", "))}
+		}
+	}
+	return repoVerdict{safe: true}
+}
+
+// remoteDeletion returns the remote branches a push deletes and the flag
+// or refspec that deletes them, or "" if it deletes none.
+func (c *GitCheck) remoteDeletion(cmd *ParsedCommand, subcommand string, args []string, flags map[string]bool) (branches, pattern string) {
+	if cmd == nil || subcommand != "push" || !c.config.Git.RepoAware {
+		return "", ""
+	}
+	remote := "origin"
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	if flags["-d"] {
+		if len(args) < 2 {
+			return "", ""
+		}
+		return prefixBranches(remote, args[1:]), "push --delete"
+	}
+
+	refspecs := args
+	if len(refspecs) > 0 {
+		refspecs = refspecs[1:]
+	}
+	var deleted []string
+	for _, spec := range refspecs {
+		spec = strings.TrimPrefix(spec, "+")
+		if strings.HasPrefix(spec, ":") && len(spec) > 1 {
+			deleted = append(deleted, strings.TrimPrefix(spec[1:], "refs/heads/"))
+			pattern = spec
+		}
+	}
+	if len(deleted) > 0 {
+		return prefixBranches(remote, deleted), pattern
+	}
+
+	for _, flag := range []string{"--prune", "--mirror"} {
+		if !flags[flag] {
+			continue
+		}
+		repo := c.openRepo(cmd)
+		if repo == nil {
+			return "branches with no local counterpart", "push " + flag
+		}
+		stale, err := repo.RemoteOnlyBranches(remote)
+		if err != nil {
+			return "branches with no local counterpart", "push " + flag
+		}
+		if len(stale) > 0 {
+			return prefixBranches(remote, stale), "push " + flag
+		}
+	}
+	return "", ""
+}
+
+// prefixBranches lists branches as remote/branch.
+func prefixBranches(remote string, branches []string) string {
+	named := make([]string, len(branches))
+	for i, b := range branches {
+		named[i] = remote + "/" + b
+	}
+	return strings.Join(limitList(named, 5), ", ")
+}
+
+// limitList cuts a list to n items, noting how many were left out.
+func limitList(items []string, n int) []string {
+	if len(items) <= n {
+		return items
+	}
+	return append(items[:n:n], fmt.Sprintf("and %d more", len(items)-n))
+}
@@ -0,0 +1,261 @@
+package checks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// gitRun runs git in dir with a fixed author.
+func gitRun(t *testing.T, dir, email string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL="+email,
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL="+email,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// newGitStateRepo builds a clone of a bare remote where:
+//   - feature was pushed, then got a commit by someone else on the remote
+//   - mine was pushed with only our commits, then amended locally
+//   - merged is at HEAD, unmerged has a commit of its own
+//
+// It returns the clone, which is also the project root.
+func newGitStateRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	base := t.TempDir()
+	remote := filepath.Join(base, "remote.git")
+	repo := filepath.Join(base, "repo")
+	other := filepath.Join(base, "other")
+	const me, them = "me@example.com", "them@example.com"
+
+	gitRun(t, base, me, "init", "-q", "--bare", "-b", "main", remote)
+	gitRun(t, base, me, "init", "-q", "-b", "main", repo)
+	gitRun(t, repo, me, "config", "user.email", me)
+	gitRun(t, repo, me, "commit", "-q", "--allow-empty", "-m", "init")
+	gitRun(t, repo, me, "remote", "add", "origin", remote)
+	gitRun(t, repo, me, "push", "-q", "-u", "origin", "main")
+
+	for _, branch := range []string{"feature", "mine"} {
+		gitRun(t, repo, me, "checkout", "-q", "-b", branch, "main")
+		gitRun(t, repo, me, "commit", "-q", "--allow-empty", "-m", branch)
+		gitRun(t, repo, me, "push", "-q", "-u", "origin", branch)
+	}
+
+	gitRun(t, base, them, "clone", "-q", "-b", "feature", remote, other)
+	gitRun(t, other, them, "commit", "-q", "--allow-empty", "-m", "theirs")
+	gitRun(t, other, them, "push", "-q", "origin", "feature")
+	gitRun(t, repo, me, "fetch", "-q", "origin")
+
+	// Rewrite both branches locally so pushing them needs --force
+	for _, branch := range []string{"feature", "mine"} {
+		gitRun(t, repo, me, "checkout", "-q", branch)
+		gitRun(t, repo, me, "commit", "-q", "--amend", "--allow-empty", "-m", branch+" v2")
+	}
+
+	gitRun(t, repo, me, "checkout", "-q", "main")
+	gitRun(t, repo, me, "branch", "merged")
+	gitRun(t, repo, me, "checkout", "-q", "-b", "unmerged")
+	gitRun(t, repo, me, "commit", "-q", "--allow-empty", "-m", "wip")
+	gitRun(t, repo, me, "checkout", "-q", "main")
+	return repo
+}
+
+func TestGitCheckRepoState(t *testing.T) {
+	repo := newGitStateRepo(t)
+	t.Setenv("CLAUDE_PROJECT_DIR", repo)
+
+	tests := []struct {
+		command string
+		want    PermissionDecision
+		rule    string
+	}{
+		// Force pushes discarding only our own commits
+		{"git push --force origin mine", DecisionAllow, ""},
+		{"git push -f origin mine", DecisionAllow, ""},
+		{"git push origin +mine", DecisionAllow, ""},
+		// ... and someone else's
+		{"git push --force origin feature", DecisionDeny, RuleGitHardBlocked},
+		{"git push -f origin feature", DecisionAsk, RuleGitConfirmRequired},
+		{"git push origin +feature", DecisionDeny, RuleGitHardBlocked},
+		{"git push origin +mine +feature", DecisionDeny, RuleGitHardBlocked},
+		// Protected branches keep the flag rules
+		{"git push --force origin main", DecisionDeny, RuleGitHardBlocked},
+		{"git push --force origin mine:main", DecisionDeny, RuleGitHardBlocked},
+		// Pushes that can't be resolved keep the flag rules
+		{"git push --force --all", DecisionDeny, RuleGitHardBlocked},
+		{"git push --force nowhere mine", DecisionDeny, RuleGitHardBlocked},
+
+		// branch -D by merge state
+		{"git branch -D merged", DecisionAllow, ""},
+		{"git branch -D unmerged", DecisionAsk, RuleGitConfirmRequired},
+		{"git branch -D merged unmerged", DecisionAsk, RuleGitConfirmRequired},
+		{"git branch -D missing", DecisionAsk, RuleGitConfirmRequired},
+
+		// Remote branch deletions
+		{"git push origin :feature", DecisionAsk, RuleGitRemoteBranchDelete},
+		{"git push origin --delete feature", DecisionAsk, RuleGitRemoteBranchDelete},
+		{"git push -d origin feature mine", DecisionAsk, RuleGitRemoteBranchDelete},
+		{"git push origin main", DecisionAllow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			check := NewGitCheck(NewEngine(config.DefaultConfig()))
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if result.RuleID != tt.rule {
+				t.Errorf("rule = %q, want %q", result.RuleID, tt.rule)
+			}
+		})
+	}
+}
+
+func TestGitCheckRepoStateNotes(t *testing.T) {
+	repo := newGitStateRepo(t)
+	t.Setenv("CLAUDE_PROJECT_DIR", repo)
+	check := NewGitCheck(NewEngine(config.DefaultConfig()))
+
+	tests := []struct {
+		command string
+		note    string
+	}{
+		{"git push --force origin feature", "Force push would discard 1 commits by others on origin/feature"},
+		{"git push --force origin main", "main is a protected branch"},
+		{"git branch -D unmerged", "unmerged has 1 commits not merged into HEAD."},
+	}
+	for _, tt := range tests {
+		result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+		if !strings.Contains(result.Guidance, tt.note) {
+			t.Errorf("%s: guidance = %q, want it to contain %q", tt.command, result.Guidance, tt.note)
+		}
+	}
+}
+
+func TestGitCheckRepoAwareOff(t *testing.T) {
+	repo := newGitStateRepo(t)
+	t.Setenv("CLAUDE_PROJECT_DIR", repo)
+	cfg := config.DefaultConfig()
+	cfg.Git.RepoAware = false
+	check := NewGitCheck(NewEngine(cfg))
+
+	for command, want := range map[string]PermissionDecision{
+		"git push --force origin mine": DecisionDeny,
+		"git branch -D merged":         DecisionAsk,
+		"git push origin :feature":     DecisionAllow,
+	} {
+		if got := check.CheckCommand(command, parseForCheck(command)).PermissionDecisionValue(); got != want {
+			t.Errorf("%s: decision = %s, want %s", command, got, want)
+		}
+	}
+}
+
+// TestGitCheckSpellings checks that every spelling of a git operation gets
+// the decision of the policy pattern it equals.
+func TestGitCheckSpellings(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", t.TempDir())
+	// ci_auto_allow would allow clean -fd and reset --hard
+	for _, v := range []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL", "CIRCLECI", "TRAVIS"} {
+		t.Setenv(v, "")
+	}
+	cfg := config.DefaultConfig()
+	cfg.Git.RepoAware = false
+	check := NewGitCheck(NewEngine(cfg))
+
+	tests := []struct {
+		command string
+		want    PermissionDecision
+	}{
+		{"git push --force origin main", DecisionDeny},
+		{"git push origin main --force", DecisionDeny},
+		{"git push origin +main", DecisionDeny},
+		{"git push -f origin main", DecisionAsk},
+		{"git push --force-with-lease origin main", DecisionAllow},
+		{"git push --force-with-lease=main origin main", DecisionAllow},
+		{"git branch -D old", DecisionAsk},
+		{"git branch --delete --force old", DecisionAsk},
+		{"git branch -d -f old", DecisionAsk},
+		{"git branch -d old", DecisionAllow},
+		{"git clean -fd", DecisionAsk},
+		{"git clean -df", DecisionAsk},
+		{"git clean --force -d", DecisionAsk},
+		{"git clean -fd --dry-run", DecisionAllow},
+		{"git clean -fdn", DecisionAllow},
+		{"git clean -f -d -n", DecisionAllow},
+		{"git reset --hard", DecisionAsk},
+		{"git checkout -- --force", DecisionAllow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Errorf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+		})
+	}
+}
+
+// TestGitCheckAliases checks git aliases from the repository config, the
+// command line and -c, and pushes the remote config forces.
+func TestGitCheckAliases(t *testing.T) {
+	repo := newGitStateRepo(t)
+	t.Setenv("CLAUDE_PROJECT_DIR", repo)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+	gitRun(t, repo, "me@example.com", "config", "alias.fp", "push --force")
+	gitRun(t, repo, "me@example.com", "config", "alias.fpo", "fp origin")
+	gitRun(t, repo, "me@example.com", "config", "alias.wipe", "reset --hard")
+	gitRun(t, repo, "me@example.com", "config", "alias.nuke", "!rm -rf .git")
+	gitRun(t, repo, "me@example.com", "config", "alias.st", "status")
+	gitRun(t, repo, "me@example.com", "remote", "add", "backup", "../remote.git")
+	gitRun(t, repo, "me@example.com", "config", "remote.backup.mirror", "true")
+	gitRun(t, repo, "me@example.com", "remote", "add", "forced", "../remote.git")
+	gitRun(t, repo, "me@example.com", "config", "remote.forced.push", "+refs/heads/*:refs/heads/*")
+
+	cfg := config.DefaultConfig()
+	cfg.Git.RepoAware = false
+	check := NewGitCheck(NewEngine(cfg))
+
+	tests := []struct {
+		command string
+		want    PermissionDecision
+		rule    string
+	}{
+		{"git fp origin main", DecisionDeny, RuleGitHardBlocked},
+		{"git fpo main", DecisionDeny, RuleGitHardBlocked},
+		{"git wipe", DecisionAsk, RuleGitConfirmRequired},
+		{"git nuke", DecisionAsk, RuleGitShellAlias},
+		{"git st", DecisionAllow, ""},
+		{"git status", DecisionAllow, ""},
+		{"git config alias.x 'push --force' && git x", DecisionDeny, RuleGitHardBlocked},
+		{"git -c alias.y='branch -D' y old", DecisionAsk, RuleGitConfirmRequired},
+		{"git push backup", DecisionDeny, RuleGitHardBlocked},
+		{"git push forced", DecisionDeny, RuleGitHardBlocked},
+		{"git push forced main", DecisionAllow, ""},
+		{"git push origin main", DecisionAllow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if result.RuleID != tt.rule {
+				t.Errorf("rule = %q, want %q", result.RuleID, tt.rule)
+			}
+		})
+	}
+}
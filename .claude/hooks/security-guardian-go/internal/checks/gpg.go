@@ -0,0 +1,129 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// GPGCheck protects the GnuPG keys developers sign commits and releases
+// with. Signing, verifying, listing and importing are routine and pass;
+// exporting secret keys, piping decrypted data to another command,
+// reading private key files and changing the GnuPG home are denied.
+type GPGCheck struct {
+	BaseCheck
+	config *config.SecurityConfig
+}
+
+// gpgCommands are the GnuPG tools that can export or decrypt.
+var gpgCommands = map[string]bool{"gpg": true, "gpg2": true, "gpgsm": true}
+
+// gpgPrivateFiles are the GnuPG home entries holding secret key material.
+var gpgPrivateFiles = []string{"private-keys-v1.d/**", "secring.gpg", "openpgp-revocs.d/**"}
+
+// NewGPGCheck creates a new GPGCheck instance.
+func NewGPGCheck(e *Engine) *GPGCheck {
+	return &GPGCheck{
+		BaseCheck: BaseCheck{CheckName: "gpg_check"},
+		config:    e.Config,
+	}
+}
+
+// CheckCommand checks gpg invocations for secret key export and for
+// decrypted output piped to another command.
+func (c *GPGCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if !c.config.GPG.Enabled {
+		return c.Allow()
+	}
+
+	for _, cmd := range parsedCommands {
+		if !gpgCommands[filepath.Base(cmd.Command)] {
+			continue
+		}
+		for _, f := range cmd.Flags {
+			// GnuPG takes any unambiguous prefix of a long option
+			if strings.HasPrefix(f, "--export-secret") {
+				return c.Deny(
+					fmt.Sprintf("Exporting GPG secret keys: %s", f),
+					"Secret signing keys must not leave the keyring. Sign with `gpg --sign` or `git commit -S` instead; the user exports keys themselves.",
+				).WithRule(RuleGPGExportSecret).WithOrigin(cmd)
+			}
+		}
+		if isGPGDecrypt(cmd) && cmd.PipesTo != nil && !hasGPGOutput(cmd) {
+			return c.Deny(
+				fmt.Sprintf("Decrypted GPG data piped to %s", cmd.PipesTo.Command),
+				"Do not pass decrypted data to other commands. Ask the user for the value you need.",
+			).WithRule(RuleGPGDecryptPiped).WithOrigin(cmd)
+		}
+	}
+
+	return c.Allow()
+}
+
+// checkResolved checks an access to a resolved path in the GnuPG home:
+// any change is denied, and so is reading private key files. It is run
+// by SecretsCheck.CheckPath for every tool.
+func (c *GPGCheck) checkResolved(path, resolved, operation string) *CheckResult {
+	if !c.config.GPG.Enabled {
+		return c.Allow()
+	}
+	home := c.home()
+	if home == "" {
+		return c.Allow()
+	}
+	rel, err := filepath.Rel(home, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return c.Allow()
+	}
+
+	if operation == "write" || operation == "edit" {
+		return c.Deny(
+			fmt.Sprintf("Cannot modify the GnuPG home: %s", path),
+			"Keyring and gpg.conf changes are the user's. Give the user the command to run instead.",
+		).WithRule(RuleGPGHomeModified).WithPaths(path)
+	}
+	if pattern := globs.MatchAny(gpgPrivateFiles, rel); pattern != "" {
+		return c.Deny(
+			fmt.Sprintf("Cannot read GPG private key files: %s", path),
+			"Secret key material stays in the keyring. Use gpg to sign or decrypt instead of reading key files.",
+		).WithRule(RuleGPGExportSecret).WithPaths(path).WithPattern(pattern)
+	}
+	return c.Allow()
+}
+
+// home returns the resolved GnuPG home: gpg.home, $GNUPGHOME or ~/.gnupg.
+func (c *GPGCheck) home() string {
+	home := c.config.GPG.Home
+	if home == "" {
+		home = os.Getenv("GNUPGHOME")
+	}
+	if home == "" {
+		home = "~/.gnupg"
+	}
+	return parsers.ResolvePath(home, "/")
+}
+
+// isGPGDecrypt reports whether a gpg invocation decrypts (-d, --decrypt).
+func isGPGDecrypt(cmd *ParsedCommand) bool {
+	for _, f := range cmd.Flags {
+		if f == "-d" || f == "--decrypt" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGPGOutput reports whether gpg writes its result to a file (-o, --output).
+func hasGPGOutput(cmd *ParsedCommand) bool {
+	for _, f := range cmd.Flags {
+		if f == "-o" || f == "--output" || strings.HasPrefix(f, "--output=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package checks
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/state"
+)
+
+// MassModificationCheck limits how many files a session may delete or
+// overwrite. Each operation alone looks innocent; the counters catch an
+// agent trashing a repo in many small steps.
+type MassModificationCheck struct {
+	BaseCheck
+	projectRoot string
+	sessionFile string
+	config      *config.SecurityConfig
+	session     *state.Session
+
+	// Counted by this invocation, saved by Commit once the operation is allowed
+	pendingDeleted     int
+	pendingOverwritten int
+}
+
+// NewMassModificationCheck creates a new MassModificationCheck instance.
+func NewMassModificationCheck(e *Engine) *MassModificationCheck {
+	return &MassModificationCheck{
+		BaseCheck:   BaseCheck{CheckName: "mass_modification_check"},
+		projectRoot: e.ProjectRoot,
+		sessionFile: e.SessionFile(),
+		config:      e.Config,
+	}
+}
+
+// CheckCommand counts files removed by deletion commands.
+func (c *MassModificationCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	mm := c.config.MassModification
+	if !mm.Enabled || mm.MaxFilesDeleted <= 0 {
+		return c.Allow()
+	}
+
+	// Piped commands are already in the slice, no need to follow PipesTo
+	deleted := 0
+	for _, cmd := range parsedCommands {
+		if deleteCommands[cmd.Command] {
+			deleted += c.countDeleted(cmd, mm.MaxFilesDeleted+1)
+		}
+	}
+	if deleted == 0 {
+		return c.Allow()
+	}
+
+	c.pendingDeleted += deleted
+	total := c.loadSession().FilesDeleted + c.pendingDeleted
+	if total > mm.MaxFilesDeleted {
+		return c.Ask(
+			fmt.Sprintf("Session deletion limit exceeded: %d files deleted this session (limit %d)", total, mm.MaxFilesDeleted),
+			fmt.Sprintf("Many files deleted in this session. Review what was removed, then give user the command: `%s`", rawCommand),
+		).WithRule(RuleMassDeletion)
+	}
+
+	return c.Allow()
+}
+
+// CheckWrite checks a Write tool call: its size and whether it replaces an existing file.
+func (c *MassModificationCheck) CheckWrite(path string, size int) *CheckResult {
+	mm := c.config.MassModification
+	if !mm.Enabled {
+		return c.Allow()
+	}
+
+	if mm.MaxWriteBytes > 0 && size > mm.MaxWriteBytes {
+		return c.Ask(
+			fmt.Sprintf("Write of %d bytes to %s exceeds limit of %d bytes", size, path, mm.MaxWriteBytes),
+			"Unusually large write. Confirm the content is expected.",
+		).WithRule(RuleMassWriteSize)
+	}
+
+	if mm.MaxFilesOverwritten <= 0 {
+		return c.Allow()
+	}
+
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	if info, err := os.Stat(resolved); err != nil || info.IsDir() {
+		// New file - not an overwrite
+		return c.Allow()
+	}
+
+	c.pendingOverwritten++
+	total := c.loadSession().FilesOverwritten + c.pendingOverwritten
+	if total > mm.MaxFilesOverwritten {
+		return c.Ask(
+			fmt.Sprintf("Session overwrite limit exceeded: %d existing files overwritten this session (limit %d)", total, mm.MaxFilesOverwritten),
+			"Many existing files replaced in this session. Confirm this is intended.",
+		).WithRule(RuleMassOverwrite)
+	}
+
+	return c.Allow()
+}
+
+// Commit adds this invocation's counts to the session.
+// Handlers call it only when the operation is allowed.
+func (c *MassModificationCheck) Commit() {
+	if c.pendingDeleted == 0 && c.pendingOverwritten == 0 {
+		return
+	}
+
+	s := c.loadSession()
+	s.FilesDeleted += c.pendingDeleted
+	s.FilesOverwritten += c.pendingOverwritten
+	s.Save()
+
+	c.pendingDeleted = 0
+	c.pendingOverwritten = 0
+}
+
+// loadSession loads session counters once per invocation.
+func (c *MassModificationCheck) loadSession() *state.Session {
+	if c.session == nil {
+		mm := c.config.MassModification
+		c.session = state.LoadSession(c.sessionFile, time.Duration(mm.SessionIdleMinutes)*time.Minute)
+	}
+	return c.session
+}
+
+// countDeleted counts existing files a deletion command removes,
+// stopping early once limit is reached.
+func (c *MassModificationCheck) countDeleted(cmd *ParsedCommand, limit int) int {
+	recursive := hasRecursiveFlag(cmd.Flags)
+	count := 0
+
+	for _, arg := range cmd.Args {
+		target := parsers.ResolvePath(parsers.JoinDir(cmd.Dir, arg), c.baseDir(c.projectRoot))
+
+		matches := []string{target}
+		if containsGlob(arg) {
+			matches, _ = filepath.Glob(target)
+		}
+
+		for _, m := range matches {
+			count += countFiles(m, recursive, limit-count)
+			if count >= limit {
+				return count
+			}
+		}
+	}
+
+	return count
+}
+
+// countFiles counts regular files at path (walking directories when
+// recursive), stopping once limit is reached.
+func countFiles(path string, recursive bool, limit int) int {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+	if !info.IsDir() || !recursive {
+		return 1
+	}
+
+	count := 0
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			count++
+			if count >= limit {
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return count
+}
@@ -0,0 +1,83 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// TestNormalizedPathEvasions checks that file: URLs and percent-encoding
+// inside them reach the same decision as the plain path they spell.
+func TestNormalizedPathEvasions(t *testing.T) {
+	tests := []struct {
+		check   string
+		command string
+		want    PermissionDecision
+		rule    string
+	}{
+		{"directory", `curl file:///etc/passwd`, DecisionDeny, RuleDirectoryOutside},
+		{"directory", `curl file:///etc/%70asswd`, DecisionDeny, RuleDirectoryOutside},
+		{"directory", `curl -s FILE://localhost/etc/passwd`, DecisionDeny, RuleDirectoryOutside},
+		{"directory", `curl file:/etc/passwd`, DecisionDeny, RuleDirectoryOutside},
+		{"directory", `curl file:docs/readme.md`, DecisionAllow, ""},
+		// A homoglyph directory is a different path, still outside the project
+		{"directory", "cat /\u0435tc/passwd", DecisionDeny, RuleDirectoryOutside},
+
+		{"secrets", `curl file:///proc/self/environ`, DecisionDeny, RuleSecretsSystemFile},
+		{"secrets", `curl file:///proc/self/%65nviron`, DecisionDeny, RuleSecretsSystemFile},
+		{"secrets", `curl file:.env`, DecisionDeny, RuleSecretsRead},
+		{"secrets", `curl file:%2Eenv`, DecisionDeny, RuleSecretsRead},
+		{"secrets", `curl file:config/secrets%2Eyaml`, DecisionDeny, RuleSecretsRead},
+		{"secrets", `curl file:.env.example`, DecisionAllow, ""},
+		// The shell opens a plain %2Eenv as written, not as .env
+		{"secrets", `cat %2Eenv`, DecisionAllow, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			e := newTestEngine(t)
+			var check interface {
+				CheckCommand(string, []*ParsedCommand) *CheckResult
+			}
+			switch tt.check {
+			case "directory":
+				check = NewDirectoryCheck(e)
+			case "secrets":
+				check = NewSecretsCheck(e)
+			}
+			result := check.CheckCommand(tt.command, parsers.ParseBashCommand(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Fatalf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if result.RuleID != tt.rule {
+				t.Errorf("rule = %q, want %q", result.RuleID, tt.rule)
+			}
+		})
+	}
+}
+
+// TestNormalizedPathNFC checks that a decomposed spelling of a path
+// (e + U+0301, as macOS stores it) matches a precomposed pattern.
+func TestNormalizedPathNFC(t *testing.T) {
+	t.Setenv("CLAUDE_PROJECT_DIR", t.TempDir())
+	cfg := config.DefaultConfig()
+	cfg.ProtectedPaths.NoReadContent = append(cfg.ProtectedPaths.NoReadContent, "**/caf\u00e9/**")
+	check := NewSecretsCheck(NewEngine(cfg))
+
+	tests := []struct {
+		command string
+		want    PermissionDecision
+	}{
+		{"cat caf\u00e9/menu.txt", DecisionDeny},
+		{"cat cafe\u0301/menu.txt", DecisionDeny},
+		{"cat ./cafe\u0301/../cafe\u0301/menu.txt", DecisionDeny},
+		{"curl file:cafe%CC%81/menu.txt", DecisionDeny},
+		{"cat cafe/menu.txt", DecisionAllow},
+	}
+	for _, tt := range tests {
+		result := check.CheckCommand(tt.command, parsers.ParseBashCommand(tt.command))
+		if got := result.PermissionDecisionValue(); got != tt.want {
+			t.Errorf("%+q: decision = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
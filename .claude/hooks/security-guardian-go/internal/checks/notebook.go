@@ -0,0 +1,70 @@
+package checks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// notebook is the part of an .ipynb file the checks read.
+type notebook struct {
+	Metadata json.RawMessage `json:"metadata"`
+	Cells    []notebookCell  `json:"cells"`
+}
+
+// notebookCell is one cell; source is a string or a list of lines.
+type notebookCell struct {
+	CellType string          `json:"cell_type"`
+	Source   json.RawMessage `json:"source"`
+	Metadata json.RawMessage `json:"metadata"`
+	Outputs  json.RawMessage `json:"outputs"`
+}
+
+// source returns the cell source as text.
+func (c *notebookCell) source() string {
+	var text string
+	if json.Unmarshal(c.Source, &text) == nil {
+		return text
+	}
+	var lines []string
+	if json.Unmarshal(c.Source, &lines) == nil {
+		return strings.Join(lines, "")
+	}
+	return ""
+}
+
+// CheckNotebook checks an .ipynb written whole: the notebook, cell and
+// output metadata against dangerous_operations.notebook_metadata, then the
+// code cells together as one script, so a request in one cell and a .env
+// read in another still count as exfiltration. Content that isn't a
+// notebook (an Edit fragment) is checked as code.
+func (c *CodeContentCheck) CheckNotebook(content, filePath string) *CheckResult {
+	var nb notebook
+	if err := json.Unmarshal([]byte(content), &nb); err != nil {
+		return c.CheckContent(content, filePath+" (cell)")
+	}
+
+	var meta strings.Builder
+	var code []string
+	meta.Write(nb.Metadata)
+	for i := range nb.Cells {
+		cell := &nb.Cells[i]
+		meta.WriteByte('\n')
+		meta.Write(cell.Metadata)
+		meta.WriteByte('\n')
+		meta.Write(cell.Outputs)
+		if cell.CellType == "code" {
+			code = append(code, cell.source())
+		}
+	}
+	for _, item := range c.notebookMeta {
+		if match := item.pattern.FindString(meta.String()); match != "" {
+			return c.Ask(
+				fmt.Sprintf("Notebook metadata with %s: %s", item.description, filePath),
+				fmt.Sprintf("%s: %s (%s). Metadata isn't visible when reading the cells. Show the user the notebook metadata and let them decide.", filePath, item.description, match),
+			).WithRule(RuleCodeNotebookMeta).WithPaths(filePath).WithPattern(item.pattern.String())
+		}
+	}
+
+	return c.CheckContent(strings.Join(code, "\n"), filePath+" (code cells)")
+}
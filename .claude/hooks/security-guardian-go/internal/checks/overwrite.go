@@ -0,0 +1,138 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// OverwriteCheck treats the destination of copy/move commands as a write
+// target, so `mv x .env` or `cp foo .claude/settings.json` go through the
+// same no_modify/no_read and boundary rules as a direct write.
+type OverwriteCheck struct {
+	BaseCheck
+	projectRoot    string
+	config         *config.SecurityConfig
+	directoryCheck *DirectoryCheck
+	secretsCheck   *SecretsCheck
+}
+
+// Commands whose final operand (or -t value) is a destination
+var copyMoveCommands = map[string]bool{
+	"mv":      true,
+	"cp":      true,
+	"install": true,
+	"rsync":   true,
+}
+
+// NewOverwriteCheck creates a new OverwriteCheck instance.
+func NewOverwriteCheck(e *Engine) *OverwriteCheck {
+	return &OverwriteCheck{
+		BaseCheck:   BaseCheck{CheckName: "overwrite_check"},
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
+	}
+}
+
+// SetPathChecks sets the checks applied to destination paths.
+func (c *OverwriteCheck) SetPathChecks(dc *DirectoryCheck, sc *SecretsCheck) {
+	c.directoryCheck = dc
+	c.secretsCheck = sc
+}
+
+// CheckCommand checks copy/move destinations.
+func (c *OverwriteCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	// Piped commands are already in the slice, no need to follow PipesTo
+	for _, cmd := range parsedCommands {
+		if copyMoveCommands[cmd.Command] {
+			result := c.checkDestination(cmd)
+			if !result.IsAllowed() {
+				return result
+			}
+		}
+	}
+
+	return c.Allow()
+}
+
+// checkDestination runs write rules against every path the command writes.
+func (c *OverwriteCheck) checkDestination(cmd *ParsedCommand) *CheckResult {
+	// install -d only creates directories
+	if cmd.Command == "install" && (containsFlag(cmd.Flags, "-d") || containsFlag(cmd.Flags, "--directory")) {
+		return c.Allow()
+	}
+
+	dest, sources := c.splitOperands(cmd)
+	if dest == "" || c.isRemote(cmd, dest) {
+		return c.Allow()
+	}
+
+	dest = parsers.JoinDir(cmd.Dir, dest)
+	targets := []string{dest}
+
+	// Into an existing directory: each source lands at dest/<basename>
+	resolved := parsers.ResolvePath(dest, c.baseDir(c.projectRoot))
+	if info, err := os.Stat(resolved); err == nil && info.IsDir() {
+		for _, src := range sources {
+			if c.isRemote(cmd, src) {
+				src = src[strings.LastIndex(src, ":")+1:]
+			}
+			name := filepath.Base(strings.TrimSuffix(src, "/"))
+			// rsync src/ copies the contents, not the directory itself
+			if cmd.Command == "rsync" && strings.HasSuffix(src, "/") || name == "." || name == "/" {
+				continue
+			}
+			targets = append(targets, filepath.Join(dest, name))
+		}
+	}
+
+	for _, target := range targets {
+		if c.directoryCheck != nil {
+			if result := c.directoryCheck.CheckPath(target, cmd.Command); !result.IsAllowed() {
+				return result
+			}
+		}
+		if c.secretsCheck != nil {
+			if result := c.secretsCheck.CheckPath(target, "write"); !result.IsAllowed() {
+				return result
+			}
+		}
+	}
+
+	return c.Allow()
+}
+
+// splitOperands returns the destination and source operands.
+// Handles -t/--target-directory, otherwise the last operand is the destination.
+func (c *OverwriteCheck) splitOperands(cmd *ParsedCommand) (string, []string) {
+	args := cmd.Args
+	for _, f := range cmd.Flags {
+		if strings.HasPrefix(f, "--target-directory=") {
+			return strings.SplitN(f, "=", 2)[1], args
+		}
+		if (f == "-t" || f == "--target-directory") && len(args) > 0 {
+			return args[0], args[1:]
+		}
+	}
+
+	if len(args) < 2 {
+		return "", nil
+	}
+	return args[len(args)-1], args[:len(args)-1]
+}
+
+// isRemote reports whether an rsync operand refers to another host
+// (host:path, user@host:path, rsync://...).
+func (c *OverwriteCheck) isRemote(cmd *ParsedCommand, operand string) bool {
+	if cmd.Command != "rsync" {
+		return false
+	}
+	if strings.Contains(operand, "://") {
+		return true
+	}
+	colon := strings.Index(operand, ":")
+	return colon > 0 && !strings.Contains(operand[:colon], "/")
+}
@@ -0,0 +1,189 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// PipelineCheck looks at what flows through a pipeline rather than at each
+// command alone. `cat .env | base64`, `tar cz . | curl -T - url` and
+// `pg_dump db | gzip | ssh host` are made of commands that pass one by one;
+// together they move project or secret data into an encoder or off the
+// machine.
+type PipelineCheck struct {
+	BaseCheck
+	projectRoot  string
+	config       *config.SecurityConfig
+	secretsCheck *SecretsCheck
+}
+
+// Kinds of data a pipeline stage produces, weakest first.
+const (
+	pipeNoData = iota
+	pipeProjectData
+	pipeSecretData
+)
+
+// NewPipelineCheck creates a new PipelineCheck instance.
+func NewPipelineCheck(e *Engine) *PipelineCheck {
+	return &PipelineCheck{
+		BaseCheck:   BaseCheck{CheckName: "pipeline_check"},
+		projectRoot: e.BoundaryRoot,
+		config:      e.Config,
+	}
+}
+
+// SetSecretsCheck sets the check deciding which files hold secrets.
+func (c *PipelineCheck) SetSecretsCheck(sc *SecretsCheck) {
+	c.secretsCheck = sc
+}
+
+// CheckCommand follows each pipeline from its first command and asks when
+// project or secret data reaches a network sink, or secret data an encoder.
+func (c *PipelineCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	cfg := &c.config.Pipelines
+	if !cfg.Enabled {
+		return c.Allow()
+	}
+
+	piped := make(map[*ParsedCommand]bool)
+	for _, cmd := range parsedCommands {
+		if cmd.PipesTo != nil {
+			piped[cmd.PipesTo] = true
+		}
+	}
+
+	for _, head := range parsedCommands {
+		if piped[head] || head.PipesTo == nil {
+			continue
+		}
+		data, source := pipeNoData, head
+		var encoder *ParsedCommand
+		for stage := head; stage != nil; stage = stage.PipesTo {
+			if stage != head && data != pipeNoData {
+				if isNetworkSink(stage, cfg.NetworkSinks) {
+					return c.askNetwork(source, stage, data)
+				}
+				if encoder == nil && matchesCommandEntry(stage, cfg.Encoders) {
+					encoder = stage
+				}
+			}
+			if kind := c.sourceKind(stage); kind > data {
+				data, source = kind, stage
+				encoder = nil
+			}
+		}
+		if data == pipeSecretData && encoder != nil {
+			return c.Ask(
+				fmt.Sprintf("Secret data piped to an encoder: %s | %s", source.Command, encoder.Command),
+				"Encoded secrets are still secrets, only harder to recognize in the output. Ask the user for the value you need instead.",
+			).WithRule(RulePipelineEncodedSecret).WithOrigin(source)
+		}
+	}
+
+	return c.Allow()
+}
+
+// askNetwork builds the confirmation for data piped to a network sink.
+func (c *PipelineCheck) askNetwork(source, sink *ParsedCommand, data int) *CheckResult {
+	reason := fmt.Sprintf("Project data piped to a network command: %s | %s", source.Command, sink.Command)
+	if data == pipeSecretData {
+		reason = fmt.Sprintf("Secret data piped to a network command: %s | %s", source.Command, sink.Command)
+	}
+	return c.Ask(
+		reason,
+		fmt.Sprintf("The output of %s reaches %s, which sends it off the machine. Show the user the command and let them run it if this upload is intended.", source.Command, sink.Command),
+	).WithRule(RulePipelineNetworkSink).WithOrigin(source)
+}
+
+// sourceKind returns the kind of data a command writes to the pipe: secret
+// for secret_sources and reads of secrets files, project for data_sources
+// and reads of existing files in the project.
+func (c *PipelineCheck) sourceKind(cmd *ParsedCommand) int {
+	cfg := &c.config.Pipelines
+	if matchesCommandEntry(cmd, cfg.SecretSources) {
+		return pipeSecretData
+	}
+	kind := pipeNoData
+	if matchesCommandEntry(cmd, cfg.DataSources) {
+		kind = pipeProjectData
+	}
+	for _, op := range parsers.ClassifyOperands(cmd) {
+		if op.Role != parsers.RoleSource || !isPathOperand(cmd, op) || strings.Contains(op.Value, "://") {
+			continue
+		}
+		path := parsers.JoinDir(cmd.Dir, op.Value)
+		if c.secretsCheck != nil && !c.secretsCheck.CheckPath(path, "read").IsAllowed() {
+			return pipeSecretData
+		}
+		if kind == pipeNoData && c.inProject(path) {
+			kind = pipeProjectData
+		}
+	}
+	return kind
+}
+
+// inProject reports whether path exists inside the project.
+func (c *PipelineCheck) inProject(path string) bool {
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+	rel, err := filepath.Rel(c.projectRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	_, err = os.Stat(resolved)
+	return err == nil
+}
+
+// isNetworkSink reports whether cmd sends its input off the machine. curl
+// and wget only do so when told to read stdin (-T -, -d @-, --post-file=-).
+func isNetworkSink(cmd *ParsedCommand, sinks []string) bool {
+	if !matchesCommandEntry(cmd, sinks) {
+		return false
+	}
+	switch filepath.Base(cmd.Command) {
+	case "curl":
+		words := append(append([]string{}, cmd.Flags...), cmd.Args...)
+		for _, w := range words {
+			if w == "-" || strings.HasSuffix(w, "@-") || strings.HasSuffix(w, "=-") {
+				return true
+			}
+		}
+		return false
+	case "wget":
+		for _, f := range cmd.Flags {
+			if f == "--post-file=-" || f == "--body-file=-" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matchesCommandEntry reports whether cmd matches one of the entries: the
+// command name, then words that must appear among its arguments or flags.
+func matchesCommandEntry(cmd *ParsedCommand, entries []string) bool {
+	name := filepath.Base(cmd.Command)
+	for _, entry := range entries {
+		words := strings.Fields(entry)
+		if len(words) == 0 || words[0] != name {
+			continue
+		}
+		matched := true
+		for _, w := range words[1:] {
+			if !containsArg(cmd.Args, w) && !containsFlag(cmd.Flags, w) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
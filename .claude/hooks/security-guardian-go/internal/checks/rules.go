@@ -0,0 +1,270 @@
+package checks
+
+// Rule IDs identify every decision-producing rule. They are stable and used
+// as keys in the `decisions:` config map.
+const (
+	// Directory boundaries
+	RuleDirectoryOutside       = "directory.outside_project"
+	RuleDirectorySymlinkEscape = "directory.symlink_escape"
+	RuleDirectoryUnresolved    = "directory.unresolved_path"
+
+	// Bypass prevention
+	RuleBypassHardBlocked       = "bypass.hard_blocked"
+	RuleBypassVariableAsCommand = "bypass.variable_as_command"
+	RuleBypassPipeToShell       = "bypass.pipe_to_shell"
+	RuleBypassShellExec         = "bypass.shell_exec"
+	RuleBypassInlineNetwork     = "bypass.inline_network"
+	RuleBypassInlineObfuscation = "bypass.inline_obfuscation"
+	RuleBypassInlineRCE         = "bypass.inline_rce"
+	RuleBypassHiddenPipeNetwork = "bypass.hidden_pipe_network"
+	RuleBypassSelfTrust         = "bypass.self_trust"
+	RuleBypassSelfException     = "bypass.self_exception"
+	RuleBypassSelfUpdate        = "bypass.self_update"
+
+	// Git
+	RuleGitHardBlocked        = "git.hard_blocked"
+	RuleGitConfirmRequired    = "git.confirm_required"
+	RuleGitRemoteBranchDelete = "git.remote_branch_delete"
+	RuleGitShellAlias         = "git.shell_alias"
+	RuleGitDestinationOutside = "git.destination_outside_project"
+	RuleGitRemoteNotAllowed   = "git.remote_not_allowed"
+	RuleGitExportNetwork      = "git.export_to_network"
+	RuleGitExportOutside      = "git.export_outside_project"
+
+	// Deletion
+	RuleDeletionRecursiveGlob     = "deletion.recursive_glob"
+	RuleDeletionOutside           = "deletion.outside_project"
+	RuleDeletionProtected         = "deletion.protected_path"
+	RuleDeletionProtectedAncestor = "deletion.protected_ancestor"
+	RuleDeletionProjectRoot       = "deletion.project_root"
+	RuleDeletionCritical          = "deletion.critical_path"
+	RuleDeletionMoveProjectRoot   = "deletion.move_project_root"
+
+	// Download
+	RuleDownloadPipeToShell = "download.pipe_to_shell"
+	RuleDownloadBinary      = "download.binary_executable"
+
+	// Unpack
+	RuleUnpackBypass         = "unpack.bypass_pattern"
+	RuleUnpackBlockedPattern = "unpack.blocked_pattern"
+	RuleUnpackOutside        = "unpack.outside_project"
+	RuleUnpackTraversal      = "unpack.path_traversal"
+
+	// Execution
+	RuleExecutionChmodDownloaded = "execution.chmod_downloaded"
+	RuleExecutionChmodBinary     = "execution.chmod_binary"
+	RuleExecutionRunDownloaded   = "execution.run_downloaded"
+
+	// Secrets
+	RuleSecretsNoModify        = "secrets.no_modify"
+	RuleSecretsWriteNoRead     = "secrets.write_secret_file"
+	RuleSecretsRead            = "secrets.read_secret_file"
+	RuleSecretsReadHighEntropy = "secrets.high_entropy_content"
+	RuleSecretsSystemFile      = "secrets.system_file"
+	RuleSecretsDotfileWrite    = "secrets.dotfile_write"
+	RuleSecretsVault           = "secrets.vault_access"
+	RuleSecretsCredentialFile  = "secrets.credential_file"
+
+	// Zones
+	RuleZoneStrictWrite = "zone.strict_write"
+
+	// Decoys
+	RuleDecoyAccess = "decoy.access"
+
+	// GnuPG
+	RuleGPGExportSecret = "gpg.export_secret_key"
+	RuleGPGDecryptPiped = "gpg.decrypt_piped"
+	RuleGPGHomeModified = "gpg.home_modified"
+
+	// Autoload locations
+	RuleAutoloadWrite      = "autoload.write"
+	RuleAutoloadActivation = "autoload.activation_content"
+	RuleAutoloadIDECommand = "autoload.ide_command"
+
+	// Pipelines
+	RulePipelineNetworkSink   = "pipeline.network_sink"
+	RulePipelineEncodedSecret = "pipeline.encoded_secret"
+
+	// Database dumps
+	RuleDataExportNetwork     = "data_export.dump_to_network"
+	RuleDataExportOutside     = "data_export.dump_outside_project"
+	RuleDataExportCredentials = "data_export.credentials_in_command"
+	RuleDataExportLargeDump   = "data_export.large_dump"
+
+	// Cloud metadata
+	RuleCloudMetadata = "network.cloud_metadata"
+
+	// Unattended runs
+	RuleUnattendedNetwork = "unattended.network_domain"
+
+	// Guardian tampering
+	RuleTamperProbe = "tamper.guardian_probe"
+
+	// Canary tokens
+	RuleCanaryReuse = "canary.token_reuse"
+
+	// Prompt injection
+	RuleInjectionMarkers = "injection.prompt_markers"
+
+	// WebSearch
+	RuleWebSearchSecret         = "websearch.secret_value"
+	RuleWebSearchBlockedPattern = "websearch.blocked_pattern"
+
+	// Slash commands and background shells
+	RuleSlashCommandNotAllowed = "slash_command.not_allowed"
+	RuleShellOutputDenied      = "background_shell.denied_command_output"
+
+	// Sub-agents (Task tool)
+	RuleSubagentBlocked = "subagent.blocked_instruction"
+	RuleSubagentOutside = "subagent.outside_project"
+
+	// Mass modification
+	RuleMassDeletion  = "mass.files_deleted"
+	RuleMassOverwrite = "mass.files_overwritten"
+	RuleMassWriteSize = "mass.write_size"
+
+	// Code content
+	RuleCodeExfiltration = "code.exfiltration"
+	RuleCodeSecretScan   = "code.secret_scanning"
+	RuleCodeDynamicExec  = "code.dynamic_execution"
+	RuleCodeSystemRecon  = "code.system_recon"
+	RuleCodeNotebookMeta = "code.notebook_metadata"
+
+	// Trusted scripts
+	RuleCodeTrustedChanged      = "code.trusted_script_changed"
+	RuleExecutionTrustedChanged = "execution.trusted_script_changed"
+
+	// Input limits
+	RuleInputOversized = "input.oversized"
+
+	// Check failures
+	RuleInternalTimeout = "internal.timeout"
+	RuleInternalPanic   = "internal.panic"
+	RuleInternalError   = "internal.error"
+	RuleInternalConfig  = "internal.config"
+)
+
+// Rule describes a decision-producing rule.
+type Rule struct {
+	ID          string
+	Check       string
+	Decision    PermissionDecision // built-in decision before overrides
+	Description string
+}
+
+// Rules is the registry of all built-in rules.
+var Rules = []Rule{
+	{RuleDirectoryOutside, "directory_check", DecisionDeny, "Path outside project and allowed_paths"},
+	{RuleDirectorySymlinkEscape, "directory_check", DecisionDeny, "Symlink inside project resolves outside"},
+	{RuleDirectoryUnresolved, "directory_check", DecisionAsk, "Path depends on a variable or expansion only known at run time"},
+
+	{RuleBypassHardBlocked, "bypass_check", DecisionDeny, "Hard-blocked command (eval)"},
+	{RuleBypassVariableAsCommand, "bypass_check", DecisionDeny, "Variable expansion used as command name"},
+	{RuleBypassPipeToShell, "bypass_check", DecisionDeny, "Output piped to a shell"},
+	{RuleBypassShellExec, "bypass_check", DecisionDeny, "Shell invoked with -c / via env or busybox"},
+	{RuleBypassInlineNetwork, "bypass_check", DecisionAsk, "Inline interpreter code with network calls"},
+	{RuleBypassInlineObfuscation, "bypass_check", DecisionAsk, "Inline interpreter code with import obfuscation"},
+	{RuleBypassInlineRCE, "bypass_check", DecisionAsk, "Decode+exec pattern with network access"},
+	{RuleBypassHiddenPipeNetwork, "bypass_check", DecisionAsk, "Data sent to a network command via >(...) or a named pipe"},
+	{RuleBypassSelfTrust, "bypass_check", DecisionDeny, "Agent running `guardian trust`"},
+	{RuleBypassSelfException, "bypass_check", DecisionDeny, "Agent running `guardian allow`"},
+	{RuleBypassSelfUpdate, "bypass_check", DecisionDeny, "Agent running `guardian self-update`"},
+
+	{RuleGitHardBlocked, "git_check", DecisionDeny, "git operation in git.hard_blocked"},
+	{RuleGitConfirmRequired, "git_check", DecisionAsk, "git operation in git.confirm_required"},
+	{RuleGitRemoteBranchDelete, "git_check", DecisionAsk, "git push deleting remote branches"},
+	{RuleGitShellAlias, "git_check", DecisionAsk, "git alias running a shell command"},
+	{RuleGitDestinationOutside, "git_check", DecisionAsk, "git clone or worktree add outside project"},
+	{RuleGitRemoteNotAllowed, "git_check", DecisionAsk, "git remote host not in git.allowed_remotes"},
+	{RuleGitExportNetwork, "git_check", DecisionAsk, "git archive or bundle piped to a network command"},
+	{RuleGitExportOutside, "git_check", DecisionAsk, "git archive or bundle written outside project"},
+
+	{RuleDeletionRecursiveGlob, "deletion_check", DecisionAsk, "Recursive deletion with glob pattern"},
+	{RuleDeletionOutside, "deletion_check", DecisionAsk, "Deletion outside project"},
+	{RuleDeletionProtected, "deletion_check", DecisionAsk, "Recursive deletion of protected path"},
+	{RuleDeletionProtectedAncestor, "deletion_check", DecisionAsk, "Recursive deletion of directory containing protected path"},
+	{RuleDeletionProjectRoot, "deletion_check", DecisionAsk, "Recursive deletion of project root"},
+	{RuleDeletionCritical, "deletion_check", DecisionDeny, "Recursive deletion of /, home or a directory containing the project"},
+	{RuleDeletionMoveProjectRoot, "deletion_check", DecisionDeny, "mv of the project root or a directory containing it"},
+
+	{RuleDownloadPipeToShell, "download_check", DecisionDeny, "Download piped to a shell"},
+	{RuleDownloadBinary, "download_check", DecisionAsk, "Download of binary executable"},
+
+	{RuleUnpackBypass, "unpack_check", DecisionDeny, "Archive option that bypasses path protection (bsdtar -s)"},
+	{RuleUnpackBlockedPattern, "unpack_check", DecisionAsk, "Unpack command in unpack_protection.blocked_patterns"},
+	{RuleUnpackOutside, "unpack_check", DecisionAsk, "Unpack target outside project"},
+	{RuleUnpackTraversal, "unpack_check", DecisionDeny, "Path traversal in unpack target"},
+
+	{RuleExecutionChmodDownloaded, "execution_check", DecisionAsk, "chmod +x on downloaded file"},
+	{RuleExecutionChmodBinary, "execution_check", DecisionAsk, "chmod +x on binary or script file"},
+	{RuleExecutionRunDownloaded, "execution_check", DecisionAsk, "First run of a downloaded file, by any invocation (download_protection.confirm_first_run)"},
+
+	{RuleSecretsNoModify, "secrets_check", DecisionDeny, "Write to protected_paths.no_modify"},
+	{RuleSecretsWriteNoRead, "secrets_check", DecisionDeny, "Write to secrets file"},
+	{RuleSecretsRead, "secrets_check", DecisionDeny, "Read of secrets file"},
+	{RuleSecretsReadHighEntropy, "secrets_check", DecisionAsk, "Read of file with high-entropy tokens (content_scan)"},
+	{RuleSecretsSystemFile, "secrets_check", DecisionDeny, "Access to a /proc, /sys or device file in sensitive_files.system_paths"},
+	{RuleSecretsDotfileWrite, "secrets_check", DecisionDeny, "Write to a shell rc, ssh, git or cron file in protected_paths.no_write_outside_tools"},
+	{RuleSecretsCredentialFile, "secrets_check", DecisionDeny, "Access to a cloud, cluster or registry credentials file (sensitive_files.credential_files)"},
+	{RuleSecretsVault, "secrets_check", DecisionDeny, "Access to crypto wallet or password manager data (sensitive_files.vaults)"},
+	{RuleDecoyAccess, "decoy_check", DecisionDeny, "Access to a decoy secrets file or its canary values"},
+	{RuleGPGExportSecret, "gpg_check", DecisionDeny, "Export or read of GPG secret keys"},
+	{RuleGPGDecryptPiped, "gpg_check", DecisionDeny, "gpg --decrypt output piped to another command"},
+	{RuleGPGHomeModified, "gpg_check", DecisionDeny, "Change to a file in the GnuPG home"},
+	{RuleAutoloadWrite, "autoload_check", DecisionAsk, "Write to a file the project runs automatically (autoload.paths)"},
+	{RuleAutoloadIDECommand, "autoload_check", DecisionAsk, "Network call, sensitive access or dynamic execution in an editor task or run configuration (autoload.ide)"},
+	{RuleAutoloadActivation, "autoload_check", DecisionAsk, "Network call, PATH change or exported secret in a shell activation file (autoload.activation)"},
+	{RulePipelineNetworkSink, "pipeline_check", DecisionAsk, "Project or secret data piped to a network command (pipelines.network_sinks)"},
+	{RulePipelineEncodedSecret, "pipeline_check", DecisionAsk, "Secret data piped to an encoder (pipelines.encoders)"},
+	{RuleDataExportNetwork, "data_export_check", DecisionAsk, "Database dump piped to a network command (dangerous_operations.data_export)"},
+	{RuleDataExportOutside, "data_export_check", DecisionAsk, "Database dump written outside the project (dangerous_operations.data_export)"},
+	{RuleDataExportCredentials, "data_export_check", DecisionAsk, "Connection string or password option with credentials on the command line"},
+	{RuleDataExportLargeDump, "data_export_check", DecisionAsk, "Dump written inside the project is larger than max_dump_bytes (PostToolUse warning)"},
+	{RuleCloudMetadata, "cloud_metadata_check", DecisionDeny, "Request to a cloud instance metadata endpoint not in cloud_metadata.allowed"},
+	{RuleUnattendedNetwork, "unattended_check", DecisionDeny, "Network access outside unattended.allowed_domains while the run is unattended"},
+	{RuleTamperProbe, "tamper_check", DecisionDeny, "Command looks for the guardian or tries to disable it (tamper_detection.patterns)"},
+	{RuleCanaryReuse, "canary_check", DecisionDeny, "Tool input repeats the canary token of a guardian message"},
+	{RuleInjectionMarkers, "prompt_injection_check", DecisionAsk, "Prompt-injection markers in fetched or written content"},
+	{RuleWebSearchSecret, "web_search_check", DecisionDeny, "Search query contains a secret env var or secrets file value"},
+	{RuleWebSearchBlockedPattern, "web_search_check", DecisionDeny, "Search query matches web_search.blocked_patterns"},
+	{RuleSlashCommandNotAllowed, "slash_command_check", DecisionAsk, "Slash command is not in slash_commands.allowed"},
+	{RuleShellOutputDenied, "background_shell_check", DecisionAsk, "Background shell output contains a command denied earlier in the session"},
+	{RuleSubagentBlocked, "subagent_check", DecisionDeny, "Sub-agent prompt asks to disable hooks, escalate or bypass rules"},
+	{RuleSubagentOutside, "subagent_check", DecisionAsk, "Sub-agent prompt refers to paths outside the project"},
+
+	{RuleZoneStrictWrite, "secrets_check", DecisionAsk, "Modification inside a strict zone"},
+
+	{RuleMassDeletion, "mass_modification_check", DecisionAsk, "Session file deletion limit exceeded"},
+	{RuleMassOverwrite, "mass_modification_check", DecisionAsk, "Session file overwrite limit exceeded"},
+	{RuleMassWriteSize, "mass_modification_check", DecisionAsk, "Single write larger than max_write_bytes"},
+
+	{RuleCodeExfiltration, "code_content_check", DecisionAsk, "Script combines network and sensitive data access"},
+	{RuleCodeSecretScan, "code_content_check", DecisionAsk, "Script searches for secrets"},
+	{RuleCodeDynamicExec, "code_content_check", DecisionAsk, "Script uses dynamic code execution"},
+	{RuleCodeSystemRecon, "code_content_check", DecisionAsk, "Script gathers system info with network access"},
+	{RuleCodeNotebookMeta, "code_content_check", DecisionAsk, "Notebook metadata with a kernel command, hidden cell or script output (dangerous_operations.notebook_metadata)"},
+	{RuleCodeTrustedChanged, "code_content_check", DecisionAsk, "Trusted script changed since `guardian trust`"},
+	{RuleExecutionTrustedChanged, "execution_check", DecisionAsk, "chmod +x on trusted script changed since `guardian trust`"},
+
+	{RuleInputOversized, "input_limits", DecisionAsk, "Tool call larger than input_limits.max_input_bytes, not checked"},
+	{RuleInternalTimeout, "performance", DecisionAsk, "Checks did not finish within performance.check_timeout_ms"},
+	{RuleInternalPanic, "internal_error", DecisionAsk, "A check failed with an internal error (on_internal_error)"},
+	{RuleInternalError, "internal_error", DecisionAsk, "Hook input could not be read or parsed (on_internal_error)"},
+	{RuleInternalConfig, "internal_error", DecisionDeny, "The config has patterns that don't compile and strict_config is on"},
+}
+
+// LookupRule returns the rule with the given ID.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// WithRule tags the result with the rule that produced it.
+func (r *CheckResult) WithRule(ruleID string) *CheckResult {
+	r.RuleID = ruleID
+	return r
+}
@@ -0,0 +1,170 @@
+package checks
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// lineSlack is how far a sample window is stretched to whole lines.
+const lineSlack = 1024
+
+// scanWindows returns the byte ranges of content that content checks scan.
+// Content up to input_limits.max_scan_bytes is scanned whole; larger
+// content by its first and last sample_kb plus sample_windows windows of
+// the same size spread evenly in between, each stretched to line bounds
+// so a pattern isn't cut in two.
+func scanWindows(content string, limits config.InputLimitsConfig) [][2]int {
+	n := len(content)
+	size := limits.SampleKB * 1024
+	if limits.MaxScanBytes <= 0 || n <= limits.MaxScanBytes || size <= 0 {
+		return [][2]int{{0, n}}
+	}
+
+	starts := []int{0}
+	if gap := n - 2*size; gap > 0 {
+		for i := 1; i <= limits.SampleWindows; i++ {
+			starts = append(starts, size+gap*i/(limits.SampleWindows+1)-size/2)
+		}
+	}
+	starts = append(starts, n-size)
+
+	var windows [][2]int
+	for _, start := range starts {
+		from, to := lineStart(content, start), lineStop(content, start+size)
+		// Merge overlapping windows
+		if last := len(windows) - 1; last >= 0 && from <= windows[last][1] {
+			if to > windows[last][1] {
+				windows[last][1] = to
+			}
+			continue
+		}
+		windows = append(windows, [2]int{from, to})
+	}
+	return windows
+}
+
+// sampleContent returns the scanned windows of content joined by newlines.
+func sampleContent(content string, limits config.InputLimitsConfig) string {
+	windows := scanWindows(content, limits)
+	if len(windows) == 1 {
+		return content[windows[0][0]:windows[0][1]]
+	}
+	parts := make([]string, len(windows))
+	for i, w := range windows {
+		parts[i] = content[w[0]:w[1]]
+	}
+	return strings.Join(parts, "\n")
+}
+
+// matchChunks calls fn with the offsets of each match of re in text, in
+// order, until fn returns false or max matches were found (max <= 0: no
+// limit). The text is searched in chunks of input_limits.chunk_kb that
+// overlap by chunk_overlap bytes, each starting on a line, so a match is
+// found whole unless it is longer than the overlap, and the matches built
+// at a time are those of one chunk. The search stops between chunks when
+// ctx is done.
+func matchChunks(ctx context.Context, re *regexp.Regexp, text string, limits config.InputLimitsConfig, max int, fn func(start, end int) bool) {
+	size := limits.ChunkKB * 1024
+	if size <= 0 {
+		size = len(text)
+	}
+	overlap := limits.ChunkOverlap
+	if overlap < 0 {
+		overlap = 0
+	}
+
+	found, next := 0, 0
+	for from := 0; from < len(text) || from == 0; {
+		if ctx.Err() != nil {
+			return
+		}
+		// The next chunk starts on the line at from+size; matches starting
+		// there are left to it
+		stop, to := len(text), len(text)
+		if from+size < len(text) {
+			stop = lineStart(text, from+size)
+			if stop <= from {
+				stop = from + size
+			}
+			to = lineStop(text, stop+overlap)
+		}
+
+		// One more for a match the previous chunk already reported
+		limit := -1
+		if max > 0 {
+			limit = max - found + 1
+		}
+		for _, loc := range re.FindAllStringIndex(text[from:to], limit) {
+			start, end := from+loc[0], from+loc[1]
+			if start >= stop {
+				break
+			}
+			if start == end || start < next {
+				continue
+			}
+			next = end
+			if !fn(start, end) {
+				return
+			}
+			if found++; max > 0 && found >= max {
+				return
+			}
+		}
+		if stop >= len(text) {
+			return
+		}
+		from = stop
+	}
+}
+
+// lineCounter returns line numbers of ascending offsets in one text,
+// counting each newline once.
+type lineCounter struct {
+	text   string
+	offset int
+	line   int
+}
+
+// lineColumn returns the 1-based line and column of offset. An offset
+// before the previous one starts the count over.
+func (lc *lineCounter) lineColumn(offset int) (int, int) {
+	if offset < lc.offset || lc.line == 0 {
+		lc.offset, lc.line = 0, 1
+	}
+	lc.line += strings.Count(lc.text[lc.offset:offset], "\n")
+	lc.offset = offset
+	return lc.line, offset - strings.LastIndexByte(lc.text[:offset], '\n')
+}
+
+// lineStart moves i back to the start of its line, by at most lineSlack.
+func lineStart(s string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	from := i - lineSlack
+	if from < 0 {
+		from = 0
+	}
+	if idx := strings.LastIndexByte(s[from:i], '\n'); idx >= 0 {
+		return from + idx + 1
+	}
+	return from
+}
+
+// lineStop moves i forward to the end of its line, by at most lineSlack.
+func lineStop(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+	to := i + lineSlack
+	if to > len(s) {
+		to = len(s)
+	}
+	if idx := strings.IndexByte(s[i:to], '\n'); idx >= 0 {
+		return i + idx
+	}
+	return to
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
 )
 
@@ -15,21 +16,22 @@ type SecretsCheck struct {
 	BaseCheck
 	projectRoot string
 	config      *config.SecurityConfig
+	zones       *Zones
+	gpg         *GPGCheck
+	engine      *Engine
 }
 
 // NewSecretsCheck creates a new SecretsCheck instance.
-func NewSecretsCheck(cfg *config.SecurityConfig) *SecretsCheck {
-	projectRoot := cfg.Directories.ProjectRoot
-	if projectRoot == "" {
-		projectRoot = parsers.GetProjectRoot()
-	} else {
-		projectRoot = parsers.ResolvePath(projectRoot, "")
-	}
+func NewSecretsCheck(e *Engine) *SecretsCheck {
+	projectRoot := e.BoundaryRoot
 
 	return &SecretsCheck{
 		BaseCheck:   BaseCheck{CheckName: "secrets_check"},
 		projectRoot: projectRoot,
-		config:      cfg,
+		config:      e.Config,
+		zones:       e.Zones(projectRoot),
+		gpg:         NewGPGCheck(e),
+		engine:      e,
 	}
 }
 
@@ -43,88 +45,88 @@ var fileArgCommands = map[string]bool{
 	"ln": true, "readlink": true, "realpath": true,
 	"source": true, "open": true, "xdg-open": true,
 	"nano": true, "vim": true, "vi": true, "code": true,
-}
-
-// patternFirstArgCommands lists commands whose first positional argument is a pattern,
-// not a file path. For these commands, the first arg should be skipped during path checks.
-// e.g. grep ".env" README.md — ".env" is a search pattern, not a file.
-var patternFirstArgCommands = map[string]bool{
-	"grep": true, "egrep": true, "fgrep": true, "rg": true,
-	"sed": true, "awk": true, "gawk": true,
-	"expr": true,
-}
-
-// nonPathCommands lists commands whose ALL positional arguments are non-paths.
-// None of their args should be checked as file paths.
-var nonPathCommands = map[string]bool{
-	"echo": true, "printf": true, "export": true, "unset": true,
-	"alias": true, "unalias": true, "set": true,
-	"true": true, "false": true, "test": true, "[": true,
+	// Encoders and dumps: base64 config reads it as much as cat does
+	"base64": true, "base32": true, "basenc": true, "xxd": true, "od": true,
+	"hexdump": true, "strings": true, "gzip": true, "bzip2": true, "xz": true,
+	"zstd": true, "tac": true, "nl": true,
 }
 
 // CheckCommand checks for access to protected files.
+// Operands the command writes get write rules (no_modify, secrets, strict
+// zones); operands it only reads get no_read rules; patterns are skipped.
 func (c *SecretsCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
 	for _, cmd := range parsedCommands {
-		// For commands that never take file path arguments (echo, printf, etc.),
-		// still check redirect targets (echo secret > .env.bak could write secrets).
-		if nonPathCommands[cmd.Command] {
-			for _, redir := range cmd.Redirects {
-				result := c.CheckPath(redir, "write")
-				if !result.IsAllowed() {
-					return result
-				}
+		for _, op := range parsers.ClassifyOperands(cmd) {
+			if !isPathOperand(cmd, op) {
+				continue
 			}
-			continue
-		}
-
-		// Get path-like args from the command
-		paths := parsers.ExtractPathsFromCommand(convertParsedCommand(cmd))
-
-		// For commands with a pattern first arg (grep, sed, awk),
-		// skip the first arg which is a pattern, not a path.
-		skipFirstArg := patternFirstArgCommands[cmd.Command]
-		firstArgSkipped := false
-
-		for _, pathStr := range paths {
-			if skipFirstArg && !firstArgSkipped {
-				// Check if this path corresponds to the first positional arg
-				if len(cmd.Args) > 0 && pathStr == cmd.Args[0] {
-					firstArgSkipped = true
-					continue
-				}
+			operation := "read"
+			if op.Role == parsers.RoleDestination {
+				operation = "write"
 			}
-			result := c.CheckPath(pathStr, cmd.Command)
+			result := c.CheckPath(parsers.JoinDir(cmd.Dir, op.Value), operation)
 			if !result.IsAllowed() {
 				return result
 			}
 		}
-
-		// For file-operating commands, also check bare args that ExtractPathsFromCommand
-		// may have filtered out (e.g. bare filenames like "id_rsa" without /, ., or ~).
-		if fileArgCommands[cmd.Command] {
-			for _, arg := range cmd.Args {
-				if strings.HasPrefix(arg, "-") {
-					continue
-				}
-				// Skip args already covered by ExtractPathsFromCommand
-				if strings.Contains(arg, "/") || strings.HasPrefix(arg, ".") || strings.HasPrefix(arg, "~") || strings.Contains(arg, ".") {
-					continue
-				}
-				result := c.CheckPath(arg, cmd.Command)
-				if !result.IsAllowed() {
-					return result
-				}
-			}
-		}
 	}
 
 	return c.Allow()
 }
 
+// isPathOperand reports whether an operand should be checked as a path.
+// Bare names without /, . or ~ (e.g. "id_rsa", a symlink) are only checked
+// for commands known to take files, or when the command writes them.
+func isPathOperand(cmd *ParsedCommand, op parsers.Operand) bool {
+	if op.Role == parsers.RolePattern || op.Value == "" {
+		return false
+	}
+	if parsers.IsPathLike(op.Value) {
+		return true
+	}
+	if strings.HasPrefix(op.Value, "-") {
+		return false
+	}
+	return fileArgCommands[cmd.Command] || op.Role == parsers.RoleDestination
+}
+
 // CheckPath checks if a path matches protected patterns.
 func (c *SecretsCheck) CheckPath(path string, operation string) *CheckResult {
-	// Resolve relative to project root
-	resolved := parsers.ResolvePath(path, c.projectRoot)
+	// Resolve relative to the working directory
+	resolved := parsers.ResolvePath(path, c.baseDir(c.projectRoot))
+
+	// Machine-wide rules, inside the project or not
+	if pattern := machinePathPattern(c.config.SensitiveFiles.SystemPaths, path, resolved); pattern != "" {
+		return c.Deny(
+			fmt.Sprintf("Cannot access system file: %s", path),
+			fmt.Sprintf("%s exposes process environment, memory or machine identifiers. Ask the user for the specific value you need.", path),
+		).WithRule(RuleSecretsSystemFile).WithPaths(path).WithPattern(pattern)
+	}
+	if pattern := machinePathPattern(c.config.SensitiveFiles.CredentialFiles, path, resolved); pattern != "" {
+		return c.Deny(
+			fmt.Sprintf("Cannot access credentials file: %s", path),
+			fmt.Sprintf("%s holds cloud, cluster or registry credentials. Do not read or copy it; ask the user to run what needs them.", path),
+		).WithRule(RuleSecretsCredentialFile).WithPaths(path).WithPattern(pattern)
+	}
+	if pattern := machinePathPattern(c.config.SensitiveFiles.Vaults, path, resolved); pattern != "" {
+		return c.Deny(
+			fmt.Sprintf("Cannot access wallet or password store: %s", path),
+			fmt.Sprintf("%s holds wallet keys or stored passwords. Do not read or copy it; ask the user for what you need.", path),
+		).WithRule(RuleSecretsVault).WithPaths(path).WithPattern(pattern)
+	}
+
+	if operation == "write" || operation == "edit" {
+		if pattern := machinePathPattern(c.config.ProtectedPaths.NoWriteOutsideTools, path, resolved); pattern != "" {
+			return c.Deny(
+				fmt.Sprintf("Cannot modify shell, ssh, git or cron config: %s", path),
+				fmt.Sprintf("%s runs or configures things outside this session. Show the user the change and let them apply it.", path),
+			).WithRule(RuleSecretsDotfileWrite).WithPaths(path).WithPattern(pattern)
+		}
+	}
+
+	if result := c.gpg.checkResolved(path, resolved, operation); !result.IsAllowed() {
+		return result
+	}
 
 	// Get relative path to project
 	relStr, err := filepath.Rel(c.projectRoot, resolved)
@@ -134,101 +136,103 @@ func (c *SecretsCheck) CheckPath(path string, operation string) *CheckResult {
 	}
 
 	// Check patterns based on operation type
-	if c.isWriteOperation(operation) {
-		if c.matchesNoModify(relStr) {
+	if operation == "write" || operation == "edit" {
+		if pattern := c.noModifyPattern(relStr); pattern != "" {
 			return c.Deny(
 				fmt.Sprintf("Cannot modify protected file: %s", path),
 				fmt.Sprintf("File is protected. Cannot modify %s.", path),
-			)
+			).WithRule(RuleSecretsNoModify).WithPaths(path).WithPattern(pattern)
 		}
 		// Writing to secrets files is also forbidden (e.g. echo secret > .env)
-		if c.matchesNoRead(relStr) {
+		if pattern := c.noReadPattern(relStr); pattern != "" {
 			return c.Deny(
 				fmt.Sprintf("Cannot write to secrets file: %s", path),
 				fmt.Sprintf("File %s is a secrets file. Cannot write to it.", path),
-			)
+			).WithRule(RuleSecretsWriteNoRead).WithPaths(path).WithPattern(pattern)
+		}
+		// Strict zones (infra, CI config) need confirmation for any change
+		if c.zones.policyForRel(relStr) == ZoneStrict {
+			return c.Ask(
+				fmt.Sprintf("Modification in strict zone: %s", path),
+				fmt.Sprintf("Path %s is in a strict zone. Show the user the change and let them apply it.", path),
+			).WithRule(RuleZoneStrictWrite).WithPaths(path)
 		}
 	} else {
-		if c.matchesNoRead(relStr) {
+		if pattern := c.noReadPattern(relStr); pattern != "" {
 			return c.Deny(
 				fmt.Sprintf("Cannot read secrets file: %s", path),
 				c.getSecretsGuidance(path, relStr),
-			)
+			).WithRule(RuleSecretsRead).WithPaths(path).WithPattern(pattern)
 		}
 	}
 
 	return c.Allow()
 }
 
-// isWriteOperation checks if operation is a write operation.
-func (c *SecretsCheck) isWriteOperation(operation string) bool {
-	writeOps := map[string]bool{
-		"write": true,
-		"edit":  true,
-		"tee":   true,
-		"echo":  true,
-		">":     true,
-		">>":    true,
-		"cp":    true,
-		"mv":    true,
-		"rm":    true,
-		"touch": true,
-		"sed":   true,
-		"awk":   true,
+// machinePathPattern returns the entry of a machine-wide pattern list
+// (system_paths, vaults, no_write_outside_tools) matching path, or "". Entries are absolute globs,
+// globs under the home directory (~/.electrum/**), or globs matched at any
+// depth and against the file name (**/*.kdbx). Both the path as written
+// and resolved are tried: /proc/self resolves to the guardian's own PID,
+// and /sys/class/dmi/id is a symlink into /sys/devices.
+func machinePathPattern(patterns []string, path, resolved string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	candidates := []string{resolved}
+	if written := parsers.NormalizePath(path); filepath.IsAbs(written) {
+		candidates = append(candidates, filepath.Clean(written))
+	}
+
+	for _, p := range candidates {
+		pattern, ok := globs.MatchListFunc(patterns, func(glob string) bool {
+			switch {
+			case strings.HasPrefix(glob, "/"):
+				return globs.Match(glob, p)
+			case strings.HasPrefix(glob, "~/"):
+				return home != "" && globs.Match(home+glob[1:], p)
+			}
+			return matchPathOrName(glob, strings.TrimPrefix(p, "/"), filepath.Base(p))
+		})
+		if ok {
+			return pattern
+		}
 	}
-	return writeOps[strings.ToLower(operation)]
+	return ""
 }
 
 // matchesNoRead checks if path matches no_read_content or forbidden_read patterns.
 func (c *SecretsCheck) matchesNoRead(relPath string) bool {
-	// Combine protected_paths.no_read_content and sensitive_files.forbidden_read
+	return c.noReadPattern(relPath) != ""
+}
+
+// noReadPattern returns the no_read_content or forbidden_read pattern
+// matching path, or "". The lists are read as one, forbidden_read first,
+// last match wins, so a "!pattern" exception in no_read_content also
+// lifts a forbidden_read match.
+func (c *SecretsCheck) noReadPattern(relPath string) string {
 	var allPatterns []string
-	allPatterns = append(allPatterns, c.config.ProtectedPaths.NoReadContent...)
 	allPatterns = append(allPatterns, c.config.SensitiveFiles.ForbiddenRead...)
+	allPatterns = append(allPatterns, c.config.ProtectedPaths.NoReadContent...)
 
 	filename := filepath.Base(relPath)
-
-	// First check negation patterns (they take precedence)
-	for _, pattern := range allPatterns {
-		if strings.HasPrefix(pattern, "!") {
-			negated := pattern[1:]
-			// Remove **/ prefix
-			if strings.HasPrefix(negated, "**/") {
-				negated = negated[3:]
-			}
-			if matchGlob(filename, negated) || matchGlob(relPath, negated) {
-				return false // Explicitly allowed
-			}
-		}
+	pattern, ok := globs.MatchListFunc(allPatterns, func(p string) bool {
+		return matchPathOrName(p, relPath, filename)
+	})
+	if !ok {
+		return ""
 	}
-
-	// Then check blocking patterns
-	for _, pattern := range allPatterns {
-		if !strings.HasPrefix(pattern, "!") {
-			cleanPattern := pattern
-			if strings.HasPrefix(cleanPattern, "**/") {
-				cleanPattern = cleanPattern[3:]
-			}
-			if matchGlob(filename, cleanPattern) || matchGlob(relPath, cleanPattern) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return pattern
 }
 
-// matchesNoModify checks if path matches no_modify patterns.
-func (c *SecretsCheck) matchesNoModify(relPath string) bool {
-	patterns := c.config.ProtectedPaths.NoModify
-
-	for _, pattern := range patterns {
-		if matchGlob(relPath, pattern) {
-			return true
-		}
+// noModifyPattern returns the no_modify pattern matching path, or "".
+func (c *SecretsCheck) noModifyPattern(relPath string) string {
+	pattern, ok := globs.MatchList(c.config.ProtectedPaths.NoModify, relPath)
+	if !ok {
+		return ""
 	}
-
-	return false
+	return pattern
 }
 
 // getSecretsGuidance returns appropriate guidance for secrets access.
@@ -248,64 +252,8 @@ func (c *SecretsCheck) getSecretsGuidance(path string, relPath string) string {
 	return fmt.Sprintf("Cannot read %s (protected file). Ask user for needed information.", path)
 }
 
-// matchGlob performs simple glob matching.
-func matchGlob(name string, pattern string) bool {
-	// Handle ** (matches any path component)
-	if strings.Contains(pattern, "**") {
-		// Split pattern by **
-		parts := strings.Split(pattern, "**")
-		if len(parts) == 2 {
-			prefix := parts[0]
-			suffix := parts[1]
-
-			// PREFIX/** should also match PREFIX itself
-			// e.g. ".git/**" should match ".git" to prevent renaming/moving .git
-			trimmedPrefix := strings.TrimSuffix(prefix, "/")
-			if trimmedPrefix != "" && name == trimmedPrefix {
-				return true
-			}
-
-			// Check if name starts with prefix and ends with suffix
-			if prefix != "" && !strings.HasPrefix(name, prefix) {
-				return false
-			}
-			suffix = strings.TrimPrefix(suffix, "/")
-			if suffix != "" && !strings.HasSuffix(name, suffix) && !matchSimpleGlob(filepath.Base(name), suffix) {
-				return false
-			}
-			return true
-		}
-	}
-
-	return matchSimpleGlob(name, pattern)
-}
-
-// matchSimpleGlob performs simple glob matching with * and ?.
-func matchSimpleGlob(name string, pattern string) bool {
-	// Convert to regex-like matching
-	i, j := 0, 0
-	starIdx, matchIdx := -1, -1
-
-	for i < len(name) {
-		if j < len(pattern) && (pattern[j] == '?' || pattern[j] == name[i]) {
-			i++
-			j++
-		} else if j < len(pattern) && pattern[j] == '*' {
-			starIdx = j
-			matchIdx = i
-			j++
-		} else if starIdx != -1 {
-			j = starIdx + 1
-			matchIdx++
-			i = matchIdx
-		} else {
-			return false
-		}
-	}
-
-	for j < len(pattern) && pattern[j] == '*' {
-		j++
-	}
-
-	return j == len(pattern)
+// matchPathOrName matches a secrets pattern against the relative path, and
+// against the file name alone so "*.pem" protects keys at any depth.
+func matchPathOrName(pattern, relPath, filename string) bool {
+	return globs.Match(pattern, relPath) || globs.Match(strings.TrimPrefix(pattern, "**/"), filename)
 }
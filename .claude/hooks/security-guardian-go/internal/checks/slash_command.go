@@ -0,0 +1,63 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+)
+
+// SlashCommandCheck screens SlashCommand invocations against an allowlist.
+// Custom commands expand to arbitrary prompts (and may allow tools in their
+// frontmatter), so only reviewed ones run without confirmation.
+type SlashCommandCheck struct {
+	BaseCheck
+	config *config.SecurityConfig
+}
+
+// NewSlashCommandCheck creates a new SlashCommandCheck instance.
+func NewSlashCommandCheck(e *Engine) *SlashCommandCheck {
+	return &SlashCommandCheck{
+		BaseCheck: BaseCheck{CheckName: "slash_command_check"},
+		config:    e.Config,
+	}
+}
+
+// CheckCommand is not used - see CheckSlashCommand.
+func (c *SlashCommandCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.Allow()
+}
+
+// CheckSlashCommand checks a command line such as "/review-pr 123".
+func (c *SlashCommandCheck) CheckSlashCommand(command string) *CheckResult {
+	if !c.config.SlashCommands.Enabled {
+		return c.Allow()
+	}
+
+	name := slashCommandName(command)
+	if name == "" {
+		return c.Allow()
+	}
+
+	for _, allowed := range c.config.SlashCommands.Allowed {
+		allowed = strings.TrimPrefix(allowed, "/")
+		if globs.Match(allowed, name) || allowed == name {
+			return c.Allow()
+		}
+	}
+
+	return c.Ask(
+		fmt.Sprintf("Slash command /%s is not in slash_commands.allowed", name),
+		"Custom commands run their own prompt. Confirm with the user, or add it to the allowlist after review.",
+	).WithRule(RuleSlashCommandNotAllowed)
+}
+
+// slashCommandName returns the command name without "/" and arguments.
+func slashCommandName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fields[0], "/")
+}
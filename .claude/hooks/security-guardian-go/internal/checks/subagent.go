@@ -0,0 +1,94 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// SubagentCheck screens Task (sub-agent) prompts. A child agent runs under
+// the same hooks, but its prompt can still ask it to switch them off, gain
+// privileges or work outside the project - laundering an operation that was
+// blocked for the parent.
+type SubagentCheck struct {
+	BaseCheck
+	config         *config.SecurityConfig
+	patterns       []codePatternItem
+	directoryCheck *DirectoryCheck
+}
+
+// Absolute or home-relative paths in free text
+var promptPathPattern = regexp.MustCompile("(?:^|[\\s\"'`(=])((?:/|~/)[^\\s\"'`),;]+)")
+
+// NewSubagentCheck creates a new SubagentCheck instance.
+func NewSubagentCheck(e *Engine) *SubagentCheck {
+	c := &SubagentCheck{
+		BaseCheck: BaseCheck{CheckName: "subagent_check"},
+		config:    e.Config,
+	}
+	for _, item := range e.Config.Subagents.BlockedPatterns {
+		pattern := item.Pattern
+		if !strings.HasPrefix(pattern, "(?") {
+			pattern = "(?i)" + pattern
+		}
+		if re := e.compile(pattern); re != nil {
+			c.patterns = append(c.patterns, codePatternItem{pattern: re, description: item.Description})
+		}
+	}
+	return c
+}
+
+// SetDirectoryCheck sets the check used for paths mentioned in prompts.
+func (c *SubagentCheck) SetDirectoryCheck(dc *DirectoryCheck) {
+	c.directoryCheck = dc
+}
+
+// CheckCommand is not used - see CheckTask.
+func (c *SubagentCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.Allow()
+}
+
+// CheckTask checks a sub-agent type and prompt.
+func (c *SubagentCheck) CheckTask(subagentType, description, prompt string) *CheckResult {
+	if !c.config.Subagents.Enabled {
+		return c.Allow()
+	}
+
+	for _, blocked := range c.config.Subagents.BlockedTypes {
+		if strings.EqualFold(subagentType, blocked) {
+			return c.Deny(
+				fmt.Sprintf("Sub-agent type '%s' is blocked", subagentType),
+				"Use one of the other agent types, or do the work directly.",
+			).WithRule(RuleSubagentBlocked).WithPattern(blocked)
+		}
+	}
+
+	text := strings.Join([]string{subagentType, description, prompt}, "\n")
+	for _, item := range c.patterns {
+		if match := item.pattern.FindString(text); match != "" {
+			return c.Deny(
+				fmt.Sprintf("Sub-agent prompt asks to get around the guardian: %s", item.description),
+				fmt.Sprintf("Matched %q. A sub-agent is bound by the same rules; do not delegate blocked operations. Ask the user instead.", truncate(match, 80)),
+			).WithRule(RuleSubagentBlocked).WithPattern(item.pattern.String())
+		}
+	}
+
+	if c.directoryCheck != nil {
+		for _, m := range promptPathPattern.FindAllStringSubmatch(prompt, -1) {
+			path := strings.TrimRight(m[1], ".:")
+			if path == "/" || path == "~/" {
+				continue
+			}
+			if result := c.directoryCheck.CheckPath(path, "read"); !result.IsAllowed() {
+				return c.Ask(
+					fmt.Sprintf("Sub-agent prompt refers to a path outside the project: %s", path),
+					"The sub-agent would be blocked there too. Confirm with the user, or give them the commands to run.",
+				).WithRule(RuleSubagentOutside)
+			}
+		}
+	}
+
+	return c.Allow()
+}
@@ -0,0 +1,53 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TamperCheck denies commands that look for the guardian or try to switch
+// it off: searching for its hook, reading the hooks section of settings,
+// killing its process, moving its binary, changing CLAUDE_PROJECT_DIR.
+// Reconnaissance against the guardrail usually comes right before an
+// attempt to get around it, so a hit is denied and reported like a decoy.
+type TamperCheck struct {
+	BaseCheck
+	enabled  bool
+	patterns []codePatternItem
+}
+
+// NewTamperCheck creates a new TamperCheck instance.
+func NewTamperCheck(e *Engine) *TamperCheck {
+	c := &TamperCheck{
+		BaseCheck: BaseCheck{CheckName: "tamper_check"},
+		enabled:   e.Config.TamperDetection.Enabled,
+	}
+	for _, item := range e.Config.TamperDetection.Patterns {
+		pattern := item.Pattern
+		if !strings.HasPrefix(pattern, "(?") {
+			pattern = "(?i)" + pattern
+		}
+		if re := e.compile(pattern); re != nil {
+			c.patterns = append(c.patterns, codePatternItem{pattern: re, description: item.Description})
+		}
+	}
+	return c
+}
+
+// CheckCommand checks the raw command against tamper_detection.patterns.
+func (c *TamperCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if !c.enabled {
+		return c.Allow()
+	}
+
+	for _, item := range c.patterns {
+		if match := item.pattern.FindString(rawCommand); match != "" {
+			return c.Deny(
+				fmt.Sprintf("Command probes or disables the guardian: %s", item.description),
+				fmt.Sprintf("Matched %q. The security hooks are the user's; do not look for them or try to turn them off. If a rule blocks the task, ask the user.", truncate(match, 80)),
+			).WithRule(RuleTamperProbe).WithPattern(item.pattern.String())
+		}
+	}
+
+	return c.Allow()
+}
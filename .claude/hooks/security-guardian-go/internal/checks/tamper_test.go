@@ -0,0 +1,48 @@
+package checks
+
+import (
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+func TestTamperCheck(t *testing.T) {
+	check := NewTamperCheck(NewEngine(config.DefaultConfig()))
+
+	tests := []struct {
+		command string
+		want    PermissionDecision
+	}{
+		// Probing the Claude config for the hook
+		{`grep -r security-guardian ~/.claude`, DecisionDeny},
+		{`rg -i PreToolUse .claude/settings.json`, DecisionDeny},
+		{`find ~/.claude -name '*security-guardian*'`, DecisionDeny},
+		{`grep hooks ~/.claude/settings.json`, DecisionDeny},
+		{`cat .claude/settings.json | jq .hooks`, DecisionDeny},
+
+		// Stopping or disabling it
+		{`pkill -f guardian`, DecisionDeny},
+		{`mv .claude/hooks/bin/guardian /tmp/g`, DecisionDeny},
+		{`unset CLAUDE_PROJECT_DIR`, DecisionDeny},
+		{`CLAUDE_PROJECT_DIR=/ ls`, DecisionDeny},
+
+		// Searching a source tree that mentions the guardian
+		{`grep -r security-guardian .`, DecisionAllow},
+		{`rg security-guardian`, DecisionAllow},
+		{`rg -n PreToolUse docs/`, DecisionAllow},
+		{`find . -name '*security-guardian*'`, DecisionAllow},
+		{`grep -r security-guardian . | grep -v .claude`, DecisionAllow},
+		{`ls .claude`, DecisionAllow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Errorf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+			if tt.want == DecisionDeny && result.RuleID != RuleTamperProbe {
+				t.Errorf("rule = %s, want %s", result.RuleID, RuleTamperProbe)
+			}
+		})
+	}
+}
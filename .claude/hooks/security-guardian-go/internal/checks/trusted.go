@@ -0,0 +1,20 @@
+package checks
+
+import (
+	"github.com/artwist-polyakov/security-guardian/internal/trust"
+)
+
+// loadTrustedScripts loads the trusted script manifest, or returns nil if
+// the feature is disabled or the manifest is unreadable (an ErrorConfig).
+func loadTrustedScripts(e *Engine) *trust.Manifest {
+	if !e.Config.TrustedScripts.Enabled {
+		return nil
+	}
+	path := trust.ManifestPath(e.Config, e.ProjectRoot)
+	m, err := trust.Load(path)
+	if err != nil {
+		e.Errorf(ErrorConfig, "trusted scripts manifest %s: %v", path, err)
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// UnattendedNetworkCheck limits the network to unattended.allowed_domains
+// while the run is unattended (config.IsUnattended). Every URL in a
+// command is checked, inline code included; curl, wget, ssh and the like
+// must name a host that can be checked.
+type UnattendedNetworkCheck struct {
+	BaseCheck
+	config *config.SecurityConfig
+	active bool
+}
+
+// NewUnattendedNetworkCheck creates a new UnattendedNetworkCheck instance.
+func NewUnattendedNetworkCheck(e *Engine) *UnattendedNetworkCheck {
+	return &UnattendedNetworkCheck{
+		BaseCheck: BaseCheck{CheckName: "unattended_check"},
+		config:    e.Config,
+		active:    config.IsUnattended(e.Config),
+	}
+}
+
+// urlHostPattern finds the host of scheme://[user@]host URLs.
+var urlHostPattern = regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://(?:[^@/\s'"]*@)?(\[[0-9a-f:.]+\]|[^/\s'"?#:\[\]\\()<>;|&,` + "`" + `]+)`)
+
+// remoteShellCommands take the host as their first argument ([user@]host).
+var remoteShellCommands = map[string]bool{
+	"ssh": true, "sftp": true, "telnet": true, "ftp": true,
+	"nc": true, "ncat": true, "netcat": true,
+}
+
+// CheckCommand checks the hosts a command reaches.
+func (c *UnattendedNetworkCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	if !c.active {
+		return c.Allow()
+	}
+
+	for _, m := range urlHostPattern.FindAllStringSubmatch(rawCommand, -1) {
+		if result := c.checkHost(m[1]); !result.IsAllowed() {
+			return result
+		}
+	}
+
+	for _, cmd := range parsedCommands {
+		hosts, network := commandHosts(cmd)
+		if network && len(hosts) == 0 {
+			return c.Deny(
+				fmt.Sprintf("Network access to an unknown host while unattended: %s", cmd.Command),
+				"This run is unattended: network commands must name a host in unattended.allowed_domains. Leave this step for the user.",
+			).WithRule(RuleUnattendedNetwork).WithOrigin(cmd)
+		}
+		for _, host := range hosts {
+			if result := c.checkHost(host); !result.IsAllowed() {
+				return result.WithOrigin(cmd)
+			}
+		}
+	}
+
+	return c.Allow()
+}
+
+// CheckURL checks the host of a URL (WebFetch).
+func (c *UnattendedNetworkCheck) CheckURL(url string) *CheckResult {
+	if !c.active || url == "" {
+		return c.Allow()
+	}
+	m := urlHostPattern.FindStringSubmatch(url)
+	if m == nil {
+		return c.checkHost(url)
+	}
+	return c.checkHost(m[1])
+}
+
+// checkHost denies a host that is not in unattended.allowed_domains.
+func (c *UnattendedNetworkCheck) checkHost(host string) *CheckResult {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	for _, domain := range c.config.Unattended.AllowedDomains {
+		domain = strings.TrimPrefix(strings.ToLower(domain), "*.")
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return c.Allow()
+		}
+	}
+	return c.Deny(
+		fmt.Sprintf("Network access to a host not allowed while unattended: %s", host),
+		fmt.Sprintf("This run is unattended and %s is not in unattended.allowed_domains. Leave this step for the user.", host),
+	).WithRule(RuleUnattendedNetwork).WithPattern(host)
+}
+
+// commandHosts returns the hosts a command names (URLs, ssh [user@]host,
+// scp/rsync host:path), and whether the command talks to the network at
+// all. Flag values can't be told from arguments, so a download without a
+// scheme://host URL names no host.
+func commandHosts(cmd *ParsedCommand) (hosts []string, network bool) {
+	switch {
+	case downloadCommands[cmd.Command]:
+		for _, arg := range cmd.Args {
+			if host := urlHost(arg); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		return hosts, true
+	case remoteShellCommands[cmd.Command]:
+		// ssh -i key user@host: the argument with a user is the host
+		for _, arg := range cmd.Args {
+			if strings.Contains(arg, "@") {
+				return []string{remoteHost(arg)}, true
+			}
+		}
+		if len(cmd.Args) > 0 {
+			hosts = append(hosts, remoteHost(cmd.Args[0]))
+		}
+		return hosts, true
+	case cmd.Command == "scp" || cmd.Command == "rsync":
+		for _, arg := range cmd.Args {
+			if host := urlHost(arg); host != "" {
+				hosts = append(hosts, host)
+				network = true
+			} else if i := strings.Index(arg, ":"); i > 0 && !strings.ContainsAny(arg[:i], "/") {
+				hosts = append(hosts, remoteHost(arg[:i]))
+				network = true
+			}
+		}
+		return hosts, network
+	case networkCommands[cmd.Command]:
+		// socat and the like: addresses can't be told apart reliably
+		return nil, true
+	}
+	return nil, false
+}
+
+// urlHost returns the host of a scheme://host URL, or "".
+func urlHost(url string) string {
+	if m := urlHostPattern.FindStringSubmatch(url); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// remoteHost strips the user and port of [user@]host[:port].
+func remoteHost(target string) string {
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		target = target[i+1:]
+	}
+	return stripPort(target)
+}
+
+// stripPort drops a :port suffix (IPv6 addresses keep their colons).
+func stripPort(host string) string {
+	if strings.HasPrefix(host, "[") {
+		if end := strings.Index(host, "]"); end > 0 {
+			return host[1:end]
+		}
+	}
+	if strings.Count(host, ":") == 1 {
+		host, _, _ = strings.Cut(host, ":")
+	}
+	return host
+}
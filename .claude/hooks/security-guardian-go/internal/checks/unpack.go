@@ -14,6 +14,8 @@ type UnpackCheck struct {
 	projectRoot  string
 	allowedPaths []string
 	config       *config.SecurityConfig
+
+	downloadCheck *DownloadCheck
 }
 
 // Unpack commands
@@ -43,12 +45,12 @@ var securityBypassPatterns = []string{
 }
 
 // NewUnpackCheck creates a new UnpackCheck instance.
-func NewUnpackCheck(cfg *config.SecurityConfig) *UnpackCheck {
+func NewUnpackCheck(e *Engine) *UnpackCheck {
 	return &UnpackCheck{
 		BaseCheck:    BaseCheck{CheckName: "unpack_check"},
-		projectRoot:  parsers.GetProjectRoot(),
-		allowedPaths: cfg.Directories.AllowedPaths,
-		config:       cfg,
+		projectRoot:  e.ProjectRoot,
+		allowedPaths: e.Config.Directories.AllowedPaths,
+		config:       e.Config,
 	}
 }
 
@@ -60,7 +62,7 @@ func (c *UnpackCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCo
 			return c.Deny(
 				fmt.Sprintf("Security bypass pattern: %s", pattern),
 				fmt.Sprintf("%s can bypass path protection. Not allowed.", pattern),
-			)
+			).WithRule(RuleUnpackBypass).WithPattern(pattern)
 		}
 	}
 
@@ -70,7 +72,7 @@ func (c *UnpackCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCo
 			return c.Ask(
 				fmt.Sprintf("Blocked unpack pattern: %s", pattern),
 				fmt.Sprintf("Unpack to allowed directory only. Give user: `%s`", rawCommand),
-			)
+			).WithRule(RuleUnpackBlockedPattern).WithPattern(pattern)
 		}
 	}
 
@@ -103,12 +105,12 @@ func (c *UnpackCheck) checkUnpack(cmd *ParsedCommand, rawCommand string) *CheckR
 
 	if targetDir != "" {
 		// Check if target is outside project - ASK (user can confirm)
-		resolved := parsers.ResolvePath(targetDir, c.projectRoot)
+		resolved := parsers.ResolvePath(parsers.JoinDir(cmd.Dir, targetDir), c.baseDir(c.projectRoot))
 		if !parsers.IsPathWithinAllowed(resolved, c.projectRoot, c.allowedPaths) {
 			return c.Ask(
 				fmt.Sprintf("Unpack target outside project: %s", targetDir),
 				fmt.Sprintf("Cannot unpack outside project. Give user: `%s`", rawCommand),
-			)
+			).WithRule(RuleUnpackOutside)
 		}
 
 		// Check for path traversal - DENY (security bypass)
@@ -116,7 +118,7 @@ func (c *UnpackCheck) checkUnpack(cmd *ParsedCommand, rawCommand string) *CheckR
 			return c.Deny(
 				fmt.Sprintf("Path traversal in unpack target: %s", targetDir),
 				"Path traversal detected. This is a security bypass.",
-			)
+			).WithRule(RuleUnpackTraversal)
 		}
 	}
 
@@ -125,7 +127,7 @@ func (c *UnpackCheck) checkUnpack(cmd *ParsedCommand, rawCommand string) *CheckR
 		return c.Deny(
 			"bsdtar -s (substitution) can bypass path protection",
 			"bsdtar -s is blocked as it can bypass security.",
-		)
+		).WithRule(RuleUnpackBypass)
 	}
 
 	return c.Allow()
@@ -185,13 +187,13 @@ func (c *UnpackCheck) checkPythonUnpack(rawCommand string) *CheckResult {
 	for i, part := range parts {
 		if part == "-e" && i+2 < len(parts) {
 			targetDir := parts[i+2]
-			resolved := parsers.ResolvePath(targetDir, c.projectRoot)
+			resolved := parsers.ResolvePath(targetDir, c.baseDir(c.projectRoot))
 
 			if !parsers.IsPathWithinAllowed(resolved, c.projectRoot, c.allowedPaths) {
 				return c.Ask(
 					fmt.Sprintf("Python unpack target outside project: %s", targetDir),
 					fmt.Sprintf("Cannot unpack outside project. Give user: `%s`", rawCommand),
-				)
+				).WithRule(RuleUnpackOutside)
 			}
 		}
 	}
@@ -0,0 +1,133 @@
+package checks
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// WebSearchCheck keeps secrets and internal names out of search queries,
+// which leave the machine in plain text.
+type WebSearchCheck struct {
+	BaseCheck
+	projectRoot  string
+	config       *config.SecurityConfig
+	patterns     []codePatternItem
+	secretsCheck *SecretsCheck
+}
+
+// Env var names that hold secrets even when not listed in secret_env_vars
+var secretEnvNamePattern = regexp.MustCompile(`(?i)(api_?key|token|secret|passw|credential|private_key)`)
+
+// Values shorter than this are too likely to appear by chance
+const minSecretValueLength = 8
+
+// NewWebSearchCheck creates a new WebSearchCheck instance.
+func NewWebSearchCheck(e *Engine) *WebSearchCheck {
+	c := &WebSearchCheck{
+		BaseCheck:   BaseCheck{CheckName: "web_search_check"},
+		projectRoot: e.ProjectRoot,
+		config:      e.Config,
+	}
+	for _, item := range e.Config.WebSearch.BlockedPatterns {
+		pattern := item.Pattern
+		if !strings.HasPrefix(pattern, "(?") {
+			pattern = "(?i)" + pattern
+		}
+		if re := e.compile(pattern); re != nil {
+			c.patterns = append(c.patterns, codePatternItem{pattern: re, description: item.Description})
+		}
+	}
+	return c
+}
+
+// SetSecretsCheck sets the check whose no_read rules select secrets files.
+func (c *WebSearchCheck) SetSecretsCheck(sc *SecretsCheck) {
+	c.secretsCheck = sc
+}
+
+// CheckCommand is not used - see CheckQuery.
+func (c *WebSearchCheck) CheckCommand(rawCommand string, parsedCommands []*ParsedCommand) *CheckResult {
+	return c.Allow()
+}
+
+// CheckQuery checks a search query for secret values and blocked patterns.
+func (c *WebSearchCheck) CheckQuery(query string) *CheckResult {
+	if !c.config.WebSearch.Enabled || query == "" {
+		return c.Allow()
+	}
+
+	for name, value := range c.secretValues() {
+		if strings.Contains(query, value) {
+			return c.Deny(
+				fmt.Sprintf("Search query contains the value of %s", name),
+				"Secrets must not be sent to a search engine. Search for the error or topic without the value.",
+			).WithRule(RuleWebSearchSecret)
+		}
+	}
+
+	for _, item := range c.patterns {
+		if match := item.pattern.FindString(query); match != "" {
+			return c.Deny(
+				fmt.Sprintf("Search query contains %s: %s", strings.ToLower(item.description), match),
+				"Internal names must not be sent to a search engine. Rephrase the query without them.",
+			).WithRule(RuleWebSearchBlockedPattern).WithPattern(item.pattern.String())
+		}
+	}
+
+	return c.Allow()
+}
+
+// secretValues returns secret values by source name: secret env vars of the
+// environment and KEY=VALUE entries of no_read files in the project root.
+func (c *WebSearchCheck) secretValues() map[string]string {
+	values := make(map[string]string)
+	add := func(name, value string) {
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if len(value) >= minSecretValueLength {
+			values[name] = value
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, name := range c.config.SensitiveFiles.SecretEnvVars {
+		listed[name] = true
+	}
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && (listed[name] || secretEnvNamePattern.MatchString(name)) {
+			add("$"+name, value)
+		}
+	}
+
+	if c.secretsCheck == nil {
+		return values
+	}
+	entries, err := os.ReadDir(c.projectRoot)
+	if err != nil {
+		return values
+	}
+	for _, e := range entries {
+		if e.IsDir() || !c.secretsCheck.matchesNoRead(e.Name()) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(c.projectRoot, e.Name()))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "export ")
+			if key, value, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(key, "#") {
+				add(fmt.Sprintf("%s (%s)", strings.TrimSpace(key), e.Name()), value)
+			}
+		}
+		f.Close()
+	}
+	return values
+}
@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// Whitelist matches commands against pre-approved entries from config.
+// A whitelisted command short-circuits all other checks.
+type Whitelist struct {
+	projectRoot  string
+	allowedPaths []string
+	workDir      string
+	entries      []config.WhitelistEntry
+}
+
+// NewWhitelist creates a new Whitelist instance.
+func NewWhitelist(e *Engine) *Whitelist {
+	projectRoot := e.BoundaryRoot
+
+	return &Whitelist{
+		projectRoot:  projectRoot,
+		allowedPaths: e.Config.Directories.AllowedPaths,
+		entries:      e.Config.Whitelist,
+	}
+}
+
+// SetWorkDir sets the directory relative arguments are resolved against.
+func (w *Whitelist) SetWorkDir(dir string) {
+	w.workDir = dir
+}
+
+// Matches returns true if the whole command line is pre-approved:
+// either it equals an exact template, or every parsed command
+// (including piped and substituted ones) matches a command entry.
+func (w *Whitelist) Matches(rawCommand string, parsedCommands []*ParsedCommand) bool {
+	if len(w.entries) == 0 {
+		return false
+	}
+
+	normalized := strings.Join(strings.Fields(rawCommand), " ")
+	for _, e := range w.entries {
+		if e.Exact != "" && strings.Join(strings.Fields(e.Exact), " ") == normalized {
+			return true
+		}
+	}
+
+	if len(parsedCommands) == 0 {
+		return false
+	}
+	for _, cmd := range parsedCommands {
+		if !w.matchesCommand(cmd) {
+			return false
+		}
+		for next := cmd.PipesTo; next != nil; next = next.PipesTo {
+			if !w.matchesCommand(next) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesCommand checks a single command against command entries.
+func (w *Whitelist) matchesCommand(cmd *ParsedCommand) bool {
+	if cmd.VariableAsCommand {
+		return false
+	}
+	for _, e := range w.entries {
+		if e.Command == "" || e.Command != cmd.Command {
+			continue
+		}
+		if w.matchesEntry(cmd, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesEntry checks flags and argument constraints of one entry.
+func (w *Whitelist) matchesEntry(cmd *ParsedCommand, e config.WhitelistEntry) bool {
+	if len(e.Flags) > 0 {
+		allowed := expandFlags(e.Flags)
+		for f := range expandFlags(cmd.Flags) {
+			if !allowed[f] {
+				return false
+			}
+		}
+	}
+
+	for _, arg := range cmd.Args {
+		// Unresolvable expansions can't be verified against constraints
+		if strings.Contains(arg, "$") {
+			return false
+		}
+
+		if len(e.Args) > 0 && !matchesAnyGlob(arg, e.Args) {
+			return false
+		}
+
+		if e.ArgsWithin != "" && !w.argWithin(parsers.JoinDir(cmd.Dir, arg), e.ArgsWithin, e.MaxDepth) {
+			return false
+		}
+	}
+
+	// Redirect targets stay in the project or allowed_paths whatever the
+	// entry says (a bare `echo` must not write ~/.bashrc), and within
+	// args_within when it is set.
+	for _, target := range cmd.Redirects {
+		if strings.Contains(target, "$") || !w.redirectAllowed(parsers.JoinDir(cmd.Dir, target)) {
+			return false
+		}
+		if e.ArgsWithin != "" && !w.argWithin(parsers.JoinDir(cmd.Dir, target), e.ArgsWithin, e.MaxDepth) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redirectAllowed checks that a redirect target resolves inside the
+// project or allowed_paths, as the directory check would.
+func (w *Whitelist) redirectAllowed(target string) bool {
+	if containsGlob(target) {
+		return false
+	}
+
+	baseDir := w.workDir
+	if baseDir == "" {
+		baseDir = w.projectRoot
+	}
+
+	if parsers.IsSymlinkEscape(target, w.projectRoot, baseDir) {
+		return false
+	}
+	return parsers.IsPathWithinAllowed(parsers.ResolvePath(target, baseDir), w.projectRoot, w.allowedPaths)
+}
+
+// argWithin checks that an argument resolves inside the given scope
+// ("project" or a path relative to project root) at most maxDepth levels deep.
+func (w *Whitelist) argWithin(arg string, scope string, maxDepth int) bool {
+	if containsGlob(arg) {
+		return false
+	}
+
+	root := w.projectRoot
+	if scope != "project" {
+		root = parsers.ResolvePath(scope, w.projectRoot)
+	}
+
+	baseDir := w.workDir
+	if baseDir == "" {
+		baseDir = w.projectRoot
+	}
+
+	if parsers.IsSymlinkEscape(arg, w.projectRoot, baseDir) {
+		return false
+	}
+
+	resolved := parsers.ResolvePath(arg, baseDir)
+	rel, err := relPath(root, resolved)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		// Outside scope, or the scope root itself (rm -rf . is never pre-approved)
+		return false
+	}
+
+	if maxDepth > 0 && len(strings.Split(rel, "/")) > maxDepth {
+		return false
+	}
+
+	return true
+}
+
+// matchesAnyGlob checks if s matches the glob patterns (last match wins,
+// "!pattern" excludes).
+func matchesAnyGlob(s string, patterns []string) bool {
+	_, ok := globs.MatchList(patterns, s)
+	return ok
+}
@@ -0,0 +1,105 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// parseForCheck parses command the way the bash handler does.
+func parseForCheck(command string) []*ParsedCommand {
+	return parsers.ParseBashCommand(command)
+}
+
+func TestWhitelistMatches(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"build/cache", "a/b/c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/etc", filepath.Join(root, "etc-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	cfg.Whitelist = []config.WhitelistEntry{
+		{Exact: "rm -rf node_modules"},
+		{Command: "rm", Flags: []string{"-r", "-f"}, ArgsWithin: "project", MaxDepth: 2},
+		{Command: "git", Args: []string{"status", "log", "diff"}},
+		{Command: "go", Args: []string{"test", "./..."}},
+		{Command: "grep"},
+		{Command: "echo"},
+	}
+	w := NewWhitelist(NewEngine(cfg))
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		// Exact templates ignore extra whitespace
+		{"rm -rf node_modules", true},
+		{"rm  -rf   node_modules", true},
+		{"sudo rm -rf node_modules", false},
+
+		// Flags and args_within constraints
+		{"rm -rf build", true},
+		{"rm -r -f build/cache", true},
+		{"rm -rfv build", false},
+		{"rm -rf a/b/c", false},
+		{"rm -rf .", false},
+		{"rm -rf ../other", false},
+		{"rm -rf /tmp/x", false},
+		{"rm -rf build/*", false},
+		{"rm -rf $HOME/build", false},
+		{"rm -rf etc-link/passwd", false},
+
+		// Argument globs
+		{"git status", true},
+		{"git diff", true},
+		{"git push", false},
+		{"go test ./...", true},
+		{"go run main.go", false},
+
+		// Every command in a pipeline or chain must match
+		{"git log | grep fix", true},
+		{"git status && go test ./...", true},
+		{"git status && curl https://example.com", false},
+		{"git log | sh", false},
+
+		// Redirect targets stay in the project, whatever the entry allows
+		{"git status > status.txt", true},
+		{"grep x > out/x.txt", true},
+		{"echo x > ~/.bashrc", false},
+		{"echo x >> /etc/hosts", false},
+		{"grep x >> ~/.bashrc", false},
+		{"grep x > ../out.txt", false},
+		{"grep x > etc-link/hosts", false},
+		{"grep x > $HOME/out.txt", false},
+		{"rm -rf build > /tmp/log", false},
+
+		// Not whitelisted
+		{"ls", false},
+		{"$CMD status", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := w.Matches(tt.command, parseForCheck(tt.command)); got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhitelistEmpty(t *testing.T) {
+	cfg := config.DefaultConfig()
+	w := NewWhitelist(NewEngine(cfg))
+	if w.Matches("ls", parseForCheck("ls")) {
+		t.Error("empty whitelist matched")
+	}
+}
@@ -0,0 +1,106 @@
+package checks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// newWorkDirProject creates a project with sub/deep inside a temporary
+// directory and returns its root.
+func newWorkDirProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub", "deep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// TestDirectoryCheckWorkDir checks relative paths against the hook's cwd
+// and the cd/pushd/popd before them.
+func TestDirectoryCheckWorkDir(t *testing.T) {
+	tests := []struct {
+		cwd     string // relative to the project root
+		command string
+		want    PermissionDecision
+	}{
+		{"", `cat file`, DecisionAllow},
+		{"", `cat ../file`, DecisionDeny},
+		{"sub", `cat ../file`, DecisionAllow},
+		{"sub", `cat ../..`, DecisionDeny},
+		{"sub/deep", `cat ../../file`, DecisionAllow},
+		{"sub/deep", `cat ../../../file`, DecisionDeny},
+		{"sub", `cat /etc/hosts`, DecisionDeny},
+		{"", `cd sub && cat ../file`, DecisionAllow},
+		{"", `cd sub && cat ../..`, DecisionDeny},
+		{"", `cd sub/deep && cat ../../file`, DecisionAllow},
+		{"", `cd sub/deep && cat ../../../file`, DecisionDeny},
+		{"", `cd sub && cd .. && cat ../file`, DecisionDeny},
+		{"sub", `cd .. && cat file`, DecisionAllow},
+		{"sub", `cd .. && cat ../file`, DecisionDeny},
+		{"sub", `cd deep && cat ../../file`, DecisionAllow},
+		{"", `pushd sub && cat ../file && popd`, DecisionAllow},
+		{"", `pushd sub && popd && cat ../file`, DecisionDeny},
+		{"", `pushd sub && pushd deep && popd && cat ../file`, DecisionAllow},
+		{"", `(cd sub && cat ../file) && cat ../file`, DecisionDeny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cwd+": "+tt.command, func(t *testing.T) {
+			root := newWorkDirProject(t)
+			cfg := config.DefaultConfig()
+			t.Setenv("CLAUDE_PROJECT_DIR", root)
+			cfg.Directories.ProjectRoot = root
+			check := NewDirectoryCheck(NewEngine(cfg))
+			if tt.cwd != "" {
+				check.SetWorkDir(filepath.Join(root, tt.cwd))
+			}
+			result := check.CheckCommand(tt.command, parseForCheck(tt.command))
+			if got := result.PermissionDecisionValue(); got != tt.want {
+				t.Errorf("decision = %s (%s), want %s", got, result.Reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectoryCheckPathWorkDir(t *testing.T) {
+	root := newWorkDirProject(t)
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	check := NewDirectoryCheck(NewEngine(cfg))
+	check.SetWorkDir(filepath.Join(root, "sub"))
+
+	if got := check.CheckPath("../file", "read").PermissionDecisionValue(); got != DecisionAllow {
+		t.Errorf("../file from sub = %s, want allow", got)
+	}
+	if got := check.CheckPath("../../file", "read").PermissionDecisionValue(); got != DecisionDeny {
+		t.Errorf("../../file from sub = %s, want deny", got)
+	}
+
+	// Without a working directory, paths resolve against the project root
+	check.SetWorkDir("")
+	if got := check.CheckPath("../file", "read").PermissionDecisionValue(); got != DecisionDeny {
+		t.Errorf("../file from root = %s, want deny", got)
+	}
+}
+
+func TestWhitelistWorkDir(t *testing.T) {
+	root := newWorkDirProject(t)
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	cfg.Whitelist = []config.WhitelistEntry{{Command: "rm", ArgsWithin: "sub"}}
+	w := NewWhitelist(NewEngine(cfg))
+
+	command := "rm deep"
+	if w.Matches(command, parseForCheck(command)) {
+		t.Error("rm deep matched from the project root")
+	}
+	w.SetWorkDir(filepath.Join(root, "sub"))
+	if !w.Matches(command, parseForCheck(command)) {
+		t.Error("rm deep didn't match from sub")
+	}
+}
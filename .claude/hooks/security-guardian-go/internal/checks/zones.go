@@ -0,0 +1,61 @@
+package checks
+
+import (
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// Zone policies for path-scoped zones inside the project.
+const (
+	// ZoneDefault applies the regular checks.
+	ZoneDefault = ""
+	// ZonePermissive skips content heuristics (ask-level) for files in the zone.
+	ZonePermissive = "permissive"
+	// ZoneStrict requires confirmation for every modification in the zone.
+	ZoneStrict = "strict"
+)
+
+// Zones resolves which policy zone a path belongs to.
+type Zones struct {
+	projectRoot string
+	zones       []config.ZoneConfig
+}
+
+// NewZones creates a new Zones instance.
+func NewZones(cfg *config.SecurityConfig, projectRoot string) *Zones {
+	return &Zones{
+		projectRoot: projectRoot,
+		zones:       cfg.Zones,
+	}
+}
+
+// PolicyFor returns the zone policy for a path (relative paths resolve
+// against baseDir). Zone patterns are relative to project root; the last
+// matching zone wins.
+func (z *Zones) PolicyFor(path string, baseDir string) string {
+	if len(z.zones) == 0 || path == "" {
+		return ZoneDefault
+	}
+
+	resolved := parsers.ResolvePath(path, baseDir)
+	rel, err := relPath(z.projectRoot, resolved)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ZoneDefault
+	}
+
+	return z.policyForRel(rel)
+}
+
+// policyForRel returns the zone policy for a path relative to project root.
+func (z *Zones) policyForRel(rel string) string {
+	policy := ZoneDefault
+	for _, zone := range z.zones {
+		if globs.Match(zone.Path, rel) {
+			policy = strings.ToLower(zone.Policy)
+		}
+	}
+	return policy
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The schema's own source: the comments of its types and fields are the
+// documentation of the config keys, so they can't drift apart.
+//
+//go:embed schema.go
+var schemaSource []byte
+
+// KeyDoc documents one config key.
+type KeyDoc struct {
+	Key     string `json:"key"`               // dotted path; items of a list of sections add []
+	Type    string `json:"type"`              // Go type, "section" for a nested mapping
+	Default string `json:"default,omitempty"` // the DefaultConfig value
+	Doc     string `json:"doc,omitempty"`     // the comment of the field in schema.go
+}
+
+// Reference returns every config key with its type, default and comment,
+// in the order of the schema.
+func Reference() []KeyDoc {
+	var docs []KeyDoc
+	walkKeys(reflect.ValueOf(DefaultConfig()).Elem(), "", schemaComments(), &docs)
+	return docs
+}
+
+// DefaultYAML returns DefaultConfig as YAML, with the comments of the
+// schema above each key when comments is set.
+func DefaultYAML(comments bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(DefaultConfig()); err != nil {
+		return nil, err
+	}
+	if comments {
+		annotate(&doc, reflect.TypeOf(SecurityConfig{}), schemaComments())
+	}
+
+	var buf bytes.Buffer
+	if comments {
+		buf.WriteString("# Security Guardian built-in defaults (guardian config print-default --comments).\n")
+		buf.WriteString("# Keys left out of security_config.yaml take these values.\n\n")
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var yamlNodeType = reflect.TypeOf(yaml.Node{})
+
+// walkKeys appends the keys of struct v under prefix to docs.
+func walkKeys(v reflect.Value, prefix string, comments map[string]string, docs *[]KeyDoc) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, ok := yamlName(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			walkKeys(v.Field(i), prefix, comments, docs)
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		doc := fieldDoc(comments, t, f, name)
+
+		switch ft := f.Type; {
+		case ft.Kind() == reflect.Struct && ft != yamlNodeType:
+			if doc == "" {
+				doc = comments[ft.Name()]
+			}
+			*docs = append(*docs, KeyDoc{Key: key, Type: "section", Doc: doc})
+			walkKeys(v.Field(i), key, comments, docs)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			if doc == "" {
+				doc = comments[ft.Elem().Name()]
+			}
+			*docs = append(*docs, KeyDoc{Key: key, Type: typeName(ft), Default: describeDefault(v.Field(i)), Doc: doc})
+			walkKeys(reflect.New(ft.Elem()).Elem(), key+"[]", comments, docs)
+		default:
+			*docs = append(*docs, KeyDoc{Key: key, Type: typeName(ft), Default: describeDefault(v.Field(i)), Doc: doc})
+		}
+	}
+}
+
+// annotate sets the comments of the fields of struct type t as head
+// comments of the keys in mapping node.
+func annotate(node *yaml.Node, t reflect.Type, comments map[string]string) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		annotate(node.Content[0], t, comments)
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		f, owner, ok := fieldByYAMLName(t, key.Value)
+		if !ok {
+			continue
+		}
+		doc := fieldDoc(comments, owner, f, key.Value)
+		switch ft := f.Type; {
+		case ft.Kind() == reflect.Struct && ft != yamlNodeType:
+			if doc == "" {
+				doc = comments[ft.Name()]
+			}
+			annotate(value, ft, comments)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			for _, item := range value.Content {
+				annotate(item, ft.Elem(), comments)
+			}
+		}
+		if doc != "" {
+			key.HeadComment = wrapComment(doc, 76)
+		}
+	}
+}
+
+// fieldDoc returns the comment of field f of struct type t, with the Go
+// name it starts with replaced by the key.
+func fieldDoc(comments map[string]string, t reflect.Type, f reflect.StructField, key string) string {
+	doc := comments[t.Name()+"."+f.Name]
+	if strings.HasPrefix(doc, f.Name+" ") {
+		doc = key + doc[len(f.Name):]
+	}
+	return doc
+}
+
+// fieldByYAMLName returns the field of struct type t with yaml name and
+// the struct declaring it, looking into inline structs.
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		n, inline, ok := yamlName(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			if field, owner, ok := fieldByYAMLName(f.Type, name); ok {
+				return field, owner, true
+			}
+			continue
+		}
+		if n == name {
+			return f, t, true
+		}
+	}
+	return reflect.StructField{}, nil, false
+}
+
+// yamlName returns the key of a struct field as yaml.v3 encodes it, and
+// whether the field is inlined. Unexported and "-" fields have no key.
+func yamlName(f reflect.StructField) (string, bool, bool) {
+	if f.PkgPath != "" {
+		return "", false, false
+	}
+	tag := strings.Split(f.Tag.Get("yaml"), ",")
+	if tag[0] == "-" {
+		return "", false, false
+	}
+	for _, opt := range tag[1:] {
+		if opt == "inline" {
+			return "", true, true
+		}
+	}
+	if tag[0] == "" {
+		return strings.ToLower(f.Name), false, true
+	}
+	return tag[0], false, true
+}
+
+// typeName shows a Go type without the package name.
+func typeName(t reflect.Type) string {
+	return strings.ReplaceAll(t.String(), "config.", "")
+}
+
+// describeDefault shows a default value on one line: JSON for scalars
+// and short lists, a count for longer ones.
+func describeDefault(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.Len() == 0 {
+			if v.Kind() == reflect.Map {
+				return "{}"
+			}
+			return "[]"
+		}
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+			return fmt.Sprintf("%d entries", v.Len())
+		}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	if s := string(data); len(s) <= 60 {
+		return s
+	}
+	return fmt.Sprintf("%d entries", v.Len())
+}
+
+// schemaComments returns the comments of schema.go by "Type" and
+// "Type.Field": the doc comment above, then the comment after.
+func schemaComments() map[string]string {
+	comments := make(map[string]string)
+	file, err := parser.ParseFile(token.NewFileSet(), "schema.go", schemaSource, parser.ParseComments)
+	if err != nil {
+		return comments
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if text := commentText(doc, nil); text != "" {
+				comments[ts.Name.Name] = text
+			}
+			for _, field := range st.Fields.List {
+				text := commentText(field.Doc, field.Comment)
+				for _, name := range field.Names {
+					if text != "" {
+						comments[ts.Name.Name+"."+name.Name] = text
+					}
+				}
+			}
+		}
+	}
+	return comments
+}
+
+// commentText joins a doc comment and a line comment into one paragraph.
+func commentText(groups ...*ast.CommentGroup) string {
+	var parts []string
+	for _, g := range groups {
+		if g == nil {
+			continue
+		}
+		if text := strings.Join(strings.Fields(g.Text()), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// wrapComment breaks text into lines of at most width characters.
+func wrapComment(text string, width int) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
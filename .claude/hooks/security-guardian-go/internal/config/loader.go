@@ -1,9 +1,14 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,15 +26,30 @@ func expandConfigEnvVars(config *SecurityConfig) {
 		config.Directories.AllowedPaths[i] = expandEnvVars(config.Directories.AllowedPaths[i])
 	}
 
+	config.StateDirectory = expandEnvVars(config.StateDirectory)
+
 	// Expand download protection
 	config.DownloadProtection.DownloadedFilesMetadata = expandEnvVars(config.DownloadProtection.DownloadedFilesMetadata)
 
+	// Expand mass modification
+	config.MassModification.StateFile = expandEnvVars(config.MassModification.StateFile)
+
+	// Expand trash
+	config.Trash.Directory = expandEnvVars(config.Trash.Directory)
+	config.TrustedScripts.Manifest = expandEnvVars(config.TrustedScripts.Manifest)
+	config.Decoys.Registry = expandEnvVars(config.Decoys.Registry)
+	config.RememberApprovals.Store = expandEnvVars(config.RememberApprovals.Store)
+	config.SessionSummary.Store = expandEnvVars(config.SessionSummary.Store)
+	config.Explain.Store = expandEnvVars(config.Explain.Store)
+
 	// Expand logging
 	config.Logging.LogDirectory = expandEnvVars(config.Logging.LogDirectory)
 }
 
 // LoadConfig loads security configuration from a YAML file.
 // If configPath is empty, it looks for security_config.yaml in the same directory as the executable.
+// A missing file gives the defaults; a file that can't be read or parsed
+// gives the defaults with the error, for the caller to report.
 func LoadConfig(configPath string) (*SecurityConfig, error) {
 	if configPath == "" {
 		// Try to find config relative to executable
@@ -52,8 +72,7 @@ func LoadConfig(configPath string) (*SecurityConfig, error) {
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		// Return default config on read error
-		return DefaultConfig(), nil
+		return DefaultConfig(), err
 	}
 
 	// Start with defaults
@@ -61,16 +80,29 @@ func LoadConfig(configPath string) (*SecurityConfig, error) {
 
 	// Parse YAML into config
 	if err := yaml.Unmarshal(data, config); err != nil {
-		// Return default config on parse error
-		return DefaultConfig(), nil
+		return DefaultConfig(), err
 	}
 
-	// Expand environment variables
+	// Profiles of this user and machine, then environment variables
+	applyOverrides(config)
 	expandConfigEnvVars(config)
+	config.Version = Fingerprint(data)
 
 	return config, nil
 }
 
+// strictConfigRe finds strict_config turned on at the top level of a
+// config file.
+var strictConfigRe = regexp.MustCompile(`(?m)^strict_config:[ \t]*(?:true|True|TRUE)[ \t]*(?:#.*)?$`)
+
+// StrictConfigSet reports whether the config file at path turns
+// strict_config on. It reads the file line by line, so it answers for a
+// file LoadConfig fails to parse; a file that can't be read doesn't.
+func StrictConfigSet(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strictConfigRe.Match(data)
+}
+
 // LoadConfigFromBytes loads configuration from YAML bytes.
 func LoadConfigFromBytes(data []byte) (*SecurityConfig, error) {
 	config := DefaultConfig()
@@ -79,11 +111,20 @@ func LoadConfigFromBytes(data []byte) (*SecurityConfig, error) {
 		return nil, err
 	}
 
+	applyOverrides(config)
 	expandConfigEnvVars(config)
+	config.Version = Fingerprint(data)
 
 	return config, nil
 }
 
+// Fingerprint identifies a config file's content: the first 12 hex digits
+// of its sha256.
+func Fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 // FindConfigPath looks for configuration file in common locations.
 func FindConfigPath() string {
 	// Check environment variable
@@ -186,6 +227,83 @@ func IsInCIEnvironment() bool {
 	return false
 }
 
+// IsYoloMode reports whether ASK decisions must be elevated to DENY.
+// SECURITY_GUARDIAN_YOLO_MODE overrides the configured mode. In "auto" mode
+// the hook's permission_mode decides: "bypassPermissions" is YOLO, any other
+// known mode has interactive prompts. An empty permission_mode (older Claude
+// Code versions) is treated as YOLO, since that is what the hook was built for.
+func IsYoloMode(configMode string, permissionMode string) bool {
+	mode := configMode
+	if env := os.Getenv("SECURITY_GUARDIAN_YOLO_MODE"); env != "" {
+		mode = env
+	}
+
+	switch strings.ToLower(mode) {
+	case YoloModeOn, "true", "1":
+		return true
+	case YoloModeOff, "false", "0":
+		return false
+	}
+
+	return permissionMode == "" || permissionMode == "bypassPermissions"
+}
+
+// IsUnattended reports whether nobody is watching the run: the
+// unattended.env variable is 1 (or true/yes/on), or the local time is in
+// one of unattended.hours. Unattended runs get no confirmations, like YOLO
+// mode, and reach only unattended.allowed_domains over the network.
+func IsUnattended(cfg *SecurityConfig) bool {
+	u := cfg.Unattended
+	if u.Env != "" {
+		switch strings.ToLower(os.Getenv(u.Env)) {
+		case "1", "true", "yes", "on":
+			return true
+		}
+	}
+
+	now := time.Now()
+	minute := now.Hour()*60 + now.Minute()
+	for _, window := range u.Hours {
+		start, end, err := ParseTimeWindow(window)
+		if err != nil {
+			continue
+		}
+		if start <= end && minute >= start && minute < end {
+			return true
+		}
+		// Past midnight: 22:00-07:00
+		if start > end && (minute >= start || minute < end) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTimeWindow parses "HH:MM-HH:MM" into minutes after midnight. The
+// end may be earlier than the start for a window past midnight.
+func ParseTimeWindow(window string) (start, end int, err error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q is not HH:MM-HH:MM", window)
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", strings.TrimSpace(s))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
 // ExpandPath expands ~ and environment variables in a path.
 func ExpandPath(path string) string {
 	// Expand ~
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // "": no file
+		wantErr bool
+		strict  bool
+	}{
+		{"missing", "", false, false},
+		{"valid", "strict_config: true\n", false, true},
+		{"malformed", "a: [\n", true, false},
+		{"wrong type", "strict_config: [1]\n", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "security_config.yaml")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg, err := LoadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if cfg == nil {
+				t.Fatal("cfg = nil, want the defaults at least")
+			}
+			if cfg.StrictConfig != tt.strict {
+				t.Errorf("StrictConfig = %v, want %v", cfg.StrictConfig, tt.strict)
+			}
+		})
+	}
+}
+
+func TestStrictConfigSet(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"strict_config: true\n", true},
+		{"a: [\nstrict_config: true # fail closed\n", true},
+		{"strict_config:   True\n", true},
+		{"strict_config: false\na: [\n", false},
+		{"# strict_config: true\na: [\n", false},
+		{"nested:\n  strict_config: true\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "security_config.yaml")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := StrictConfigSet(path); got != tt.want {
+			t.Errorf("StrictConfigSet(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+	if StrictConfigSet(filepath.Join(t.TempDir(), "missing.yaml")) {
+		t.Error("StrictConfigSet(missing file) = true")
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migrationHeader starts a config written by MigratePython.
+const migrationHeader = `Migrated from the Python guardian config by guardian migrate-config.
+Options the Python version doesn't have use their built-in defaults;
+see the shipped security_config.yaml for all of them.`
+
+// MigratePython converts a config of the Python guardian
+// (.claude/hooks/security-guardian/config/security_config.yaml) into one
+// for this version. Comments and key order are kept. Keys this version
+// doesn't know are dropped; each change is described in warnings.
+func MigratePython(data []byte) (out []byte, warnings []string, err error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config is not a mapping")
+	}
+
+	warnings = migrateKeys(root, "", reflect.ValueOf(DefaultConfig()).Elem())
+
+	// Sections the Python version has no counterpart for
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		set[root.Content[i].Value] = true
+	}
+	var missing []string
+	t := reflect.TypeOf(SecurityConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]; name != "" && !set[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("not in the old config, built-in defaults apply: %s", strings.Join(missing, ", ")))
+	}
+	if doc.HeadComment == "" {
+		doc.HeadComment = migrationHeader
+	} else {
+		doc.HeadComment = migrationHeader + "\n\n" + doc.HeadComment
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	enc.Close()
+
+	// The result must load as this version's config
+	if _, err := LoadConfigFromBytes(buf.Bytes()); err != nil {
+		return nil, warnings, fmt.Errorf("migrated config does not load: %w", err)
+	}
+	return buf.Bytes(), warnings, nil
+}
+
+// migrateKeys drops the keys of mapping node that v, a config struct,
+// has no field for, and returns a warning for each.
+func migrateKeys(node *yaml.Node, prefix string, v reflect.Value) []string {
+	var warnings []string
+	kept := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		field, ok := fieldByTag(v, keyNode.Value)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s (line %d): not an option of this version, dropped", key, keyNode.Line))
+			continue
+		}
+		if valueNode.Kind == yaml.MappingNode && field.Kind() == reflect.Struct {
+			warnings = append(warnings, migrateKeys(valueNode, key, field)...)
+		}
+		if key == "dangerous_operations" {
+			warnings = append(warnings, "dangerous_operations: top-level lists apply to files in every language; this version can also keep them per language (python, javascript, shell)")
+		}
+		kept = append(kept, keyNode, valueNode)
+	}
+	node.Content = kept
+	return warnings
+}
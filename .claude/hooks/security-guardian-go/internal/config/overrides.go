@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// ConfigOverride applies a profile on the machines it matches. User and
+// Hostname are globs ("ci-*"); an override with both must match both.
+type ConfigOverride struct {
+	User     string `yaml:"user"`
+	Hostname string `yaml:"hostname"`
+	Profile  string `yaml:"profile"`
+}
+
+// applyOverrides merges the profile of every override matching the
+// current OS user and host name into config, in order, so a later
+// override wins. A profile is a piece of config: the keys it sets
+// replace the file's (lists whole), decisions are merged. Overrides that
+// name an unknown profile are skipped here and reported by Validate.
+func applyOverrides(config *SecurityConfig) {
+	if len(config.Overrides) == 0 {
+		return
+	}
+	username, hostname := currentUser(), currentHostname()
+	overrides := config.Overrides
+	for _, o := range overrides {
+		if !o.matches(username, hostname) {
+			continue
+		}
+		profile, ok := config.Profiles[o.Profile]
+		if !ok {
+			continue
+		}
+		if err := profile.Decode(config); err == nil {
+			config.ActiveProfiles = append(config.ActiveProfiles, o.Profile)
+		}
+	}
+	// A profile doesn't get to change which profiles apply
+	config.Overrides = overrides
+}
+
+// matches reports whether the override applies to username on hostname.
+func (o ConfigOverride) matches(username, hostname string) bool {
+	if o.User == "" && o.Hostname == "" {
+		return false
+	}
+	if o.User != "" {
+		if ok, _ := filepath.Match(o.User, username); !ok {
+			return false
+		}
+	}
+	if o.Hostname != "" {
+		if ok, _ := filepath.Match(o.Hostname, hostname); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// validateOverrides reports overrides that can never apply.
+func validateOverrides(cfg *SecurityConfig) []error {
+	var errs []error
+	for i, o := range cfg.Overrides {
+		key := fmt.Sprintf("overrides[%d]", i)
+		if o.User == "" && o.Hostname == "" {
+			errs = append(errs, fmt.Errorf("%s: needs user or hostname", key))
+		}
+		for _, glob := range []string{o.User, o.Hostname} {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q: %v", key, glob, err))
+			}
+		}
+		profile, ok := cfg.Profiles[o.Profile]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown profile %q", key, o.Profile))
+			continue
+		}
+		var probe SecurityConfig
+		if err := profile.Decode(&probe); err != nil {
+			errs = append(errs, fmt.Errorf("profiles.%s: %v", o.Profile, err))
+		}
+	}
+	return errs
+}
+
+// currentUser returns the name of the OS user, or $USER.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// currentHostname returns the host name, or "" if unknown.
+func currentHostname() string {
+	name, _ := os.Hostname()
+	return name
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+)
+
+// PatternErrors compiles every regexp and glob in cfg and reports the ones
+// that don't compile, with their config key. The checks skip such a
+// pattern, so the rule it belongs to is silently off until it is fixed;
+// with strict_config the hook refuses to check calls instead.
+func PatternErrors(cfg *SecurityConfig) []error {
+	var errs []error
+	regexps := func(key string, patterns []string) {
+		for i, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				errs = append(errs, fmt.Errorf("%s[%d]: %q: %v", key, i, p, err))
+			}
+		}
+	}
+	codePatterns := func(key string, items []CodePattern) {
+		for i, item := range items {
+			if _, err := regexp.Compile(item.Pattern); err != nil {
+				errs = append(errs, fmt.Errorf("%s[%d].pattern: %q: %v", key, i, item.Pattern, err))
+			}
+		}
+	}
+	globList := func(key string, patterns []string) {
+		for i, p := range patterns {
+			if _, err := globs.Compile(strings.TrimPrefix(p, "!")); err != nil {
+				errs = append(errs, fmt.Errorf("%s[%d]: %q: %v", key, i, p, err))
+			}
+		}
+	}
+
+	ops := &cfg.DangerousOperations
+	sections := []struct {
+		key string
+		lp  *LanguagePatterns
+	}{
+		{"dangerous_operations", &ops.LanguagePatterns},
+		{"dangerous_operations.python", &ops.Python},
+		{"dangerous_operations.javascript", &ops.JavaScript},
+		{"dangerous_operations.shell", &ops.Shell},
+	}
+	for _, s := range sections {
+		regexps(s.key+".network", s.lp.Network)
+		regexps(s.key+".sensitive_access", s.lp.SensitiveAccess)
+		regexps(s.key+".secret_scanning", s.lp.SecretScanning)
+		regexps(s.key+".system_recon", s.lp.SystemRecon)
+		regexps(s.key+".dynamic_execution", s.lp.DynamicExecution)
+	}
+	regexps("autoload.activation.path_changes", cfg.Autoload.Activation.PathChanges)
+	regexps("autoload.activation.secret_exports", cfg.Autoload.Activation.SecretExports)
+	codePatterns("dangerous_operations.notebook_metadata", cfg.DangerousOperations.NotebookMetadata)
+	codePatterns("dangerous_operations.data_export.credentials", cfg.DangerousOperations.DataExport.Credentials)
+	codePatterns("sensitive_files.code_patterns", cfg.SensitiveFiles.CodePatterns)
+	codePatterns("sensitive_files.custom_patterns", cfg.SensitiveFiles.CustomPatterns)
+	codePatterns("tamper_detection.patterns", cfg.TamperDetection.Patterns)
+	codePatterns("prompt_injection.patterns", cfg.PromptInjection.Patterns)
+	codePatterns("subagents.blocked_patterns", cfg.Subagents.BlockedPatterns)
+	codePatterns("web_search.blocked_patterns", cfg.WebSearch.BlockedPatterns)
+	for i, c := range cfg.DryRun.Commands {
+		if _, err := regexp.Compile(c.Match); err != nil {
+			errs = append(errs, fmt.Errorf("dry_run.commands[%d].match: %q: %v", i, c.Match, err))
+		}
+	}
+	for i, a := range cfg.Autoload.Paths {
+		if _, err := globs.Compile(a.Path); err != nil {
+			errs = append(errs, fmt.Errorf("autoload.paths[%d].path: %q: %v", i, a.Path, err))
+		}
+		if a.Contains == "" {
+			continue
+		}
+		if _, err := regexp.Compile(a.Contains); err != nil {
+			errs = append(errs, fmt.Errorf("autoload.paths[%d].contains: %q: %v", i, a.Contains, err))
+		}
+	}
+
+	globList("protected_paths.no_modify", cfg.ProtectedPaths.NoModify)
+	globList("protected_paths.no_read_content", cfg.ProtectedPaths.NoReadContent)
+	globList("protected_paths.no_write_outside_tools", cfg.ProtectedPaths.NoWriteOutsideTools)
+	globList("sensitive_files.forbidden_read", cfg.SensitiveFiles.ForbiddenRead)
+	globList("sensitive_files.system_paths", cfg.SensitiveFiles.SystemPaths)
+	globList("sensitive_files.credential_files", cfg.SensitiveFiles.CredentialFiles)
+	globList("sensitive_files.vaults", cfg.SensitiveFiles.Vaults)
+	globList("sensitive_files.content_scan.skip_files", cfg.SensitiveFiles.ContentScan.SkipFiles)
+	globList("autoload.activation.files", cfg.Autoload.Activation.Files)
+	globList("autoload.ide.files", cfg.Autoload.IDE.Files)
+	globList("git.protected_branches", cfg.Git.ProtectedBranches)
+	globList("slash_commands.allowed", cfg.SlashCommands.Allowed)
+	for i, z := range cfg.Zones {
+		if _, err := globs.Compile(z.Path); err != nil {
+			errs = append(errs, fmt.Errorf("zones[%d].path: %q: %v", i, z.Path, err))
+		}
+	}
+	for i, w := range cfg.Whitelist {
+		globList(fmt.Sprintf("whitelist[%d].args", i), w.Args)
+	}
+
+	return errs
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources records where keys are set in a config file. Keys are dotted
+// paths ("git.hard_blocked"); list items add their index
+// ("git.hard_blocked[0]"). Keys the file doesn't set come from the
+// built-in defaults (DefaultConfig).
+type Sources struct {
+	Path  string
+	lines map[string]int
+}
+
+// LoadSources parses the config file at path and records the line of
+// every key and list item. A missing file has no sources.
+func LoadSources(path string) (*Sources, error) {
+	s := &Sources{Path: path, lines: make(map[string]int)}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return s, err
+	}
+	if len(doc.Content) > 0 {
+		s.walk(doc.Content[0], "")
+	}
+	return s, nil
+}
+
+// walk records the lines of node's keys under prefix.
+func (s *Sources) walk(node *yaml.Node, prefix string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			s.lines[key] = node.Content[i].Line
+			s.walk(node.Content[i+1], key)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", prefix, i)
+			s.lines[key] = item.Line
+			s.walk(item, key)
+		}
+	}
+}
+
+// Line returns the line key is set on, if the file sets it.
+func (s *Sources) Line(key string) (int, bool) {
+	line, ok := s.lines[key]
+	return line, ok
+}
+
+// Describe returns "path:line" for a key set in the file, or "default".
+func (s *Sources) Describe(key string) string {
+	if line, ok := s.lines[key]; ok {
+		return fmt.Sprintf("%s:%d", s.Path, line)
+	}
+	return "default"
+}
+
+// Value returns the config value at a dotted key, following yaml tags.
+func Value(cfg *SecurityConfig, key string) (interface{}, bool) {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, name := range strings.Split(key, ".") {
+		field, ok := fieldByTag(v, name)
+		if !ok {
+			return nil, false
+		}
+		v = field
+	}
+	return v.Interface(), true
+}
+
+// fieldByTag returns the field of struct v with yaml name, looking into
+// inline structs.
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		tag := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")
+		if tag[0] == name {
+			return v.Field(i), true
+		}
+		if len(tag) > 1 && tag[1] == "inline" {
+			if field, ok := fieldByTag(v.Field(i), name); ok {
+				return field, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -1,10 +1,15 @@
 // Package config provides configuration loading and schema definitions.
 package config
 
+import "gopkg.in/yaml.v3"
+
 // DirectoriesConfig holds directory boundaries configuration.
 type DirectoriesConfig struct {
 	ProjectRoot  string   `yaml:"project_root"`
 	AllowedPaths []string `yaml:"allowed_paths"`
+	// PathVariables are environment variables expanded in command paths
+	// besides HOME, TMPDIR and CLAUDE_PROJECT_DIR.
+	PathVariables []string `yaml:"path_variables"`
 }
 
 // GitConfig holds git operations configuration.
@@ -13,6 +18,16 @@ type GitConfig struct {
 	ConfirmRequired []string `yaml:"confirm_required"`
 	Allowed         []string `yaml:"allowed"`
 	CIAutoAllow     []string `yaml:"ci_auto_allow"`
+	// BackupBeforeDestructive snapshots the working tree to
+	// refs/guardian/backup-<ts> before an allowed reset --hard, clean -f, etc.
+	BackupBeforeDestructive bool `yaml:"backup_before_destructive"`
+	// RepoAware decides force pushes, branch -D and remote branch deletion
+	// by the repository state (see gitstate) rather than by flags alone.
+	RepoAware         bool     `yaml:"repo_aware"`
+	ProtectedBranches []string `yaml:"protected_branches"`
+	// AllowedRemotes are the hosts (exact or *.suffix) git may clone, fetch
+	// and add remotes from; empty allows any host.
+	AllowedRemotes []string `yaml:"allowed_remotes"`
 }
 
 // BypassPreventionConfig holds bypass prevention configuration.
@@ -26,6 +41,10 @@ type BypassPreventionConfig struct {
 	NetworkPatterns                   []string `yaml:"network_patterns"`
 	ObfuscationPatterns               []string `yaml:"obfuscation_patterns"`
 	RCEPatternsRequireNetwork         []string `yaml:"rce_patterns_require_network"`
+	// InlineNetworkAllowedHosts are hosts (exact, *.suffix, or host:port) that
+	// don't count as network when every host in the inline code matches.
+	InlineNetworkAllowedHosts         []string `yaml:"inline_network_allowed_hosts"`
+	InspectTaskRunners                bool     `yaml:"inspect_task_runners"`
 }
 
 // DownloadProtectionConfig holds download protection configuration.
@@ -35,10 +54,16 @@ type DownloadProtectionConfig struct {
 	AutoDownload              []string `yaml:"auto_download"`
 	BlockPipeToShell          bool     `yaml:"block_pipe_to_shell"`
 	TrackDownloadedExecutables bool     `yaml:"track_downloaded_executables"`
-	DownloadedFilesMetadata   string   `yaml:"downloaded_files_metadata"`
+	DownloadedFilesMetadata   string   `yaml:"downloaded_files_metadata"` // "" = in StateDirectory
 	DetectBinaryByMagic       bool     `yaml:"detect_binary_by_magic"`
 	GitTrackedAllow           bool     `yaml:"git_tracked_allow"`
 	FileCommandFallback       bool     `yaml:"file_command_fallback"`
+	// SubprocessFallback runs `git ls-files` / `file` when the in-process
+	// lookup (go-git index, content type detection) can't tell.
+	SubprocessFallback bool `yaml:"subprocess_fallback"`
+	// ConfirmFirstRun asks before a tracked download is first run, however
+	// it is invoked (./file, an interpreter, source, sh < file).
+	ConfirmFirstRun bool `yaml:"confirm_first_run"`
 }
 
 // UnpackProtectionConfig holds archive unpacking protection configuration.
@@ -46,12 +71,54 @@ type UnpackProtectionConfig struct {
 	CheckExtractedFiles       bool     `yaml:"check_extracted_files"`
 	CheckArchivePathTraversal bool     `yaml:"check_archive_path_traversal"`
 	BlockedPatterns           []string `yaml:"blocked_patterns"`
+	// TrackExtractedExecutables records scripts and binaries extracted from
+	// archives with the downloads, so their first run asks too.
+	TrackExtractedExecutables bool `yaml:"track_extracted_executables"`
 }
 
 // ProtectedPathsConfig holds protected paths configuration.
 type ProtectedPathsConfig struct {
-	NoModify      []string `yaml:"no_modify"`
-	NoReadContent []string `yaml:"no_read_content"`
+	NoModify            []string `yaml:"no_modify"`
+	NoReadContent       []string `yaml:"no_read_content"`
+	NoWriteOutsideTools []string `yaml:"no_write_outside_tools"`
+}
+
+// AutoloadConfig holds the project locations whose files run without being
+// invoked: git hooks, direnv, editor tasks. Creating or changing one is
+// code execution on the user's next commit, cd or folder open.
+type AutoloadConfig struct {
+	Enabled    bool             `yaml:"enabled"`
+	Paths      []AutoloadPath   `yaml:"paths"`
+	Activation ActivationConfig `yaml:"activation"`
+	IDE        IDEConfig        `yaml:"ide"`
+}
+
+// IDEConfig screens the commands in editor task and run configurations
+// (VS Code tasks.json and launch.json, JetBrains run configurations) with
+// the dangerous_operations network, sensitive access and dynamic execution
+// patterns.
+type IDEConfig struct {
+	Files []string `yaml:"files"` // globs relative to the project root
+}
+
+// ActivationConfig screens what is written to the shell files run on cd or
+// environment activation (.envrc, .tool-versions, conda activate.d) for
+// network calls (dangerous_operations network patterns), PATH changes and
+// exported secrets.
+type ActivationConfig struct {
+	Files         []string `yaml:"files"`          // globs relative to the project root
+	PathChanges   []string `yaml:"path_changes"`   // regexps
+	SecretExports []string `yaml:"secret_exports"` // regexps
+}
+
+// AutoloadPath is a location that runs automatically.
+type AutoloadPath struct {
+	Path string `yaml:"path"` // glob relative to the project root
+	Runs string `yaml:"runs"` // who runs it and when, shown to the user
+	// Contains, if set, is a regexp the file content must match to run
+	// (tasks.json runs only tasks with runOn: folderOpen). Content that
+	// can't be seen (Bash writes) counts as matching.
+	Contains string `yaml:"contains"`
 }
 
 // CodePattern represents a code pattern for sensitive file detection.
@@ -60,16 +127,70 @@ type CodePattern struct {
 	Description string `yaml:"description"`
 }
 
+// DataExportConfig holds the screening of database dumps and credentials
+// on the command line.
+type DataExportConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Commands are dump clients, in the form of pipelines entries
+	// ("sqlite3 .dump"); their output going outside the project or to a
+	// network command needs confirmation
+	Commands []string `yaml:"commands"`
+	// Credentials match connection strings and password options that put
+	// credentials on the command line
+	Credentials []CodePattern `yaml:"credentials"`
+	// MaxDumpBytes is the size of a dump written inside the project above
+	// which the Bash PostToolUse warns (0 = off)
+	MaxDumpBytes int64 `yaml:"max_dump_bytes"`
+}
+
 // SensitiveFilesConfig holds sensitive files configuration.
 type SensitiveFilesConfig struct {
-	ForbiddenRead  []string      `yaml:"forbidden_read"`
-	CodePatterns   []CodePattern `yaml:"code_patterns"`
-	SecretEnvVars  []string      `yaml:"secret_env_vars"`
-	CustomPatterns []CodePattern `yaml:"custom_patterns"`
+	ForbiddenRead   []string      `yaml:"forbidden_read"`
+	SystemPaths     []string      `yaml:"system_paths"`     // absolute globs, denied for any access, allowed_paths or not
+	CredentialFiles []string      `yaml:"credential_files"` // cloud, cluster and registry credentials, globs as in Vaults
+	Vaults          []string      `yaml:"vaults"`           // wallets and password stores: absolute, ~/ or **/ globs, any access denied
+	CodePatterns    []CodePattern `yaml:"code_patterns"`
+	SecretEnvVars   []string      `yaml:"secret_env_vars"`
+	CustomPatterns  []CodePattern `yaml:"custom_patterns"`
+	// ContentScan samples files allowed for Read for embedded credentials
+	ContentScan ContentScanConfig `yaml:"content_scan"`
+}
+
+// ContentScanConfig holds high-entropy credential detection for Read.
+type ContentScanConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	MaxBytes   int      `yaml:"max_bytes"`   // sampled from the start of the file
+	MinLength  int      `yaml:"min_length"`  // shortest token considered
+	MinEntropy float64  `yaml:"min_entropy"` // bits per char for base64-like tokens
+	SkipFiles  []string `yaml:"skip_files"`  // lockfiles etc. full of hashes
 }
 
 // DangerousOperationsConfig holds dangerous operations patterns.
+// Top-level lists apply to every file; the per-language sections apply
+// only to content detected as that language.
 type DangerousOperationsConfig struct {
+	LanguagePatterns `yaml:",inline"`
+
+	// Strict scans comments and docstrings too (they are skipped by default)
+	Strict bool `yaml:"strict"`
+	// ReportAllMatches lists every match with line/column instead of the
+	// first few per category
+	ReportAllMatches bool `yaml:"report_all_matches"`
+	// NotebookMetadata is matched against the notebook, cell and output
+	// metadata of .ipynb files (kernel commands, hidden cells, scripts in
+	// outputs); code cells get the language patterns
+	NotebookMetadata []CodePattern `yaml:"notebook_metadata"`
+	// DataExport screens Bash database dumps and connection strings with
+	// credentials
+	DataExport DataExportConfig `yaml:"data_export"`
+
+	Python     LanguagePatterns `yaml:"python"`
+	JavaScript LanguagePatterns `yaml:"javascript"`
+	Shell      LanguagePatterns `yaml:"shell"`
+}
+
+// LanguagePatterns holds dangerous operation patterns by category.
+type LanguagePatterns struct {
 	Network          []string `yaml:"network"`
 	SensitiveAccess  []string `yaml:"sensitive_access"`
 	SecretScanning   []string `yaml:"secret_scanning"`
@@ -78,6 +199,274 @@ type DangerousOperationsConfig struct {
 	ShellExecution   []string `yaml:"shell_execution"`
 }
 
+// ForLanguage returns the section for a language ("python", "javascript",
+// "shell"), or nil for languages without one.
+func (d *DangerousOperationsConfig) ForLanguage(lang string) *LanguagePatterns {
+	switch lang {
+	case "python":
+		return &d.Python
+	case "javascript":
+		return &d.JavaScript
+	case "shell":
+		return &d.Shell
+	}
+	return nil
+}
+
+// ZoneConfig assigns a policy to a path pattern inside the project.
+type ZoneConfig struct {
+	Path   string `yaml:"path"`   // glob relative to project root, e.g. "sandbox/**"
+	Policy string `yaml:"policy"` // permissive | strict
+}
+
+// WhitelistEntry describes a pre-approved command that skips all checks.
+// Either Exact (whole command line) or Command with optional constraints.
+type WhitelistEntry struct {
+	Exact      string   `yaml:"exact"`
+	Command    string   `yaml:"command"`
+	Flags      []string `yaml:"flags"`       // allowed flags (any if empty)
+	Args       []string `yaml:"args"`        // allowed argument globs (any if empty)
+	ArgsWithin string   `yaml:"args_within"` // "project" or a path relative to project root
+	MaxDepth   int      `yaml:"max_depth"`   // max path depth of args inside args_within
+}
+
+// CustomRule denies or asks when a boolean expression over signals of a
+// tool call holds, e.g. "download && outside_project && !trusted_script".
+// Signals are listed in checks.BashSignals and checks.FileSignals.
+type CustomRule struct {
+	ID       string   `yaml:"id"`       // rule ID is custom.<id>
+	When     string   `yaml:"when"`     // ! && || and parentheses (or not, and, or)
+	Decision string   `yaml:"decision"` // ask | deny
+	Tools    []string `yaml:"tools"`    // Bash (default), Read, Write, Edit, NotebookEdit
+	Reason   string   `yaml:"reason"`
+	Guidance string   `yaml:"guidance"`
+}
+
+// MassModificationConfig holds per-session blast radius limits.
+type MassModificationConfig struct {
+	Enabled             bool   `yaml:"enabled"`
+	MaxFilesDeleted     int    `yaml:"max_files_deleted"`     // per session
+	MaxFilesOverwritten int    `yaml:"max_files_overwritten"` // per session
+	MaxWriteBytes       int    `yaml:"max_write_bytes"`       // single Write
+	SessionIdleMinutes  int    `yaml:"session_idle_minutes"`  // counters reset after inactivity
+	StateFile           string `yaml:"state_file"`
+}
+
+// TrustedScriptsConfig holds the hash-based script allowlist configuration.
+type TrustedScriptsConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Manifest string `yaml:"manifest"` // relative to project root
+}
+
+// RememberApprovalsConfig holds the asks that are remembered once the user
+// approves them.
+type RememberApprovalsConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Rules   []string `yaml:"rules"` // rule IDs whose approvals are remembered
+	Store   string   `yaml:"store"` // "" = approvals.json in StateDirectory
+}
+
+// ExceptionCodesConfig holds the one-time codes deny messages carry for
+// `guardian allow`.
+type ExceptionCodesConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	DefaultTTL    string   `yaml:"default_ttl"`    // without --ttl
+	MaxTTL        string   `yaml:"max_ttl"`        // longest --ttl accepted
+	ExcludedRules []string `yaml:"excluded_rules"` // rule IDs (or "prefix.*") that get no code
+}
+
+// AllowCacheConfig holds the cache of allowed Bash commands, which skips
+// the checks for a command allowed before in the same directory.
+type AllowCacheConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	TTL        string   `yaml:"ttl"`         // how long an allow is reused
+	MaxEntries int      `yaml:"max_entries"` // the oldest are dropped beyond
+	Commands   []string `yaml:"commands"`    // name and leading arguments of the commands whose allows are cached
+	Store      string   `yaml:"store"`       // "" = allow_cache.json in StateDirectory
+}
+
+// GPGConfig holds GnuPG key protection.
+type GPGConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Home    string `yaml:"home"` // "" = $GNUPGHOME or ~/.gnupg
+}
+
+// PipelinesConfig holds the source and sink analysis of Bash pipelines.
+// Entries are a command name, optionally followed by words that must also
+// appear in its arguments ("git archive", "openssl enc").
+type PipelinesConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	DataSources   []string `yaml:"data_sources"`   // commands whose output is project or database data
+	SecretSources []string `yaml:"secret_sources"` // commands whose output is secrets, besides reads of secrets files
+	Encoders      []string `yaml:"encoders"`       // commands that encode or compress what they read
+	NetworkSinks  []string `yaml:"network_sinks"`  // commands that send what they read off the machine
+}
+
+// UnattendedConfig holds the stricter policy of unattended runs
+// (see IsUnattended).
+type UnattendedConfig struct {
+	Env            string   `yaml:"env"`             // variable marking a run unattended (=1)
+	Hours          []string `yaml:"hours"`           // local time windows, "22:00-07:00"
+	AllowedDomains []string `yaml:"allowed_domains"` // the only hosts reachable, subdomains included
+}
+
+// CloudMetadataConfig holds cloud instance metadata endpoint blocking.
+type CloudMetadataConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Endpoints []string `yaml:"endpoints"` // hosts and addresses, case-insensitive
+	Allowed   []string `yaml:"allowed"`   // host/path prefixes still allowed
+}
+
+// TamperDetectionConfig holds the patterns of commands that look for the
+// guardian or try to disable it.
+type TamperDetectionConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Patterns []CodePattern `yaml:"patterns"` // case-insensitive, matched on the raw command
+}
+
+// CanaryTokensConfig holds guidance canary token configuration.
+type CanaryTokensConfig struct {
+	Enabled bool `yaml:"enabled"` // add a per-session token to deny messages
+}
+
+// PromptInjectionConfig holds prompt-injection screening configuration.
+type PromptInjectionConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	ScanWrites bool          `yaml:"scan_writes"` // Write/Edit content, not only WebFetch results
+	Patterns   []CodePattern `yaml:"patterns"`    // case-insensitive
+}
+
+// SubagentsConfig holds Task (sub-agent) prompt screening configuration.
+type SubagentsConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	BlockedTypes    []string      `yaml:"blocked_types"`    // subagent_type values to deny
+	BlockedPatterns []CodePattern `yaml:"blocked_patterns"` // case-insensitive, matched on the prompt
+}
+
+// WebSearchConfig holds WebSearch query screening configuration.
+type WebSearchConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	BlockedPatterns []CodePattern `yaml:"blocked_patterns"` // case-insensitive
+}
+
+// SlashCommandsConfig holds the SlashCommand tool allowlist.
+type SlashCommandsConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Allowed []string `yaml:"allowed"` // command names without "/", globs allowed
+}
+
+// BackgroundShellsConfig holds BashOutput/KillShell audit configuration.
+type BackgroundShellsConfig struct {
+	Audit            bool `yaml:"audit"`              // log output retrieval and kills
+	FlagDeniedOutput bool `yaml:"flag_denied_output"` // warn when denied commands show up in output
+	MaxTracked       int  `yaml:"max_tracked"`        // denied commands remembered per session
+}
+
+// SessionSummaryConfig holds Stop/SessionEnd summary configuration.
+type SessionSummaryConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Store       string `yaml:"store"`         // decision store, relative to project root
+	PrintOnStop bool   `yaml:"print_on_stop"` // show totals after turns with asks/denies
+	TopRules    int    `yaml:"top_rules"`     // rules listed in the summary
+}
+
+// ExplainConfig holds the decision records replayed by `guardian explain`.
+type ExplainConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Store           string `yaml:"store"`             // relative to project root
+	MaxRecords      int    `yaml:"max_records"`       // oldest records are dropped
+	MaxContentBytes int    `yaml:"max_content_bytes"` // longer input values (file content) are cut
+}
+
+// SessionStartConfig holds SessionStart report configuration.
+type SessionStartConfig struct {
+	Enabled       bool `yaml:"enabled"`
+	ShowWarnings  bool `yaml:"show_warnings"`  // risky settings shown to the user
+	InjectContext bool `yaml:"inject_context"` // active policy added to Claude's context
+}
+
+// MessagesConfig holds deny/ask message configuration.
+type MessagesConfig struct {
+	Structured  bool                       `yaml:"structured"`  // append a JSON decision line to messages
+	Remediation bool                       `yaml:"remediation"` // end denies with the config change that allows them
+	Language    string                     `yaml:"language"`    // en, ru
+	Overrides   map[string]MessageOverride `yaml:"overrides"`   // by rule ID
+}
+
+// MessageOverride replaces the reason and/or guidance of a rule's message.
+// Both are templates, see messages.ApplyOverride for the variables.
+type MessageOverride struct {
+	Reason   string `yaml:"reason"`
+	Guidance string `yaml:"guidance"`
+}
+
+// DecoysConfig holds honeypot secrets file configuration.
+type DecoysConfig struct {
+	Enabled       bool   `yaml:"enabled"`
+	Registry      string `yaml:"registry"`       // relative to project root
+	NotifyCommand string `yaml:"notify_command"` // run via sh -c on a hit
+}
+
+// TrashConfig holds recoverable deletion configuration.
+type TrashConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Directory string `yaml:"directory"` // relative to project root
+}
+
+// DryRunConfig holds dry-run substitution configuration.
+type DryRunConfig struct {
+	Enabled        bool            `yaml:"enabled"`
+	OnlyWhenDenied bool            `yaml:"only_when_denied"` // only when asks become denials (YOLO mode)
+	Commands       []DryRunCommand `yaml:"commands"`
+}
+
+// DryRunCommand maps a command to its preview: the Match words are
+// replaced with Replace (default: Match), the Drop flags removed and
+// Append added at the end.
+type DryRunCommand struct {
+	Match   string   `yaml:"match"`
+	Replace string   `yaml:"replace"`
+	Append  string   `yaml:"append"`
+	Drop    []string `yaml:"drop"`
+}
+
+// Values for InputLimitsConfig.OnOversized.
+const (
+	OversizedAllow = "allow"
+	OversizedAsk   = "ask"
+)
+
+// InputLimitsConfig bounds how much hook input is read and scanned.
+type InputLimitsConfig struct {
+	MaxInputBytes     int    `yaml:"max_input_bytes"`     // hook input read from stdin; larger input is not checked
+	OnOversized       string `yaml:"on_oversized"`        // allow | ask, for input over max_input_bytes
+	MaxScanBytes      int    `yaml:"max_scan_bytes"`      // content scanned whole up to this size
+	SampleKB          int    `yaml:"sample_kb"`           // beyond it: first/last sample_kb and sampled windows
+	SampleWindows     int    `yaml:"sample_windows"`      // windows between the first and last
+	ChunkKB           int    `yaml:"chunk_kb"`            // patterns run over chunks of this size, 0: whole windows
+	ChunkOverlap      int    `yaml:"chunk_overlap"`       // bytes adjacent chunks share: the longest match found whole
+	MaxPatternMatches int    `yaml:"max_pattern_matches"` // matches kept per pattern and file, report_all_matches included
+}
+
+// Values for PerformanceConfig.OnTimeout and SecurityConfig.OnInternalError:
+// the decision when a tool call can't be checked.
+const (
+	FailAllow = "allow"
+	FailAsk   = "ask"
+	FailDeny  = "deny"
+)
+
+// PerformanceConfig holds how checks are scheduled and bounded.
+type PerformanceConfig struct {
+	ParallelMinCommands int            `yaml:"parallel_min_commands"` // run Bash checks concurrently from this many commands, 0: never
+	MaxParallel         int            `yaml:"max_parallel"`          // concurrent checks, 0: number of CPUs
+	CheckTimeoutMs      int            `yaml:"check_timeout_ms"`      // time for the checks of one tool call, 0: no limit
+	ToolTimeoutsMs      map[string]int `yaml:"tool_timeouts_ms"`      // per tool name, overrides check_timeout_ms
+	OnTimeout           string         `yaml:"on_timeout"`            // allow | ask | deny, when checks time out
+	ProbeCacheSeconds   int            `yaml:"probe_cache_seconds"`   // keep `file` / `git ls-files` results across calls, 0: per call only
+	BudgetP95Ms         float64        `yaml:"budget_p95_ms"`         // guardian bench fails above this p95 latency, 0: no budget
+}
+
 // LoggingConfig holds logging configuration.
 type LoggingConfig struct {
 	Enabled      bool   `yaml:"enabled"`
@@ -89,30 +478,107 @@ type LoggingConfig struct {
 	MaxLogFiles  int    `yaml:"max_log_files"`
 }
 
+// YOLO mode values for SecurityConfig.YoloMode.
+const (
+	// YoloModeAuto detects YOLO mode from the hook's permission_mode.
+	YoloModeAuto = "auto"
+	// YoloModeOn always elevates ASK to DENY.
+	YoloModeOn = "on"
+	// YoloModeOff always emits real ASK decisions.
+	YoloModeOff = "off"
+)
+
 // SecurityConfig is the main security configuration model.
 type SecurityConfig struct {
+	YoloMode            string                    `yaml:"yolo_mode"`
+	OnInternalError     string                    `yaml:"on_internal_error"` // allow | ask | deny
+	// StrictConfig denies every call while a regexp or glob in the config
+	// doesn't compile (see PatternErrors), instead of running without it,
+	// and while the config file fails to load (see StrictConfigSet).
+	StrictConfig        bool                      `yaml:"strict_config"`
+	Unattended          UnattendedConfig          `yaml:"unattended"`
+	// StateDirectory holds per-user state, shared by the worktrees of a
+	// project (see state.ProjectDir).
+	StateDirectory      string                    `yaml:"state_directory"`
 	Directories         DirectoriesConfig         `yaml:"directories"`
 	Git                 GitConfig                 `yaml:"git"`
 	BypassPrevention    BypassPreventionConfig    `yaml:"bypass_prevention"`
 	DownloadProtection  DownloadProtectionConfig  `yaml:"download_protection"`
 	UnpackProtection    UnpackProtectionConfig    `yaml:"unpack_protection"`
 	ProtectedPaths      ProtectedPathsConfig      `yaml:"protected_paths"`
+	Autoload            AutoloadConfig            `yaml:"autoload"`
 	SensitiveFiles      SensitiveFilesConfig      `yaml:"sensitive_files"`
 	DangerousOperations DangerousOperationsConfig `yaml:"dangerous_operations"`
+	MassModification    MassModificationConfig    `yaml:"mass_modification"`
+	Trash               TrashConfig               `yaml:"trash"`
+	DryRun              DryRunConfig              `yaml:"dry_run"`
+	TrustedScripts      TrustedScriptsConfig      `yaml:"trusted_scripts"`
+	RememberApprovals   RememberApprovalsConfig   `yaml:"remember_approvals"`
+	ExceptionCodes      ExceptionCodesConfig      `yaml:"exception_codes"`
+	AllowCache          AllowCacheConfig          `yaml:"allow_cache"`
+	Decoys              DecoysConfig              `yaml:"decoys"`
+	GPG                 GPGConfig                 `yaml:"gpg"`
+	Pipelines           PipelinesConfig           `yaml:"pipelines"`
+	CloudMetadata       CloudMetadataConfig       `yaml:"cloud_metadata"`
+	TamperDetection     TamperDetectionConfig     `yaml:"tamper_detection"`
+	CanaryTokens        CanaryTokensConfig        `yaml:"canary_tokens"`
+	PromptInjection     PromptInjectionConfig     `yaml:"prompt_injection"`
+	Subagents           SubagentsConfig           `yaml:"subagents"`
+	WebSearch           WebSearchConfig           `yaml:"web_search"`
+	SlashCommands       SlashCommandsConfig       `yaml:"slash_commands"`
+	BackgroundShells    BackgroundShellsConfig    `yaml:"background_shells"`
+	SessionSummary      SessionSummaryConfig      `yaml:"session_summary"`
+	Explain             ExplainConfig             `yaml:"explain"`
+	SessionStart        SessionStartConfig        `yaml:"session_start"`
+	Messages            MessagesConfig            `yaml:"messages"`
+	InputLimits         InputLimitsConfig         `yaml:"input_limits"`
+	Performance         PerformanceConfig         `yaml:"performance"`
 	Logging             LoggingConfig             `yaml:"logging"`
+	Zones               []ZoneConfig              `yaml:"zones"`
+	Whitelist           []WhitelistEntry          `yaml:"whitelist"`
+	CustomRules         []CustomRule              `yaml:"custom_rules"`
+	// Decisions overrides the built-in decision per rule ID (allow/ask/deny).
+	Decisions map[string]string `yaml:"decisions"`
+	// Profiles are named pieces of config; Overrides apply them on
+	// matching OS users and hosts when the config is loaded.
+	Profiles  map[string]yaml.Node `yaml:"profiles"`
+	Overrides []ConfigOverride     `yaml:"overrides"`
+	// ActiveProfiles are the profiles applied, in order.
+	ActiveProfiles []string `yaml:"-"`
+
+	// Version is the Fingerprint of the file the config was loaded from,
+	// "default" for the built-in config.
+	Version string `yaml:"-"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
 func DefaultConfig() *SecurityConfig {
 	return &SecurityConfig{
+		Version:         "default",
+		YoloMode:        YoloModeAuto,
+		OnInternalError: FailAsk,
+		Unattended: UnattendedConfig{
+			Env:   "UNATTENDED",
+			Hours: []string{},
+			AllowedDomains: []string{
+				"github.com", "githubusercontent.com",
+				"pypi.org", "files.pythonhosted.org",
+				"registry.npmjs.org", "proxy.golang.org", "sum.golang.org",
+			},
+		},
+		StateDirectory: "${HOME}/.local/state/security-guardian",
 		Directories: DirectoriesConfig{
-			AllowedPaths: []string{},
+			AllowedPaths:  []string{},
+			PathVariables: []string{},
 		},
 		Git: GitConfig{
 			HardBlocked:     []string{"push --force"},
 			ConfirmRequired: []string{"push -f", "reset --hard", "branch -D", "clean -fd", "reflog expire"},
 			Allowed:         []string{"push --force-with-lease", "clean -fd --dry-run", "clean -fdn"},
 			CIAutoAllow:     []string{"clean -fd", "reset --hard"},
+			RepoAware:         true,
+			ProtectedBranches: []string{"main", "master"},
+			AllowedRemotes:    []string{},
 		},
 		BypassPrevention: BypassPreventionConfig{
 			BlockedOutsideProject:             []string{"base64 -d", "xxd -r"},
@@ -124,6 +590,8 @@ func DefaultConfig() *SecurityConfig {
 			NetworkPatterns:                   []string{"import requests", "import urllib", "import http.client", "import socket", "import httpx", "import aiohttp", "require('http')", "fetch("},
 			ObfuscationPatterns:               []string{"importlib.import_module", "__import__"},
 			RCEPatternsRequireNetwork:         []string{"exec(base64", "exec(bytes.fromhex", "eval(base64"},
+			InlineNetworkAllowedHosts:         []string{"localhost", "127.0.0.1", "::1"},
+			InspectTaskRunners:                true,
 		},
 		DownloadProtection: DownloadProtectionConfig{
 			RequireUserDownload:       []string{".py", ".sh", ".bash", ".rb", ".pl", ".js", ".exe", ".app", ".dmg", ".pkg", ".deb", ".bin", ".msi"},
@@ -131,13 +599,16 @@ func DefaultConfig() *SecurityConfig {
 			AutoDownload:              []string{".json", ".yaml", ".yml", ".txt", ".csv", ".md", ".xml", ".html"},
 			BlockPipeToShell:          true,
 			TrackDownloadedExecutables: true,
-			DownloadedFilesMetadata:   ".claude/hooks/security-guardian/.downloaded.json",
+			DownloadedFilesMetadata:   "",
 			DetectBinaryByMagic:       true,
 			GitTrackedAllow:           true,
 			FileCommandFallback:       true,
+			SubprocessFallback:        false,
+			ConfirmFirstRun:           true,
 		},
 		UnpackProtection: UnpackProtectionConfig{
 			CheckExtractedFiles:       true,
+			TrackExtractedExecutables: true,
 			CheckArchivePathTraversal: true,
 			BlockedPatterns:           []string{"tar -C ../", "tar --directory=../", "tar --one-top-level=../", "unzip -d ../", "bsdtar -C ../", "bsdtar -s", "python -m zipfile -e", "python3 -m zipfile -e"},
 		},
@@ -160,8 +631,54 @@ func DefaultConfig() *SecurityConfig {
 				".claude/hooks/security-guardian-go/go.sum",
 				".claude/hooks/security-guardian-go/Makefile",
 				".claude/hooks/security-guardian-go/scripts/**",
+				// Only `guardian trust` may change the trusted script manifest
+				".claude/hooks/security-guardian/trusted_scripts.yaml",
+				// Only `guardian decoy` may change the decoy registry
+				".claude/hooks/security-guardian/decoys.yaml",
+			},
+			NoReadContent: []string{"**/.env", "**/.env.*", "!**/.env.example", "!**/.env.template", ".claude/hooks/security-guardian/decoys.yaml", ".claude/hooks/security-guardian/recent_decisions.jsonl"},
+			NoWriteOutsideTools: []string{
+				"~/.bashrc", "~/.bash_profile", "~/.bash_login", "~/.profile",
+				"~/.zshrc", "~/.zshenv", "~/.zprofile", "~/.zlogin",
+				"~/.config/fish/config.fish", "~/.config/fish/conf.d/**",
+				"~/.ssh/config", "~/.ssh/authorized_keys", "~/.ssh/rc",
+				"~/.gitconfig", "~/.config/git/config", "~/.config/git/attributes",
+				"/etc/crontab", "/etc/cron.d/**", "/var/spool/cron/**",
+			},
+		},
+		Autoload: AutoloadConfig{
+			Enabled: true,
+			Paths: []AutoloadPath{
+				{Path: ".git/hooks/**", Runs: "git runs it on commit, checkout, merge or push"},
+				{Path: ".husky/**", Runs: "husky runs it as a git hook on commit or push"},
+				{Path: ".githooks/**", Runs: "git runs it as a hook when core.hooksPath points here"},
+				{Path: ".pre-commit-config.yaml", Runs: "pre-commit runs the hooks it lists on every commit"},
+				{Path: "**/.envrc", Runs: "direnv runs it when the user enters the directory"},
+				{Path: ".vscode/tasks.json", Runs: "VS Code runs tasks with runOn: folderOpen when the folder is opened", Contains: `"runOn"\s*:\s*"folderOpen"`},
+				{Path: ".devcontainer/**/devcontainer.json", Runs: "the dev container runs its lifecycle commands when it is built or opened", Contains: `"(initializeCommand|onCreateCommand|postCreateCommand|postStartCommand|postAttachCommand)"`},
+				{Path: ".devcontainer.json", Runs: "the dev container runs its lifecycle commands when it is built or opened", Contains: `"(initializeCommand|onCreateCommand|postCreateCommand|postStartCommand|postAttachCommand)"`},
+				{Path: "node_modules/.bin/**", Runs: "npm scripts and npx run it by name"},
+				{Path: "**/etc/conda/activate.d/**", Runs: "conda runs it when the environment is activated"},
+				{Path: "**/etc/conda/deactivate.d/**", Runs: "conda runs it when the environment is deactivated"},
+				{Path: "**/.ipython/profile_*/startup/**", Runs: "IPython runs it when a kernel or shell starts"},
+				{Path: "**/kernels/*/kernel.json", Runs: "Jupyter starts the kernel with the command in its argv"},
+			},
+			IDE: IDEConfig{
+				Files: []string{".vscode/tasks.json", ".vscode/launch.json", "**/*.code-workspace", ".idea/runConfigurations/*.xml", ".run/*.run.xml"},
+			},
+			Activation: ActivationConfig{
+				Files: []string{"**/.envrc", "**/.tool-versions", "**/etc/conda/activate.d/**", "**/etc/conda/deactivate.d/**"},
+				PathChanges: []string{
+					`\bPATH_add\b`,
+					`\bpath_add\b`,
+					`\b(export\s+)?PATH=`,
+					`\b(export\s+)?(LD_PRELOAD|LD_LIBRARY_PATH|DYLD_\w+|PYTHONPATH|NODE_OPTIONS|PERL5OPT|RUBYOPT)=`,
+					`(?m)^\S+\s+path:`,
+				},
+				SecretExports: []string{
+					`(?i)\bexport\s+\w*(secret|token|passw(or)?d|api_?key|private_?key|access_?key)\w*=['"]?[^\s'"$]{8,}`,
+				},
 			},
-			NoReadContent: []string{"**/.env", "**/.env.*", "!**/.env.example", "!**/.env.template"},
 		},
 		SensitiveFiles: SensitiveFilesConfig{
 			ForbiddenRead: []string{
@@ -170,6 +687,32 @@ func DefaultConfig() *SecurityConfig {
 				"**/*.pem", "**/*.key",
 				"**/id_rsa*", "**/id_ed25519*",
 			},
+			SystemPaths: []string{
+				"/proc/*/environ", "/proc/*/task/*/environ",
+				"/proc/*/maps", "/proc/*/smaps", "/proc/*/mem", "/proc/kcore",
+				"/sys/class/dmi/id/*_serial", "/sys/class/dmi/id/product_uuid",
+				"/sys/devices/virtual/dmi/id/*_serial", "/sys/devices/virtual/dmi/id/product_uuid",
+				"/dev/mem", "/dev/kmem", "/dev/port",
+			},
+			CredentialFiles: []string{
+				"~/.aws/credentials", "~/.aws/sso/cache/**", "~/.aws/cli/cache/**",
+				"~/.kube/config", "**/kubeconfig",
+				"~/.docker/config.json",
+				"~/.config/gcloud/**",
+				"~/.azure/**",
+				"~/.config/gh/hosts.yml",
+				"~/.netrc", "~/.npmrc", "~/.pypirc",
+			},
+			Vaults: []string{
+				"~/.config/Exodus/exodus.wallet/**", "~/Library/Application Support/Exodus/exodus.wallet/**",
+				"~/.electrum/wallets/**", "~/Library/Application Support/Electrum/wallets/**",
+				"~/.config/Ledger Live/**", "~/Library/Application Support/Ledger Live/**",
+				"**/wallet.dat", "~/.ethereum/keystore/**",
+				"**/*.kdbx", "**/*.kdb",
+				"~/.config/1Password/**", "~/.config/op/**", "~/Library/Group Containers/*.com.1password/**",
+				"~/.config/Bitwarden/**", "~/.config/Bitwarden CLI/**", "~/Library/Application Support/Bitwarden/**", "~/Library/Application Support/Bitwarden CLI/**",
+				"~/.password-store/**",
+			},
 			CodePatterns: []CodePattern{
 				{Pattern: `open\(['""].*\.env`, Description: "Reading .env file"},
 				{Pattern: `open\(['""].*\.pem`, Description: "Reading private key"},
@@ -186,14 +729,259 @@ func DefaultConfig() *SecurityConfig {
 				"STRIPE_SECRET_KEY", "PRIVATE_KEY", "PASSWORD", "DB_PASSWORD",
 			},
 			CustomPatterns: []CodePattern{},
+			ContentScan: ContentScanConfig{
+				Enabled:    false,
+				MaxBytes:   256 * 1024,
+				MinLength:  20,
+				MinEntropy: 4.5,
+				SkipFiles: []string{
+					"**/package-lock.json", "**/yarn.lock", "**/pnpm-lock.yaml",
+					"**/go.sum", "**/Cargo.lock", "**/poetry.lock", "**/*.lock",
+					"**/*.min.js", "**/*.map", "**/*.svg",
+				},
+			},
 		},
 		DangerousOperations: DangerousOperationsConfig{
-			Network:          []string{`import\s+(requests|urllib|httpx|aiohttp)`, `from\s+(requests|urllib|httpx)\s`, `socket\.`, `urlopen\(`, `curl\s`, `wget\s`},
-			SensitiveAccess:  []string{`\.env`, `/etc/passwd`, `~/.ssh`, `\.aws/credentials`, `\.netrc`, `\.npmrc`, `\.pypirc`},
-			SecretScanning:   []string{`grep.*password`, `grep.*secret`, `grep.*token`, `grep.*api.key`, `find.*\.env`, `find.*\.ssh`, `find.*\.aws`, `glob\(.*\.env`, `os\.walk.*password`, `re\.search.*password`, `re\.findall.*secret`},
-			SystemRecon:      []string{`os\.environ`, `getpass\.getuser`, `socket\.gethostname`, `platform\.`, `subprocess.*whoami`, `subprocess.*id\s`, `subprocess.*uname`},
-			DynamicExecution: []string{`exec\(`, `eval\(`, `compile\(`, `__import__\(`, `importlib\.import_module`, `subprocess\..*shell=True`},
-			ShellExecution:   []string{`subprocess\.`, `os\.system\(`, `os\.popen\(`},
+			LanguagePatterns: LanguagePatterns{
+				Network:          []string{`curl\s`, `wget\s`},
+				SensitiveAccess:  []string{`\.env`, `/etc/passwd`, `~/.ssh`, `\.aws/credentials`, `\.netrc`, `\.npmrc`, `\.pypirc`},
+				SecretScanning:   []string{`grep.*password`, `grep.*secret`, `grep.*token`, `grep.*api.key`, `find.*\.env`, `find.*\.ssh`, `find.*\.aws`},
+				DynamicExecution: []string{`exec\(`, `eval\(`},
+			},
+			NotebookMetadata: []CodePattern{
+				{Pattern: `"argv"\s*:`, Description: "kernel started with its own command"},
+				{Pattern: `"source_hidden"\s*:\s*true`, Description: "code cell hidden from view"},
+				{Pattern: `"application/javascript"`, Description: "JavaScript output that runs when the notebook is opened"},
+				{Pattern: `(?i)<script\b`, Description: "script tag in an HTML output"},
+			},
+			DataExport: DataExportConfig{
+				Enabled: true,
+				Commands: []string{
+					"pg_dump", "pg_dumpall", "mysqldump", "mariadb-dump", "mongodump", "mongoexport",
+					"sqlite3 .dump", "redis-cli --rdb",
+				},
+				Credentials: []CodePattern{
+					{Pattern: `(?i)\b(postgres(ql)?|mysql|mariadb|mongodb(\+srv)?|rediss?|amqps?|mssql|sqlserver|jdbc:[a-z]+)://[^\s:/@]+:[^\s/@]+@`, Description: "connection string with a password"},
+					{Pattern: `\b(PGPASSWORD|MYSQL_PWD|REDISCLI_AUTH)=\S+`, Description: "password in an environment variable"},
+					{Pattern: `\b(mysql|mysqldump|mariadb|mariadb-dump)\b.*\s(-p\S+|--password=\S+)`, Description: "password option"},
+					{Pattern: `\b(mongo|mongosh|mongodump|mongoexport|mongorestore)\b.*\s(-p|--password)[=\s]+\S+`, Description: "password option"},
+					{Pattern: `\bredis-cli\b.*\s(-a|--pass)\s+\S+`, Description: "password option"},
+				},
+				MaxDumpBytes: 100 * 1024 * 1024,
+			},
+			Python: LanguagePatterns{
+				Network:          []string{`import\s+(requests|urllib|httpx|aiohttp)`, `from\s+(requests|urllib|httpx)\s`, `socket\.`, `urlopen\(`},
+				SecretScanning:   []string{`glob\(.*\.env`, `os\.walk.*password`, `re\.search.*password`, `re\.findall.*secret`},
+				SystemRecon:      []string{`os\.environ`, `getpass\.getuser`, `socket\.gethostname`, `platform\.`, `subprocess.*whoami`, `subprocess.*id\s`, `subprocess.*uname`},
+				DynamicExecution: []string{`compile\(`, `__import__\(`, `importlib\.import_module`, `subprocess\..*shell=True`},
+				ShellExecution:   []string{`subprocess\.`, `os\.system\(`, `os\.popen\(`},
+			},
+			JavaScript: LanguagePatterns{
+				Network:          []string{`require\(['"](http|https|net|axios|node-fetch)['"]\)`, `from\s+['"](http|https|net|axios|node-fetch)['"]`, `fetch\(`, `XMLHttpRequest`, `net\.connect`},
+				SecretScanning:   []string{`readdir.*\.env`, `glob.*\.env`},
+				SystemRecon:      []string{`JSON\.stringify\(process\.env\)`, `Object\.(keys|entries)\(process\.env\)`, `os\.hostname\(`, `os\.userInfo\(`, `os\.networkInterfaces\(`},
+				DynamicExecution: []string{`new\s+Function\(`, `vm\.run`},
+				ShellExecution:   []string{`child_process`, `execSync\(`, `spawn\(`},
+			},
+			Shell: LanguagePatterns{
+				Network:          []string{`\bnc\s`, `/dev/tcp/`, `\bssh\s`, `\bscp\s`},
+				SecretScanning:   []string{`rg.*password`, `rg.*secret`},
+				SystemRecon:      []string{`\bwhoami\b`, `\buname\s`, `\bhostname\b`, `(?m)^\s*(env|printenv)\s*$`},
+				DynamicExecution: []string{`base64\s+(-d|--decode).*\|\s*(ba|z)?sh`},
+			},
+		},
+		MassModification: MassModificationConfig{
+			Enabled:             true,
+			MaxFilesDeleted:     50,
+			MaxFilesOverwritten: 50,
+			MaxWriteBytes:       5 * 1024 * 1024,
+			SessionIdleMinutes:  60,
+			StateFile:           ".claude/hooks/security-guardian/.session.json",
+		},
+		Trash: TrashConfig{
+			Enabled:   false,
+			Directory: ".claude/trash",
+		},
+		DryRun: DryRunConfig{
+			Enabled:        false,
+			OnlyWhenDenied: true,
+			Commands: []DryRunCommand{
+				{Match: "git clean", Append: "--dry-run"},
+				{Match: "terraform apply", Replace: "terraform plan", Drop: []string{"-auto-approve"}},
+				{Match: "terraform destroy", Replace: "terraform plan -destroy", Drop: []string{"-auto-approve"}},
+				{Match: "tofu apply", Replace: "tofu plan", Drop: []string{"-auto-approve"}},
+				{Match: "kubectl apply", Append: "--dry-run=server"},
+				{Match: "kubectl delete", Append: "--dry-run=server"},
+				{Match: "helm upgrade", Append: "--dry-run"},
+				{Match: "helm uninstall", Append: "--dry-run"},
+				{Match: "rsync", Append: "--dry-run"},
+			},
+		},
+		TrustedScripts: TrustedScriptsConfig{
+			Enabled:  true,
+			Manifest: ".claude/hooks/security-guardian/trusted_scripts.yaml",
+		},
+		RememberApprovals: RememberApprovalsConfig{
+			Enabled: false,
+			Rules:   []string{"execution.chmod_downloaded", "execution.chmod_binary", "unpack.outside_project"},
+			Store:   "",
+		},
+		ExceptionCodes: ExceptionCodesConfig{
+			Enabled:    true,
+			DefaultTTL: "30m",
+			MaxTTL:     "24h",
+			ExcludedRules: []string{
+				"tamper.*", "canary.*", "decoy.*", "internal.*",
+				"bypass.self_trust", "bypass.self_exception", "bypass.self_update", "secrets.vault_access", "gpg.export_secret_key",
+				"deletion.critical_path", "deletion.move_project_root", "git.hard_blocked", "bypass.hard_blocked",
+				"unpack.bypass_pattern", "unpack.path_traversal", "directory.symlink_escape",
+			},
+		},
+		AllowCache: AllowCacheConfig{
+			Enabled:    true,
+			TTL:        "1h",
+			MaxEntries: 500,
+			Commands: []string{
+				"go build", "go test", "go vet",
+				"cargo build", "cargo check", "cargo test",
+				"npm test", "npm run", "yarn test", "yarn run", "pnpm test", "pnpm run",
+				"make", "pytest", "tsc",
+				"git status", "git diff", "git log", "ls",
+			},
+			Store: "",
+		},
+		Decoys: DecoysConfig{
+			Enabled:  true,
+			Registry: ".claude/hooks/security-guardian/decoys.yaml",
+		},
+		GPG: GPGConfig{
+			Enabled: true,
+			Home:    "",
+		},
+		Pipelines: PipelinesConfig{
+			Enabled: true,
+			DataSources: []string{
+				"tar", "zip", "7z", "git archive", "git bundle",
+				"pg_dump", "pg_dumpall", "mysqldump", "mariadb-dump", "mongodump", "mongoexport",
+				"sqlite3 .dump", "redis-cli --rdb",
+			},
+			SecretSources: []string{"env", "printenv", "export -p", "declare -x"},
+			Encoders: []string{
+				"base64", "base32", "basenc", "xxd", "od", "hexdump", "uuencode",
+				"gzip", "bzip2", "xz", "zstd", "openssl enc", "openssl base64", "gpg",
+			},
+			NetworkSinks: []string{
+				"curl", "wget", "nc", "ncat", "netcat", "socat", "ssh", "telnet",
+				"openssl s_client", "aws s3", "gsutil cp", "rclone rcat",
+			},
+		},
+		CloudMetadata: CloudMetadataConfig{
+			Enabled: true,
+			Endpoints: []string{
+				"169.254.169.254", "2852039166", "0xa9fea9fe", "0251.0376.0251.0376",
+				"fd00:ec2::254", "169.254.170.2",
+				"metadata.google.internal", "metadata.goog",
+				"168.63.129.16", "100.100.100.200",
+			},
+			Allowed: []string{},
+		},
+		TamperDetection: TamperDetectionConfig{
+			Enabled: true,
+			Patterns: []CodePattern{
+				{Pattern: `\b(grep|egrep|fgrep|rg|ag|ack|find|fd|locate)\b[^|;&\n]*((security[-_]guardian|pre-?tool-?use)[^|;&\n]*\.claude\b|\.claude\b[^|;&\n]*(security[-_]guardian|pre-?tool-?use))`, Description: "Search the Claude config for the guardian hook"},
+				{Pattern: `\b(grep|egrep|fgrep|rg|ag|ack)\b[^|;&\n]*\bhooks\b[^|;&\n]*settings(\.local)?\.json`, Description: "Search the hooks in settings"},
+				{Pattern: `\bjq\b[^|;&\n]*\.hooks\b`, Description: "Read the hooks in settings"},
+				{Pattern: `\b(kill|pkill|killall|pgrep|pidof)\b[^|;&\n]*guardian`, Description: "Find or stop the guardian process"},
+				{Pattern: `\b(mv|rm|unlink|chmod|truncate|shred)\b[^|;&\n]*(bin/guardian\b|security-guardian/main\.py)`, Description: "Move or disable the guardian binary"},
+				{Pattern: `\bunset\b[^|;&\n]*\bCLAUDE_PROJECT_DIR\b|\benv\b[^|;&\n]*-u\s*CLAUDE_PROJECT_DIR\b|(^|[\s;&|(])CLAUDE_PROJECT_DIR=`, Description: "Change CLAUDE_PROJECT_DIR"},
+			},
+		},
+		CanaryTokens: CanaryTokensConfig{
+			Enabled: false,
+		},
+		Subagents: SubagentsConfig{
+			Enabled:      true,
+			BlockedTypes: []string{},
+			BlockedPatterns: []CodePattern{
+				{Pattern: `(disable|remove|bypass|skip|turn\s+off|delete|uninstall)\s+(the\s+|all\s+)?(security\s+|pre-?tool-?use\s+)?(hooks?|guardian)`, Description: "Disable hooks"},
+				{Pattern: `(edit|modify|change|overwrite|rewrite)\s+(the\s+)?\.?claude/settings(\.local)?\.json`, Description: "Change Claude Code settings"},
+				{Pattern: `dangerously-skip-permissions|bypassPermissions`, Description: "Skip permission prompts"},
+				{Pattern: `(ignore|bypass|work\s*around|get\s+around|circumvent|evade)\s+(the\s+)?(security|guardian|hooks?|permissions?|restrictions?|blocks?)`, Description: "Get around restrictions"},
+				{Pattern: `\bsudo\s|\bas\s+root\b|\broot\s+(access|privileges|permissions)`, Description: "Elevated privileges"},
+			},
+		},
+		WebSearch: WebSearchConfig{
+			Enabled: true,
+			BlockedPatterns: []CodePattern{
+				{Pattern: `\b[a-z0-9][a-z0-9.-]*\.(internal|local|corp|lan|intranet|private)\b`, Description: "Internal hostname"},
+				{Pattern: `\b(10\.\d{1,3}|192\.168|172\.(1[6-9]|2\d|3[01]))\.\d{1,3}\.\d{1,3}\b`, Description: "Private IP address"},
+				{Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----`, Description: "Private key"},
+				{Pattern: `\b(AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36}|sk-[A-Za-z0-9_-]{20,}|xox[abpr]-[A-Za-z0-9-]{10,})\b`, Description: "API token"},
+			},
+		},
+		SlashCommands: SlashCommandsConfig{
+			Enabled: false,
+			Allowed: []string{},
+		},
+		BackgroundShells: BackgroundShellsConfig{
+			Audit:            true,
+			FlagDeniedOutput: true,
+			MaxTracked:       20,
+		},
+		SessionSummary: SessionSummaryConfig{
+			Enabled:     true,
+			Store:       ".claude/hooks/security-guardian/decisions.jsonl",
+			PrintOnStop: true,
+			TopRules:    3,
+		},
+		Explain: ExplainConfig{
+			Enabled:         true,
+			Store:           ".claude/hooks/security-guardian/recent_decisions.jsonl",
+			MaxRecords:      50,
+			MaxContentBytes: 65536,
+		},
+		SessionStart: SessionStartConfig{
+			Enabled:       true,
+			ShowWarnings:  true,
+			InjectContext: true,
+		},
+		PromptInjection: PromptInjectionConfig{
+			Enabled:    true,
+			ScanWrites: true,
+			Patterns: []CodePattern{
+				{Pattern: `(ignore|disregard|forget)\s+(all\s+|any\s+)?(the\s+|your\s+)?(previous|prior|above|earlier)\s+(instructions|prompts|directions|rules)`, Description: "Override of previous instructions"},
+				{Pattern: `(new|updated|real)\s+(system\s+)?instructions\s*:`, Description: "Injected instructions block"},
+				{Pattern: `</?(system|system-reminder|instructions|admin)>`, Description: "Fake system/instruction tags"},
+				{Pattern: `<!--[^>]{0,300}\b(assistant|claude|ai agent|llm|run|execute|bash|curl|tool)\b[^>]{0,300}-->`, Description: "Hidden HTML comment with tool directives"},
+				{Pattern: `you\s+are\s+now\s+(in\s+)?(developer|god|jailbreak|unrestricted|dan)\b`, Description: "Role hijack"},
+				{Pattern: `(do\s+not|don't|never)\s+(tell|inform|mention\s+(this\s+)?to)\s+the\s+user`, Description: "Instruction to hide actions from the user"},
+				{Pattern: `(send|post|upload|exfiltrate)\s+(the\s+|your\s+|all\s+)?(contents\s+of\s+)?(\.env|secrets?|api\s+keys?|credentials|ssh\s+keys?)`, Description: "Request to send secrets"},
+			},
+		},
+		Messages: MessagesConfig{
+			Structured:  true,
+			Remediation: true,
+			Language:    "en",
+			Overrides:   map[string]MessageOverride{},
+		},
+		InputLimits: InputLimitsConfig{
+			MaxInputBytes:     32 * 1024 * 1024,
+			OnOversized:       OversizedAsk,
+			MaxScanBytes:      1024 * 1024,
+			SampleKB:          64,
+			SampleWindows:     16,
+			ChunkKB:           256,
+			ChunkOverlap:      4096,
+			MaxPatternMatches: 100,
+		},
+		Performance: PerformanceConfig{
+			ParallelMinCommands: 8,
+			MaxParallel:         0,
+			CheckTimeoutMs:      2000,
+			ToolTimeoutsMs:      map[string]int{},
+			OnTimeout:           FailAsk,
+			ProbeCacheSeconds:   300,
+			BudgetP95Ms:         15,
 		},
 		Logging: LoggingConfig{
 			Enabled:      true,
@@ -204,5 +992,6 @@ func DefaultConfig() *SecurityConfig {
 			MaxLogSizeMB: 10,
 			MaxLogFiles:  5,
 		},
+		CustomRules: []CustomRule{},
 	}
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate reports settings a config can't mean: values outside the
+// choices of an option. A config that fails is rejected where it can be
+// (a reload by guardian serve) instead of running with a guessed meaning.
+func Validate(cfg *SecurityConfig) error {
+	var errs []error
+	oneOf := func(key, value string, choices ...string) {
+		for _, c := range choices {
+			if strings.EqualFold(value, c) {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s: %q is not one of %s", key, value, strings.Join(choices, ", ")))
+	}
+
+	oneOf("yolo_mode", cfg.YoloMode, YoloModeAuto, YoloModeOn, YoloModeOff, "true", "false", "1", "0")
+	oneOf("on_internal_error", cfg.OnInternalError, FailAllow, FailAsk, FailDeny)
+	oneOf("performance.on_timeout", cfg.Performance.OnTimeout, FailAllow, FailAsk, FailDeny)
+	oneOf("input_limits.on_oversized", cfg.InputLimits.OnOversized, OversizedAllow, OversizedAsk)
+
+	for _, window := range cfg.Unattended.Hours {
+		if _, _, err := ParseTimeWindow(window); err != nil {
+			errs = append(errs, fmt.Errorf("unattended.hours: %w", err))
+		}
+	}
+
+	for _, ttl := range []struct{ key, value string }{
+		{"exception_codes.default_ttl", cfg.ExceptionCodes.DefaultTTL},
+		{"exception_codes.max_ttl", cfg.ExceptionCodes.MaxTTL},
+		{"allow_cache.ttl", cfg.AllowCache.TTL},
+	} {
+		if _, err := time.ParseDuration(ttl.value); ttl.value != "" && err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", ttl.key, err))
+		}
+	}
+
+	errs = append(errs, validateOverrides(cfg)...)
+
+	rules := make([]string, 0, len(cfg.Decisions))
+	for rule := range cfg.Decisions {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		oneOf("decisions."+rule, cfg.Decisions[rule], FailAllow, FailAsk, FailDeny)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,187 @@
+// Package decoy manages honeypot secret files. `guardian decoy install`
+// writes a realistic-looking .env with random canary values and records it
+// in a registry; any access to the file or use of a canary value is a
+// strong signal that something is hunting for secrets.
+package decoy
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Decoy is an installed decoy file and the canary values it contains.
+type Decoy struct {
+	Path      string   `yaml:"path"` // relative to project root
+	Canaries  []string `yaml:"canaries"`
+	Installed string   `yaml:"installed"`
+}
+
+// Registry lists installed decoys.
+type Registry struct {
+	Decoys []Decoy `yaml:"decoys"`
+
+	path string
+}
+
+// RegistryPath returns the registry location for a project.
+func RegistryPath(cfg *config.SecurityConfig, projectRoot string) string {
+	if filepath.IsAbs(cfg.Decoys.Registry) {
+		return cfg.Decoys.Registry
+	}
+	return filepath.Join(projectRoot, cfg.Decoys.Registry)
+}
+
+// Load reads the registry at path. A missing file yields an empty registry.
+func Load(path string) (*Registry, error) {
+	r := &Registry{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Save writes the registry back to disk (owner-only: it holds the canaries).
+func (r *Registry) Save() error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	header := "# Managed by `guardian decoy install`. Do not edit by hand.\n"
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append([]byte(header), data...), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// Install writes a decoy env file at rel (relative to projectRoot) and
+// records it. An existing file is never overwritten.
+func (r *Registry) Install(projectRoot, rel string) (*Decoy, error) {
+	abs := filepath.Join(projectRoot, rel)
+	if _, err := os.Lstat(abs); err == nil {
+		return nil, fmt.Errorf("%s already exists, refusing to overwrite", rel)
+	}
+
+	d := Decoy{
+		Path:      filepath.ToSlash(rel),
+		Installed: time.Now().UTC().Format(time.RFC3339),
+	}
+	content := d.generate()
+
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(abs, []byte(content), 0600); err != nil {
+		return nil, err
+	}
+
+	r.Decoys = append(r.Decoys, d)
+	return &r.Decoys[len(r.Decoys)-1], nil
+}
+
+// generate returns the decoy file content, filling d.Canaries.
+func (d *Decoy) generate() string {
+	canary := func(prefix, alphabet string, n int) string {
+		v := prefix + randomString(alphabet, n)
+		d.Canaries = append(d.Canaries, v)
+		return v
+	}
+	const (
+		upperDigits = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+		alnum       = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+		hexDigits   = "0123456789abcdef"
+	)
+
+	var b strings.Builder
+	b.WriteString("# Production environment - do not commit\n")
+	fmt.Fprintf(&b, "DATABASE_URL=postgres://app_prod:%s@db-prod.internal:5432/app\n", canary("", alnum, 24))
+	b.WriteString("REDIS_URL=redis://cache-prod.internal:6379/0\n")
+	fmt.Fprintf(&b, "AWS_ACCESS_KEY_ID=%s\n", canary("AKIA", upperDigits, 16))
+	fmt.Fprintf(&b, "AWS_SECRET_ACCESS_KEY=%s\n", canary("", alnum+"/+", 40))
+	fmt.Fprintf(&b, "STRIPE_SECRET_KEY=%s\n", canary("sk_live_", alnum, 24))
+	fmt.Fprintf(&b, "JWT_SECRET=%s\n", canary("", hexDigits, 64))
+	return b.String()
+}
+
+// randomString returns n characters drawn from alphabet using crypto/rand.
+func randomString(alphabet string, n int) string {
+	out := make([]byte, n)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			idx = big.NewInt(int64(time.Now().UnixNano() % int64(len(alphabet))))
+		}
+		out[i] = alphabet[idx.Int64()]
+	}
+	return string(out)
+}
+
+// Match returns the decoy at the resolved absolute path, or nil.
+func (r *Registry) Match(projectRoot, resolved string) *Decoy {
+	rel, err := filepath.Rel(projectRoot, resolved)
+	if err != nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	for i := range r.Decoys {
+		if r.Decoys[i].Path == rel {
+			return &r.Decoys[i]
+		}
+	}
+	return nil
+}
+
+// FindCanary returns the decoy whose canary value appears in s, or nil.
+func (r *Registry) FindCanary(s string) *Decoy {
+	for i := range r.Decoys {
+		for _, c := range r.Decoys[i].Canaries {
+			if c != "" && strings.Contains(s, c) {
+				return &r.Decoys[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Notify runs decoys.notify_command with details in the environment
+// (GUARDIAN_TOOL, GUARDIAN_REASON). Failures are returned for logging;
+// they never change the decision.
+func Notify(cfg *config.SecurityConfig, tool, reason string) error {
+	command := cfg.Decoys.NotifyCommand
+	if command == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"GUARDIAN_TOOL="+tool,
+		"GUARDIAN_REASON="+reason,
+	)
+	return cmd.Run()
+}
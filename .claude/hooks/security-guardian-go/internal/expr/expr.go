@@ -0,0 +1,214 @@
+// Package expr parses and evaluates the boolean expressions of
+// custom_rules over named signals:
+//
+//	download && (outside_project || secrets_path) && !trusted_script
+//
+// Operators are ! (not), && (and) and || (or), in that order of
+// precedence, with parentheses for grouping. The words not, and, or (in
+// any case) may be used instead.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+type node interface {
+	eval(signal func(string) bool) bool
+	names(seen map[string]bool)
+}
+
+type ident string
+
+type notNode struct{ x node }
+
+type andNode struct{ l, r node }
+
+type orNode struct{ l, r node }
+
+func (n ident) eval(signal func(string) bool) bool { return signal(string(n)) }
+func (n ident) names(seen map[string]bool)         { seen[string(n)] = true }
+
+func (n notNode) eval(signal func(string) bool) bool { return !n.x.eval(signal) }
+func (n notNode) names(seen map[string]bool)         { n.x.names(seen) }
+
+func (n andNode) eval(signal func(string) bool) bool {
+	return n.l.eval(signal) && n.r.eval(signal)
+}
+func (n andNode) names(seen map[string]bool) { n.l.names(seen); n.r.names(seen) }
+
+func (n orNode) eval(signal func(string) bool) bool {
+	return n.l.eval(signal) || n.r.eval(signal)
+}
+func (n orNode) names(seen map[string]bool) { n.l.names(seen); n.r.names(seen) }
+
+// Parse parses an expression.
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression; signal reports whether a named signal
+// is present.
+func (e *Expr) Eval(signal func(name string) bool) bool {
+	return e.root.eval(signal)
+}
+
+// Names returns the signal names the expression uses, sorted.
+func (e *Expr) Names() []string {
+	seen := make(map[string]bool)
+	e.root.names(seen)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// lex splits src into operators, parentheses and identifiers.
+func lex(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(src[i:], "&&") || strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, src[i:i+2])
+			i += 2
+		case isIdentChar(rune(c)):
+			j := i
+			for j < len(src) && isIdentChar(rune(src[j])) {
+				j++
+			}
+			word := src[i:j]
+			switch strings.ToLower(word) {
+			case "not":
+				word = "!"
+			case "and":
+				word = "&&"
+			case "or":
+				word = "||"
+			}
+			tokens = append(tokens, word)
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+// isIdentChar reports whether c may appear in a signal name.
+func isIdentChar(c rune) bool {
+	return c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.' || c == '-')
+}
+
+// parser is a recursive descent parser over tokens.
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// or := and { "||" and }
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// and := unary { "&&" unary }
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// unary := "!" unary | "(" or ")" | name
+func (p *parser) unary() (node, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case "!":
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	case "(":
+		p.pos++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	case ")", "&&", "||":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+	p.pos++
+	return ident(tok), nil
+}
@@ -0,0 +1,91 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	signals := map[string]bool{"download": true, "outside_project": true, "trusted_script": false, "ci": false}
+	signal := func(name string) bool { return signals[name] }
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"download", true},
+		{"ci", false},
+		{"unknown", false},
+		{"!ci", true},
+		{"!!download", true},
+		{"download && outside_project", true},
+		{"download && ci", false},
+		{"ci || download", true},
+		{"ci || trusted_script", false},
+		// && binds tighter than ||
+		{"download || ci && trusted_script", true},
+		{"(download || ci) && trusted_script", false},
+		// ! binds tighter than &&
+		{"!ci && download", true},
+		{"!(ci || download)", false},
+		{"download && (outside_project || secrets_path) && !trusted_script", true},
+		// Word operators in any case
+		{"download and not ci", true},
+		{"ci OR Download", false},
+		{"ci OR download", true},
+		{"NOT download", false},
+		{"  download\n&&\toutside_project ", true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		if got := e.Eval(signal); got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+		if e.String() != tt.src {
+			t.Errorf("String() = %q, want %q", e.String(), tt.src)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{"", "empty expression"},
+		{"   ", "empty expression"},
+		{"download &&", "unexpected end"},
+		{"&& download", `unexpected "&&"`},
+		{"download ci", `unexpected "ci"`},
+		{"(download", "missing )"},
+		{"download)", `unexpected ")"`},
+		{"()", `unexpected ")"`},
+		{"!", "unexpected end"},
+		{"download & ci", "unexpected character"},
+		{"download | ci", "unexpected character"},
+		{"down/load", "unexpected character"},
+		{"télé", "unexpected character"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.src, err, tt.err)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	e, err := Parse("(writes || delete) && !ci && writes && git.dirty && x-y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ci", "delete", "git.dirty", "writes", "x-y"}
+	if got := e.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+// Package gitbackup snapshots the working tree into a ref before
+// destructive git operations (reset --hard, clean -f, checkout -- ...),
+// so discarded changes can be recovered. The snapshot is a regular
+// commit of tracked and untracked (non-ignored) files; the working tree,
+// index and HEAD are left untouched.
+package gitbackup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// RefPrefix is the namespace for backup refs.
+const RefPrefix = "refs/guardian/"
+
+// gitTimeout bounds each git call; snapshots must not stall the hook.
+const gitTimeout = 10 * time.Second
+
+// Backup describes a snapshot ref.
+type Backup struct {
+	Ref     string
+	Date    time.Time
+	Subject string
+}
+
+// DestructiveCommand returns the first git command that discards
+// working tree changes, or nil.
+func DestructiveCommand(cmds []*parsers.ParsedCommand) *parsers.ParsedCommand {
+	for _, cmd := range cmds {
+		if cmd.Command != "git" {
+			continue
+		}
+		subcommand, flags := parsers.GetGitSubcommandAndFlags([]*parsers.ParsedCommand{cmd})
+		if isDestructive(subcommand, expandFlags(flags), cmd.Args) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// RepoDir returns the directory git operates on for cmd, honoring
+// `git -C <dir>` relative to the directory the command runs in.
+func RepoDir(cmd *parsers.ParsedCommand) string {
+	for _, f := range cmd.Flags {
+		if f == "-C" && len(cmd.Args) > 0 {
+			return parsers.JoinDir(cmd.Dir, cmd.Args[0])
+		}
+	}
+	return parsers.JoinDir(cmd.Dir, ".")
+}
+
+// isDestructive reports whether a git subcommand discards uncommitted work.
+func isDestructive(subcommand string, flags map[string]bool, args []string) bool {
+	switch subcommand {
+	case "reset":
+		return flags["--hard"]
+	case "clean":
+		return (flags["-f"] || flags["--force"]) && !flags["-n"] && !flags["--dry-run"]
+	case "checkout":
+		if flags["--"] || flags["-f"] || flags["--force"] {
+			return true
+		}
+		for _, a := range args {
+			if a == "." {
+				return true
+			}
+		}
+		return false
+	case "restore":
+		// --staged alone only touches the index
+		return !flags["--staged"] && !flags["-S"] || flags["--worktree"] || flags["-W"]
+	case "switch":
+		return flags["-f"] || flags["--force"] || flags["--discard-changes"]
+	}
+	return false
+}
+
+// expandFlags expands combined short flags (-fd -> -f, -d) into a set.
+func expandFlags(flags []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, f := range flags {
+		if strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && len(f) > 2 {
+			for _, ch := range f[1:] {
+				result["-"+string(ch)] = true
+			}
+		} else {
+			result[f] = true
+		}
+	}
+	return result
+}
+
+// Create snapshots the working tree of the repository containing dir
+// and stores it as refs/guardian/backup-<timestamp>. Returns the ref.
+func Create(dir string, message string) (string, error) {
+	top, err := git(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	// Use a copy of the real index so `git add -A` only rehashes changed files
+	tmp, err := os.CreateTemp("", "guardian-index-*")
+	if err != nil {
+		return "", err
+	}
+	tmpIndex := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpIndex)
+
+	if indexPath, err := git(top, nil, "rev-parse", "--git-path", "index"); err == nil {
+		if !filepath.IsAbs(indexPath) {
+			indexPath = filepath.Join(top, indexPath)
+		}
+		if data, err := os.ReadFile(indexPath); err == nil {
+			os.WriteFile(tmpIndex, data, 0600)
+		} else {
+			os.Remove(tmpIndex)
+		}
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpIndex}
+	if _, err := git(top, env, "add", "-A"); err != nil {
+		return "", err
+	}
+	tree, err := git(top, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if head, err := git(top, nil, "rev-parse", "--verify", "-q", "HEAD"); err == nil && head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := git(top, guardianIdentity(), args...)
+	if err != nil {
+		return "", err
+	}
+
+	base := RefPrefix + "backup-" + time.Now().Format("20060102-150405")
+	ref := base
+	for i := 1; ; i++ {
+		if _, err := git(top, nil, "rev-parse", "--verify", "-q", ref); err != nil {
+			break
+		}
+		ref = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	if _, err := git(top, nil, "update-ref", ref, commit); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// List returns backup refs, newest first.
+func List(dir string) ([]Backup, error) {
+	out, err := git(dir, nil, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname)%09%(creatordate:iso-strict)%09%(subject)", RefPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[1])
+		backups = append(backups, Backup{Ref: parts[0], Date: date, Subject: parts[2]})
+	}
+	return backups, nil
+}
+
+// Prune deletes backup refs older than olderThan and returns how many were removed.
+func Prune(dir string, olderThan time.Duration) (int, error) {
+	backups, err := List(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	for _, b := range backups {
+		if b.Date.After(cutoff) {
+			continue
+		}
+		if _, err := git(dir, nil, "update-ref", "-d", b.Ref); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// guardianIdentity provides a commit identity when the user has none configured.
+func guardianIdentity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=security-guardian", "GIT_AUTHOR_EMAIL=security-guardian@localhost",
+		"GIT_COMMITTER_NAME=security-guardian", "GIT_COMMITTER_EMAIL=security-guardian@localhost",
+	}
+}
+
+// git runs a git command in dir and returns trimmed stdout.
+func git(dir string, env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,93 @@
+package gitstate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitconfig "github.com/go-git/go-git/v5/config"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// Aliases returns the git aliases in effect in dir by lower-case name: those
+// of the system and global config, overridden by the repository's. Outside
+// a repository only the system and global ones apply.
+func Aliases(dir string) map[string]string {
+	aliases := make(map[string]string)
+	for _, file := range configFiles() {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		cfg, err := gitconfig.ReadConfig(f)
+		f.Close()
+		if err == nil {
+			addAliases(aliases, cfg.Raw)
+		}
+	}
+	if r, err := Open(dir); err == nil {
+		if cfg, err := r.repo.Config(); err == nil {
+			addAliases(aliases, cfg.Raw)
+		}
+	}
+	return aliases
+}
+
+// configFiles returns the system and global config files in the order git
+// reads them, later ones overriding earlier ones.
+func configFiles() []string {
+	files := []string{"/etc/gitconfig"}
+	home, _ := os.UserHomeDir()
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		files = append(files, filepath.Join(xdg, "git", "config"))
+	} else if home != "" {
+		files = append(files, filepath.Join(home, ".config", "git", "config"))
+	}
+	if home != "" {
+		files = append(files, filepath.Join(home, ".gitconfig"))
+	}
+	return files
+}
+
+// addAliases adds the [alias] entries of a config.
+func addAliases(aliases map[string]string, raw *format.Config) {
+	if raw == nil || !raw.HasSection("alias") {
+		return
+	}
+	for _, opt := range raw.Section("alias").Options {
+		aliases[strings.ToLower(opt.Key)] = opt.Value
+	}
+}
+
+// PushRemote returns the remote `git push` without arguments pushes branch
+// to: branch.<name>.pushRemote, remote.pushDefault, branch.<name>.remote,
+// or origin.
+func (r *Repo) PushRemote(branch string) string {
+	cfg, err := r.repo.Config()
+	if err != nil || cfg.Raw == nil {
+		return "origin"
+	}
+	if branch != "" {
+		if remote := cfg.Raw.Section("branch").Subsection(branch).Option("pushRemote"); remote != "" {
+			return remote
+		}
+	}
+	if remote := cfg.Raw.Section("remote").Option("pushDefault"); remote != "" {
+		return remote
+	}
+	if b, ok := cfg.Branches[branch]; ok && b.Remote != "" {
+		return b.Remote
+	}
+	return "origin"
+}
+
+// RemotePush returns the refspecs remote.<name>.push configures for pushes
+// without refspecs, and whether remote.<name>.mirror is set.
+func (r *Repo) RemotePush(remote string) (refspecs []string, mirror bool) {
+	cfg, err := r.repo.Config()
+	if err != nil || cfg.Raw == nil || !cfg.Raw.Section("remote").HasSubsection(remote) {
+		return nil, false
+	}
+	sub := cfg.Raw.Section("remote").Subsection(remote)
+	return sub.Options.GetAll("push"), strings.EqualFold(sub.Option("mirror"), "true")
+}
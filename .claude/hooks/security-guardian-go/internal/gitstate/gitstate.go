@@ -0,0 +1,269 @@
+// Package gitstate reads the index, branches, upstreams and history of a
+// repository (via go-git, without running git): which files are tracked,
+// and for judging git operations by what they would do, which commits a
+// force push discards, whether a branch is merged, which remote branches
+// a push deletes.
+package gitstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// maxWalk bounds history walks; the hook must not stall on huge repos.
+const maxWalk = 5000
+
+// ErrTooDeep is returned when a history walk hits maxWalk.
+var ErrTooDeep = errors.New("history too deep to walk")
+
+// Commit is a commit a git operation would discard or leave behind.
+type Commit struct {
+	Hash  string // abbreviated
+	Email string // author
+}
+
+// Repo is an opened repository.
+type Repo struct {
+	repo *git.Repository
+}
+
+// Open opens the repository containing dir.
+func Open(dir string) (*Repo, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{repo: repo}, nil
+}
+
+// CurrentBranch returns the checked out branch, or "" on a detached HEAD.
+func (r *Repo) CurrentBranch() string {
+	head, err := r.repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+	return head.Name().Short()
+}
+
+// UserEmail returns the email commits are authored with: GIT_AUTHOR_EMAIL,
+// or user.email from the repository and global config.
+func (r *Repo) UserEmail() string {
+	if email := os.Getenv("GIT_AUTHOR_EMAIL"); email != "" {
+		return email
+	}
+	cfg, err := r.repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return ""
+	}
+	return cfg.User.Email
+}
+
+// Upstream returns the remote and remote branch branch tracks, or "" if
+// it has no upstream.
+func (r *Repo) Upstream(branch string) (remote, remoteBranch string) {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return "", ""
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return "", ""
+	}
+	return b.Remote, b.Merge.Short()
+}
+
+// HasRemote reports whether remote is configured.
+func (r *Repo) HasRemote(remote string) bool {
+	_, err := r.repo.Remote(remote)
+	return err == nil
+}
+
+// Unmerged returns the commits of a local branch not reachable from HEAD
+// (what `git branch -D` would leave unreferenced).
+func (r *Repo) Unmerged(branch string) ([]Commit, error) {
+	tip, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return r.commitsNotIn(tip.Hash(), head.Hash())
+}
+
+// Overwritten returns the commits of remote/remoteBranch, as of the last
+// fetch, that pushing src with --force would discard. A branch the remote
+// doesn't have yet has none.
+func (r *Repo) Overwritten(src, remote, remoteBranch string) ([]Commit, error) {
+	local, err := r.repo.ResolveRevision(plumbing.Revision(src))
+	if err != nil {
+		return nil, err
+	}
+	tracking, err := r.repo.Reference(plumbing.NewRemoteReferenceName(remote, remoteBranch), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.commitsNotIn(tracking.Hash(), *local)
+}
+
+// RemoteOnlyBranches returns the remote-tracking branches of remote with
+// no local branch of the same name (what push --prune would delete).
+func (r *Repo) RemoteOnlyBranches(remote string) ([]string, error) {
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	prefix := remote + "/"
+	var branches []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsRemote() || !strings.HasPrefix(ref.Name().Short(), prefix) {
+			return nil
+		}
+		name := strings.TrimPrefix(ref.Name().Short(), prefix)
+		if name == "HEAD" {
+			return nil
+		}
+		if _, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), false); err != nil {
+			branches = append(branches, name)
+		}
+		return nil
+	})
+	sort.Strings(branches)
+	return branches, err
+}
+
+// Index is a snapshot of the paths in a repository's index.
+type Index struct {
+	root  string
+	paths map[string]bool
+}
+
+// ReadIndex reads the index of the repository containing dir. An error
+// means it couldn't be read (no repository, an index format go-git
+// doesn't support).
+func ReadIndex(dir string) (*Index, error) {
+	r, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.EvalSymlinks(wt.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		paths[entry.Name] = true
+	}
+	return &Index{root: root, paths: paths}, nil
+}
+
+// IndexFile returns the index file of the repository containing dir,
+// found by walking up to its .git: a directory, or the file a worktree or
+// submodule has pointing to its git directory.
+func IndexFile(dir string) (string, error) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return filepath.Join(dotGit, "index"), nil
+			}
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", err
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", errors.New(dotGit + ": no gitdir")
+			}
+			gitDir = strings.TrimSpace(gitDir)
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return filepath.Join(gitDir, "index"), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", git.ErrRepositoryNotExists
+		}
+		dir = parent
+	}
+}
+
+// Tracked reports whether path is in the index.
+func (x *Index) Tracked(path string) bool {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(x.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	return x.paths[filepath.ToSlash(rel)]
+}
+
+// commitsNotIn returns the commits reachable from tip but not from base.
+func (r *Repo) commitsNotIn(tip, base plumbing.Hash) ([]Commit, error) {
+	baseCommit, err := r.repo.CommitObject(base)
+	if err != nil {
+		return nil, err
+	}
+	reachable := make(map[plumbing.Hash]bool)
+	err = walk(baseCommit, nil, func(c *object.Commit) {
+		reachable[c.Hash] = true
+	})
+	// A truncated base only makes more commits count as not in it
+	if err != nil && err != ErrTooDeep {
+		return nil, err
+	}
+
+	tipCommit, err := r.repo.CommitObject(tip)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	err = walk(tipCommit, reachable, func(c *object.Commit) {
+		commits = append(commits, Commit{Hash: c.Hash.String()[:7], Email: c.Author.Email})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// walk visits up to maxWalk commits from start, not descending into seen.
+func walk(start *object.Commit, seen map[plumbing.Hash]bool, visit func(*object.Commit)) error {
+	iter := object.NewCommitPreorderIter(start, seen, nil)
+	defer iter.Close()
+	n := 0
+	return iter.ForEach(func(c *object.Commit) error {
+		if n++; n > maxWalk {
+			return ErrTooDeep
+		}
+		visit(c)
+		return nil
+	})
+}
@@ -0,0 +1,235 @@
+// Package globs matches slash-separated paths against glob patterns with
+// doublestar semantics:
+//
+//	syntax  matches
+//	*       any run of characters except /
+//	?       one character except /
+//	[abc]   a character class ([!abc] or [^abc] negated, ranges allowed)
+//	{a,b}   either alternative, within a segment (may hold globs, not nest)
+//	**      as a whole segment: zero or more segments, so a/**/b matches
+//	        a/b and a/x/y/b, **/x matches x at any depth and a/** matches
+//	        a itself and everything under it
+//	\x      x literally
+//
+// Pattern lists (MatchList) follow gitignore: a "!pattern" entry excludes
+// what earlier entries matched and the last matching entry wins, so
+// [".claude/**", "!.claude/notes/**"] covers .claude except its notes.
+//
+// Patterns are compiled once and cached for the life of the process.
+package globs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Glob is a compiled glob pattern.
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+var (
+	cacheMu sync.RWMutex
+	cache   = make(map[string]*Glob)
+)
+
+// Compile compiles a glob pattern.
+func Compile(pattern string) (*Glob, error) {
+	cacheMu.RLock()
+	g, ok := cache[pattern]
+	cacheMu.RUnlock()
+	if ok {
+		return g, nil
+	}
+
+	expr, err := translate(pattern)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("glob %q: %w", pattern, err)
+	}
+	g = &Glob{pattern: pattern, re: re}
+
+	cacheMu.Lock()
+	cache[pattern] = g
+	cacheMu.Unlock()
+	return g, nil
+}
+
+// Match reports whether name matches pattern. An invalid pattern matches
+// nothing.
+func Match(pattern, name string) bool {
+	g, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return g.Match(name)
+}
+
+// MatchAny returns the first pattern name matches, or "".
+func MatchAny(patterns []string, name string) string {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return p
+		}
+	}
+	return ""
+}
+
+// MatchList matches name against a pattern list with last-match-wins
+// semantics. pattern is the last entry that matched (with its "!" if it is
+// a negation), ok whether name is in the list: matched, and not by a
+// negation.
+func MatchList(patterns []string, name string) (pattern string, ok bool) {
+	return MatchListFunc(patterns, func(p string) bool { return Match(p, name) })
+}
+
+// MatchListFunc is MatchList with a custom matcher, called with each
+// pattern without its "!".
+func MatchListFunc(patterns []string, match func(pattern string) bool) (pattern string, ok bool) {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		p := patterns[i]
+		if negated := strings.HasPrefix(p, "!"); match(strings.TrimPrefix(p, "!")) {
+			return p, !negated
+		}
+	}
+	return "", false
+}
+
+// Match reports whether name matches the glob.
+func (g *Glob) Match(name string) bool {
+	return g.re.MatchString(name)
+}
+
+// String returns the pattern the glob was compiled from.
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// translate converts a glob into a regular expression (without anchors).
+func translate(pattern string) (string, error) {
+	if pattern == "**" {
+		return ".*", nil
+	}
+	segments := strings.Split(pattern, "/")
+
+	var b strings.Builder
+	for i, seg := range segments {
+		if seg == "**" {
+			if i == 0 {
+				// **/x: x at any depth, the next segment writes no slash
+				b.WriteString("(?:.*/)?")
+			} else {
+				// a/** and a/**/b: zero or more segments after a
+				b.WriteString("(?:/.*)?")
+			}
+			continue
+		}
+		if i > 0 && !(i == 1 && segments[0] == "**") {
+			b.WriteString("/")
+		}
+		expr, err := translateSegment(seg)
+		if err != nil {
+			return "", fmt.Errorf("glob %q: %w", pattern, err)
+		}
+		b.WriteString(expr)
+	}
+	return b.String(), nil
+}
+
+// translateSegment converts one path segment of a glob.
+func translateSegment(seg string) (string, error) {
+	var b strings.Builder
+	inBraces := false
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		switch c {
+		case '*':
+			// ** inside a segment (a**b) is a plain *
+			for i+1 < len(seg) && seg[i+1] == '*' {
+				i++
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := classEnd(seg, i)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := seg[i+1 : end]
+			b.WriteString("[")
+			if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+				b.WriteString("^/")
+				class = class[1:]
+			}
+			b.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			b.WriteString("]")
+			i = end
+		case '{':
+			if inBraces {
+				return "", fmt.Errorf("nested braces")
+			}
+			inBraces = true
+			b.WriteString("(?:")
+		case '}':
+			if !inBraces {
+				b.WriteString(`\}`)
+				continue
+			}
+			inBraces = false
+			b.WriteString(")")
+		case ',':
+			if inBraces {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(seg) {
+				_, size := utf8.DecodeRuneInString(seg[i+1:])
+				b.WriteString(regexp.QuoteMeta(seg[i+1 : i+1+size]))
+				i += size
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			// Copy the whole character, not its first byte
+			_, size := utf8.DecodeRuneInString(seg[i:])
+			b.WriteString(regexp.QuoteMeta(seg[i : i+size]))
+			i += size - 1
+		}
+	}
+	if inBraces {
+		return "", fmt.Errorf("unterminated braces")
+	}
+	return b.String(), nil
+}
+
+// classEnd returns the index of the ] closing the class opened at i, or -1.
+func classEnd(seg string, i int) int {
+	j := i + 1
+	if j < len(seg) && (seg[j] == '!' || seg[j] == '^') {
+		j++
+	}
+	// A ] right after [ or [! is literal
+	if j < len(seg) && seg[j] == ']' {
+		j++
+	}
+	for ; j < len(seg); j++ {
+		if seg[j] == '\\' {
+			j++
+			continue
+		}
+		if seg[j] == ']' {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,287 @@
+package globs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/globs"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		// * and ? stay within a segment
+		{"*.key", "server.key", true},
+		{"*.key", "certs/server.key", false},
+		{"*", "", true},
+		{"*", "a/b", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"file?.txt", "file/.txt", false},
+		{"a/*/c", "a/b/c", true},
+		{"a/*/c", "a/b/x/c", false},
+		{"a/*/c", "a/c", false},
+
+		// ** as a whole segment
+		{"**", "", true},
+		{"**", "a/b/c", true},
+		{"**/x", "x", true},
+		{"**/x", "a/b/x", true},
+		{"**/x", "ax", false},
+		{"**/x", "a/x/y", false},
+		{"a/**", "a", true},
+		{"a/**", "a/b/c", true},
+		{"a/**", "ab", false},
+		{"a/**", "b/a/c", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/xb", false},
+		{"a/**/b", "ab", false},
+		{"a/**/b/*.key", "a/b/s.key", true},
+		{"a/**/b/*.key", "a/x/y/b/s.key", true},
+		{"a/**/b/*.key", "a/x/b/y/s.key", false},
+		{"a/**/b/*.key", "a/x/b/s.pem", false},
+		{"**/a/**/b/**", "a/b", true},
+		{"**/a/**/b/**", "x/a/y/b/z", true},
+		{"**/a/**/b/**", "x/a/y/z", false},
+		{"a/**/**/b", "a/b", true},
+		{"a/**/**/b", "a/x/y/b", true},
+		{"**/.git/**", ".git/config", true},
+		{"**/.git/**", "sub/.git/hooks/pre-commit", true},
+		{"**/.git/**", ".gitignore", false},
+
+		// ** inside a segment is a plain *
+		{"a**b", "axxb", true},
+		{"a**b", "a/b", false},
+
+		// classes
+		{"[abc].txt", "b.txt", true},
+		{"[abc].txt", "d.txt", false},
+		{"[!abc].txt", "d.txt", true},
+		{"[^abc].txt", "a.txt", false},
+		{"[a-c]x", "bx", true},
+		{"[a-c]x", "dx", false},
+		{"[]]x", "]x", true},
+		{"[/]x", "/x", false},
+
+		// alternatives
+		{"*.{pem,key}", "id.pem", true},
+		{"*.{pem,key}", "id.key", true},
+		{"*.{pem,key}", "id.crt", false},
+		{"{src,lib}/*.go", "lib/a.go", true},
+		{"{src,lib}/*.go", "cmd/a.go", false},
+		{"{*.yaml,*.yml}", "ci.yml", true},
+
+		// escapes and regexp metacharacters are literal
+		{`\*.txt`, "*.txt", true},
+		{`\*.txt`, "a.txt", false},
+		{"a+b(c).txt", "a+b(c).txt", true},
+		{"a.b", "axb", false},
+		{"$HOME/x", "$HOME/x", true},
+
+		// non-ASCII literals match whole characters
+		{"caf\u00e9/*.txt", "caf\u00e9/menu.txt", true},
+		{"**/\u0434\u043e\u043a\u0443\u043c\u0435\u043d\u0442\u044b/**", "home/\u0434\u043e\u043a\u0443\u043c\u0435\u043d\u0442\u044b/a", true},
+		{"caf\u00e9", "cafe", false},
+		{"caf?", "caf\u00e9", true},
+		{"caf\\\u00e9", "caf\u00e9", true},
+		{"[\u00e9e]x", "\u00e9x", true},
+
+		// anchored at both ends
+		{".env", "x.env", false},
+		{".env", ".env.local", false},
+	}
+	for _, tt := range tests {
+		if got := globs.Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, pattern := range []string{"[abc", "a/[x", "{a,b"} {
+		if _, err := globs.Compile(pattern); err == nil {
+			t.Errorf("Compile(%q) succeeded", pattern)
+		}
+		if globs.Match(pattern, "a") {
+			t.Errorf("invalid %q matches", pattern)
+		}
+	}
+}
+
+func TestCompileCache(t *testing.T) {
+	a, err := globs.Compile("cache/**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := globs.Compile("cache/**/*.go")
+	if a != b {
+		t.Error("Compile doesn't cache the compiled pattern")
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"**/*.pem", "**/secrets/**", "*.key"}
+	tests := []struct {
+		name    string
+		pattern string
+	}{
+		{"certs/server.pem", "**/*.pem"},
+		{"config/secrets/db.yaml", "**/secrets/**"},
+		{"tls.key", "*.key"},
+		{"src/main.go", ""},
+	}
+	for _, tt := range tests {
+		if got := globs.MatchAny(patterns, tt.name); got != tt.pattern {
+			t.Errorf("MatchAny(%q) = %q, want %q", tt.name, got, tt.pattern)
+		}
+	}
+}
+
+func TestMatchList(t *testing.T) {
+	patterns := []string{".claude/**", "!.claude/notes/**", ".claude/notes/secret.md"}
+	tests := []struct {
+		name    string
+		pattern string
+		ok      bool
+	}{
+		{".claude/settings.json", ".claude/**", true},
+		{".claude/notes/todo.md", "!.claude/notes/**", false},
+		{".claude/notes/secret.md", ".claude/notes/secret.md", true},
+		{"src/main.go", "", false},
+	}
+	for _, tt := range tests {
+		pattern, ok := globs.MatchList(patterns, tt.name)
+		if pattern != tt.pattern || ok != tt.ok {
+			t.Errorf("MatchList(%q) = %q, %v; want %q, %v", tt.name, pattern, ok, tt.pattern, tt.ok)
+		}
+	}
+}
+
+// TestDefaultPatterns checks the protected-path patterns the default config
+// ships with against the paths they are meant to cover and near misses.
+func TestDefaultPatterns(t *testing.T) {
+	cfg := config.DefaultConfig()
+	lists := map[string][]string{
+		"protected_paths.no_modify":              cfg.ProtectedPaths.NoModify,
+		"protected_paths.no_read_content":        cfg.ProtectedPaths.NoReadContent,
+		"sensitive_files.forbidden_read":         cfg.SensitiveFiles.ForbiddenRead,
+		"sensitive_files.system_paths":           cfg.SensitiveFiles.SystemPaths,
+		"sensitive_files.vaults":                 cfg.SensitiveFiles.Vaults,
+		"sensitive_files.credential_files":       cfg.SensitiveFiles.CredentialFiles,
+		"protected_paths.no_write_outside_tools": cfg.ProtectedPaths.NoWriteOutsideTools,
+	}
+	tests := []struct {
+		list string
+		name string
+		want bool
+	}{
+		{"protected_paths.no_modify", ".git/config", true},
+		{"protected_paths.no_modify", ".git/hooks/pre-commit", true},
+		{"protected_paths.no_modify", ".git", true},
+		{"protected_paths.no_modify", ".gitignore", false},
+		{"protected_paths.no_modify", ".github/workflows/ci.yml", false},
+		{"protected_paths.no_modify", ".claude/settings.json", true},
+		{"protected_paths.no_modify", ".claude/settings.local.json", true},
+		{"protected_paths.no_modify", ".claude/settings.json.bak", false},
+		{"protected_paths.no_modify", ".claude/commands/review.md", false},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian/config/security_config.yaml", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian/checks/deletion.py", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian-go/internal/checks/deletion.go", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian-go/cmd/guardian/main.go", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian-go/go.mod", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian-go/README.md", false},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian/trusted_scripts.yaml", true},
+		{"protected_paths.no_modify", ".claude/hooks/security-guardian/decoys.yaml", true},
+		{"protected_paths.no_modify", "src/.git.go", false},
+
+		{"protected_paths.no_read_content", ".env", true},
+		{"protected_paths.no_read_content", "services/api/.env", true},
+		{"protected_paths.no_read_content", ".env.local", true},
+		{"protected_paths.no_read_content", "deploy/.env.production", true},
+		{"protected_paths.no_read_content", ".env.example", false},
+		{"protected_paths.no_read_content", "web/.env.template", false},
+		{"protected_paths.no_read_content", "env", false},
+		{"protected_paths.no_read_content", "my.env", false},
+		{"protected_paths.no_read_content", ".envrc", false},
+		{"protected_paths.no_read_content", ".claude/hooks/security-guardian/decoys.yaml", true},
+
+		{"sensitive_files.forbidden_read", "config/secrets.yaml", true},
+		{"sensitive_files.forbidden_read", "secrets.yaml", true},
+		{"sensitive_files.forbidden_read", "secrets.yaml.example", false},
+		{"sensitive_files.forbidden_read", "gcp/credentials.json", true},
+		{"sensitive_files.forbidden_read", "certs/server.pem", true},
+		{"sensitive_files.forbidden_read", "a/b/c/tls.key", true},
+		{"sensitive_files.forbidden_read", "keyboard.go", false},
+		{"sensitive_files.forbidden_read", "docs/keys.md", false},
+		{"sensitive_files.forbidden_read", "home/.ssh/id_rsa", true},
+		{"sensitive_files.forbidden_read", "id_rsa.pub", true},
+		{"sensitive_files.forbidden_read", "id_ed25519", true},
+		{"sensitive_files.forbidden_read", "x/.env.example", false},
+
+		{"sensitive_files.system_paths", "/proc/1/environ", true},
+		{"sensitive_files.system_paths", "/proc/self/environ", true},
+		{"sensitive_files.system_paths", "/proc/1/task/2/environ", true},
+		{"sensitive_files.system_paths", "/proc/1/status", false},
+		{"sensitive_files.system_paths", "/proc/1/fd/environ", false},
+		{"sensitive_files.system_paths", "/sys/class/dmi/id/board_serial", true},
+		{"sensitive_files.system_paths", "/sys/class/dmi/id/board_name", false},
+		{"sensitive_files.system_paths", "/dev/mem", true},
+		{"sensitive_files.system_paths", "/dev/null", false},
+
+		{"sensitive_files.vaults", "~/.config/Exodus/exodus.wallet/seed.seco", true},
+		{"sensitive_files.vaults", "~/Library/Application Support/Electrum/wallets/default_wallet", true},
+		{"sensitive_files.vaults", "backups/old/wallet.dat", true},
+		{"sensitive_files.vaults", "wallet.dat.txt", false},
+		{"sensitive_files.vaults", "~/Documents/Passwords.kdbx", true},
+		{"sensitive_files.vaults", "~/Library/Group Containers/2BUA8C4S2C.com.1password/data.sqlite", true},
+		{"sensitive_files.vaults", "~/Library/Group Containers/other.app/data", false},
+		{"sensitive_files.vaults", "~/.password-store/email/gmail.gpg", true},
+		{"sensitive_files.vaults", "~/.password-store-backup/x", false},
+
+		{"sensitive_files.credential_files", "~/.aws/credentials", true},
+		{"sensitive_files.credential_files", "~/.aws/config", false},
+		{"sensitive_files.credential_files", "~/.aws/sso/cache/abc.json", true},
+		{"sensitive_files.credential_files", "~/.kube/config", true},
+		{"sensitive_files.credential_files", "deploy/kubeconfig", true},
+		{"sensitive_files.credential_files", "kubeconfig", true},
+		{"sensitive_files.credential_files", "kubeconfig.yaml", false},
+		{"sensitive_files.credential_files", "~/.config/gcloud/application_default_credentials.json", true},
+		{"sensitive_files.credential_files", "~/.azure", true},
+		{"sensitive_files.credential_files", "~/.netrc", true},
+
+		{"protected_paths.no_write_outside_tools", "~/.bashrc", true},
+		{"protected_paths.no_write_outside_tools", "~/.zshrc", true},
+		{"protected_paths.no_write_outside_tools", "~/.config/fish/conf.d/abbr.fish", true},
+		{"protected_paths.no_write_outside_tools", "~/.config/fish/functions/x.fish", false},
+		{"protected_paths.no_write_outside_tools", "~/.ssh/authorized_keys", true},
+		{"protected_paths.no_write_outside_tools", "~/.ssh/known_hosts", false},
+		{"protected_paths.no_write_outside_tools", "/etc/cron.d/backup", true},
+		{"protected_paths.no_write_outside_tools", "/var/spool/cron/crontabs/root", true},
+		{"protected_paths.no_write_outside_tools", "project/.bashrc", false},
+	}
+	for _, tt := range tests {
+		patterns, found := lists[tt.list]
+		if !found {
+			t.Fatalf("unknown list %s", tt.list)
+		}
+		pattern, ok := globs.MatchList(patterns, tt.name)
+		if ok != tt.want {
+			t.Errorf("%s: MatchList(%q) = %q, %v; want %v", tt.list, tt.name, pattern, ok, tt.want)
+		}
+	}
+
+	// Every shipped pattern compiles
+	for list, patterns := range lists {
+		for _, p := range patterns {
+			if _, err := globs.Compile(strings.TrimPrefix(p, "!")); err != nil {
+				t.Errorf("%s: %v", list, err)
+			}
+		}
+	}
+}
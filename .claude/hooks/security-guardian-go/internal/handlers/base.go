@@ -4,6 +4,7 @@ package handlers
 import (
 	"github.com/artwist-polyakov/security-guardian/internal/checks"
 	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/policy"
 )
 
 // ToolHandler is the interface for tool handlers.
@@ -12,12 +13,41 @@ type ToolHandler interface {
 	Name() string
 	// Handle handles a tool invocation.
 	Handle(toolInput map[string]interface{}) *checks.CheckResult
+	// SetWorkDir sets the session working directory (hook's cwd)
+	// relative paths are resolved against.
+	SetWorkDir(dir string)
+	// SetTracer sets a function called for every check result.
+	SetTracer(t Tracer)
+}
+
+// TraceStep is one check run by a handler.
+type TraceStep struct {
+	// Result is the result as the check returned it, before decisions:
+	// overrides; Decision is the decision after them.
+	Result   checks.CheckResult
+	Decision checks.PermissionDecision
+}
+
+// Tracer receives the check results a handler resolves (guardian explain).
+type Tracer func(step TraceStep)
+
+// ResponseHandler inspects a tool's output after it ran (PostToolUse).
+type ResponseHandler interface {
+	ToolHandler
+	// HandleResponse checks the tool response before it enters the session.
+	HandleResponse(toolInput map[string]interface{}, toolResponse interface{}) *checks.CheckResult
 }
 
 // BaseHandler provides common functionality for tool handlers.
 type BaseHandler struct {
 	ToolName string
 	Config   *config.SecurityConfig
+	// Engine is the state the handler's checks share.
+	Engine *checks.Engine
+	// WorkDir is the session working directory; empty means project root.
+	WorkDir string
+	// Tracer, if set, sees every check result.
+	Tracer Tracer
 }
 
 // Name returns the handler name.
@@ -50,6 +80,26 @@ func (h *BaseHandler) Confirm(reason, guidance string) *checks.CheckResult {
 	return checks.Confirm(h.ToolName, reason, guidance)
 }
 
+// SetTracer sets a function called for every check result.
+func (h *BaseHandler) SetTracer(t Tracer) {
+	h.Tracer = t
+}
+
+// Resolve applies per-rule decision overrides to a check result.
+func (h *BaseHandler) Resolve(result *checks.CheckResult) *checks.CheckResult {
+	if h.Engine != nil && result != nil {
+		h.Engine.Finished(result.CheckName)
+	}
+	if h.Tracer == nil || result == nil {
+		return policy.ApplyOverrides(result, h.Config)
+	}
+	step := TraceStep{Result: *result}
+	result = policy.ApplyOverrides(result, h.Config)
+	step.Decision = result.PermissionDecisionValue()
+	h.Tracer(step)
+	return result
+}
+
 // GetString gets a string value from tool input.
 func GetString(input map[string]interface{}, key string) string {
 	if v, ok := input[key]; ok {
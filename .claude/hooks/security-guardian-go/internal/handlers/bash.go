@@ -1,22 +1,39 @@
 package handlers
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"sync/atomic"
 
 	"github.com/artwist-polyakov/security-guardian/internal/checks"
-	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/gitbackup"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+	"github.com/artwist-polyakov/security-guardian/internal/trash"
+	"golang.org/x/sync/errgroup"
 )
 
 // BashHandler handles Bash tool invocations.
 type BashHandler struct {
 	BaseHandler
 	checks           []checks.SecurityCheck
+	chained          map[checks.SecurityCheck]bool // run after the check before them, not concurrently
 	codeContentCheck *checks.CodeContentCheck
+	directoryCheck   *checks.DirectoryCheck
+	executionCheck   *checks.ExecutionCheck
+	dataExportCheck  *checks.DataExportCheck
+	unpackCheck      *checks.UnpackCheck
+	massCheck        *checks.MassModificationCheck
+	whitelist        *checks.Whitelist
 }
 
+// MaxRecipeDepth limits recursion through nested task-runner invocations:
+// recipes are resolved for the command and for this many levels below it.
+const MaxRecipeDepth = 3
+
 // Script execution patterns
 var scriptExecutionPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`^python3?\s+(.+\.py)\b`),
@@ -30,36 +47,84 @@ var scriptExecutionPatterns = []*regexp.Regexp{
 }
 
 // NewBashHandler creates a new BashHandler instance.
-func NewBashHandler(cfg *config.SecurityConfig) *BashHandler {
-	bypassCheck := checks.NewBypassCheck(cfg)
-	unpackCheck := checks.NewUnpackCheck(cfg)
-	directoryCheck := checks.NewDirectoryCheck(cfg)
-	gitCheck := checks.NewGitCheck(cfg)
-	deletionCheck := checks.NewDeletionCheck(cfg)
-	downloadCheck := checks.NewDownloadCheck(cfg)
-	executionCheck := checks.NewExecutionCheck(cfg)
-	secretsCheck := checks.NewSecretsCheck(cfg)
+func NewBashHandler(e *checks.Engine) *BashHandler {
+	bypassCheck := checks.NewBypassCheck(e)
+	unpackCheck := checks.NewUnpackCheck(e)
+	directoryCheck := checks.NewDirectoryCheck(e)
+	gitCheck := checks.NewGitCheck(e)
+	deletionCheck := checks.NewDeletionCheck(e)
+	downloadCheck := checks.NewDownloadCheck(e)
+	executionCheck := checks.NewExecutionCheck(e)
+	secretsCheck := checks.NewSecretsCheck(e)
+	massCheck := checks.NewMassModificationCheck(e)
+	overwriteCheck := checks.NewOverwriteCheck(e)
+	decoyCheck := checks.NewDecoyCheck(e)
+	tamperCheck := checks.NewTamperCheck(e)
+	metadataCheck := checks.NewCloudMetadataCheck(e)
+	unattendedCheck := checks.NewUnattendedNetworkCheck(e)
+	gpgCheck := checks.NewGPGCheck(e)
+	customCheck := checks.NewCustomRuleCheck(e)
+	autoloadCheck := checks.NewAutoloadCheck(e)
+	pipelineCheck := checks.NewPipelineCheck(e)
+	dataExportCheck := checks.NewDataExportCheck(e)
 
 	// Link execution check with download check for file tracking
 	executionCheck.SetDownloadCheck(downloadCheck)
+	// Extracted scripts and binaries are recorded with the downloads
+	unpackCheck.SetDownloadCheck(downloadCheck)
+	// Copy/move destinations get the same boundary and secrets rules as writes
+	overwriteCheck.SetPathChecks(directoryCheck, secretsCheck)
+	// Reads of secrets files make a pipeline carry secret data
+	pipelineCheck.SetSecretsCheck(secretsCheck)
 
 	return &BashHandler{
 		BaseHandler: BaseHandler{
 			ToolName: "Bash",
-			Config:   cfg,
+			Config:   e.Config,
+			Engine:   e,
 		},
 		checks: []checks.SecurityCheck{
+			decoyCheck,      // Decoy secrets and canary values (before secrets so the hit is recorded as such)
+			tamperCheck,     // Probing or disabling the guardian
+			metadataCheck,   // Cloud metadata endpoints (credentials)
+			unattendedCheck, // Network hosts of unattended runs
 			bypassCheck,     // Security bypasses first (eval, pipe to shell)
+			deletionCheck,   // Deletion protection (before directory so rm -rf / gets its own DENY)
 			directoryCheck,  // Boundary protection (before unpack so DENY overrides ASK)
 			unpackCheck,     // Archive security (bsdtar -s bypass)
 			gitCheck,        // Git operations
-			deletionCheck,   // Deletion protection
+			massCheck,       // Session deletion limits
 			downloadCheck,   // Download protection
 			executionCheck,  // Execution protection
 			secretsCheck,    // Secrets protection
+			gpgCheck,        // GPG secret key export and decrypt pipes
+			dataExportCheck, // Database dumps leaving the project, credentials in arguments
+			pipelineCheck,   // Project or secret data piped to encoders and network commands
+			overwriteCheck,  // mv/cp/install/rsync destinations
+			autoloadCheck,   // Git hooks, .envrc and other files that run by themselves
+			customCheck,     // custom_rules (last: only tightens what the rest allow)
+		},
+		chained: map[checks.SecurityCheck]bool{
+			executionCheck: true, // reads the downloads downloadCheck tracks
 		},
-		codeContentCheck: checks.NewCodeContentCheck(cfg),
+		codeContentCheck: checks.NewCodeContentCheck(e),
+		directoryCheck:   directoryCheck,
+		executionCheck:   executionCheck,
+		dataExportCheck:  dataExportCheck,
+		unpackCheck:      unpackCheck,
+		massCheck:        massCheck,
+		whitelist:        checks.NewWhitelist(e),
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *BashHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	for _, check := range h.checks {
+		check.SetWorkDir(dir)
 	}
+	h.codeContentCheck.SetWorkDir(dir)
+	h.whitelist.SetWorkDir(dir)
 }
 
 // Handle handles a Bash tool invocation.
@@ -70,27 +135,324 @@ func (h *BashHandler) Handle(toolInput map[string]interface{}) *checks.CheckResu
 		return h.Allow()
 	}
 
+	result := h.evaluate(command, 0)
+	if result.IsAllowed() {
+		h.massCheck.Commit()
+
+		// Recoverable deletion: move to trash instead of rm
+		if h.Config.Trash.Enabled {
+			if rewritten := h.trashRewrite(command); rewritten != "" {
+				result.UpdatedInput = make(map[string]interface{}, len(toolInput))
+				for k, v := range toolInput {
+					result.UpdatedInput[k] = v
+				}
+				result.UpdatedInput["command"] = rewritten
+			}
+		}
+	}
+
+	// Safety snapshot before destructive git ops (allowed or pending confirmation)
+	if h.Config.Git.BackupBeforeDestructive && (result.IsAllowed() || result.NeedsConfirmation()) {
+		if ref := h.gitSnapshot(command); ref != "" && result.NeedsConfirmation() {
+			result.Guidance += fmt.Sprintf(" Uncommitted work saved to %s (restore: `git checkout %s -- .`).", ref, ref)
+		}
+	}
+	return result
+}
+
+// HandleResponse records what a command that ran extracted and executed
+// (PostToolUse): downloaded and extracted files ask before their first
+// run only.
+func (h *BashHandler) HandleResponse(toolInput map[string]interface{}, toolResponse interface{}) *checks.CheckResult {
+	if command := GetString(toolInput, "command"); command != "" {
+		parsed := parsers.ParseBashCommand(command)
+		h.unpackCheck.RecordExtracted(parsed)
+		h.executionCheck.RecordRuns(parsed)
+		return h.Resolve(h.dataExportCheck.CheckDumpSize(parsed))
+	}
+	return h.Allow()
+}
+
+// gitSnapshot saves the working tree to a backup ref if the command
+// discards uncommitted changes. Returns the ref, or "" if none was made.
+func (h *BashHandler) gitSnapshot(command string) string {
+	cmd := gitbackup.DestructiveCommand(parsers.ParseBashCommand(command))
+	if cmd == nil {
+		return ""
+	}
+
+	base := h.WorkDir
+	if base == "" {
+		base = h.Engine.ProjectRoot
+	}
+	dir := parsers.ResolvePath(gitbackup.RepoDir(cmd), base)
+
+	ref, err := gitbackup.Create(dir, "guardian backup before: "+cmd.Raw)
+	if err != nil {
+		return ""
+	}
+	return ref
+}
+
+// trashRewrite rewrites a lone `rm -r` of in-project paths into
+// `guardian trash put`. Returns "" when the command can't be rewritten
+// safely (compound commands, expansions, unknown flags, outside targets).
+func (h *BashHandler) trashRewrite(command string) string {
+	parsed := parsers.ParseBashCommand(command)
+	if len(parsed) != 1 {
+		return ""
+	}
+	cmd := parsed[0]
+	if cmd.Command != "rm" || cmd.PipesTo != nil || len(cmd.Redirects) > 0 || len(cmd.Args) == 0 {
+		return ""
+	}
+
+	recursive, force := false, false
+	for _, f := range cmd.Flags {
+		switch {
+		case f == "--recursive":
+			recursive = true
+		case f == "--force":
+			force = true
+		case f == "--verbose":
+		case strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && len(f) > 1:
+			for _, ch := range f[1:] {
+				switch ch {
+				case 'r', 'R':
+					recursive = true
+				case 'f':
+					force = true
+				case 'v':
+				default:
+					return ""
+				}
+			}
+		default:
+			return ""
+		}
+	}
+	if !recursive {
+		return ""
+	}
+
+	projectRoot := h.Engine.BoundaryRoot
+	baseDir := h.WorkDir
+	if baseDir == "" {
+		baseDir = projectRoot
+	}
+	trashRoot := trash.Root(h.Config, projectRoot)
+
+	args := make([]string, 0, len(cmd.Args))
+	for _, arg := range cmd.Args {
+		// Expansions can't be verified; quoted globs would change meaning
+		if strings.ContainsAny(arg, "$`") || (strings.ContainsAny(arg, "*?[") && strings.ContainsAny(command, `'"\`)) {
+			return ""
+		}
+
+		resolved := parsers.ResolvePath(arg, baseDir)
+		if resolved == projectRoot || !parsers.IsPathWithinAllowed(resolved, projectRoot, nil) {
+			return ""
+		}
+		// Emptying the trash itself is a real deletion
+		if rel, err := filepath.Rel(trashRoot, resolved); err == nil && !strings.HasPrefix(rel, "..") {
+			return ""
+		}
+
+		if strings.ContainsAny(arg, "*?[") {
+			args = append(args, arg) // left unquoted for the shell to expand
+		} else {
+			args = append(args, shellQuote(arg))
+		}
+	}
+
+	guardian, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+
+	rewritten := fmt.Sprintf("%s trash put --dir %s", shellQuote(guardian), shellQuote(trashRoot))
+	if force {
+		rewritten += " -f"
+	}
+	return rewritten + " -- " + strings.Join(args, " ")
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// evaluate runs all checks on a command string. depth tracks nesting of
+// task-runner recipes (make target -> recipe line -> make target ...).
+func (h *BashHandler) evaluate(command string, depth int) *checks.CheckResult {
 	// Parse command
 	parsedCommands := parsers.ParseBashCommand(command)
 	if len(parsedCommands) == 0 {
 		return h.Allow()
 	}
 
-	// Convert to checks.ParsedCommand
-	checkCommands := convertParsedCommands(parsedCommands)
+	// Pre-approved commands skip all checks
+	if h.whitelist.Matches(command, parsedCommands) {
+		return h.Allow()
+	}
 
-	// Run all checks
-	for _, check := range h.checks {
-		result := check.CheckCommand(command, checkCommands)
-		if !result.IsAllowed() {
-			return result
-		}
+	// Run all checks. DENY returns immediately; the first ASK is kept
+	// while remaining checks run, so a later DENY still overrides it.
+	denied, pending := h.runChecks(command, parsedCommands)
+	if denied != nil {
+		return denied
 	}
 
 	// Check content of scripts being executed
-	result := h.checkScriptExecution(command, checkCommands)
+	result := h.checkScriptExecution(command, parsedCommands)
 	if !result.IsAllowed() {
-		return result
+		if !result.NeedsConfirmation() {
+			return result
+		}
+		if pending == nil {
+			pending = result
+		}
+	}
+
+	// Check recipes behind make/just/npm run - wrapping a command in a target
+	// must not hide it from the checks above
+	if h.Config.BypassPrevention.InspectTaskRunners {
+		result = h.checkTaskRecipes(parsedCommands, depth)
+		if !result.IsAllowed() {
+			if !result.NeedsConfirmation() {
+				return result
+			}
+			if pending == nil {
+				pending = result
+			}
+		}
+	}
+
+	if pending != nil {
+		return pending
+	}
+	return h.Allow()
+}
+
+// runChecks runs h.checks on a command and returns the first DENY in
+// check order, or else the first ASK. Long command lists are checked
+// concurrently (performance.parallel_min_commands) with the same result.
+// Once the engine's context is done the remaining checks are skipped: the
+// call is decided as timed out and nobody waits for the result.
+func (h *BashHandler) runChecks(command string, cmds []*checks.ParsedCommand) (denied, pending *checks.CheckResult) {
+	perf := h.Config.Performance
+	// A trace must list the checks in order
+	if h.Tracer != nil || perf.ParallelMinCommands <= 0 || len(cmds) < perf.ParallelMinCommands {
+		ctx := h.Engine.Context()
+		for _, check := range h.checks {
+			if ctx.Err() != nil {
+				break
+			}
+			result := h.Resolve(check.CheckCommand(command, cmds))
+			if result.IsAllowed() {
+				continue
+			}
+			if !result.NeedsConfirmation() {
+				return result, pending
+			}
+			if pending == nil {
+				pending = result
+			}
+		}
+		return nil, pending
+	}
+
+	results := h.runChecksParallel(command, cmds, perf.MaxParallel)
+	for _, result := range results {
+		if result == nil || result.IsAllowed() {
+			continue
+		}
+		if !result.NeedsConfirmation() {
+			return result, pending
+		}
+		if pending == nil {
+			pending = result
+		}
+	}
+	return nil, pending
+}
+
+// runChecksParallel runs h.checks concurrently, chained checks in one
+// goroutine after the check they depend on. Once a check denies, checks
+// after it that haven't started are skipped (their result is nil); checks
+// before it always run, so the first DENY in check order is found.
+func (h *BashHandler) runChecksParallel(command string, cmds []*checks.ParsedCommand, limit int) []*checks.CheckResult {
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+	results := make([]*checks.CheckResult, len(h.checks))
+
+	// Index of the first check known to deny
+	var firstDeny atomic.Int64
+	firstDeny.Store(int64(len(h.checks)))
+	markDenied := func(i int) {
+		for {
+			current := firstDeny.Load()
+			if int64(i) >= current || firstDeny.CompareAndSwap(current, int64(i)) {
+				return
+			}
+		}
+	}
+
+	g, ctx := errgroup.WithContext(h.Engine.Context())
+	g.SetLimit(limit)
+	for start := 0; start < len(h.checks); {
+		end := start + 1
+		for end < len(h.checks) && h.chained[h.checks[end]] {
+			end++
+		}
+		first, last := start, end
+		g.Go(func() error {
+			for i := first; i < last; i++ {
+				if int64(i) > firstDeny.Load() || ctx.Err() != nil {
+					return nil
+				}
+				result := h.Resolve(h.checks[i].CheckCommand(command, cmds))
+				results[i] = result
+				if !result.IsAllowed() && !result.NeedsConfirmation() {
+					markDenied(i)
+					return nil
+				}
+			}
+			return nil
+		})
+		start = end
+	}
+	g.Wait()
+	return results
+}
+
+// checkTaskRecipes resolves task-runner targets and runs all checks on each recipe line.
+func (h *BashHandler) checkTaskRecipes(parsedCommands []*checks.ParsedCommand, depth int) *checks.CheckResult {
+	if depth >= MaxRecipeDepth {
+		return h.Allow()
+	}
+
+	baseDir := h.WorkDir
+	if baseDir == "" {
+		baseDir = h.Engine.BoundaryRoot
+	}
+
+	for _, cmd := range parsedCommands {
+		dir := baseDir
+		if cmd.Dir != "" {
+			dir = parsers.ResolvePath(cmd.Dir, baseDir)
+		}
+		for _, recipe := range parsers.ResolveTaskRecipes(cmd, dir) {
+			for _, line := range recipe.Commands {
+				result := h.evaluate(line, depth+1)
+				if !result.IsAllowed() {
+					result.Reason = fmt.Sprintf("%s (in %s target '%s' from %s)",
+						result.Reason, recipe.Runner, recipe.Target, filepath.Base(recipe.Source))
+					return result
+				}
+			}
+		}
 	}
 
 	return h.Allow()
@@ -101,7 +463,10 @@ func (h *BashHandler) checkScriptExecution(command string, parsedCommands []*che
 	for _, cmd := range parsedCommands {
 		scriptPath := h.extractScriptPath(cmd)
 		if scriptPath != "" {
-			result := h.codeContentCheck.CheckFile(scriptPath)
+			scriptPath = parsers.JoinDir(cmd.Dir, scriptPath)
+		}
+		if scriptPath != "" && h.directoryCheck.ZoneFor(scriptPath) != checks.ZonePermissive {
+			result := h.Resolve(h.codeContentCheck.CheckFile(scriptPath))
 			if !result.IsAllowed() {
 				return result
 			}
@@ -162,34 +527,6 @@ func (h *BashHandler) extractScriptPath(cmd *checks.ParsedCommand) string {
 	return ""
 }
 
-// convertParsedCommands converts parsers.ParsedCommand to checks.ParsedCommand.
-func convertParsedCommands(cmds []*parsers.ParsedCommand) []*checks.ParsedCommand {
-	result := make([]*checks.ParsedCommand, len(cmds))
-	for i, cmd := range cmds {
-		result[i] = convertParserCommand(cmd)
-	}
-	return result
-}
-
-// convertParserCommand converts a single parsers.ParsedCommand to checks.ParsedCommand.
-func convertParserCommand(cmd *parsers.ParsedCommand) *checks.ParsedCommand {
-	if cmd == nil {
-		return nil
-	}
-	result := &checks.ParsedCommand{
-		Command:           cmd.Command,
-		Args:              cmd.Args,
-		Flags:             cmd.Flags,
-		Redirects:         cmd.Redirects,
-		VariableAsCommand: cmd.VariableAsCommand,
-		Raw:               cmd.Raw,
-	}
-	if cmd.PipesTo != nil {
-		result.PipesTo = convertParserCommand(cmd.PipesTo)
-	}
-	return result
-}
-
 // ScriptExtensions returns script file extensions.
 func ScriptExtensions() map[string]bool {
 	return map[string]bool{
@@ -202,6 +539,11 @@ func ScriptExtensions() map[string]bool {
 	}
 }
 
+// IsNotebookFile checks if file is a Jupyter notebook (code cells in JSON).
+func IsNotebookFile(filePath string) bool {
+	return strings.ToLower(filepath.Ext(filePath)) == ".ipynb"
+}
+
 // IsScriptFile checks if file is a script that needs content checking.
 func IsScriptFile(filePath string) bool {
 	ext := strings.ToLower(filepath.Ext(filePath))
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"github.com/artwist-polyakov/security-guardian/internal/parsers"
+)
+
+// DryRun turns a Bash call that needs confirmation into its preview:
+// `git clean -fd` into `git clean -fd --dry-run`, `terraform apply` into
+// `terraform plan` (dry_run.commands). The preview runs in place of the
+// call, after a line telling Claude what ran, so the agent can show the
+// user what would happen. Returns nil if the command has no preview, or
+// the preview itself isn't allowed.
+func (h *BashHandler) DryRun(toolInput map[string]interface{}, asked *checks.CheckResult) *checks.CheckResult {
+	command := strings.TrimSpace(GetString(toolInput, "command"))
+	preview := dryRunCommand(command, h.Config.DryRun.Commands)
+	if preview == "" || !h.evaluate(preview, 0).IsAllowed() {
+		return nil
+	}
+
+	note := fmt.Sprintf("[security-guardian] Dry run only: %s needs the user (%s). Show them this output; they run `%s` themselves.", command, asked.Reason, command)
+	result := checks.Allow("dry_run")
+	result.Reason = fmt.Sprintf("Security Guardian: ran `%s` instead (%s)", preview, asked.Reason)
+	result.UpdatedInput = make(map[string]interface{}, len(toolInput))
+	for k, v := range toolInput {
+		result.UpdatedInput[k] = v
+	}
+	result.UpdatedInput["command"] = fmt.Sprintf("echo %s; %s", shellQuote(note), preview)
+	return result
+}
+
+// dryRunCommand returns the preview of command, or "" if no entry matches.
+// Only a lone simple command is rewritten: pipes, redirects and
+// expansions could make the preview do more than the entry says.
+func dryRunCommand(command string, entries []config.DryRunCommand) string {
+	parsed := parsers.ParseBashCommand(command)
+	if len(parsed) != 1 {
+		return ""
+	}
+	cmd := parsed[0]
+	if cmd.PipesTo != nil || len(cmd.Redirects) > 0 || len(cmd.Unresolved) > 0 || strings.ContainsAny(command, "`$;&|<>\n") {
+		return ""
+	}
+
+	for _, entry := range entries {
+		words := strings.Fields(entry.Match)
+		if len(words) == 0 {
+			continue
+		}
+		// The match words start the command, each followed by a space
+		prefix := regexp.MustCompile(`^` + strings.Join(quoteAll(words), `\s+`) + `(\s|$)`)
+		loc := prefix.FindStringIndex(command)
+		if loc == nil {
+			continue
+		}
+
+		rest := command[loc[1]:]
+		for _, flag := range entry.Drop {
+			dropFlag := regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(flag) + `(=\S*)?(\s|$)`)
+			rest = dropFlag.ReplaceAllString(rest, "$1")
+		}
+
+		replace := entry.Replace
+		if replace == "" {
+			replace = entry.Match
+		}
+		parts := []string{replace}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			parts = append(parts, rest)
+		}
+		if entry.Append != "" {
+			parts = append(parts, entry.Append)
+		}
+		return strings.Join(parts, " ")
+	}
+	return ""
+}
+
+// quoteAll escapes words for use in a regexp.
+func quoteAll(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return quoted
+}
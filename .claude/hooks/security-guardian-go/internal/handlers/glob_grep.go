@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/artwist-polyakov/security-guardian/internal/checks"
-	"github.com/artwist-polyakov/security-guardian/internal/config"
 	"github.com/artwist-polyakov/security-guardian/internal/parsers"
 )
 
@@ -13,20 +12,31 @@ type GlobGrepHandler struct {
 	BaseHandler
 	directoryCheck *checks.DirectoryCheck
 	secretsCheck   *checks.SecretsCheck
+	decoyCheck     *checks.DecoyCheck
 }
 
 // NewGlobGrepHandler creates a new GlobGrepHandler instance.
-func NewGlobGrepHandler(cfg *config.SecurityConfig) *GlobGrepHandler {
+func NewGlobGrepHandler(e *checks.Engine) *GlobGrepHandler {
 	return &GlobGrepHandler{
 		BaseHandler: BaseHandler{
 			ToolName: "Glob",
-			Config:   cfg,
+			Config:   e.Config,
+			Engine:   e,
 		},
-		directoryCheck: checks.NewDirectoryCheck(cfg),
-		secretsCheck:   checks.NewSecretsCheck(cfg),
+		directoryCheck: checks.NewDirectoryCheck(e),
+		secretsCheck:   checks.NewSecretsCheck(e),
+		decoyCheck:     checks.NewDecoyCheck(e),
 	}
 }
 
+// SetWorkDir sets the working directory on all checks.
+func (h *GlobGrepHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.directoryCheck.SetWorkDir(dir)
+	h.secretsCheck.SetWorkDir(dir)
+	h.decoyCheck.SetWorkDir(dir)
+}
+
 // Handle handles a Glob/Grep tool invocation.
 func (h *GlobGrepHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
 	// Get path from input (both Glob and Grep use 'path')
@@ -35,6 +45,13 @@ func (h *GlobGrepHandler) Handle(toolInput map[string]interface{}) *checks.Check
 	// Also check the pattern field — if it contains a path outside the project,
 	// it indicates the operation targets that directory (e.g. pattern="/etc/*", "~/Documents/*")
 	pattern := GetString(toolInput, "pattern")
+
+	// Searching for a canary value means the decoy was read somehow
+	result := h.Resolve(h.decoyCheck.CheckContent(pattern))
+	if !result.IsAllowed() {
+		return result
+	}
+
 	if path == "" && pattern != "" {
 		// Expand ~/... and $HOME/... before checking
 		expanded := parsers.ExpandPath(pattern)
@@ -48,14 +65,20 @@ func (h *GlobGrepHandler) Handle(toolInput map[string]interface{}) *checks.Check
 		return h.Allow()
 	}
 
+	// Decoy secrets files
+	result = h.Resolve(h.decoyCheck.CheckPath(path, "read"))
+	if !result.IsAllowed() {
+		return result
+	}
+
 	// Check directory boundaries
-	result := h.directoryCheck.CheckPath(path, "find")
+	result = h.Resolve(h.directoryCheck.CheckPath(path, "find"))
 	if !result.IsAllowed() {
 		return result
 	}
 
 	// Check secrets/sensitive file access
-	result = h.secretsCheck.CheckPath(path, "read")
+	result = h.Resolve(h.secretsCheck.CheckPath(path, "read"))
 	if !result.IsAllowed() {
 		return result
 	}
@@ -69,8 +92,8 @@ type GrepHandler struct {
 }
 
 // NewGrepHandler creates a new GrepHandler instance.
-func NewGrepHandler(cfg *config.SecurityConfig) *GrepHandler {
-	h := NewGlobGrepHandler(cfg)
+func NewGrepHandler(e *checks.Engine) *GrepHandler {
+	h := NewGlobGrepHandler(e)
 	h.ToolName = "Grep"
 	return &GrepHandler{GlobGrepHandler: *h}
 }
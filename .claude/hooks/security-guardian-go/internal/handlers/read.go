@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/artwist-polyakov/security-guardian/internal/checks"
-	"github.com/artwist-polyakov/security-guardian/internal/config"
 )
 
 // ReadHandler handles Read tool invocations.
@@ -10,20 +9,34 @@ type ReadHandler struct {
 	BaseHandler
 	directoryCheck *checks.DirectoryCheck
 	secretsCheck   *checks.SecretsCheck
+	decoyCheck     *checks.DecoyCheck
+	customCheck    *checks.CustomRuleCheck
 }
 
 // NewReadHandler creates a new ReadHandler instance.
-func NewReadHandler(cfg *config.SecurityConfig) *ReadHandler {
+func NewReadHandler(e *checks.Engine) *ReadHandler {
 	return &ReadHandler{
 		BaseHandler: BaseHandler{
 			ToolName: "Read",
-			Config:   cfg,
+			Config:   e.Config,
+			Engine:   e,
 		},
-		directoryCheck: checks.NewDirectoryCheck(cfg),
-		secretsCheck:   checks.NewSecretsCheck(cfg),
+		directoryCheck: checks.NewDirectoryCheck(e),
+		secretsCheck:   checks.NewSecretsCheck(e),
+		decoyCheck:     checks.NewDecoyCheck(e),
+		customCheck:    checks.NewCustomRuleCheck(e),
 	}
 }
 
+// SetWorkDir sets the working directory on all checks.
+func (h *ReadHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.directoryCheck.SetWorkDir(dir)
+	h.secretsCheck.SetWorkDir(dir)
+	h.decoyCheck.SetWorkDir(dir)
+	h.customCheck.SetWorkDir(dir)
+}
+
 // Handle handles a Read tool invocation.
 func (h *ReadHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
 	filePath := GetString(toolInput, "file_path")
@@ -32,14 +45,32 @@ func (h *ReadHandler) Handle(toolInput map[string]interface{}) *checks.CheckResu
 		return h.Allow()
 	}
 
+	// Decoy secrets files
+	result := h.Resolve(h.decoyCheck.CheckPath(filePath, "read"))
+	if !result.IsAllowed() {
+		return result
+	}
+
 	// Check directory boundaries
-	result := h.directoryCheck.CheckPath(filePath, "read")
+	result = h.Resolve(h.directoryCheck.CheckPath(filePath, "read"))
 	if !result.IsAllowed() {
 		return result
 	}
 
 	// Check secrets/protected files
-	result = h.secretsCheck.CheckPath(filePath, "read")
+	result = h.Resolve(h.secretsCheck.CheckPath(filePath, "read"))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	// Sample content for credentials in files not named like secrets
+	result = h.Resolve(h.secretsCheck.CheckReadContent(filePath))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	// Custom rules
+	result = h.Resolve(h.customCheck.CheckFile(h.ToolName, filePath))
 	if !result.IsAllowed() {
 		return result
 	}
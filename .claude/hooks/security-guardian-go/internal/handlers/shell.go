@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// SlashCommandHandler handles SlashCommand tool invocations.
+type SlashCommandHandler struct {
+	BaseHandler
+	slashCommandCheck *checks.SlashCommandCheck
+}
+
+// NewSlashCommandHandler creates a new SlashCommandHandler instance.
+func NewSlashCommandHandler(e *checks.Engine) *SlashCommandHandler {
+	return &SlashCommandHandler{
+		BaseHandler: BaseHandler{
+			ToolName: "SlashCommand",
+			Config:   e.Config,
+			Engine:   e,
+		},
+		slashCommandCheck: checks.NewSlashCommandCheck(e),
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *SlashCommandHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+}
+
+// Handle handles a SlashCommand tool invocation.
+func (h *SlashCommandHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
+	result := h.Resolve(h.slashCommandCheck.CheckSlashCommand(GetString(toolInput, "command")))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	return h.Allow()
+}
+
+// BackgroundShellHandler handles BashOutput and KillShell. Both only touch
+// shells started by an already checked Bash call, so they are allowed;
+// main logs them for audit and BashOutput results are screened.
+type BackgroundShellHandler struct {
+	BaseHandler
+	backgroundShellCheck *checks.BackgroundShellCheck
+}
+
+// NewBackgroundShellHandler creates a handler for toolName
+// (BashOutput or KillShell).
+func NewBackgroundShellHandler(e *checks.Engine, toolName string) *BackgroundShellHandler {
+	return &BackgroundShellHandler{
+		BaseHandler: BaseHandler{
+			ToolName: toolName,
+			Config:   e.Config,
+			Engine:   e,
+		},
+		backgroundShellCheck: checks.NewBackgroundShellCheck(e),
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *BackgroundShellHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+}
+
+// Handle allows the call (PreToolUse).
+func (h *BackgroundShellHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
+	return h.Allow()
+}
+
+// HandleResponse checks retrieved output (PostToolUse).
+func (h *BackgroundShellHandler) HandleResponse(toolInput map[string]interface{}, toolResponse interface{}) *checks.CheckResult {
+	if h.ToolName != "BashOutput" {
+		return h.Allow()
+	}
+	output := strings.Join(collectStrings(toolResponse, nil), "\n")
+	return h.Resolve(h.backgroundShellCheck.CheckOutput(output))
+}
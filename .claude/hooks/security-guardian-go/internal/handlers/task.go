@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// TaskHandler handles Task (sub-agent) tool invocations.
+type TaskHandler struct {
+	BaseHandler
+	directoryCheck *checks.DirectoryCheck
+	subagentCheck  *checks.SubagentCheck
+}
+
+// NewTaskHandler creates a new TaskHandler instance.
+func NewTaskHandler(e *checks.Engine) *TaskHandler {
+	directoryCheck := checks.NewDirectoryCheck(e)
+	subagentCheck := checks.NewSubagentCheck(e)
+	subagentCheck.SetDirectoryCheck(directoryCheck)
+
+	return &TaskHandler{
+		BaseHandler: BaseHandler{
+			ToolName: "Task",
+			Config:   e.Config,
+			Engine:   e,
+		},
+		directoryCheck: directoryCheck,
+		subagentCheck:  subagentCheck,
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *TaskHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.directoryCheck.SetWorkDir(dir)
+	h.subagentCheck.SetWorkDir(dir)
+}
+
+// Handle handles a Task tool invocation.
+func (h *TaskHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
+	result := h.Resolve(h.subagentCheck.CheckTask(
+		GetString(toolInput, "subagent_type"),
+		GetString(toolInput, "description"),
+		GetString(toolInput, "prompt"),
+	))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	return h.Allow()
+}
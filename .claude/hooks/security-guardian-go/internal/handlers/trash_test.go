@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+func TestTrashRewrite(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	cfg.Trash.Enabled = true
+	h := NewBashHandler(checks.NewEngine(cfg))
+
+	guardian, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashRoot := filepath.Join(root, ".claude", "trash")
+	prefix := shellQuote(guardian) + " trash put --dir " + shellQuote(trashRoot)
+
+	tests := []struct {
+		command string
+		want    string // "" means not rewritten
+	}{
+		{"rm -rf build", " -f -- 'build'"},
+		{"rm -r build dist", " -- 'build' 'dist'"},
+		{"rm -R --force build", " -f -- 'build'"},
+		{"rm --recursive -v build", " -- 'build'"},
+		{`rm -rf "it's"`, ` -f -- 'it'\''s'`},
+		{"rm -rf build/*.o", " -f -- build/*.o"},
+
+		// Not recursive
+		{"rm file.txt", ""},
+		{"rm -f file.txt", ""},
+		// Unknown flags
+		{"rm -rfi build", ""},
+		{"rm -rf --one-file-system build", ""},
+		// Compound commands, pipes and redirects
+		{"rm -rf build && ls", ""},
+		{"rm -rf build > log", ""},
+		// Expansions and quoted globs
+		{"rm -rf $DIR", ""},
+		{"rm -rf 'build/*.o'", ""},
+		// Outside the project, the root itself and the trash
+		{"rm -rf /tmp/x", ""},
+		{"rm -rf ../other", ""},
+		{"rm -rf .", ""},
+		{"rm -rf .claude/trash", ""},
+		{"rm -rf .claude/trash/20240101-000000", ""},
+		// Not rm
+		{"rmdir build", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := h.trashRewrite(tt.command)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("trashRewrite(%q) = %q, want no rewrite", tt.command, got)
+				}
+				return
+			}
+			if got != prefix+tt.want {
+				t.Errorf("trashRewrite(%q) = %q, want %q", tt.command, strings.TrimPrefix(got, prefix), tt.want)
+			}
+		})
+	}
+}
+
+func TestTrashRewriteWorkDir(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	cfg.Trash.Enabled = true
+	h := NewBashHandler(checks.NewEngine(cfg))
+
+	h.SetWorkDir(filepath.Join(root, "sub"))
+	if got := h.trashRewrite("rm -rf ../build"); got == "" {
+		t.Error("rm -rf ../build from sub wasn't rewritten")
+	}
+	if got := h.trashRewrite("rm -rf ../.."); got != "" {
+		t.Errorf("rm -rf ../.. from sub rewritten to %q", got)
+	}
+}
+
+func TestHandleTrashUpdatedInput(t *testing.T) {
+	root := t.TempDir()
+	cfg := config.DefaultConfig()
+	t.Setenv("CLAUDE_PROJECT_DIR", root)
+	cfg.Directories.ProjectRoot = root
+	cfg.Trash.Enabled = true
+	h := NewBashHandler(checks.NewEngine(cfg))
+
+	input := map[string]interface{}{"command": "rm -rf build", "description": "clean"}
+	result := h.Handle(input)
+	if !result.IsAllowed() {
+		t.Fatalf("rm -rf build not allowed: %s", result.Reason)
+	}
+	command, _ := result.UpdatedInput["command"].(string)
+	if !strings.Contains(command, " trash put ") {
+		t.Errorf("updated command = %q, want a trash put", command)
+	}
+	if result.UpdatedInput["description"] != "clean" {
+		t.Error("other tool input fields not kept")
+	}
+	if input["command"] != "rm -rf build" {
+		t.Error("original tool input modified")
+	}
+
+	cfg.Trash.Enabled = false
+	if result := NewBashHandler(checks.NewEngine(cfg)).Handle(input); result.UpdatedInput != nil {
+		t.Errorf("trash disabled but input updated: %v", result.UpdatedInput)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// WebFetchHandler screens fetched web content for prompt injection.
+// Registered for PostToolUse, since the content only exists after the fetch;
+// before it only the URL is checked (cloud metadata endpoints, the hosts
+// of unattended runs).
+type WebFetchHandler struct {
+	BaseHandler
+	injectionCheck *checks.PromptInjectionCheck
+	metadataCheck  *checks.CloudMetadataCheck
+	hostCheck      *checks.UnattendedNetworkCheck
+}
+
+// NewWebFetchHandler creates a new WebFetchHandler instance.
+func NewWebFetchHandler(e *checks.Engine) *WebFetchHandler {
+	return &WebFetchHandler{
+		BaseHandler: BaseHandler{
+			ToolName: "WebFetch",
+			Config:   e.Config,
+			Engine:   e,
+		},
+		injectionCheck: checks.NewPromptInjectionCheck(e),
+		metadataCheck:  checks.NewCloudMetadataCheck(e),
+		hostCheck:      checks.NewUnattendedNetworkCheck(e),
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *WebFetchHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+}
+
+// Handle checks the URL of the fetch (PreToolUse).
+func (h *WebFetchHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
+	url := GetString(toolInput, "url")
+	if result := h.Resolve(h.metadataCheck.CheckText(url)); !result.IsAllowed() {
+		return result
+	}
+	return h.Resolve(h.hostCheck.CheckURL(url))
+}
+
+// HandleResponse checks the fetched content (PostToolUse).
+func (h *WebFetchHandler) HandleResponse(toolInput map[string]interface{}, toolResponse interface{}) *checks.CheckResult {
+	source := GetString(toolInput, "url")
+	if source == "" {
+		source = "fetched content"
+	}
+	content := strings.Join(collectStrings(toolResponse, nil), "\n")
+	return h.Resolve(h.injectionCheck.CheckContent(content, source))
+}
+
+// collectStrings returns every string inside a decoded JSON value
+// (tool_response shape differs between tools and versions).
+func collectStrings(v interface{}, out []string) []string {
+	switch t := v.(type) {
+	case string:
+		out = append(out, t)
+	case []interface{}:
+		for _, item := range t {
+			out = collectStrings(item, out)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = collectStrings(t[k], out)
+		}
+	}
+	return out
+}
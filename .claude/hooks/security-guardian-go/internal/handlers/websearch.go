@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// WebSearchHandler handles WebSearch tool invocations.
+type WebSearchHandler struct {
+	BaseHandler
+	webSearchCheck *checks.WebSearchCheck
+	decoyCheck     *checks.DecoyCheck
+}
+
+// NewWebSearchHandler creates a new WebSearchHandler instance.
+func NewWebSearchHandler(e *checks.Engine) *WebSearchHandler {
+	webSearchCheck := checks.NewWebSearchCheck(e)
+	webSearchCheck.SetSecretsCheck(checks.NewSecretsCheck(e))
+
+	return &WebSearchHandler{
+		BaseHandler: BaseHandler{
+			ToolName: "WebSearch",
+			Config:   e.Config,
+			Engine:   e,
+		},
+		webSearchCheck: webSearchCheck,
+		decoyCheck:     checks.NewDecoyCheck(e),
+	}
+}
+
+// SetWorkDir sets the working directory on all checks.
+func (h *WebSearchHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.webSearchCheck.SetWorkDir(dir)
+	h.decoyCheck.SetWorkDir(dir)
+}
+
+// Handle handles a WebSearch tool invocation.
+func (h *WebSearchHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
+	query := GetString(toolInput, "query")
+
+	// Canary values from decoy files
+	result := h.Resolve(h.decoyCheck.CheckContent(query))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	result = h.Resolve(h.webSearchCheck.CheckQuery(query))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	return h.Allow()
+}
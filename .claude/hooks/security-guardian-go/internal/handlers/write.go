@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"github.com/artwist-polyakov/security-guardian/internal/checks"
-	"github.com/artwist-polyakov/security-guardian/internal/config"
 )
 
 // WriteHandler handles Write and Edit tool invocations.
@@ -11,21 +10,44 @@ type WriteHandler struct {
 	directoryCheck   *checks.DirectoryCheck
 	secretsCheck     *checks.SecretsCheck
 	codeContentCheck *checks.CodeContentCheck
+	massCheck        *checks.MassModificationCheck
+	decoyCheck       *checks.DecoyCheck
+	injectionCheck   *checks.PromptInjectionCheck
+	customCheck      *checks.CustomRuleCheck
+	autoloadCheck    *checks.AutoloadCheck
 }
 
 // NewWriteHandler creates a new WriteHandler instance.
-func NewWriteHandler(cfg *config.SecurityConfig) *WriteHandler {
+func NewWriteHandler(e *checks.Engine) *WriteHandler {
 	return &WriteHandler{
 		BaseHandler: BaseHandler{
 			ToolName: "Write",
-			Config:   cfg,
+			Config:   e.Config,
+			Engine:   e,
 		},
-		directoryCheck:   checks.NewDirectoryCheck(cfg),
-		secretsCheck:     checks.NewSecretsCheck(cfg),
-		codeContentCheck: checks.NewCodeContentCheck(cfg),
+		directoryCheck:   checks.NewDirectoryCheck(e),
+		secretsCheck:     checks.NewSecretsCheck(e),
+		codeContentCheck: checks.NewCodeContentCheck(e),
+		massCheck:        checks.NewMassModificationCheck(e),
+		decoyCheck:       checks.NewDecoyCheck(e),
+		injectionCheck:   checks.NewPromptInjectionCheck(e),
+		customCheck:      checks.NewCustomRuleCheck(e),
+		autoloadCheck:    checks.NewAutoloadCheck(e),
 	}
 }
 
+// SetWorkDir sets the working directory on all checks.
+func (h *WriteHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.directoryCheck.SetWorkDir(dir)
+	h.secretsCheck.SetWorkDir(dir)
+	h.codeContentCheck.SetWorkDir(dir)
+	h.massCheck.SetWorkDir(dir)
+	h.decoyCheck.SetWorkDir(dir)
+	h.customCheck.SetWorkDir(dir)
+	h.autoloadCheck.SetWorkDir(dir)
+}
+
 // Handle handles a Write/Edit tool invocation.
 func (h *WriteHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
 	filePath := GetString(toolInput, "file_path")
@@ -35,26 +57,89 @@ func (h *WriteHandler) Handle(toolInput map[string]interface{}) *checks.CheckRes
 		return h.Allow()
 	}
 
+	// Decoy secrets files, and canary values copied out of them
+	result := h.Resolve(h.decoyCheck.CheckPath(filePath, "write"))
+	if !result.IsAllowed() {
+		return result
+	}
+	result = h.Resolve(h.decoyCheck.CheckContent(content + "\n" + GetString(toolInput, "new_string")))
+	if !result.IsAllowed() {
+		return result
+	}
+
 	// Check directory boundaries
-	result := h.directoryCheck.CheckPath(filePath, "write")
+	result = h.Resolve(h.directoryCheck.CheckPath(filePath, "write"))
 	if !result.IsAllowed() {
 		return result
 	}
 
 	// Check protected files (no_modify)
-	result = h.secretsCheck.CheckPath(filePath, "write")
+	result = h.Resolve(h.secretsCheck.CheckPath(filePath, "write"))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	// Git hooks, .envrc, folderOpen tasks: run on the user's next action
+	if h.ToolName == "Write" {
+		result = h.Resolve(h.autoloadCheck.CheckWrite(filePath, content, false))
+	} else {
+		result = h.Resolve(h.autoloadCheck.CheckWrite(filePath, GetString(toolInput, "new_string"), true))
+	}
 	if !result.IsAllowed() {
 		return result
 	}
 
-	// Check content for dangerous patterns (for script files)
-	if IsScriptFile(filePath) && content != "" {
-		result = h.codeContentCheck.CheckContent(content, filePath)
+	// Check content for dangerous patterns (for script files).
+	// Permissive zones (scratch directories) skip content heuristics.
+	if IsScriptFile(filePath) && content != "" && h.directoryCheck.ZoneFor(filePath) != checks.ZonePermissive {
+		result = h.Resolve(h.codeContentCheck.CheckContent(content, filePath))
 		if !result.IsAllowed() {
 			return result
 		}
 	}
 
+	// Notebooks: code cells and metadata inside JSON
+	if IsNotebookFile(filePath) && h.directoryCheck.ZoneFor(filePath) != checks.ZonePermissive {
+		written := content
+		if written == "" {
+			written = GetString(toolInput, "new_string")
+		}
+		if written != "" {
+			result = h.Resolve(h.codeContentCheck.CheckNotebook(written, filePath))
+			if !result.IsAllowed() {
+				return result
+			}
+		}
+	}
+
+	// Saved content (e.g. a fetched page written to docs/) may carry
+	// instructions aimed at the assistant
+	if h.Config.PromptInjection.ScanWrites {
+		written := content
+		if written == "" {
+			written = GetString(toolInput, "new_string")
+		}
+		result = h.Resolve(h.injectionCheck.CheckContent(written, filePath))
+		if !result.IsAllowed() {
+			return result
+		}
+	}
+
+	// Custom rules
+	result = h.Resolve(h.customCheck.CheckFile(h.ToolName, filePath))
+	if !result.IsAllowed() {
+		return result
+	}
+
+	// Session overwrite and write size limits (Write replaces the whole file, Edit doesn't)
+	if h.ToolName == "Write" {
+		result = h.Resolve(h.massCheck.CheckWrite(filePath, len(content)))
+		if !result.IsAllowed() {
+			return result
+		}
+		h.massCheck.Commit()
+	}
+
 	return h.Allow()
 }
 
@@ -64,8 +149,8 @@ type EditHandler struct {
 }
 
 // NewEditHandler creates a new EditHandler instance.
-func NewEditHandler(cfg *config.SecurityConfig) *EditHandler {
-	h := NewWriteHandler(cfg)
+func NewEditHandler(e *checks.Engine) *EditHandler {
+	h := NewWriteHandler(e)
 	h.ToolName = "Edit"
 	return &EditHandler{WriteHandler: *h}
 }
@@ -76,21 +161,33 @@ type NotebookEditHandler struct {
 	directoryCheck   *checks.DirectoryCheck
 	secretsCheck     *checks.SecretsCheck
 	codeContentCheck *checks.CodeContentCheck
+	customCheck      *checks.CustomRuleCheck
 }
 
 // NewNotebookEditHandler creates a new NotebookEditHandler instance.
-func NewNotebookEditHandler(cfg *config.SecurityConfig) *NotebookEditHandler {
+func NewNotebookEditHandler(e *checks.Engine) *NotebookEditHandler {
 	return &NotebookEditHandler{
 		BaseHandler: BaseHandler{
 			ToolName: "NotebookEdit",
-			Config:   cfg,
+			Config:   e.Config,
+			Engine:   e,
 		},
-		directoryCheck:   checks.NewDirectoryCheck(cfg),
-		secretsCheck:     checks.NewSecretsCheck(cfg),
-		codeContentCheck: checks.NewCodeContentCheck(cfg),
+		directoryCheck:   checks.NewDirectoryCheck(e),
+		secretsCheck:     checks.NewSecretsCheck(e),
+		codeContentCheck: checks.NewCodeContentCheck(e),
+		customCheck:      checks.NewCustomRuleCheck(e),
 	}
 }
 
+// SetWorkDir sets the working directory on all checks.
+func (h *NotebookEditHandler) SetWorkDir(dir string) {
+	h.WorkDir = dir
+	h.directoryCheck.SetWorkDir(dir)
+	h.secretsCheck.SetWorkDir(dir)
+	h.codeContentCheck.SetWorkDir(dir)
+	h.customCheck.SetWorkDir(dir)
+}
+
 // Handle handles a NotebookEdit tool invocation.
 func (h *NotebookEditHandler) Handle(toolInput map[string]interface{}) *checks.CheckResult {
 	notebookPath := GetString(toolInput, "notebook_path")
@@ -102,24 +199,30 @@ func (h *NotebookEditHandler) Handle(toolInput map[string]interface{}) *checks.C
 	}
 
 	// Check directory boundaries
-	result := h.directoryCheck.CheckPath(notebookPath, "write")
+	result := h.Resolve(h.directoryCheck.CheckPath(notebookPath, "write"))
 	if !result.IsAllowed() {
 		return result
 	}
 
 	// Check protected files (no_modify)
-	result = h.secretsCheck.CheckPath(notebookPath, "write")
+	result = h.Resolve(h.secretsCheck.CheckPath(notebookPath, "write"))
 	if !result.IsAllowed() {
 		return result
 	}
 
 	// Check code cell content for dangerous patterns
-	if cellType == "code" && newSource != "" {
-		result = h.codeContentCheck.CheckContent(newSource, notebookPath+" (cell)")
+	if cellType == "code" && newSource != "" && h.directoryCheck.ZoneFor(notebookPath) != checks.ZonePermissive {
+		result = h.Resolve(h.codeContentCheck.CheckContent(newSource, notebookPath+" (cell)"))
 		if !result.IsAllowed() {
 			return result
 		}
 	}
 
+	// Custom rules
+	result = h.Resolve(h.customCheck.CheckFile(h.ToolName, notebookPath))
+	if !result.IsAllowed() {
+		return result
+	}
+
 	return h.Allow()
 }
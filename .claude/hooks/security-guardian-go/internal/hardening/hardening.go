@@ -0,0 +1,171 @@
+// Package hardening inspects the environment a session starts in: whether
+// the guardian's config loaded, which session settings weaken it, and
+// whether the paths it protects are where the config says.
+package hardening
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// Severity levels for findings.
+const (
+	LevelWarning = "warning"
+	LevelInfo    = "info"
+)
+
+// Finding is one item of the report.
+type Finding struct {
+	Level   string
+	Message string
+}
+
+// Report is the result of Inspect.
+type Report struct {
+	Findings []Finding
+	// Yolo is true when confirmations are turned into denials.
+	Yolo bool
+}
+
+// claudeSettings is the part of .claude/settings*.json the report reads.
+type claudeSettings struct {
+	Permissions struct {
+		DefaultMode           string   `json:"defaultMode"`
+		AdditionalDirectories []string `json:"additionalDirectories"`
+	} `json:"permissions"`
+}
+
+// Inspect builds the report. configPath is the loaded config ("" for
+// built-in defaults) and loadErr the error LoadConfig returned, if any.
+func Inspect(cfg *config.SecurityConfig, configPath string, loadErr error, projectRoot string) *Report {
+	r := &Report{}
+
+	// Config integrity
+	switch {
+	case loadErr != nil:
+		r.warn("Config %s failed to load, running with built-in defaults: %v", configPath, loadErr)
+	case configPath == "":
+		r.info("No security_config.yaml found, running with built-in defaults")
+	}
+
+	// Rules left off by patterns that don't compile
+	for _, err := range config.PatternErrors(cfg) {
+		r.warn("Pattern skipped by the checks (fix it or the rule stays off): %v", err)
+	}
+
+	// Session settings
+	permissionMode := ""
+	for _, path := range settingsFiles(projectRoot) {
+		s, err := readSettings(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				r.warn("Cannot parse %s: %v", path, err)
+			}
+			continue
+		}
+		if s.Permissions.DefaultMode != "" {
+			permissionMode = s.Permissions.DefaultMode
+		}
+		for _, dir := range s.Permissions.AdditionalDirectories {
+			if broad, why := isBroadDirectory(dir, projectRoot); broad {
+				r.warn("additionalDirectories in %s grants %s (%s); the guardian still blocks it, but Claude Code won't ask", path, dir, why)
+			}
+		}
+	}
+
+	if permissionMode == "bypassPermissions" || strings.EqualFold(os.Getenv("SECURITY_GUARDIAN_YOLO_MODE"), config.YoloModeOn) ||
+		strings.EqualFold(cfg.YoloMode, config.YoloModeOn) {
+		r.Yolo = true
+		r.warn("YOLO mode: permission prompts are skipped, so every confirmation is turned into a denial")
+	}
+
+	// Protected paths
+	var missing []string
+	for _, pattern := range cfg.ProtectedPaths.NoModify {
+		if strings.ContainsAny(pattern, "*?[") || strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		path := pattern
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectRoot, path)
+		}
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) > 0 {
+		r.info("Protected paths not found (moved, or a stale entry in no_modify): %s", strings.Join(missing, ", "))
+	}
+
+	return r
+}
+
+// Warnings returns warning messages only.
+func (r *Report) Warnings() []string {
+	var out []string
+	for _, f := range r.Findings {
+		if f.Level == LevelWarning {
+			out = append(out, f.Message)
+		}
+	}
+	return out
+}
+
+func (r *Report) warn(format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{LevelWarning, fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) info(format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{LevelInfo, fmt.Sprintf(format, args...)})
+}
+
+// settingsFiles returns Claude Code settings files in increasing precedence.
+func settingsFiles(projectRoot string) []string {
+	var files []string
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".claude", "settings.json"))
+	}
+	return append(files,
+		filepath.Join(projectRoot, ".claude", "settings.json"),
+		filepath.Join(projectRoot, ".claude", "settings.local.json"),
+	)
+}
+
+func readSettings(path string) (*claudeSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s claudeSettings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// isBroadDirectory reports whether an additional directory exposes far more
+// than a sibling project: the filesystem root, the home directory, or an
+// ancestor of the project.
+func isBroadDirectory(dir, projectRoot string) (bool, string) {
+	abs := config.ExpandPath(dir)
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(projectRoot, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	if abs == string(filepath.Separator) {
+		return true, "filesystem root"
+	}
+	if home, err := os.UserHomeDir(); err == nil && abs == filepath.Clean(home) {
+		return true, "home directory"
+	}
+	if rel, err := filepath.Rel(abs, projectRoot); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return true, "parent of the project"
+	}
+	return false, ""
+}
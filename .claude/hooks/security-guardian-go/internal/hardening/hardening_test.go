@@ -0,0 +1,42 @@
+package hardening
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// TestInspectConfigLoad checks that a config that fails to load is
+// reported, and a good one isn't.
+func TestInspectConfigLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		warned  bool
+	}{
+		{"malformed", "a: [\n", true},
+		{"valid", "strict_config: false\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "security_config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.LoadConfig(path)
+			warned := false
+			for _, w := range Inspect(cfg, path, err, dir).Warnings() {
+				if strings.Contains(w, "failed to load") {
+					warned = true
+				}
+			}
+			if warned != tt.warned {
+				t.Errorf("failed to load warning = %v, want %v", warned, tt.warned)
+			}
+		})
+	}
+}
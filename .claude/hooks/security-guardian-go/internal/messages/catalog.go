@@ -0,0 +1,188 @@
+package messages
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Languages with a message catalog.
+const (
+	LanguageEnglish = "en"
+	LanguageRussian = "ru"
+)
+
+// language is the output language, set once from config by SetLanguage.
+var language = LanguageEnglish
+
+// catalogs maps a language to English format string -> translated format
+// string. Checks keep producing English; messages are translated on output,
+// so the checks stay free of i18n plumbing. A translation uses the same
+// verbs in the same order as the English format.
+var catalogs = map[string]map[string]string{
+	LanguageRussian: catalogRu,
+}
+
+// labels translates the message prefixes.
+var labels = map[string]map[string]string{
+	LanguageRussian: {
+		"BLOCKED":  "ЗАПРЕЩЕНО",
+		"CONFIRM":  "ПОДТВЕРДИТЕ",
+		"WARNING":  "ВНИМАНИЕ",
+		"Guidance": "Что делать",
+	},
+}
+
+// SetLanguage selects the message language. Unknown languages fall back
+// to English.
+func SetLanguage(lang string) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if _, ok := catalogs[lang]; ok {
+		language = lang
+		return
+	}
+	language = LanguageEnglish
+}
+
+// label returns the prefix in the current language.
+func label(name string) string {
+	if l, ok := labels[language][name]; ok {
+		return l
+	}
+	return name
+}
+
+// entry is a compiled catalog template.
+type entry struct {
+	pattern *regexp.Regexp
+	target  string
+	// tail allows a further sentence after the template (guidance that a
+	// handler extended, e.g. with the git snapshot note)
+	tail bool
+}
+
+var (
+	compiled   = map[string][]entry{}
+	compileMux sync.Mutex
+)
+
+// Format verbs used by the checks
+var verbPattern = regexp.MustCompile(`%[sdqc%]`)
+
+// entries compiles the catalog for lang once. Templates with more literal
+// text are tried first, so the most specific one wins.
+func entries(lang string) []entry {
+	compileMux.Lock()
+	defer compileMux.Unlock()
+
+	if e, ok := compiled[lang]; ok {
+		return e
+	}
+
+	type source struct{ from, to string }
+	var sources []source
+	for from, to := range catalogs[lang] {
+		sources = append(sources, source{from, to})
+	}
+	literal := func(s string) int { return len(verbPattern.ReplaceAllString(s, "")) }
+	sort.Slice(sources, func(i, j int) bool {
+		if li, lj := literal(sources[i].from), literal(sources[j].from); li != lj {
+			return li > lj
+		}
+		return sources[i].from < sources[j].from
+	})
+
+	var result []entry
+	for _, s := range sources {
+		tail := strings.HasSuffix(s.from, ".") || strings.HasSuffix(s.from, "`")
+		result = append(result, entry{
+			pattern: regexp.MustCompile(templatePattern(s.from, tail)),
+			target: verbPattern.ReplaceAllStringFunc(s.to, func(v string) string {
+				if v == "%%" {
+					return v
+				}
+				return "%s"
+			}),
+			tail: tail,
+		})
+	}
+	compiled[lang] = result
+	return result
+}
+
+// templatePattern turns a format string into an anchored regexp capturing
+// each verb's value.
+func templatePattern(format string, tail bool) string {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range verbPattern.FindAllStringIndex(format, -1) {
+		b.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		switch format[loc[0]:loc[1]] {
+		case "%d":
+			b.WriteString(`(-?\d+)`)
+		case "%q":
+			b.WriteString(`("(?:[^"\\]|\\.)*")`)
+		case "%c":
+			b.WriteString(`(.)`)
+		case "%%":
+			b.WriteString(`%`)
+		default:
+			b.WriteString(`(.*?)`)
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(format[last:]))
+	if tail {
+		b.WriteString(`(?: (.+))?`)
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// Translate translates text line by line into the current language.
+// Lines without a catalog entry (findings, file names) are kept as is.
+func Translate(text string) string {
+	if language == LanguageEnglish || text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+		lines[i] = indent + translateLine(trimmed, entries(language), 2)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// translateLine translates one line. Captured values are translated too
+// (depth levels), since some reasons wrap another reason.
+func translateLine(line string, catalog []entry, depth int) string {
+	if line == "" || depth == 0 {
+		return line
+	}
+	for _, e := range catalog {
+		m := e.pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		values := m[1:]
+		rest := ""
+		if e.tail {
+			rest = values[len(values)-1]
+			values = values[:len(values)-1]
+		}
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			args[i] = translateLine(v, catalog, depth-1)
+		}
+		out := fmt.Sprintf(e.target, args...)
+		if rest != "" {
+			out += " " + translateLine(rest, catalog, depth)
+		}
+		return out
+	}
+	return line
+}
@@ -10,10 +10,10 @@ import (
 
 // FormatBlockMessage formats a DENY message for Claude (hard block, no confirmation possible).
 func FormatBlockMessage(result *checks.CheckResult) string {
-	parts := []string{fmt.Sprintf("BLOCKED: %s", result.Reason)}
+	parts := []string{fmt.Sprintf("%s: %s", label("BLOCKED"), Translate(result.Reason))}
 
 	if result.Guidance != "" {
-		parts = append(parts, fmt.Sprintf("Guidance: %s", result.Guidance))
+		parts = append(parts, fmt.Sprintf("%s: %s", label("Guidance"), Translate(result.Guidance)))
 	}
 
 	return strings.Join(parts, "\n")
@@ -21,10 +21,22 @@ func FormatBlockMessage(result *checks.CheckResult) string {
 
 // FormatConfirmMessage formats an ASK message for Claude (soft block, user can confirm).
 func FormatConfirmMessage(result *checks.CheckResult) string {
-	parts := []string{fmt.Sprintf("CONFIRM: %s", result.Reason)}
+	parts := []string{fmt.Sprintf("%s: %s", label("CONFIRM"), Translate(result.Reason))}
 
 	if result.Guidance != "" {
-		parts = append(parts, fmt.Sprintf("Guidance: %s", result.Guidance))
+		parts = append(parts, fmt.Sprintf("%s: %s", label("Guidance"), Translate(result.Guidance)))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// FormatWarningMessage formats a warning about tool output that was already
+// produced (PostToolUse), e.g. fetched content with prompt-injection markers.
+func FormatWarningMessage(result *checks.CheckResult) string {
+	parts := []string{fmt.Sprintf("%s: %s", label("WARNING"), Translate(result.Reason))}
+
+	if result.Guidance != "" {
+		parts = append(parts, fmt.Sprintf("%s: %s", label("Guidance"), Translate(result.Guidance)))
 	}
 
 	return strings.Join(parts, "\n")
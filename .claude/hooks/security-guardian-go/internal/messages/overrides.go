@@ -0,0 +1,52 @@
+package messages
+
+import (
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// ApplyOverride returns result with the reason and guidance replaced by the
+// messages.overrides templates for its rule, or result itself if there is
+// no override. The rule is taken from payload, so a decoy hit gets the
+// override of the rule it poses as. Template variables:
+//
+//	{rule_id}       rule ID
+//	{path}          first offending path
+//	{command}       Bash command
+//	{project_root}  project root
+//	{reason}        built-in reason
+//	{guidance}      built-in guidance
+func ApplyOverride(result *checks.CheckResult, payload *Payload, cfg *config.SecurityConfig, toolInput map[string]interface{}, projectRoot string) *checks.CheckResult {
+	override, ok := cfg.Messages.Overrides[payload.RuleID]
+	if !ok || (override.Reason == "" && override.Guidance == "") {
+		return result
+	}
+
+	path := ""
+	if len(payload.Paths) > 0 {
+		path = payload.Paths[0]
+	}
+	command, _ := toolInput["command"].(string)
+
+	// The built-in text is inserted already translated; Translate leaves
+	// the rest of the template (not in the catalog) as written
+	vars := strings.NewReplacer(
+		"{rule_id}", payload.RuleID,
+		"{path}", path,
+		"{command}", command,
+		"{project_root}", projectRoot,
+		"{reason}", Translate(result.Reason),
+		"{guidance}", Translate(result.Guidance),
+	)
+
+	shown := *result
+	if override.Reason != "" {
+		shown.Reason = vars.Replace(override.Reason)
+	}
+	if override.Guidance != "" {
+		shown.Guidance = vars.Replace(override.Guidance)
+	}
+	return &shown
+}
@@ -0,0 +1,157 @@
+package messages
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// Payload is the machine-readable part of a deny/ask message, so Claude can
+// offer the safer command or ask the user about the right setting instead
+// of parsing prose.
+type Payload struct {
+	DecisionID string   `json:"decision_id,omitempty"` // see guardian explain
+	RuleID     string   `json:"rule_id,omitempty"`
+	Decision   string   `json:"decision"`
+	Check      string   `json:"check"`
+	Paths      []string `json:"paths,omitempty"`
+	Suggestion string   `json:"suggested_command,omitempty"`
+	ConfigKeys []string `json:"config_keys,omitempty"` // settings the user could change to allow it
+	// The guardian and config that decided (see guardian version)
+	GuardianVersion string `json:"guardian_version,omitempty"`
+	ConfigVersion   string `json:"config_version,omitempty"`
+}
+
+// Backticked command the guidance asks to hand to the user or use instead
+var suggestionPattern = regexp.MustCompile("(?i)(?:give (?:the )?user[^`]*|instead:\\s*|(?:they can|and) run\\s*)`([^`]+)`")
+
+// Tool input keys that hold the path a tool operates on
+var pathInputKeys = []string{"file_path", "notebook_path", "path"}
+
+// ruleConfigKeys maps rule IDs (or "prefix.*") to the config keys that
+// control them. decisions.<rule_id> is always added.
+var ruleConfigKeys = map[string][]string{
+	"directory.unresolved_path":              {"directories.path_variables"},
+	"directory.*":                            {"directories.allowed_paths"},
+	"bypass.inline_network":                  {"bypass_prevention.inline_network_allowed_hosts"},
+	"git.hard_blocked":                       {"git.hard_blocked", "git.allowed", "git.protected_branches"},
+	"git.confirm_required":                   {"git.confirm_required", "git.allowed", "git.protected_branches"},
+	"git.remote_branch_delete":               {"git.repo_aware", "git.allowed"},
+	"git.destination_outside_project":        {"directories.allowed_paths"},
+	"git.remote_not_allowed":                 {"git.allowed_remotes"},
+	"git.export_to_network":                  {"pipelines.network_sinks"},
+	"deletion.*":                             {"whitelist", "trash.enabled"},
+	"download.binary_executable":             {"download_protection.require_user_download"},
+	"unpack.blocked_pattern":                 {"unpack_protection.blocked_patterns"},
+	"unpack.outside_project":                 {"directories.allowed_paths"},
+	"execution.run_downloaded":               {"download_protection.confirm_first_run", "trusted_scripts"},
+	"execution.*":                            {"trusted_scripts"},
+	"secrets.no_modify":                      {"protected_paths.no_modify"},
+	"secrets.write_secret_file":              {"protected_paths.no_read_content"},
+	"secrets.read_secret_file":               {"protected_paths.no_read_content"},
+	"secrets.high_entropy_content":           {"sensitive_files.content_scan"},
+	"secrets.system_file":                    {"sensitive_files.system_paths"},
+	"secrets.dotfile_write":                  {"protected_paths.no_write_outside_tools"},
+	"secrets.vault_access":                   {"sensitive_files.vaults"},
+	"secrets.credential_file":                {"sensitive_files.credential_files"},
+	"zone.strict_write":                      {"zones"},
+	"injection.prompt_markers":               {"prompt_injection.patterns"},
+	"gpg.*":                                  {"gpg.enabled", "gpg.home"},
+	"autoload.write":                         {"autoload.paths"},
+	"autoload.activation_content":            {"autoload.activation", "dangerous_operations.network"},
+	"autoload.ide_command":                   {"autoload.ide.files", "dangerous_operations"},
+	"pipeline.*":                             {"pipelines"},
+	"data_export.*":                          {"dangerous_operations.data_export"},
+	"network.cloud_metadata":                 {"cloud_metadata.endpoints", "cloud_metadata.allowed"},
+	"unattended.network_domain":              {"unattended.allowed_domains"},
+	"tamper.guardian_probe":                  {"tamper_detection.patterns"},
+	"canary.token_reuse":                     {"canary_tokens.enabled"},
+	"websearch.blocked_pattern":              {"web_search.blocked_patterns"},
+	"slash_command.not_allowed":              {"slash_commands.allowed"},
+	"subagent.blocked_instruction":           {"subagents.blocked_patterns"},
+	"subagent.outside_project":               {"directories.allowed_paths"},
+	"mass.files_deleted":                     {"mass_modification.max_files_deleted"},
+	"mass.files_overwritten":                 {"mass_modification.max_files_overwritten"},
+	"mass.write_size":                        {"mass_modification.max_write_bytes"},
+	"code.trusted_script_changed":            {"trusted_scripts"},
+	"code.notebook_metadata":                 {"dangerous_operations.notebook_metadata"},
+	"code.*":                                 {"dangerous_operations", "zones"},
+	"background_shell.denied_command_output": {"background_shells.flag_denied_output"},
+	"input.oversized":                        {"input_limits.max_input_bytes", "input_limits.on_oversized"},
+	"internal.timeout":                       {"performance.check_timeout_ms", "performance.on_timeout"},
+	"internal.panic":                         {"on_internal_error"},
+	"internal.error":                         {"on_internal_error"},
+	"internal.config":                        {"strict_config"},
+	"custom.*":                               {"custom_rules"},
+}
+
+// BuildPayload extracts the structured part of a non-allow result.
+// toolInput fills in the path when the check didn't record one.
+func BuildPayload(result *checks.CheckResult, toolInput map[string]interface{}) *Payload {
+	p := &Payload{
+		RuleID:     result.RuleID,
+		Decision:   string(result.PermissionDecisionValue()),
+		Check:      result.CheckName,
+		Paths:      result.Paths,
+		Suggestion: result.Suggestion,
+	}
+
+	if len(p.Paths) == 0 {
+		seen := make(map[string]bool)
+		for _, d := range result.Details {
+			if d.File != "" && !seen[d.File] {
+				seen[d.File] = true
+				p.Paths = append(p.Paths, d.File)
+			}
+		}
+	}
+	if len(p.Paths) == 0 {
+		for _, key := range pathInputKeys {
+			if s, ok := toolInput[key].(string); ok && s != "" {
+				p.Paths = []string{s}
+				break
+			}
+		}
+	}
+
+	if p.Suggestion == "" {
+		if m := suggestionPattern.FindStringSubmatch(result.Guidance); m != nil {
+			p.Suggestion = m[1]
+		}
+	}
+
+	// Decoys must stay indistinguishable from real secrets files
+	if p.RuleID == checks.RuleDecoyAccess {
+		p.RuleID = checks.RuleSecretsRead
+		p.Check = "secrets_check"
+	}
+	if p.RuleID != "" {
+		p.ConfigKeys = append(p.ConfigKeys, configKeysFor(p.RuleID)...)
+		p.ConfigKeys = append(p.ConfigKeys, "decisions."+p.RuleID)
+	}
+
+	return p
+}
+
+// configKeysFor returns the config keys for a rule: an exact entry, else
+// the entry for its prefix.
+func configKeysFor(ruleID string) []string {
+	if keys, ok := ruleConfigKeys[ruleID]; ok {
+		return keys
+	}
+	if i := strings.Index(ruleID, "."); i > 0 {
+		return ruleConfigKeys[ruleID[:i]+".*"]
+	}
+	return nil
+}
+
+// FormatPayload renders the payload as a single JSON line for the message.
+func FormatPayload(p *Payload) string {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return "Decision data: " + string(data)
+}
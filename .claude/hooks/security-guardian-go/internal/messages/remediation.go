@@ -0,0 +1,149 @@
+package messages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+)
+
+// remedy is the config change that would allow a rule's operations.
+// value may use {path}, {dir}, {command} and {match} (the text after the
+// last ": " of the reason - the matched operation or pattern).
+type remedy struct {
+	action string // add, remove, set, raise; "" means no remedy is offered
+	value  string
+	key    string
+}
+
+// ruleRemedies maps rule IDs (or "prefix.*") to their remedy. Rules not
+// listed fall back to decisions.<rule_id>: ask.
+var ruleRemedies = map[string]remedy{
+	"directory.unresolved_path":              {"set", "allow", "decisions.directory.unresolved_path"},
+	"directory.*":                            {"add", "{dir}", "directories.allowed_paths"},
+	"unpack.outside_project":                 {"add", "{dir}", "directories.allowed_paths"},
+	"subagent.outside_project":               {"add", "{dir}", "directories.allowed_paths"},
+	"bypass.inline_network":                  {"add", "the host", "bypass_prevention.inline_network_allowed_hosts"},
+	"bypass.self_trust":                      {},
+	"bypass.self_exception":                  {},
+	"bypass.self_update":                     {},
+	"git.remote_branch_delete":               {"set", "allow", "decisions.git.remote_branch_delete"},
+	"git.shell_alias":                        {"set", "allow", "decisions.git.shell_alias"},
+	"git.destination_outside_project":        {"add", "{dir}", "directories.allowed_paths"},
+	"git.remote_not_allowed":                 {"add", `"{match}"`, "git.allowed_remotes"},
+	"git.export_to_network":                  {"set", "allow", "decisions.git.export_to_network"},
+	"git.export_outside_project":             {"set", "allow", "decisions.git.export_outside_project"},
+	"git.*":                                  {"add", `"{match}"`, "git.allowed"},
+	"deletion.recursive_glob":                {"add", `exact: "{command}"`, "whitelist"},
+	"deletion.outside_project":               {"add", `exact: "{command}"`, "whitelist"},
+	"deletion.protected_path":                {"add", `exact: "{command}"`, "whitelist"},
+	"deletion.protected_ancestor":            {"add", `exact: "{command}"`, "whitelist"},
+	"deletion.project_root":                  {"add", `exact: "{command}"`, "whitelist"},
+	"deletion.critical_path":                 {},
+	"deletion.move_project_root":             {},
+	"download.binary_executable":             {"remove", "the file extension", "download_protection.require_user_download"},
+	"unpack.blocked_pattern":                 {"remove", `"{match}"`, "unpack_protection.blocked_patterns"},
+	"execution.trusted_script_changed":       {},
+	"execution.run_downloaded":               {"set", "false", "download_protection.confirm_first_run"},
+	"code.trusted_script_changed":            {},
+	"code.notebook_metadata":                 {"remove", "the matching pattern", "dangerous_operations.notebook_metadata"},
+	"secrets.no_modify":                      {"remove", "the pattern matching {path}", "protected_paths.no_modify"},
+	"secrets.write_secret_file":              {"add", `"!{path}"`, "protected_paths.no_read_content"},
+	"secrets.read_secret_file":               {"add", `"!{path}"`, "protected_paths.no_read_content"},
+	"secrets.high_entropy_content":           {"add", `"{path}"`, "sensitive_files.content_scan.skip_files"},
+	"secrets.system_file":                    {"add", `"!{path}"`, "sensitive_files.system_paths"},
+	"secrets.dotfile_write":                  {"add", `"!{path}"`, "protected_paths.no_write_outside_tools"},
+	"secrets.vault_access":                   {},
+	"secrets.credential_file":                {"add", `"!{path}"`, "sensitive_files.credential_files"},
+	"zone.strict_write":                      {"remove", "the strict zone covering {path}", "zones"},
+	"injection.prompt_markers":               {"remove", "the matching pattern", "prompt_injection.patterns"},
+	"gpg.export_secret_key":                  {},
+	"gpg.*":                                  {"set", "false", "gpg.enabled"},
+	"autoload.write":                         {"remove", "the entry matching {path}", "autoload.paths"},
+	"autoload.activation_content":            {"remove", "the matching pattern", "autoload.activation"},
+	"autoload.ide_command":                   {"remove", "the pattern matching {path}", "autoload.ide.files"},
+	"pipeline.network_sink":                  {"remove", "the matching command", "pipelines.network_sinks"},
+	"pipeline.encoded_secret":                {"remove", "the matching command", "pipelines.encoders"},
+	"data_export.dump_to_network":            {"remove", "the matching command", "dangerous_operations.data_export.commands"},
+	"data_export.dump_outside_project":       {"remove", "the matching command", "dangerous_operations.data_export.commands"},
+	"data_export.credentials_in_command":     {"remove", "the matching pattern", "dangerous_operations.data_export.credentials"},
+	"data_export.large_dump":                 {"raise", "", "dangerous_operations.data_export.max_dump_bytes"},
+	"network.cloud_metadata":                 {},
+	"unattended.network_domain":              {"add", `"{match}"`, "unattended.allowed_domains"},
+	"tamper.guardian_probe":                  {"remove", "the matching pattern", "tamper_detection.patterns"},
+	"canary.token_reuse":                     {},
+	"websearch.blocked_pattern":              {"remove", "the matching pattern", "web_search.blocked_patterns"},
+	"slash_command.not_allowed":              {"add", "the command name", "slash_commands.allowed"},
+	"subagent.blocked_instruction":           {"remove", "the matching pattern or type", "subagents"},
+	"mass.files_deleted":                     {"raise", "", "mass_modification.max_files_deleted"},
+	"mass.files_overwritten":                 {"raise", "", "mass_modification.max_files_overwritten"},
+	"mass.write_size":                        {"raise", "", "mass_modification.max_write_bytes"},
+	"background_shell.denied_command_output": {"set", "false", "background_shells.flag_denied_output"},
+	"input.oversized":                        {"raise", "", "input_limits.max_input_bytes"},
+	"internal.timeout":                       {"raise", "", "performance.check_timeout_ms"},
+	"internal.panic":                         {},
+	"internal.error":                         {},
+	"internal.config":                        {},
+	"custom.*":                               {"remove", "the rule", "custom_rules"},
+}
+
+// remedyFor returns the remedy for a rule ID.
+func remedyFor(ruleID string) (remedy, bool) {
+	if r, ok := ruleRemedies[ruleID]; ok {
+		return r, true
+	}
+	if i := strings.Index(ruleID, "."); i > 0 {
+		if r, ok := ruleRemedies[ruleID[:i]+".*"]; ok {
+			return r, true
+		}
+	}
+	return remedy{}, false
+}
+
+// Remediation returns a sentence naming the config key (and value) that
+// would allow this class of operation, or "" when the rule has no remedy
+// (e.g. deleting / is never offered). The rule is taken from payload, so
+// a decoy hit gets the remedy of the rule it poses as.
+func Remediation(result *checks.CheckResult, payload *Payload, toolInput map[string]interface{}) string {
+	if payload.RuleID == "" {
+		return ""
+	}
+	r, ok := remedyFor(payload.RuleID)
+	if !ok {
+		return fmt.Sprintf("To have the user confirm this class of operation instead, set decisions.%s: ask.", payload.RuleID)
+	}
+
+	path, dir := "", ""
+	if len(payload.Paths) > 0 {
+		path = payload.Paths[0]
+		dir = path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+	}
+	match := result.Reason
+	if i := strings.LastIndex(match, ": "); i >= 0 {
+		match = match[i+2:]
+	}
+	command, _ := toolInput["command"].(string)
+	value := strings.NewReplacer(
+		"{path}", path,
+		"{dir}", dir,
+		"{command}", command,
+		"{match}", match,
+	).Replace(r.value)
+
+	switch r.action {
+	case "add":
+		return fmt.Sprintf("To allow this class of operation, add %s to %s.", value, r.key)
+	case "remove":
+		return fmt.Sprintf("To allow this class of operation, remove %s from %s.", value, r.key)
+	case "set":
+		return fmt.Sprintf("To allow this class of operation, set %s: %s.", r.key, value)
+	case "raise":
+		return fmt.Sprintf("To allow this class of operation, raise %s.", r.key)
+	}
+	return ""
+}
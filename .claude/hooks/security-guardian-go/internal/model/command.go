@@ -0,0 +1,23 @@
+// Package model holds the types shared by the parsers, the checks and the
+// handlers, so each is defined once. The command model is the one of
+// pkg/shparse.
+package model
+
+import "github.com/artwist-polyakov/security-guardian/pkg/shparse"
+
+// ParsedCommand represents a parsed bash command (see shparse.Command).
+type ParsedCommand = shparse.Command
+
+// Redirect is a file redirection (see shparse.Redirect).
+type Redirect = shparse.Redirect
+
+const (
+	// PipeViaProcSubst marks the command inside a >(...) write target.
+	PipeViaProcSubst = shparse.PipeViaProcSubst
+	// PipeViaFifo marks a command reading a named pipe.
+	PipeViaFifo = shparse.PipeViaFifo
+	// NestingCmdSubst marks a command inside $(...) or `...`.
+	NestingCmdSubst = shparse.NestingCmdSubst
+	// NestingProcSubst marks a command inside <(...) or >(...).
+	NestingProcSubst = shparse.NestingProcSubst
+)
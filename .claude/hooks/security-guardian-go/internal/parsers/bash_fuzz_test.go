@@ -0,0 +1,93 @@
+package parsers
+
+import (
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are inputs that have tripped shell parsers: nested
+// substitutions, broken quoting, long lists and huge heredocs. The checked-in
+// corpus in testdata/fuzz has more.
+func fuzzSeeds() []string {
+	return []string{
+		"",
+		"ls -la",
+		`cd sub && cat ../../etc/passwd > out 2>&1`,
+		`echo $(cat $(ls <(rm -rf ~)))`,
+		"echo `echo \\`id\\``",
+		`cat "unterminated`,
+		`echo 'a"b'"c'd" \"`,
+		`cat <<EOF` + "\n" + strings.Repeat("line $(rm -rf /)\n", 2000) + "EOF\n",
+		strings.Repeat("true && ", 5000) + "rm -rf /",
+		strings.Repeat("a | ", 5000) + "sh",
+		strings.Repeat("$(", 500) + "x" + strings.Repeat(")", 500),
+		strings.Repeat(`"`, 4001),
+		strings.Repeat("; ", 3000),
+	}
+}
+
+// FuzzParseBashCommand checks that parsing never panics and yields
+// well-formed commands.
+func FuzzParseBashCommand(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, command string) {
+		cmds := ParseBashCommand(command)
+		inResult := make(map[*ParsedCommand]bool, len(cmds))
+		for _, cmd := range cmds {
+			if cmd == nil {
+				t.Fatalf("nil command for %q", command)
+			}
+			inResult[cmd] = true
+		}
+		for _, cmd := range cmds {
+			if cmd.Command == "" {
+				t.Errorf("empty command name for %q", command)
+			}
+			// A pipeline chain ends: PipesTo never loops back
+			steps := 0
+			for next := cmd.PipesTo; next != nil; next = next.PipesTo {
+				if steps++; steps > len(cmds) {
+					t.Fatalf("PipesTo cycle for %q", command)
+				}
+				if !inResult[next] {
+					t.Errorf("PipesTo outside the result for %q", command)
+				}
+			}
+			for _, sub := range cmd.Subcommands {
+				if sub.Parent != cmd {
+					t.Errorf("subcommand %q of %q has parent %v for %q", sub.Command, cmd.Command, sub.Parent, command)
+				}
+			}
+			for _, word := range cmd.Unresolved {
+				if !cmd.IsUnresolved(word) {
+					t.Errorf("IsUnresolved(%q) = false for %q", word, command)
+				}
+			}
+		}
+	})
+}
+
+// FuzzExtractPaths checks that path extraction and operand classification
+// never panic and only return path-like operands of the command.
+func FuzzExtractPaths(f *testing.F) {
+	for _, seed := range fuzzSeeds() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, command string) {
+		for _, cmd := range ParseBashCommand(command) {
+			for _, path := range ExtractPathsFromCommand(cmd) {
+				if !IsPathLike(path) {
+					t.Errorf("ExtractPathsFromCommand returned %q, not path-like, for %q", path, command)
+				}
+			}
+			for _, op := range ClassifyOperands(cmd) {
+				if op.Value == "" {
+					t.Errorf("empty operand of %q for %q", cmd.Command, command)
+				}
+				JoinDir(cmd.Dir, op.Value)
+			}
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseDirs checks the directory each command runs in after the
+// cd/pushd/popd before it.
+func TestParseDirs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		dirs    []string
+	}{
+		{"cd into subdir", `cd sub && cat a`, []string{"", "sub"}},
+		{"cd out of subdir", `cd sub && cd .. && cat a`, []string{"", "sub", "."}},
+		{"cd escapes", `cd sub && cat ../..`, []string{"", "sub"}},
+		{"cd dot dot", `cd ../.. && cat a`, []string{"", "../.."}},
+		{"cd absolute", `cd /tmp && rm -rf data`, []string{"", "/tmp"}},
+		{"cd home", `cd && cat a`, []string{"", "~"}},
+		{"cd - after cd", `cd sub && cd /tmp && cd - && cat a`, []string{"", "sub", "/tmp", "sub"}},
+		{"subshell cd doesn't leak", `(cd sub && cat a) && cat b`, []string{"", "sub", ""}},
+		{"pipeline cd doesn't leak", `cd sub | cat a; cat b`, []string{"", "", ""}},
+		{"pushd popd", `pushd sub && cat a && popd && cat b`, []string{"", "sub", "sub", ""}},
+		{"nested pushd", `pushd sub; pushd deep; cat a; popd; cat b; popd; cat c`, []string{"", "sub", "sub/deep", "sub/deep", "sub", "sub", ""}},
+		{"pushd swap", `pushd sub; pushd; cat a`, []string{"", "sub", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dirs []string
+			for _, cmd := range ParseBashCommand(tt.command) {
+				dirs = append(dirs, cmd.Dir)
+			}
+			if !reflect.DeepEqual(dirs, tt.dirs) {
+				t.Errorf("ParseBashCommand(%q) dirs = %q, want %q", tt.command, dirs, tt.dirs)
+			}
+		})
+	}
+}
+
+func TestJoinDir(t *testing.T) {
+	tests := []struct {
+		dir, path, want string
+	}{
+		{"", "a", "a"},
+		{"sub", "a", "sub/a"},
+		{"sub", "../a", "a"},
+		{"sub", "/etc/passwd", "/etc/passwd"},
+		{"sub", "~/a", "~/a"},
+		{"sub", "", ""},
+	}
+	for _, tt := range tests {
+		if got := JoinDir(tt.dir, tt.path); got != tt.want {
+			t.Errorf("JoinDir(%q, %q) = %q, want %q", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package parsers
+
+import "strings"
+
+// GitInvocation is a git command with its arguments in canonical form, so
+// one policy pattern covers every spelling of an operation.
+type GitInvocation struct {
+	Subcommand string
+	// Flags are the options of the subcommand: combined short flags split
+	// (-fd: -f -d), values dropped (--force-with-lease=main), long aliases
+	// replaced by the short flag (branch --delete: -d) and shorthands by
+	// what they stand for (branch -D: -d -f). A push with a +refspec has
+	// --force. Words after "--" are never flags.
+	Flags []string
+	// Args are the operands: refspecs, branches, paths; "--" dropped.
+	Args []string
+}
+
+// gitFlagAliases maps long options to the short flag they equal, per
+// subcommand. push -f and push --force are left apart: the default config
+// asks for one and denies the other.
+var gitFlagAliases = map[string]map[string]string{
+	"branch":   {"--delete": "-d", "--force": "-f", "--move": "-m", "--copy": "-c", "--remotes": "-r", "--all": "-a"},
+	"checkout": {"--force": "-f", "--quiet": "-q"},
+	"clean":    {"--force": "-f", "--dry-run": "-n", "--quiet": "-q", "--interactive": "-i"},
+	"fetch":    {"--prune": "-p", "--force": "-f"},
+	"push":     {"--delete": "-d", "--dry-run": "-n", "--set-upstream": "-u", "--quiet": "-q", "--verbose": "-v"},
+	"reset":    {"--quiet": "-q", "--patch": "-p"},
+	"restore":  {"--staged": "-S", "--worktree": "-W", "--source": "-s"},
+	"rm":       {"--force": "-f", "--quiet": "-q", "--dry-run": "-n"},
+	"stash":    {"--all": "-a", "--keep-index": "-k", "--include-untracked": "-u"},
+	"switch":   {"--force": "-f", "--discard-changes": "-f", "--create": "-c", "--force-create": "-C"},
+	"tag":      {"--delete": "-d", "--force": "-f"},
+}
+
+// gitFlagShorthands maps short flags that combine others, per subcommand.
+var gitFlagShorthands = map[string]map[string][]string{
+	"branch": {"-D": {"-d", "-f"}, "-M": {"-m", "-f"}, "-C": {"-c", "-f"}},
+}
+
+// NormalizeGitArgs returns the canonical subcommand, flags and operands of
+// a git command, or nil if it has no subcommand. Global options before the
+// subcommand (-C dir, -c key=value) are skipped.
+func NormalizeGitArgs(cmd *ParsedCommand) *GitInvocation {
+	if cmd == nil || cmd.Command != "git" {
+		return nil
+	}
+	words := cmd.Words
+	if len(words) == 0 {
+		// Commands decoded from JSON without words: flags and args apart
+		subcommand, flags := GetGitSubcommandAndFlags([]*ParsedCommand{cmd})
+		if subcommand == "" {
+			return nil
+		}
+		inv := &GitInvocation{Subcommand: subcommand, Args: GetGitArgs(cmd)}
+		inv.addFlags(flags)
+		return inv
+	}
+
+	i := gitSubcommandIndex(words)
+	if i < 0 {
+		return nil
+	}
+	inv := &GitInvocation{Subcommand: words[i]}
+
+	var flags []string
+	rest := words[i+1:]
+	for j, word := range rest {
+		if word == "--" {
+			inv.Args = append(inv.Args, rest[j+1:]...)
+			break
+		}
+		if strings.HasPrefix(word, "-") && word != "-" {
+			flags = append(flags, word)
+			continue
+		}
+		inv.Args = append(inv.Args, word)
+	}
+	inv.addFlags(flags)
+
+	// A +refspec forces the push like --force does, and --mirror
+	// force-updates every ref
+	if inv.Subcommand == "push" && inv.HasFlag("--mirror") {
+		inv.addFlags([]string{"--force"})
+	}
+	if inv.Subcommand == "push" {
+		for _, arg := range inv.Args {
+			if strings.HasPrefix(arg, "+") {
+				inv.addFlags([]string{"--force"})
+				break
+			}
+		}
+	}
+	return inv
+}
+
+// gitSubcommandIndex returns the index of the subcommand in the words of
+// a git command, after the global options, or -1.
+func gitSubcommandIndex(words []string) int {
+	i := 0
+	for ; i < len(words) && strings.HasPrefix(words[i], "-"); i++ {
+		if gitGlobalFlagsWithValue[words[i]] {
+			i++
+		}
+	}
+	if i >= len(words) {
+		return -1
+	}
+	return i
+}
+
+// gitValueFlags are the options taking the next word as their value, for
+// the subcommands whose operands name a destination or a remote.
+var gitValueFlags = map[string]map[string]bool{
+	"clone": {
+		"-b": true, "--branch": true, "-o": true, "--origin": true, "-c": true, "--config": true,
+		"-u": true, "--upload-pack": true, "-j": true, "--jobs": true, "--depth": true,
+		"--reference": true, "--reference-if-able": true, "--template": true, "--filter": true,
+		"--separate-git-dir": true, "--shallow-since": true, "--shallow-exclude": true,
+		"--bundle-uri": true, "--server-option": true,
+	},
+	"worktree":  {"-b": true, "-B": true, "--reason": true},
+	"submodule": {"-b": true, "--branch": true, "--name": true, "--reference": true, "--depth": true},
+	"remote":    {"-t": true, "-m": true},
+}
+
+// GitOperands returns the subcommand of a git command and its operands,
+// without the values of options that take the next word ("clone -b main
+// <url> <dir>": <url> <dir>). It returns "" for commands without words.
+func GitOperands(cmd *ParsedCommand) (subcommand string, operands []string) {
+	if cmd == nil || cmd.Command != "git" {
+		return "", nil
+	}
+	i := gitSubcommandIndex(cmd.Words)
+	if i < 0 {
+		return "", nil
+	}
+	subcommand = cmd.Words[i]
+	rest := cmd.Words[i+1:]
+	for j := 0; j < len(rest); j++ {
+		word := rest[j]
+		if word == "--" {
+			return subcommand, append(operands, rest[j+1:]...)
+		}
+		if strings.HasPrefix(word, "-") && word != "-" {
+			if gitValueFlags[subcommand][word] {
+				j++
+			}
+			continue
+		}
+		operands = append(operands, word)
+	}
+	return subcommand, operands
+}
+
+// GitOptionValues returns the values a git command gives a subcommand
+// option, spelled short ("-o file", "-ofile") or long ("--output file",
+// "--output=file").
+func GitOptionValues(cmd *ParsedCommand, short, long string) []string {
+	if cmd == nil || cmd.Command != "git" {
+		return nil
+	}
+	i := gitSubcommandIndex(cmd.Words)
+	if i < 0 {
+		return nil
+	}
+	var values []string
+	rest := cmd.Words[i+1:]
+	for j := 0; j < len(rest); j++ {
+		word := rest[j]
+		switch {
+		case word == "--":
+			return values
+		case word == short || word == long:
+			if j+1 < len(rest) {
+				values = append(values, rest[j+1])
+				j++
+			}
+		case strings.HasPrefix(word, long+"="):
+			values = append(values, word[len(long)+1:])
+		case len(short) == 2 && strings.HasPrefix(word, short) && !strings.HasPrefix(word, "--"):
+			values = append(values, word[2:])
+		}
+	}
+	return values
+}
+
+// gitBuiltins are the git commands an alias of the same name can't hide.
+var gitBuiltins = map[string]bool{
+	"add": true, "am": true, "annotate": true, "apply": true, "archive": true, "bisect": true,
+	"blame": true, "branch": true, "bundle": true, "cat-file": true, "check-ignore": true,
+	"checkout": true, "checkout-index": true, "cherry": true, "cherry-pick": true, "clean": true,
+	"clone": true, "commit": true, "commit-tree": true, "config": true, "count-objects": true,
+	"describe": true, "diff": true, "diff-files": true, "diff-index": true, "diff-tree": true,
+	"difftool": true, "fast-export": true, "fast-import": true, "fetch": true, "filter-branch": true,
+	"for-each-ref": true, "format-patch": true, "fsck": true, "gc": true, "grep": true,
+	"hash-object": true, "help": true, "init": true, "log": true, "ls-files": true,
+	"ls-remote": true, "ls-tree": true, "maintenance": true, "merge": true, "merge-base": true,
+	"mergetool": true, "mv": true, "notes": true, "pack-refs": true, "prune": true, "pull": true,
+	"push": true, "range-diff": true, "read-tree": true, "rebase": true, "reflog": true,
+	"remote": true, "repack": true, "replace": true, "reset": true, "restore": true,
+	"rev-list": true, "rev-parse": true, "revert": true, "rm": true, "shortlog": true,
+	"show": true, "show-ref": true, "sparse-checkout": true, "stash": true, "status": true,
+	"submodule": true, "switch": true, "symbolic-ref": true, "tag": true, "update-index": true,
+	"update-ref": true, "var": true, "version": true, "worktree": true, "write-tree": true,
+}
+
+// maxAliasDepth bounds aliases defined by other aliases.
+const maxAliasDepth = 10
+
+// ExpandGitAlias returns cmd with an alias subcommand replaced by what it
+// stands for, following aliases of aliases; name is the alias used ("" if
+// none). Aliases come from `-c alias.<name>=<value>` options of cmd, then
+// lookup (by lower-case name). A shell alias ("!...") is returned as shell,
+// with cmd unexpanded. Names of git commands are never aliases.
+func ExpandGitAlias(cmd *ParsedCommand, lookup func(name string) (string, bool)) (expanded *ParsedCommand, name, shell string) {
+	words := cmd.Words
+	i := gitSubcommandIndex(words)
+	if cmd.Command != "git" || i < 0 || gitBuiltins[words[i]] {
+		return cmd, "", ""
+	}
+
+	inline := make(map[string]string)
+	for k := 0; k+1 < i; k++ {
+		if words[k] != "-c" {
+			continue
+		}
+		key, value, _ := strings.Cut(words[k+1], "=")
+		if alias := strings.ToLower(key); strings.HasPrefix(alias, "alias.") {
+			inline[strings.TrimPrefix(alias, "alias.")] = value
+		}
+	}
+
+	seen := make(map[string]bool)
+	for depth := 0; depth < maxAliasDepth; depth++ {
+		if i = gitSubcommandIndex(words); i < 0 || gitBuiltins[words[i]] {
+			break
+		}
+		sub := strings.ToLower(words[i])
+		if seen[sub] {
+			break
+		}
+		seen[sub] = true
+		value, ok := inline[sub]
+		if !ok {
+			value, ok = lookup(sub)
+		}
+		if !ok {
+			break
+		}
+		if name == "" {
+			name = words[i]
+		}
+		if strings.HasPrefix(value, "!") {
+			return cmd, name, strings.TrimSpace(value[1:])
+		}
+		var replacement []string
+		if parsed := ParseBashCommand("git " + value); len(parsed) > 0 && parsed[0].Command == "git" {
+			replacement = parsed[0].Words
+		}
+		if len(replacement) == 0 {
+			break
+		}
+		words = append(append(append([]string{}, words[:i]...), replacement...), words[i+1:]...)
+	}
+	if name == "" {
+		return cmd, "", ""
+	}
+
+	c := *cmd
+	c.Words, c.Args, c.Flags = words, nil, nil
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") {
+			c.Flags = append(c.Flags, w)
+		} else {
+			c.Args = append(c.Args, w)
+		}
+	}
+	return &c, name, ""
+}
+
+// addFlags appends flags of the invocation's subcommand in canonical form.
+func (inv *GitInvocation) addFlags(flags []string) {
+	for _, flag := range flags {
+		for _, f := range CanonicalGitFlags(inv.Subcommand, flag) {
+			if !inv.HasFlag(f) {
+				inv.Flags = append(inv.Flags, f)
+			}
+		}
+	}
+}
+
+// HasFlag reports whether the invocation has a canonical flag.
+func (inv *GitInvocation) HasFlag(flag string) bool {
+	for _, f := range inv.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalGitFlags returns the canonical flags one option of a git
+// subcommand stands for, as GitInvocation.Flags has them. It is also
+// applied to policy patterns, so they may use either spelling.
+func CanonicalGitFlags(subcommand, flag string) []string {
+	if strings.HasPrefix(flag, "--") {
+		if eq := strings.IndexByte(flag, '='); eq > 0 {
+			flag = flag[:eq]
+		}
+		if short, ok := gitFlagAliases[subcommand][flag]; ok {
+			flag = short
+		}
+		return []string{flag}
+	}
+	if !strings.HasPrefix(flag, "-") || len(flag) < 2 {
+		return []string{flag}
+	}
+
+	// -fd is -f -d; -m"msg" is -m with its value
+	var out []string
+	for _, c := range flag[1:] {
+		if !isFlagLetter(c) {
+			break
+		}
+		short := "-" + string(c)
+		if expanded, ok := gitFlagShorthands[subcommand][short]; ok {
+			out = append(out, expanded...)
+		} else {
+			out = append(out, short)
+		}
+	}
+	if len(out) == 0 {
+		return []string{flag}
+	}
+	return out
+}
+
+// isFlagLetter reports whether c can be a short option.
+func isFlagLetter(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,143 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeGitArgs(t *testing.T) {
+	tests := []struct {
+		command    string
+		subcommand string
+		flags      []string
+		args       []string
+	}{
+		{`git status`, "status", nil, nil},
+		{`git -C sub -c user.name=x push origin main`, "push", nil, []string{"origin", "main"}},
+		{`git --no-pager log --oneline`, "log", []string{"--oneline"}, nil},
+		{`git branch -D old`, "branch", []string{"-d", "-f"}, []string{"old"}},
+		{`git branch --delete --force old`, "branch", []string{"-d", "-f"}, []string{"old"}},
+		{`git branch -df old`, "branch", []string{"-d", "-f"}, []string{"old"}},
+		{`git clean -fd`, "clean", []string{"-f", "-d"}, nil},
+		{`git clean --force -d --dry-run`, "clean", []string{"-f", "-d", "-n"}, nil},
+		{`git push -f origin main`, "push", []string{"-f"}, []string{"origin", "main"}},
+		{`git push --force origin main`, "push", []string{"--force"}, []string{"origin", "main"}},
+		{`git push --force-with-lease=main origin`, "push", []string{"--force-with-lease"}, []string{"origin"}},
+		{`git push origin +main`, "push", []string{"--force"}, []string{"origin", "+main"}},
+		{`git checkout -- -f`, "checkout", nil, []string{"-f"}},
+		{`git commit -m fix`, "commit", []string{"-m"}, []string{"fix"}},
+		{`git reset --hard HEAD~1`, "reset", []string{"--hard"}, []string{"HEAD~1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cmds := ParseBashCommand(tt.command)
+			if len(cmds) != 1 {
+				t.Fatalf("ParseBashCommand(%q) returned %d commands", tt.command, len(cmds))
+			}
+			inv := NormalizeGitArgs(cmds[0])
+			if inv == nil {
+				t.Fatalf("NormalizeGitArgs(%q) = nil", tt.command)
+			}
+			if inv.Subcommand != tt.subcommand {
+				t.Errorf("subcommand = %q, want %q", inv.Subcommand, tt.subcommand)
+			}
+			if !reflect.DeepEqual(inv.Flags, tt.flags) {
+				t.Errorf("flags = %q, want %q", inv.Flags, tt.flags)
+			}
+			if !reflect.DeepEqual(inv.Args, tt.args) {
+				t.Errorf("args = %q, want %q", inv.Args, tt.args)
+			}
+		})
+	}
+}
+
+func TestNormalizeGitArgsNone(t *testing.T) {
+	for _, command := range []string{`git`, `git -C sub`, `ls -la`} {
+		cmds := ParseBashCommand(command)
+		if len(cmds) != 1 {
+			t.Fatalf("ParseBashCommand(%q) returned %d commands", command, len(cmds))
+		}
+		if inv := NormalizeGitArgs(cmds[0]); inv != nil {
+			t.Errorf("NormalizeGitArgs(%q) = %+v, want nil", command, inv)
+		}
+	}
+}
+
+func TestCanonicalGitFlags(t *testing.T) {
+	tests := []struct {
+		subcommand, flag string
+		want             []string
+	}{
+		{"branch", "-D", []string{"-d", "-f"}},
+		{"branch", "--delete", []string{"-d"}},
+		{"branch", "-M", []string{"-m", "-f"}},
+		{"clean", "-fdn", []string{"-f", "-d", "-n"}},
+		{"clean", "--dry-run", []string{"-n"}},
+		{"push", "--force", []string{"--force"}},
+		{"push", "-f", []string{"-f"}},
+		{"push", "--force-with-lease=main", []string{"--force-with-lease"}},
+		{"switch", "--discard-changes", []string{"-f"}},
+		{"log", "--delete", []string{"--delete"}},
+		{"commit", "-m\"msg\"", []string{"-m"}},
+		{"push", "-", []string{"-"}},
+		{"push", "main", []string{"main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subcommand+" "+tt.flag, func(t *testing.T) {
+			if got := CanonicalGitFlags(tt.subcommand, tt.flag); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CanonicalGitFlags(%q, %q) = %q, want %q", tt.subcommand, tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandGitAlias(t *testing.T) {
+	aliases := map[string]string{
+		"fp":     "push --force",
+		"fpo":    "fp origin",
+		"nuke":   "!rm -rf .git",
+		"loop":   "loop2",
+		"loop2":  "loop",
+		"status": "push --force", // never hides the builtin
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := aliases[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		command string
+		words   []string // nil: unexpanded
+		name    string
+		shell   string
+	}{
+		{`git fp origin main`, []string{"push", "--force", "origin", "main"}, "fp", ""},
+		{`git FP`, []string{"push", "--force"}, "FP", ""},
+		{`git fpo main`, []string{"push", "--force", "origin", "main"}, "fpo", ""},
+		{`git -C sub fp`, []string{"-C", "sub", "push", "--force"}, "fp", ""},
+		{`git -c 'alias.x=reset --hard' x`, []string{"-c", "alias.x=reset --hard", "reset", "--hard"}, "x", ""},
+		{`git nuke`, nil, "nuke", "rm -rf .git"},
+		{`git status`, nil, "", ""},
+		{`git unknown`, nil, "", ""},
+		{`git loop`, []string{"loop"}, "loop", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cmds := ParseBashCommand(tt.command)
+			if len(cmds) != 1 {
+				t.Fatalf("ParseBashCommand(%q) returned %d commands", tt.command, len(cmds))
+			}
+			expanded, name, shell := ExpandGitAlias(cmds[0], lookup)
+			if name != tt.name || shell != tt.shell {
+				t.Errorf("name, shell = %q, %q, want %q, %q", name, shell, tt.name, tt.shell)
+			}
+			want := tt.words
+			if want == nil {
+				want = cmds[0].Words
+			}
+			if !reflect.DeepEqual(expanded.Words, want) {
+				t.Errorf("words = %q, want %q", expanded.Words, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package parsers
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizePath undoes spellings of a path that literal matching misses:
+// a file:// URL (curl file:///etc/%70asswd) becomes the path it names,
+// percent-decoded, and the path is put in Unicode NFC: macOS filesystems
+// treat a decomposed name (e followed by a combining accent) and the
+// precomposed one as the same file, so patterns must see one form.
+// Plain paths are not percent-decoded: the shell and every tool open
+// %70asswd as written.
+func NormalizePath(path string) string {
+	if p, ok := fileURLPath(path); ok {
+		path = p
+	}
+	if norm.NFC.IsNormalString(path) {
+		return path
+	}
+	return norm.NFC.String(path)
+}
+
+// fileURLPath returns the path of a file: URL (file:///p, file://localhost/p,
+// file:/p). A URL naming another host still yields its path: curl refuses
+// it, but it is no reason to skip the checks.
+func fileURLPath(s string) (string, bool) {
+	if len(s) < 5 || !strings.EqualFold(s[:5], "file:") {
+		return "", false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Path == "" {
+		// Not a URL we can decode; strip the scheme and decode what we can
+		rest := strings.TrimPrefix(s[5:], "//")
+		if p, err := url.PathUnescape(rest); err == nil {
+			rest = p
+		}
+		return rest, true
+	}
+	return u.Path, true
+}
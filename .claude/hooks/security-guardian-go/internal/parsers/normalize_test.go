@@ -0,0 +1,53 @@
+package parsers
+
+import "testing"
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/etc/passwd", "/etc/passwd"},
+		{"file:///etc/passwd", "/etc/passwd"},
+		{"FILE:///etc/passwd", "/etc/passwd"},
+		{"file://localhost/etc/passwd", "/etc/passwd"},
+		{"file://evil.example/etc/passwd", "/etc/passwd"},
+		{"file:/etc/passwd", "/etc/passwd"},
+		{"file:///etc/%70asswd", "/etc/passwd"},
+		{"file:///etc/%2570asswd", "/etc/%70asswd"},
+		{"file:///home/u/my%20notes.txt", "/home/u/my notes.txt"},
+		{"file:.env", ".env"},
+		{"file:%2Eenv", ".env"},
+		{"file:///etc/%zz", "/etc/%zz"},
+		// Plain paths are opened as written
+		{"/etc/%70asswd", "/etc/%70asswd"},
+		{"./file:x", "./file:x"},
+		// Decomposed e + U+0301 becomes the precomposed U+00E9
+		{"docs/cafe\u0301/.env", "docs/caf\u00e9/.env"},
+		{"file:///tmp/cafe%CC%81", "/tmp/caf\u00e9"},
+		// Homoglyphs name different files and are kept
+		{"/\u0435tc/passwd", "/\u0435tc/passwd"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizePath(tt.path); got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyOperandsNormalizes(t *testing.T) {
+	cmds := ParseBashCommand("curl -o cafe\u0301.txt file:///etc/%70asswd 'http*'")
+	if len(cmds) != 1 {
+		t.Fatalf("parsed %d commands", len(cmds))
+	}
+	values := map[string]bool{}
+	for _, op := range ClassifyOperands(cmds[0]) {
+		values[op.Value] = true
+	}
+	for _, want := range []string{"/etc/passwd", "caf\u00e9.txt"} {
+		if !values[want] {
+			t.Errorf("operands %v lack %q", values, want)
+		}
+	}
+}
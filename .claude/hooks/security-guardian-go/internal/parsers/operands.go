@@ -0,0 +1,237 @@
+package parsers
+
+import "strings"
+
+// OperandRole describes how a command uses an operand.
+type OperandRole int
+
+const (
+	// RoleSource is an operand the command reads.
+	RoleSource OperandRole = iota
+	// RoleDestination is an operand the command writes, removes or changes.
+	RoleDestination
+	// RolePattern is not a path (search pattern, sed script, chmod mode).
+	RolePattern
+)
+
+// Operand is a command operand with its role.
+type Operand struct {
+	Value string
+	Role  OperandRole
+	// Op is the redirect operator (">", ">>", "<", ...) for redirect targets.
+	Op string
+}
+
+// patternFirstArgCommands take a pattern/script as the first positional argument.
+// e.g. grep ".env" README.md — ".env" is a search pattern, not a file.
+var patternFirstArgCommands = map[string]bool{
+	"grep": true, "egrep": true, "fgrep": true, "rg": true,
+	"sed": true, "awk": true, "gawk": true,
+	"expr": true,
+}
+
+// nonPathCommands take no file paths at all (only redirects can write).
+var nonPathCommands = map[string]bool{
+	"echo": true, "printf": true, "export": true, "unset": true,
+	"alias": true, "unalias": true, "set": true,
+	"true": true, "false": true, "test": true, "[": true,
+}
+
+// modifyCommands change or remove every path operand.
+var modifyCommands = map[string]bool{
+	"rm": true, "rmdir": true, "unlink": true, "shred": true,
+	"touch": true, "truncate": true, "mkdir": true,
+	"mv": true, "tee": true, "sponge": true,
+}
+
+// modeFirstArgCommands take a mode/owner first, then paths they modify.
+var modeFirstArgCommands = map[string]bool{
+	"chmod": true, "chown": true, "chgrp": true,
+}
+
+// copyCommands read sources and write the last operand (or -t dir).
+var copyCommands = map[string]bool{
+	"cp": true, "install": true, "rsync": true, "ln": true,
+}
+
+// installValueFlags take a non-path value (install -m 755 -o root src dst).
+var installValueFlags = map[string]bool{
+	"-m": true, "--mode": true, "-o": true, "--owner": true, "-g": true, "--group": true,
+}
+
+// outputFlags take a path that is written (--output=file).
+var outputFlags = []string{"-o=", "--output=", "--output-file=", "--log-file="}
+
+// ClassifyOperands returns the operands of cmd (args, redirect targets and
+// path values embedded in flags) with their roles. Path operands are
+// normalized (NormalizePath), so curl file:///etc/passwd reads /etc/passwd.
+// Values are not filtered; use IsPathLike to drop words that can't be paths.
+func ClassifyOperands(cmd *ParsedCommand) []Operand {
+	var operands []Operand
+
+	if cmd.Command == "dd" {
+		// dd if=src of=dst: key=value operands instead of positional paths
+		for _, arg := range cmd.Args {
+			switch {
+			case strings.HasPrefix(arg, "if="):
+				operands = append(operands, Operand{Value: arg[3:], Role: RoleSource})
+			case strings.HasPrefix(arg, "of="):
+				operands = append(operands, Operand{Value: arg[3:], Role: RoleDestination})
+			default:
+				operands = append(operands, Operand{Value: arg, Role: RolePattern})
+			}
+		}
+	} else {
+		roles := argRoles(cmd)
+		for i, arg := range cmd.Args {
+			operands = append(operands, Operand{Value: arg, Role: roles[i]})
+		}
+	}
+
+	if len(cmd.Redirections) > 0 {
+		for _, r := range cmd.Redirections {
+			role := RoleSource
+			if r.IsWrite() {
+				role = RoleDestination
+			}
+			operands = append(operands, Operand{Value: r.Target, Role: role, Op: r.Op})
+		}
+	} else {
+		// Targets without operators: assume writes
+		for _, redir := range cmd.Redirects {
+			operands = append(operands, Operand{Value: redir, Role: RoleDestination})
+		}
+	}
+
+	for _, flag := range cmd.Flags {
+		if val, ok := flagPathValue(flag); ok {
+			role := RoleSource
+			for _, prefix := range outputFlags {
+				if strings.HasPrefix(flag, prefix) {
+					role = RoleDestination
+					break
+				}
+			}
+			operands = append(operands, Operand{Value: val, Role: role})
+		}
+	}
+
+	for i := range operands {
+		if operands[i].Role != RolePattern {
+			operands[i].Value = NormalizePath(operands[i].Value)
+		}
+	}
+	return operands
+}
+
+// argRoles assigns a role to each positional argument of cmd.
+func argRoles(cmd *ParsedCommand) []OperandRole {
+	roles := make([]OperandRole, len(cmd.Args))
+	set := func(from, to int, role OperandRole) {
+		for i := from; i < to && i < len(roles); i++ {
+			roles[i] = role
+		}
+	}
+
+	switch {
+	case nonPathCommands[cmd.Command]:
+		set(0, len(roles), RolePattern)
+
+	case patternFirstArgCommands[cmd.Command]:
+		first := 1
+		// gawk -i inplace 'prog' file: "inplace" is the -i value
+		if strings.HasSuffix(cmd.Command, "awk") && containsString(cmd.Flags, "-i") &&
+			len(cmd.Args) > 0 && cmd.Args[0] == "inplace" {
+			first = 2
+		}
+		set(0, first, RolePattern)
+		if isInPlace(cmd) {
+			set(first, len(roles), RoleDestination)
+		}
+
+	case modifyCommands[cmd.Command]:
+		set(0, len(roles), RoleDestination)
+
+	case modeFirstArgCommands[cmd.Command]:
+		set(1, len(roles), RoleDestination)
+		if !hasFlagPrefix(cmd.Flags, "--reference") {
+			set(0, 1, RolePattern)
+		}
+
+	case copyCommands[cmd.Command]:
+		if cmd.Command == "install" {
+			// Values of -m/-o/-g precede the paths in Args
+			n := 0
+			for _, f := range cmd.Flags {
+				if installValueFlags[f] {
+					n++
+				}
+			}
+			set(0, n, RolePattern)
+		}
+		if hasFlagPrefix(cmd.Flags, "--target-directory=") {
+			break
+		}
+		if containsString(cmd.Flags, "-t") || containsString(cmd.Flags, "--target-directory") {
+			set(0, 1, RoleDestination)
+		} else if len(roles) > 0 {
+			set(len(roles)-1, len(roles), RoleDestination)
+		}
+	}
+
+	return roles
+}
+
+// isInPlace reports whether sed/awk edits its input files in place.
+func isInPlace(cmd *ParsedCommand) bool {
+	for _, f := range cmd.Flags {
+		if f == "--in-place" || strings.HasPrefix(f, "--in-place=") {
+			return true
+		}
+		if cmd.Command == "sed" && strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && strings.Contains(f, "i") {
+			return true
+		}
+		if cmd.Command != "sed" && f == "-i" {
+			// gawk -i inplace
+			return len(cmd.Args) > 0 && cmd.Args[0] == "inplace"
+		}
+	}
+	return false
+}
+
+// hasFlagPrefix reports whether any flag starts with prefix.
+func hasFlagPrefix(flags []string, prefix string) bool {
+	for _, f := range flags {
+		if strings.HasPrefix(f, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// flagPathValue extracts a path-like value embedded in a flag
+// (--target-directory=/tmp, -C/path).
+func flagPathValue(flag string) (string, bool) {
+	if idx := strings.Index(flag, "="); idx > 0 {
+		val := flag[idx+1:]
+		return val, val != ""
+	}
+	if strings.HasPrefix(flag, "-") && !strings.HasPrefix(flag, "--") && len(flag) > 2 {
+		for i := 1; i < len(flag); i++ {
+			rest := flag[i:]
+			if strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, "~") || strings.HasPrefix(rest, ".") {
+				return rest, true
+			}
+		}
+	}
+	return "", false
+}
+
+// IsPathLike reports whether s looks like a path rather than a bare word:
+// contains a slash, starts with . or ~, or has a file extension.
+func IsPathLike(s string) bool {
+	if strings.Contains(s, "/") || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "~") {
+		return true
+	}
+	return strings.Contains(s, ".") && !strings.HasPrefix(s, "-")
+}
@@ -1,13 +1,62 @@
 package parsers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
+// defaultPathVariables are expanded in command paths whatever the config.
+var defaultPathVariables = []string{"HOME", "TMPDIR", "CLAUDE_PROJECT_DIR"}
+
+// pathVariables are the environment variables expanded in command paths;
+// references to others are kept as written and make a word unresolved.
+var pathVariables = makePathVariables(nil)
+
+// SetPathVariables sets the variables expanded in command paths besides
+// HOME, TMPDIR and CLAUDE_PROJECT_DIR (directories.path_variables).
+func SetPathVariables(names []string) {
+	pathVariables = makePathVariables(names)
+}
+
+func makePathVariables(names []string) map[string]bool {
+	vars := make(map[string]bool)
+	for _, name := range defaultPathVariables {
+		vars[name] = true
+	}
+	for _, name := range names {
+		vars[strings.TrimPrefix(name, "$")] = true
+	}
+	return vars
+}
+
+// PathVariables returns the variables expanded in command paths.
+func PathVariables() []string {
+	names := make([]string, 0, len(pathVariables))
+	for name := range pathVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandPathVariables expands the path variables in s with their values
+// in the live environment; other references are kept.
+func expandPathVariables(s string) string {
+	return os.Expand(s, func(name string) string {
+		if pathVariables[name] {
+			return os.Getenv(name)
+		}
+		return "${" + name + "}"
+	})
+}
+
 // GetProjectRoot detects and returns the project root directory.
 // It uses CLAUDE_PROJECT_DIR env var if set, otherwise searches for .git directory.
 // The returned path has symlinks resolved (e.g. /tmp → /private/tmp on macOS)
@@ -60,8 +109,8 @@ func ResolvePath(pathStr string, baseDir string) string {
 		baseDir, _ = os.Getwd()
 	}
 
-	// Expand environment variables and user home
-	expanded := os.ExpandEnv(pathStr)
+	// Expand path variables and user home
+	expanded := expandPathVariables(NormalizePath(pathStr))
 	if strings.HasPrefix(expanded, "~/") {
 		if home, err := os.UserHomeDir(); err == nil {
 			expanded = filepath.Join(home, expanded[2:])
@@ -145,7 +194,7 @@ func IsSymlinkEscape(pathStr string, projectRoot string, baseDir string) bool {
 	// Check if this is due to a symlink WITHIN the project pointing outside
 
 	// Expand the original path
-	expanded := os.ExpandEnv(pathStr)
+	expanded := expandPathVariables(NormalizePath(pathStr))
 	if strings.HasPrefix(expanded, "~/") {
 		if home, err := os.UserHomeDir(); err == nil {
 			expanded = filepath.Join(home, expanded[2:])
@@ -198,26 +247,28 @@ func IsSymlinkEscape(pathStr string, projectRoot string, baseDir string) bool {
 	return false
 }
 
-// IsGitTracked checks if a file is tracked by git.
-func IsGitTracked(filePath string, projectRoot string) bool {
-	cmd := exec.Command("git", "ls-files", "--error-unmatch", filePath)
-	cmd.Dir = projectRoot
+// IsGitTracked checks if a file is tracked by git. git is killed when
+// ctx is done or after 5 seconds; err is set when git couldn't tell
+// (not installed, not a repository, timed out).
+func IsGitTracked(ctx context.Context, filePath string, projectRoot string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
 
-	// Set a timeout using a goroutine
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Run()
-	}()
-
-	select {
-	case err := <-done:
-		return err == nil
-	case <-time.After(5 * time.Second):
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		return false
+	cmd := exec.CommandContext(ctx, "git", "ls-files", "--error-unmatch", filePath)
+	cmd.Dir = projectRoot
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return false, fmt.Errorf("git ls-files: %w", ctx.Err())
+	}
+	// --error-unmatch exits 1 for an untracked file
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("git ls-files: %w", err)
 	}
+	return true, nil
 }
 
 // CheckArchivePathTraversal checks if an archive extraction path contains traversal attacks.
@@ -237,7 +288,7 @@ func IsInCIEnvironment() bool {
 	return false
 }
 
-// ExpandPath expands ~ and environment variables in a path.
+// ExpandPath expands ~ and the path variables in a path.
 func ExpandPath(path string) string {
 	// Expand ~
 	if strings.HasPrefix(path, "~/") {
@@ -250,8 +301,8 @@ func ExpandPath(path string) string {
 		}
 	}
 
-	// Expand environment variables
-	path = os.ExpandEnv(path)
+	// Expand path variables
+	path = expandPathVariables(path)
 
 	return path
 }
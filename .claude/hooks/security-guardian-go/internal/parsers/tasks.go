@@ -0,0 +1,444 @@
+package parsers
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TaskRecipe is the resolved body of a task-runner target (make, just, npm run).
+type TaskRecipe struct {
+	Runner   string   // make, just, npm, yarn, pnpm, bun
+	Source   string   // Makefile/justfile/package.json the recipe was read from
+	Target   string   // target, recipe, or script name
+	Commands []string // recipe lines, prerequisites first
+}
+
+// maxTaskDepth limits how many levels of prerequisites are followed.
+const maxTaskDepth = 5
+
+// makeFlagsWithValue lists make options that consume a following argument.
+var makeFlagsWithValue = map[string]bool{
+	"-C": true, "--directory": true,
+	"-f": true, "--file": true, "--makefile": true,
+	"-I": true, "--include-dir": true,
+	"-o": true, "--old-file": true,
+	"-W": true, "--what-if": true,
+}
+
+// npmBuiltinScripts lists npm lifecycle commands that run a package.json script directly.
+var npmBuiltinScripts = map[string]bool{
+	"test": true, "start": true, "stop": true, "restart": true,
+	"t": true, "tst": true,
+}
+
+// ResolveTaskRecipes resolves recipes for task-runner invocations (make, just, npm/yarn/pnpm/bun run).
+// baseDir is the directory the command runs in. Returns nil if cmd is not a task runner
+// or its definition file cannot be found.
+func ResolveTaskRecipes(cmd *ParsedCommand, baseDir string) []*TaskRecipe {
+	switch filepath.Base(cmd.Command) {
+	case "make", "gmake":
+		return resolveMakeRecipes(cmd, baseDir)
+	case "just":
+		return resolveJustRecipes(cmd, baseDir)
+	case "npm", "yarn", "pnpm", "bun":
+		return resolvePackageScripts(cmd, baseDir)
+	}
+	return nil
+}
+
+// splitFlagValues separates positional args from values consumed by flags.
+// Same approach as GetGitSubcommandAndFlags: each flag with a value consumes one arg.
+func splitFlagValues(cmd *ParsedCommand, withValue map[string]bool) (map[string]string, []string) {
+	values := make(map[string]string)
+	args := cmd.Args
+	for _, f := range cmd.Flags {
+		if idx := strings.Index(f, "="); idx > 0 {
+			values[f[:idx]] = f[idx+1:]
+			continue
+		}
+		if withValue[f] && len(args) > 0 {
+			values[f] = args[0]
+			args = args[1:]
+		} else if len(f) > 2 && !strings.HasPrefix(f, "--") && withValue[f[:2]] {
+			// Attached value: -Csub, -fbuild.mk
+			values[f[:2]] = f[2:]
+		}
+	}
+	return values, args
+}
+
+// firstValue returns the first non-empty value among the given flag names.
+func firstValue(values map[string]string, names ...string) string {
+	for _, n := range names {
+		if v := values[n]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// --- make ---
+
+var makeAssignRe = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.-]*)\s*(?:\?=|:=|::=|\+=|=)\s*(.*)$`)
+var makeVarRe = regexp.MustCompile(`\$\$|\$[({]([A-Za-z_][A-Za-z0-9_.-]*)[)}]`)
+
+// makeDefaultVars are the variables GNU make defines itself, for
+// Makefiles that use them without an assignment ($(RM) is rm -f). CP
+// isn't one, but Makefiles using it undefined mean cp.
+var makeDefaultVars = map[string]string{
+	"AR": "ar", "AS": "as", "CC": "cc", "CXX": "g++", "CPP": "$(CC) -E", "FC": "f77",
+	"LD": "ld", "LEX": "lex", "YACC": "yacc", "RM": "rm -f", "CP": "cp", "MAKE": "make",
+	"ARFLAGS": "rv", "CFLAGS": "", "CXXFLAGS": "", "CPPFLAGS": "", "FFLAGS": "",
+	"LDFLAGS": "", "LDLIBS": "", "ASFLAGS": "", "LFLAGS": "", "YFLAGS": "",
+}
+
+type makeRule struct {
+	prereqs []string
+	recipe  []string
+}
+
+func resolveMakeRecipes(cmd *ParsedCommand, baseDir string) []*TaskRecipe {
+	values, args := splitFlagValues(cmd, makeFlagsWithValue)
+
+	dir := baseDir
+	if d := firstValue(values, "-C", "--directory"); d != "" {
+		dir = ResolvePath(d, baseDir)
+	}
+
+	var makefile string
+	if f := firstValue(values, "-f", "--file", "--makefile"); f != "" {
+		makefile = ResolvePath(f, dir)
+	} else {
+		for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+			candidate := filepath.Join(dir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				makefile = candidate
+				break
+			}
+		}
+	}
+	if makefile == "" {
+		return nil
+	}
+
+	rules, order, vars, err := parseMakefile(makefile)
+	if err != nil {
+		return nil
+	}
+	if _, ok := vars["CURDIR"]; !ok {
+		vars["CURDIR"] = dir
+	}
+
+	// Command-line variable overrides (make VAR=value target)
+	var targets []string
+	for _, a := range args {
+		if m := makeAssignRe.FindStringSubmatch(a); m != nil {
+			vars[m[1]] = m[2]
+			continue
+		}
+		targets = append(targets, a)
+	}
+	if len(targets) == 0 && len(order) > 0 {
+		targets = []string{order[0]} // default goal
+	}
+
+	var recipes []*TaskRecipe
+	for _, target := range targets {
+		recipe := &TaskRecipe{Runner: "make", Source: makefile, Target: target}
+		collectMakeRecipe(target, rules, vars, make(map[string]bool), 0, recipe)
+		if len(recipe.Commands) > 0 {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes
+}
+
+// parseMakefile extracts rules, target order, and simple variable assignments.
+func parseMakefile(path string) (map[string]*makeRule, []string, map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	rules := make(map[string]*makeRule)
+	vars := make(map[string]string)
+	var order []string
+	var current []*makeRule
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var pending string
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Join continuation lines
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line = pending + line
+		pending = ""
+
+		if strings.HasPrefix(line, "\t") {
+			body := strings.TrimSpace(line)
+			if body == "" || strings.HasPrefix(body, "#") {
+				continue
+			}
+			for _, r := range current {
+				r.recipe = append(r.recipe, body)
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if m := makeAssignRe.FindStringSubmatch(trimmed); m != nil && isMakeAssignment(trimmed) {
+			vars[m[1]] = strings.TrimSpace(m[2])
+			current = nil
+			continue
+		}
+
+		idx := strings.Index(trimmed, ":")
+		if idx <= 0 {
+			current = nil
+			continue
+		}
+
+		names := strings.Fields(trimmed[:idx])
+		rest := strings.TrimPrefix(trimmed[idx+1:], ":") // double-colon rules
+		var inline string
+		if semi := strings.Index(rest, ";"); semi >= 0 {
+			inline = strings.TrimSpace(rest[semi+1:])
+			rest = rest[:semi]
+		}
+		prereqs := strings.Fields(strings.SplitN(rest, "|", 2)[0])
+
+		current = nil
+		for _, name := range names {
+			r, ok := rules[name]
+			if !ok {
+				r = &makeRule{}
+				rules[name] = r
+				if !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "%$") {
+					order = append(order, name)
+				}
+			}
+			r.prereqs = append(r.prereqs, prereqs...)
+			if inline != "" {
+				r.recipe = append(r.recipe, inline)
+			}
+			current = append(current, r)
+		}
+	}
+	return rules, order, vars, scanner.Err()
+}
+
+// isMakeAssignment reports whether a line is a variable assignment rather than a rule.
+// "CC := gcc" is an assignment, "build: deps" and "%.o: CFLAGS = -O2" are rules.
+func isMakeAssignment(line string) bool {
+	eq := strings.Index(line, "=")
+	if eq < 0 {
+		return false
+	}
+	colon := strings.Index(line, ":")
+	return colon < 0 || colon > eq || strings.HasPrefix(line[colon:], ":=") || strings.HasPrefix(line[colon:], "::=")
+}
+
+// collectMakeRecipe appends recipe lines for target and its prerequisites.
+func collectMakeRecipe(target string, rules map[string]*makeRule, vars map[string]string, visited map[string]bool, depth int, out *TaskRecipe) {
+	if visited[target] || depth > maxTaskDepth {
+		return
+	}
+	visited[target] = true
+
+	rule, ok := rules[target]
+	if !ok {
+		return
+	}
+	for _, p := range rule.prereqs {
+		collectMakeRecipe(expandMakeVars(p, vars), rules, vars, visited, depth+1, out)
+	}
+	for _, line := range rule.recipe {
+		out.Commands = append(out.Commands, normalizeMakeLine(line, vars))
+	}
+}
+
+// normalizeMakeLine strips recipe prefixes (@, -, +) and expands make
+// variables. Variables neither the Makefile nor make defines come from the
+// environment or are empty: they become their name, an opaque word, as
+// left as $(NAME) the shell would run NAME as a command substitution.
+func normalizeMakeLine(line string, vars map[string]string) string {
+	line = strings.TrimLeft(line, "@-+ \t")
+	line = expandMakeVars(line, vars)
+	return makeVarRe.ReplaceAllStringFunc(line, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		return makeVarRe.FindStringSubmatch(m)[1]
+	})
+}
+
+// expandMakeVars substitutes $(VAR) and ${VAR} from Makefile assignments
+// and make's own variables, leaving unknown ones and $$ as they are.
+// $(MAKE) expands to "make" so nested invocations are inspected recursively.
+func expandMakeVars(s string, vars map[string]string) string {
+	for i := 0; i < 5; i++ {
+		expanded := makeVarRe.ReplaceAllStringFunc(s, func(m string) string {
+			if m == "$$" {
+				return m
+			}
+			name := makeVarRe.FindStringSubmatch(m)[1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			if v, ok := makeDefaultVars[name]; ok {
+				return v
+			}
+			return m
+		})
+		if expanded == s {
+			break
+		}
+		s = expanded
+	}
+	return s
+}
+
+// --- just ---
+
+var justRecipeRe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)[^:=]*:(.*)$`)
+
+func resolveJustRecipes(cmd *ParsedCommand, baseDir string) []*TaskRecipe {
+	values, args := splitFlagValues(cmd, map[string]bool{
+		"-f": true, "--justfile": true, "-d": true, "--working-directory": true,
+	})
+
+	justfile := ""
+	if f := firstValue(values, "-f", "--justfile"); f != "" {
+		justfile = ResolvePath(f, baseDir)
+	} else {
+		for _, name := range []string{"justfile", "Justfile", ".justfile"} {
+			candidate := filepath.Join(baseDir, name)
+			if _, err := os.Stat(candidate); err == nil {
+				justfile = candidate
+				break
+			}
+		}
+	}
+	if justfile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(justfile)
+	if err != nil {
+		return nil
+	}
+
+	recipes := make(map[string]*makeRule)
+	var order []string
+	var current *makeRule
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			body := strings.TrimSpace(line)
+			if current != nil && body != "" && !strings.HasPrefix(body, "#") {
+				current.recipe = append(current.recipe, strings.TrimLeft(body, "@-"))
+			}
+			continue
+		}
+		current = nil
+		m := justRecipeRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || strings.HasPrefix(m[2], "=") {
+			continue // not a recipe header, or a `name := value` assignment
+		}
+		current = &makeRule{prereqs: strings.Fields(m[2])}
+		recipes[m[1]] = current
+		order = append(order, m[1])
+	}
+
+	// just takes recipe name first; remaining args are recipe parameters
+	target := ""
+	if len(args) > 0 {
+		target = args[0]
+	} else if len(order) > 0 {
+		target = order[0]
+	}
+	if target == "" {
+		return nil
+	}
+
+	recipe := &TaskRecipe{Runner: "just", Source: justfile, Target: target}
+	collectMakeRecipe(target, recipes, map[string]string{}, make(map[string]bool), 0, recipe)
+	if len(recipe.Commands) == 0 {
+		return nil
+	}
+	return []*TaskRecipe{recipe}
+}
+
+// --- package.json scripts ---
+
+func resolvePackageScripts(cmd *ParsedCommand, baseDir string) []*TaskRecipe {
+	runner := filepath.Base(cmd.Command)
+	values, args := splitFlagValues(cmd, map[string]bool{"--prefix": true, "-C": true, "--cwd": true, "--dir": true})
+	if len(args) == 0 {
+		return nil
+	}
+
+	var script string
+	switch {
+	case args[0] == "run" || args[0] == "run-script" || args[0] == "rum" || args[0] == "urn":
+		if len(args) < 2 {
+			return nil
+		}
+		script = args[1]
+	case npmBuiltinScripts[args[0]]:
+		script = args[0]
+		if script == "t" || script == "tst" {
+			script = "test"
+		}
+	case runner != "npm" && runner != "bun":
+		// yarn <script>, pnpm <script> run package.json scripts directly
+		script = args[0]
+	default:
+		return nil
+	}
+
+	dir := baseDir
+	if d := firstValue(values, "--prefix", "-C", "--cwd", "--dir"); d != "" {
+		dir = ResolvePath(d, baseDir)
+	}
+	pkgPath := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(pkgPath)
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || pkg.Scripts == nil {
+		return nil
+	}
+
+	body, ok := pkg.Scripts[script]
+	if !ok {
+		return nil
+	}
+
+	recipe := &TaskRecipe{Runner: runner, Source: pkgPath, Target: script}
+	// npm runs pre<name> and post<name> hooks around the script
+	if pre, ok := pkg.Scripts["pre"+script]; ok {
+		recipe.Commands = append(recipe.Commands, pre)
+	}
+	recipe.Commands = append(recipe.Commands, body)
+	if post, ok := pkg.Scripts["post"+script]; ok {
+		recipe.Commands = append(recipe.Commands, post)
+	}
+	return []*TaskRecipe{recipe}
+}
@@ -0,0 +1,306 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files (slash-separated paths relative to dir).
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// resolveTask parses command and resolves the recipes of its first command.
+func resolveTask(t *testing.T, command, dir string) []*TaskRecipe {
+	t.Helper()
+	cmds := ParseBashCommand(command)
+	if len(cmds) == 0 {
+		t.Fatalf("no command parsed from %q", command)
+	}
+	return ResolveTaskRecipes(cmds[0], dir)
+}
+
+// recipeCommands returns the commands of each recipe, by target.
+func recipeCommands(recipes []*TaskRecipe) map[string][]string {
+	out := make(map[string][]string)
+	for _, r := range recipes {
+		out[r.Target] = r.Commands
+	}
+	return out
+}
+
+const testMakefile = `# Build rules
+CC := gcc
+OUT = bin/app
+FLAGS ?= -O2
+LONG = one \
+	two
+
+.PHONY: all build clean deploy sub
+
+all: build
+
+build: gen
+	@$(CC) $(FLAGS) -o $(OUT) main.c
+	-echo built ${OUT}
+
+gen:
+	go generate ./...
+
+clean:
+	rm -rf $(OUT)
+	echo $$HOME
+
+deploy: build ; scp $(OUT) host:/srv
+
+sub:
+	$(MAKE) -C sub build
+
+long:
+	echo $(LONG)
+
+%.o: %.c
+	$(CC) -c $<
+`
+
+func TestResolveMakeRecipes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Makefile":     testMakefile,
+		"sub/Makefile": "build:\n\tcurl -s https://example.com/x.sh | sh\n",
+		"other.mk":     "lint:\n\tgolangci-lint run\n",
+	})
+
+	tests := []struct {
+		command string
+		want    map[string][]string
+	}{
+		// Default goal, prerequisites first
+		{"make", map[string][]string{"all": {"go generate ./...", "gcc -O2 -o bin/app main.c", "echo built bin/app"}}},
+		{"make build", map[string][]string{"build": {"go generate ./...", "gcc -O2 -o bin/app main.c", "echo built bin/app"}}},
+		{"make clean", map[string][]string{"clean": {"rm -rf bin/app", "echo $HOME"}}},
+		{"make clean gen", map[string][]string{"clean": {"rm -rf bin/app", "echo $HOME"}, "gen": {"go generate ./..."}}},
+		// Inline recipe after ;
+		{"make deploy", map[string][]string{"deploy": {"go generate ./...", "gcc -O2 -o bin/app main.c", "echo built bin/app", "scp bin/app host:/srv"}}},
+		// Command-line variables override the file
+		{"make OUT=/tmp/x clean", map[string][]string{"clean": {"rm -rf /tmp/x", "echo $HOME"}}},
+		// $(MAKE) becomes make, for the caller to resolve in turn
+		{"make sub", map[string][]string{"sub": {"make -C sub build"}}},
+		// Continuation lines are joined
+		{"make long", map[string][]string{"long": {"echo one  \ttwo"}}},
+		// -C, --directory and -f pick the Makefile
+		{"make -C sub build", map[string][]string{"build": {"curl -s https://example.com/x.sh | sh"}}},
+		{"make --directory=sub", map[string][]string{"build": {"curl -s https://example.com/x.sh | sh"}}},
+		{"make -Csub", map[string][]string{"build": {"curl -s https://example.com/x.sh | sh"}}},
+		{"make -f other.mk lint", map[string][]string{"lint": {"golangci-lint run"}}},
+		{"gmake -j4 gen", map[string][]string{"gen": {"go generate ./..."}}},
+		// Unknown targets have no recipe
+		{"make missing", map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := recipeCommands(resolveTask(t, tt.command, dir))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recipes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMakeVariables(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Makefile": `.PHONY: clean build
+
+BIN := app
+
+clean:
+	$(RM) $(BIN) *.o
+
+build:
+	$(CC) $(CFLAGS) -o $(BIN) main.c
+	$(AR) $(ARFLAGS) lib.a *.o
+
+install:
+	$(CP) $(BIN) $(PREFIX)/bin
+	$(DOCKER) build .
+
+dirs:
+	cd $(CURDIR) && echo $$(date) $${HOME}
+
+nested:
+	$(MAKE) -C sub clean
+	${MAKE} build
+`,
+		"sub/Makefile": "CC = clang\nclean:\n\t$(RM) -r out\nbuild:\n\t$(CC) x.c\n",
+	})
+
+	tests := []struct {
+		command string
+		want    map[string][]string
+	}{
+		// .PHONY is never the default goal
+		{"make", map[string][]string{"clean": {"rm -f app *.o"}}},
+		// make's own variables
+		{"make clean", map[string][]string{"clean": {"rm -f app *.o"}}},
+		{"make build", map[string][]string{"build": {"cc  -o app main.c", "ar rv lib.a *.o"}}},
+		{"make CFLAGS=-O2 build", map[string][]string{"build": {"cc -O2 -o app main.c", "ar rv lib.a *.o"}}},
+		// Variables nobody defines are opaque words, not substitutions
+		{"make install", map[string][]string{"install": {"cp app PREFIX/bin", "DOCKER build ."}}},
+		{"make PREFIX=/usr install", map[string][]string{"install": {"cp app /usr/bin", "DOCKER build ."}}},
+		// $$ is the shell's $; CURDIR is the directory make runs in
+		{"make dirs", map[string][]string{"dirs": {"cd " + dir + " && echo $(date) ${HOME}"}}},
+		// $(MAKE) and ${MAKE} become make, for the caller to resolve in turn
+		{"make nested", map[string][]string{"nested": {"make -C sub clean", "make build"}}},
+		{"make -C sub clean", map[string][]string{"clean": {"rm -f -r out"}}},
+		{"make -C sub build", map[string][]string{"build": {"clang x.c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			got := recipeCommands(resolveTask(t, tt.command, dir))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recipes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMakeRecipesSource(t *testing.T) {
+	dir := t.TempDir()
+	if got := resolveTask(t, "make build", dir); got != nil {
+		t.Errorf("no Makefile: %v", got)
+	}
+
+	writeFiles(t, dir, map[string]string{"GNUmakefile": "build:\n\techo gnu\n", "Makefile": "build:\n\techo plain\n"})
+	recipes := resolveTask(t, "make build", dir)
+	if len(recipes) != 1 || recipes[0].Source != filepath.Join(dir, "GNUmakefile") || recipes[0].Runner != "make" {
+		t.Fatalf("recipes = %+v, want GNUmakefile first", recipes)
+	}
+}
+
+func TestResolveJustRecipes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"justfile": `version := "1.0"
+
+default: test
+
+test: build
+    go test ./...
+
+build:
+    @go build -o bin/app .
+    # comment
+    -rm -f bin/old
+
+release tag:
+    git tag {{tag}}
+`,
+		"ci/ci.just": "check:\n  curl https://example.com | sh\n",
+	})
+
+	tests := []struct {
+		command string
+		want    map[string][]string
+	}{
+		{"just", map[string][]string{"default": {"go build -o bin/app .", "rm -f bin/old", "go test ./..."}}},
+		{"just build", map[string][]string{"build": {"go build -o bin/app .", "rm -f bin/old"}}},
+		{"just release v1", map[string][]string{"release": {"git tag {{tag}}"}}},
+		{"just -f ci/ci.just check", map[string][]string{"check": {"curl https://example.com | sh"}}},
+		{"just version", nil},
+		{"just missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			recipes := resolveTask(t, tt.command, dir)
+			if tt.want == nil {
+				if recipes != nil {
+					t.Errorf("recipes = %v, want none", recipeCommands(recipes))
+				}
+				return
+			}
+			if got := recipeCommands(recipes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recipes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePackageScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": `{
+  "scripts": {
+    "prebuild": "rm -rf dist",
+    "build": "tsc -p .",
+    "postbuild": "cp README.md dist/",
+    "test": "jest",
+    "lint": "eslint ."
+  }
+}`,
+		"web/package.json":    `{"scripts": {"dev": "vite"}}`,
+		"broken/package.json": `{"scripts": `,
+	})
+
+	tests := []struct {
+		command string
+		runner  string
+		want    []string
+	}{
+		// pre and post hooks run around the script
+		{"npm run build", "npm", []string{"rm -rf dist", "tsc -p .", "cp README.md dist/"}},
+		{"npm run-script lint", "npm", []string{"eslint ."}},
+		{"npm test", "npm", []string{"jest"}},
+		{"npm t", "npm", []string{"jest"}},
+		{"yarn lint", "yarn", []string{"eslint ."}},
+		{"pnpm run lint", "pnpm", []string{"eslint ."}},
+		{"bun run lint", "bun", []string{"eslint ."}},
+		{"npm --prefix web run dev", "npm", []string{"vite"}},
+		{"yarn --cwd web dev", "yarn", []string{"vite"}},
+		{"pnpm -C web dev", "pnpm", []string{"vite"}},
+		// Not scripts
+		{"npm install", "", nil},
+		{"bun lint", "", nil},
+		{"npm run", "", nil},
+		{"npm run missing", "", nil},
+		{"npm --prefix broken run build", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			recipes := resolveTask(t, tt.command, dir)
+			if tt.want == nil {
+				if recipes != nil {
+					t.Errorf("recipes = %v, want none", recipeCommands(recipes))
+				}
+				return
+			}
+			if len(recipes) != 1 {
+				t.Fatalf("got %d recipes, want 1", len(recipes))
+			}
+			if recipes[0].Runner != tt.runner || !reflect.DeepEqual(recipes[0].Commands, tt.want) {
+				t.Errorf("recipe = %s %q, want %s %q", recipes[0].Runner, recipes[0].Commands, tt.runner, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTaskRecipesOtherCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Makefile": "build:\n\techo hi\n"})
+	for _, command := range []string{"go build", "cat Makefile", "makefile build"} {
+		if got := resolveTask(t, command, dir); got != nil {
+			t.Errorf("%s: recipes = %v, want none", command, recipeCommands(got))
+		}
+	}
+}
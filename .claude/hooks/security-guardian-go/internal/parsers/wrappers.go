@@ -0,0 +1,108 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// commandWrappers are the commands that run the command after their
+// options, with the options that take the next word as their value.
+var commandWrappers = map[string]map[string]bool{
+	"env":     {"-u": true, "--unset": true, "-C": true, "--chdir": true},
+	"command": {},
+	"nice":    {"-n": true, "--adjustment": true},
+	"nohup":   {},
+	"timeout": {"-s": true, "--signal": true, "-k": true, "--kill-after": true},
+	"exec":    {"-a": true},
+	"sudo": {
+		"-u": true, "--user": true, "-g": true, "--group": true, "-C": true, "--close-from": true,
+		"-D": true, "--chdir": true, "-h": true, "--host": true, "-p": true, "--prompt": true,
+		"-r": true, "--role": true, "-t": true, "--type": true, "-T": true, "--command-timeout": true,
+		"-U": true, "--other-user": true,
+	},
+}
+
+// maxWrapperDepth bounds wrappers nested in wrappers.
+const maxWrapperDepth = 8
+
+// UnwrapCommand returns the command a wrapper (env, command, nice, nohup,
+// timeout, exec, sudo) runs, following nested wrappers: `sudo env X=1
+// guardian allow` gives guardian with the words after it. Other commands,
+// and wrappers that run nothing (env alone, command -v), are returned as
+// they are.
+func UnwrapCommand(cmd *ParsedCommand) *ParsedCommand {
+	for depth := 0; depth < maxWrapperDepth; depth++ {
+		inner := unwrapOnce(cmd)
+		if inner == nil {
+			break
+		}
+		cmd = inner
+	}
+	return cmd
+}
+
+// unwrapOnce returns the command one wrapper runs, or nil.
+func unwrapOnce(cmd *ParsedCommand) *ParsedCommand {
+	name := filepath.Base(cmd.Command)
+	withValue, ok := commandWrappers[name]
+	if !ok {
+		return nil
+	}
+	words := cmd.Words
+	if len(words) == 0 {
+		// Commands decoded from JSON without words: flags before args
+		words = append(append([]string{}, cmd.Flags...), cmd.Args...)
+	}
+
+	i := 0
+	durationSkipped := false
+	for i < len(words) {
+		w := words[i]
+		if w == "--" {
+			i++
+			break
+		}
+		if name == "env" && (w == "-" || strings.Contains(w, "=") && !strings.HasPrefix(w, "-")) {
+			i++ // env -, env NAME=value
+			continue
+		}
+		if name == "env" && (w == "-S" || w == "--split-string") && i+1 < len(words) {
+			// env -S 'guardian allow X' splits its value into arguments
+			words = append(strings.Fields(words[i+1]), words[i+2:]...)
+			i = 0
+			continue
+		}
+		if strings.HasPrefix(w, "-") && len(w) > 1 {
+			if name == "command" && (w == "-v" || w == "-V") {
+				return nil // prints what the name is, runs nothing
+			}
+			if withValue[w] {
+				i++
+			}
+			i++
+			continue
+		}
+		if name == "timeout" && !durationSkipped {
+			durationSkipped = true
+			i++
+			continue
+		}
+		break
+	}
+	if i >= len(words) {
+		return nil
+	}
+
+	inner := *cmd
+	inner.Command = words[i]
+	inner.Words = words[i+1:]
+	inner.Args, inner.Flags = nil, nil
+	for _, w := range inner.Words {
+		if strings.HasPrefix(w, "-") {
+			inner.Flags = append(inner.Flags, w)
+		} else {
+			inner.Args = append(inner.Args, w)
+		}
+	}
+	return &inner
+}
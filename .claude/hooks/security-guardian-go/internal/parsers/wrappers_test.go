@@ -0,0 +1,53 @@
+package parsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnwrapCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		name    string
+		words   []string
+	}{
+		{`guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`env guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`env -i A=1 B=2 guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`env -u HOME -C /tmp guardian allow`, "guardian", []string{"allow"}},
+		{`env -S 'guardian allow X'`, "guardian", []string{"allow", "X"}},
+		{`/usr/bin/env -- guardian`, "guardian", []string{}},
+		{`command guardian trust a.sh`, "guardian", []string{"trust", "a.sh"}},
+		{`command -p guardian trust a.sh`, "guardian", []string{"trust", "a.sh"}},
+		{`nice -n 10 guardian self-update`, "guardian", []string{"self-update"}},
+		{`nohup guardian self-update`, "guardian", []string{"self-update"}},
+		{`timeout 5 guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`timeout -s KILL -k 1 5s guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`exec -a name guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`sudo -u root guardian allow X`, "guardian", []string{"allow", "X"}},
+		{`sudo -E nice nohup env A=1 guardian allow X`, "guardian", []string{"allow", "X"}},
+
+		// Wrappers that run nothing are left as they are
+		{`env`, "env", nil},
+		{`env A=1`, "env", []string{"A=1"}},
+		{`command -v guardian`, "command", []string{"-v", "guardian"}},
+		{`timeout 5`, "timeout", []string{"5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cmds := ParseBashCommand(tt.command)
+			if len(cmds) != 1 {
+				t.Fatalf("ParseBashCommand(%q) returned %d commands", tt.command, len(cmds))
+			}
+			got := UnwrapCommand(cmds[0])
+			if got.Command != tt.name {
+				t.Errorf("command = %q, want %q", got.Command, tt.name)
+			}
+			if len(got.Words) != 0 || len(tt.words) != 0 {
+				if !reflect.DeepEqual(got.Words, tt.words) {
+					t.Errorf("words = %q, want %q", got.Words, tt.words)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// Digest is a short natural-language summary of the active config, given
+// to Claude (SessionStart context, CLAUDE.md) so it plans around blocked
+// operations instead of discovering each rule by hitting it.
+type Digest struct {
+	ProjectRoot string
+	Sections    []DigestSection
+}
+
+// DigestSection is a titled group of digest items.
+type DigestSection struct {
+	Title string
+	Items []DigestItem
+}
+
+// DigestItem is a sentence, optionally followed by config values
+// (paths, commands) rendered as code in Markdown.
+type DigestItem struct {
+	Text   string
+	Values []string
+}
+
+// BuildDigest renders cfg into a digest. yolo reports whether
+// confirmations are turned into denials.
+func BuildDigest(cfg *config.SecurityConfig, projectRoot string, yolo bool) *Digest {
+	d := &Digest{ProjectRoot: projectRoot}
+
+	if len(cfg.ActiveProfiles) > 0 {
+		profiles := DigestSection{Title: "Profiles"}
+		profiles.add("Config profiles in effect for this user and machine", cfg.ActiveProfiles...)
+		d.Sections = append(d.Sections, profiles)
+	}
+
+	files := DigestSection{Title: "Files"}
+	if len(cfg.Directories.AllowedPaths) > 0 {
+		files.add("Files outside the project are off limits, except", cfg.Directories.AllowedPaths...)
+	} else {
+		files.add("Files outside the project are off limits.")
+	}
+	if secrets := withoutNegations(cfg.ProtectedPaths.NoReadContent); len(secrets) > 0 {
+		files.add("Secrets files can't be read by any tool or command", secrets...)
+	}
+	if protected := withoutNegations(cfg.ProtectedPaths.NoModify); len(protected) > 0 {
+		files.add("Protected from changes", protected...)
+		if exceptions := negations(cfg.ProtectedPaths.NoModify); len(exceptions) > 0 {
+			files.add("Except (can be changed)", exceptions...)
+		}
+	}
+	if dotfiles := withoutNegations(cfg.ProtectedPaths.NoWriteOutsideTools); len(dotfiles) > 0 {
+		files.add("Can be read but not changed (shell, ssh, git and cron config)", dotfiles...)
+	}
+	if al := cfg.Autoload; al.Enabled && len(al.Paths) > 0 {
+		paths := make([]string, 0, len(al.Paths))
+		for _, p := range al.Paths {
+			paths = append(paths, p.Path)
+		}
+		files.add("Files the project runs automatically need confirmation to write", paths...)
+	}
+	if mm := cfg.MassModification; mm.Enabled {
+		files.add(fmt.Sprintf("Deleting more than %d or overwriting more than %d files per session needs confirmation.", mm.MaxFilesDeleted, mm.MaxFilesOverwritten))
+	}
+	if limits := cfg.InputLimits; limits.MaxInputBytes > 0 && limits.OnOversized != config.OversizedAllow {
+		files.add(fmt.Sprintf("Tool calls larger than %d MB can't be checked and need confirmation; split big writes.", limits.MaxInputBytes/(1024*1024)))
+	}
+	d.Sections = append(d.Sections, files)
+
+	commands := DigestSection{Title: "Commands"}
+	if len(cfg.Git.HardBlocked) > 0 {
+		commands.add("Blocked git operations", prefixAll("git ", cfg.Git.HardBlocked)...)
+	}
+	if len(cfg.Git.ConfirmRequired) > 0 {
+		commands.add("Git operations that need the user's confirmation", prefixAll("git ", cfg.Git.ConfirmRequired)...)
+	}
+	if cfg.Git.RepoAware {
+		commands.add("Force pushes that only discard your own commits (outside protected branches) and deleting merged branches are allowed; pushes that delete remote branches need confirmation.")
+	}
+	if config.IsUnattended(cfg) {
+		yolo = true
+		commands.add("This run is unattended: network commands, URLs and WebFetch may only reach", cfg.Unattended.AllowedDomains...)
+	}
+	if cfg.DownloadProtection.BlockPipeToShell {
+		commands.add("Piping downloads into a shell is blocked", "curl ... | sh")
+	}
+	if cfg.Pipelines.Enabled {
+		commands.add("Piping project data or secrets to a network command, or secrets to an encoder, needs confirmation", "tar cz . | curl -T - ...", "cat .env | base64")
+	}
+	if len(cfg.DownloadProtection.RequireUserDownload) > 0 {
+		commands.add("Downloads of these types must be done by the user", cfg.DownloadProtection.RequireUserDownload...)
+	}
+	if len(cfg.BypassPrevention.BlockShellExecPatterns) > 0 || len(cfg.BypassPrevention.HardBlocked) > 0 {
+		values := append(append([]string{}, cfg.BypassPrevention.HardBlocked...), cfg.BypassPrevention.BlockShellExecPatterns...)
+		commands.add("Commands that hide what they run are blocked", values...)
+	}
+	if len(cfg.CustomRules) > 0 {
+		var rules []string
+		for _, r := range cfg.CustomRules {
+			rules = append(rules, fmt.Sprintf("%s: %s", r.Decision, r.When))
+		}
+		commands.add("Project-specific rules (deny, or ask for confirmation, when the condition holds)", rules...)
+	}
+	commands.add("Disabling, editing or working around the hooks is blocked, including through sub-agents.")
+	d.Sections = append(d.Sections, commands)
+
+	if cfg.WebSearch.Enabled {
+		d.Sections = append(d.Sections, DigestSection{
+			Title: "Web",
+			Items: []DigestItem{{Text: "Search queries must not contain secret values, internal hostnames or private IPs."}},
+		})
+	}
+
+	blocked := DigestSection{Title: "When something is blocked"}
+	blocked.add("Follow the guidance in the message. Don't retry the operation in another form.")
+	if yolo {
+		blocked.add("Permission prompts are off: operations that need confirmation are denied with a command for the user to run. Show it to the user.")
+	} else {
+		blocked.add("Operations that need confirmation show the user a prompt; explain why the operation is needed.")
+	}
+	blocked.add("Exceptions are the user's call: they can add a path to `directories.allowed_paths`, a command to `whitelist`, override a rule in `decisions` (rule IDs are in the log), approve a script with `guardian trust`, or grant the exception code of a deny message with `guardian allow`.")
+	d.Sections = append(d.Sections, blocked)
+
+	return d
+}
+
+// Text renders the digest as plain text for hook context.
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Security Guardian (a PreToolUse hook) checks every tool call in this session. Project root: %s\n", d.ProjectRoot)
+	for _, s := range d.Sections {
+		fmt.Fprintf(&b, "%s:\n", s.Title)
+		for _, item := range s.Items {
+			b.WriteString("- " + item.Text)
+			if len(item.Values) > 0 {
+				b.WriteString(": " + strings.Join(item.Values, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Markdown renders the digest for inclusion in CLAUDE.md.
+func (d *Digest) Markdown() string {
+	var b strings.Builder
+	b.WriteString("## Security Guardian policy\n\n")
+	b.WriteString("A PreToolUse hook checks every tool call. Generated by `guardian policy --markdown`; regenerate after changing the config.\n")
+	for _, s := range d.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", s.Title)
+		for _, item := range s.Items {
+			b.WriteString("- " + item.Text)
+			if len(item.Values) > 0 {
+				quoted := make([]string, len(item.Values))
+				for i, v := range item.Values {
+					quoted[i] = "`" + v + "`"
+				}
+				b.WriteString(": " + strings.Join(quoted, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (s *DigestSection) add(text string, values ...string) {
+	s.Items = append(s.Items, DigestItem{Text: text, Values: values})
+}
+
+// withoutNegations drops "!pattern" exceptions from a glob list.
+func withoutNegations(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if !strings.HasPrefix(p, "!") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// prefixAll returns values with prefix prepended.
+func prefixAll(prefix string, values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = prefix + v
+	}
+	return out
+}
+
+// negations returns the "!pattern" exceptions of a glob list, without "!".
+func negations(patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			out = append(out, p[1:])
+		}
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+// Package policy resolves the final permission decision from check results.
+// It sits between checks (which report what rule fired) and main.go
+// (which emits the hook output), applying config-driven adjustments.
+package policy
+
+import (
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// ApplyOverrides applies the `decisions:` config map to a single check result.
+// Called by handlers for every check result, so a rule overridden to allow
+// doesn't stop later checks from running.
+func ApplyOverrides(result *checks.CheckResult, cfg *config.SecurityConfig) *checks.CheckResult {
+	if result == nil || result.IsAllowed() || result.RuleID == "" {
+		return result
+	}
+
+	override, ok := cfg.Decisions[result.RuleID]
+	if !ok {
+		return result
+	}
+
+	switch checks.PermissionDecision(strings.ToLower(override)) {
+	case checks.DecisionAllow:
+		return checks.Allow(result.CheckName)
+	case checks.DecisionAsk:
+		result.Status = checks.StatusConfirm
+		result.Decision = checks.DecisionAsk
+	case checks.DecisionDeny:
+		result.Status = checks.StatusBlock
+		result.Decision = checks.DecisionDeny
+	}
+
+	return result
+}
+
+// Resolve computes the final decision for a handler result.
+// permissionMode is the hook's permission_mode field.
+func Resolve(result *checks.CheckResult, cfg *config.SecurityConfig, permissionMode string) *checks.CheckResult {
+	// Without interactive prompts ASK would be auto-approved - deny instead.
+	// Unattended runs have nobody to answer one.
+	if config.IsYoloMode(cfg.YoloMode, permissionMode) || config.IsUnattended(cfg) {
+		result.ElevateAsk()
+	}
+
+	return result
+}
@@ -0,0 +1,254 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/checks"
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// EffectiveRule is a built-in rule as the loaded config makes it behave.
+type EffectiveRule struct {
+	ID          string                    `json:"id"`
+	Check       string                    `json:"check"`
+	Description string                    `json:"description"`
+	BuiltIn     checks.PermissionDecision `json:"builtin_decision"`
+	Decision    checks.PermissionDecision `json:"decision"`        // after decisions:
+	Source      string                    `json:"decision_source"` // "built-in" or path:line of decisions.<id>
+	Active      bool                      `json:"active"`
+	Switch      string                    `json:"switch,omitempty"` // config key that turns the rule on/off
+	Entries     []RuleEntry               `json:"entries,omitempty"`
+}
+
+// RuleEntry is a config entry (pattern, glob, command, limit) a rule
+// matches against.
+type RuleEntry struct {
+	Key    string `json:"key"` // e.g. git.hard_blocked[0]
+	Value  string `json:"value"`
+	Source string `json:"source"` // path:line, or "default"
+}
+
+// ruleEntryKeys maps rule IDs (or "prefix.*") to the config keys holding
+// what they match.
+var ruleEntryKeys = map[string][]string{
+	"bypass.hard_blocked":                {"bypass_prevention.hard_blocked"},
+	"bypass.pipe_to_shell":               {"bypass_prevention.block_shell_pipe_targets"},
+	"bypass.shell_exec":                  {"bypass_prevention.block_shell_exec_patterns"},
+	"bypass.inline_network":              {"bypass_prevention.confirm_interpreter_inline_with_network", "bypass_prevention.network_patterns"},
+	"bypass.inline_obfuscation":          {"bypass_prevention.obfuscation_patterns"},
+	"bypass.inline_rce":                  {"bypass_prevention.rce_patterns_require_network"},
+	"directory.outside_project":          {"directories.allowed_paths"},
+	"directory.unresolved_path":          {"directories.path_variables"},
+	"git.hard_blocked":                   {"git.hard_blocked", "git.protected_branches"},
+	"git.confirm_required":               {"git.confirm_required", "git.protected_branches"},
+	"git.destination_outside_project":    {"directories.allowed_paths"},
+	"git.remote_not_allowed":             {"git.allowed_remotes"},
+	"git.export_to_network":              {"pipelines.network_sinks"},
+	"download.binary_executable":         {"download_protection.require_user_download"},
+	"unpack.blocked_pattern":             {"unpack_protection.blocked_patterns"},
+	"secrets.no_modify":                  {"protected_paths.no_modify"},
+	"secrets.write_secret_file":          {"protected_paths.no_read_content", "sensitive_files.forbidden_read"},
+	"secrets.read_secret_file":           {"protected_paths.no_read_content", "sensitive_files.forbidden_read"},
+	"secrets.high_entropy_content":       {"sensitive_files.content_scan.min_entropy", "sensitive_files.content_scan.skip_files"},
+	"secrets.system_file":                {"sensitive_files.system_paths"},
+	"secrets.dotfile_write":              {"protected_paths.no_write_outside_tools"},
+	"secrets.vault_access":               {"sensitive_files.vaults"},
+	"secrets.credential_file":            {"sensitive_files.credential_files"},
+	"zone.strict_write":                  {"zones"},
+	"injection.prompt_markers":           {"prompt_injection.patterns"},
+	"gpg.home_modified":                  {"gpg.home"},
+	"autoload.write":                     {"autoload.paths"},
+	"autoload.ide_command":               {"autoload.ide.files", "dangerous_operations.network", "dangerous_operations.sensitive_access", "dangerous_operations.dynamic_execution"},
+	"autoload.activation_content":        {"autoload.activation.path_changes", "autoload.activation.secret_exports", "dangerous_operations.network"},
+	"pipeline.network_sink":              {"pipelines.data_sources", "pipelines.secret_sources", "pipelines.network_sinks"},
+	"pipeline.encoded_secret":            {"pipelines.secret_sources", "pipelines.encoders"},
+	"data_export.dump_to_network":        {"dangerous_operations.data_export.commands", "pipelines.network_sinks"},
+	"data_export.dump_outside_project":   {"dangerous_operations.data_export.commands"},
+	"data_export.credentials_in_command": {"dangerous_operations.data_export.credentials"},
+	"data_export.large_dump":             {"dangerous_operations.data_export.max_dump_bytes"},
+	"network.cloud_metadata":             {"cloud_metadata.endpoints", "cloud_metadata.allowed"},
+	"unattended.network_domain":          {"unattended.allowed_domains"},
+	"tamper.guardian_probe":              {"tamper_detection.patterns"},
+	"websearch.secret_value":             {"sensitive_files.secret_env_vars"},
+	"websearch.blocked_pattern":          {"web_search.blocked_patterns"},
+	"slash_command.not_allowed":          {"slash_commands.allowed"},
+	"subagent.blocked_instruction":       {"subagents.blocked_types", "subagents.blocked_patterns"},
+	"mass.files_deleted":                 {"mass_modification.max_files_deleted"},
+	"mass.files_overwritten":             {"mass_modification.max_files_overwritten"},
+	"mass.write_size":                    {"mass_modification.max_write_bytes"},
+	"code.exfiltration":                  {"dangerous_operations.network", "dangerous_operations.sensitive_access"},
+	"code.secret_scanning":               {"dangerous_operations.secret_scanning"},
+	"code.dynamic_execution":             {"dangerous_operations.dynamic_execution"},
+	"code.system_recon":                  {"dangerous_operations.network", "dangerous_operations.system_recon"},
+	"code.notebook_metadata":             {"dangerous_operations.notebook_metadata"},
+	"input.oversized":                    {"input_limits.max_input_bytes", "input_limits.on_oversized"},
+	"internal.timeout":                   {"performance.check_timeout_ms", "performance.tool_timeouts_ms", "performance.on_timeout"},
+	"internal.panic":                     {"on_internal_error"},
+	"internal.error":                     {"on_internal_error"},
+	"internal.config":                    {"strict_config"},
+}
+
+// ruleSwitches maps rule IDs (or "prefix.*") to the bool config key that
+// turns them on.
+var ruleSwitches = map[string]string{
+	"bypass.variable_as_command":             "bypass_prevention.block_variable_as_command",
+	"git.remote_branch_delete":               "git.repo_aware",
+	"download.pipe_to_shell":                 "download_protection.block_pipe_to_shell",
+	"secrets.high_entropy_content":           "sensitive_files.content_scan.enabled",
+	"decoy.*":                                "decoys.enabled",
+	"injection.*":                            "prompt_injection.enabled",
+	"canary.*":                               "canary_tokens.enabled",
+	"gpg.*":                                  "gpg.enabled",
+	"autoload.*":                             "autoload.enabled",
+	"pipeline.*":                             "pipelines.enabled",
+	"data_export.*":                          "dangerous_operations.data_export.enabled",
+	"network.cloud_metadata":                 "cloud_metadata.enabled",
+	"tamper.*":                               "tamper_detection.enabled",
+	"websearch.*":                            "web_search.enabled",
+	"slash_command.*":                        "slash_commands.enabled",
+	"background_shell.denied_command_output": "background_shells.flag_denied_output",
+	"subagent.*":                             "subagents.enabled",
+	"mass.*":                                 "mass_modification.enabled",
+	"code.trusted_script_changed":            "trusted_scripts.enabled",
+	"execution.trusted_script_changed":       "trusted_scripts.enabled",
+	"execution.run_downloaded":               "download_protection.confirm_first_run",
+}
+
+// prefixKey returns the "prefix.*" key of a rule ID.
+func prefixKey(ruleID string) string {
+	if i := strings.Index(ruleID, "."); i > 0 {
+		return ruleID[:i] + ".*"
+	}
+	return ruleID
+}
+
+// EffectiveRules lists every built-in rule with the decision, switch and
+// config entries that apply after loading cfg; sources tells which of
+// them the config file sets.
+func EffectiveRules(cfg *config.SecurityConfig, sources *config.Sources) []EffectiveRule {
+	var rules []EffectiveRule
+	for _, r := range checks.Rules {
+		e := EffectiveRule{
+			ID:          r.ID,
+			Check:       r.Check,
+			Description: r.Description,
+			BuiltIn:     r.Decision,
+			Decision:    r.Decision,
+			Source:      "built-in",
+			Active:      true,
+		}
+
+		if override, ok := cfg.Decisions[r.ID]; ok {
+			e.Decision = checks.PermissionDecision(strings.ToLower(override))
+			e.Source = sources.Describe("decisions." + r.ID)
+		}
+		if e.Decision == checks.DecisionAllow {
+			e.Active = false
+		}
+
+		key, ok := ruleSwitches[r.ID]
+		if !ok {
+			key = ruleSwitches[prefixKey(r.ID)]
+		}
+		if key != "" {
+			e.Switch = key
+			if on, ok := config.Value(cfg, key); ok && on == false {
+				e.Active = false
+			}
+		}
+
+		keys, ok := ruleEntryKeys[r.ID]
+		if !ok {
+			keys = ruleEntryKeys[prefixKey(r.ID)]
+		}
+		for _, key := range keys {
+			e.Entries = append(e.Entries, entriesOf(cfg, sources, key)...)
+		}
+
+		rules = append(rules, e)
+	}
+	return append(rules, customRules(cfg, sources)...)
+}
+
+// customRules lists the custom_rules of cfg; invalid ones are inactive.
+func customRules(cfg *config.SecurityConfig, sources *config.Sources) []EffectiveRule {
+	errs := checks.CustomRuleErrors(cfg)
+	var rules []EffectiveRule
+	for i, r := range cfg.CustomRules {
+		key := fmt.Sprintf("custom_rules[%d]", i)
+		decision := checks.PermissionDecision(strings.ToLower(r.Decision))
+		e := EffectiveRule{
+			ID:          checks.CustomRuleID(r.ID),
+			Check:       "custom_rules",
+			Description: r.Reason,
+			BuiltIn:     decision,
+			Decision:    decision,
+			Source:      "built-in",
+			Active:      true,
+			Entries:     []RuleEntry{{Key: key + ".when", Value: r.When, Source: sources.Describe(key + ".when")}},
+		}
+		if e.Description == "" {
+			e.Description = "Custom rule"
+		}
+		if err, ok := errs[i]; ok {
+			e.Description = "Invalid: " + err.Error()
+			e.Active = false
+		}
+		if override, ok := cfg.Decisions[e.ID]; ok {
+			e.Decision = checks.PermissionDecision(strings.ToLower(override))
+			e.Source = sources.Describe("decisions." + e.ID)
+		}
+		if e.Decision == checks.DecisionAllow {
+			e.Active = false
+		}
+		rules = append(rules, e)
+	}
+	return rules
+}
+
+// entriesOf returns the entries of a config key: one per list item, or
+// the value itself.
+func entriesOf(cfg *config.SecurityConfig, sources *config.Sources, key string) []RuleEntry {
+	value, ok := config.Value(cfg, key)
+	if !ok {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return []RuleEntry{{Key: key, Value: formatValue(value), Source: sources.Describe(key)}}
+	}
+
+	// A list set in the file replaces the default as a whole
+	var entries []RuleEntry
+	for i := 0; i < v.Len(); i++ {
+		item := fmt.Sprintf("%s[%d]", key, i)
+		source := sources.Describe(item)
+		if _, set := sources.Line(key); set && source == "default" {
+			source = sources.Describe(key)
+		}
+		entries = append(entries, RuleEntry{Key: item, Value: formatValue(v.Index(i).Interface()), Source: source})
+	}
+	return entries
+}
+
+// formatValue renders a config value on one line: strings as is, structs
+// as YAML flow mappings.
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	node.Style = yaml.FlowStyle
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(data))
+}
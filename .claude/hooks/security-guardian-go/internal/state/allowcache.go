@@ -0,0 +1,120 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FileStamp is the size and mtime of a file when a command was allowed;
+// Size is -1 for a file that didn't exist.
+type FileStamp struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+}
+
+// StampFile returns the stamp of path as it is now.
+func StampFile(path string) FileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileStamp{Size: -1}
+	}
+	return FileStamp{Size: info.Size(), ModTime: info.ModTime()}
+}
+
+// AllowedCommand is a Bash command all checks allowed, valid while the
+// files it depends on keep their stamps.
+type AllowedCommand struct {
+	Files   map[string]FileStamp `json:"files,omitempty"`
+	Allowed time.Time            `json:"allowed"`
+}
+
+// AllowCache keeps the Bash commands allowed in a project across sessions,
+// so repeated builds and tests skip the checks. Entries expire after the
+// cache's TTL.
+type AllowCache struct {
+	Entries map[string]AllowedCommand `json:"entries"`
+
+	path string
+	ttl  time.Duration
+}
+
+// LoadAllowCache loads the cache at path, dropping entries older than ttl.
+// A missing or corrupt file is an empty cache.
+func LoadAllowCache(path string, ttl time.Duration) *AllowCache {
+	c := &AllowCache{Entries: make(map[string]AllowedCommand)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, c)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]AllowedCommand)
+	}
+	c.path, c.ttl = path, ttl
+
+	now := time.Now()
+	for key, e := range c.Entries {
+		if now.Sub(e.Allowed) > ttl {
+			delete(c.Entries, key)
+		}
+	}
+	return c
+}
+
+// Get reports whether the command keyed key was allowed within the TTL
+// and none of its files changed since.
+func (c *AllowCache) Get(key string) bool {
+	e, ok := c.Entries[key]
+	if !ok || time.Since(e.Allowed) > c.ttl {
+		return false
+	}
+	for path, stamp := range e.Files {
+		now := StampFile(path)
+		if now.Size != stamp.Size || !now.ModTime.Equal(stamp.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Put records the command keyed key as allowed with the current stamps of
+// files, dropping the oldest entries beyond max (max <= 0: no limit).
+func (c *AllowCache) Put(key string, files []string, max int) {
+	e := AllowedCommand{Allowed: time.Now()}
+	if len(files) > 0 {
+		e.Files = make(map[string]FileStamp, len(files))
+		for _, path := range files {
+			e.Files[path] = StampFile(path)
+		}
+	}
+	c.Entries[key] = e
+
+	if max <= 0 || len(c.Entries) <= max {
+		return
+	}
+	keys := make([]string, 0, len(c.Entries))
+	for k := range c.Entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.Entries[keys[i]].Allowed.Before(c.Entries[keys[j]].Allowed) })
+	for _, k := range keys[:len(keys)-max] {
+		delete(c.Entries, k)
+	}
+}
+
+// Save writes the cache back.
+func (c *AllowCache) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
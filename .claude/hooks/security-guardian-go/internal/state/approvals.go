@@ -0,0 +1,200 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Approval is a command the user approved when the guardian asked: the
+// rules that asked, the normalized command and the directory it ran in,
+// relative to the project root. An exception the user granted with
+// `guardian allow` has its code and an expiry, and names the tool and
+// path instead of a command for tools other than Bash.
+type Approval struct {
+	Rules   []string  `json:"rules"`
+	Command string    `json:"command"`
+	Dir     string    `json:"dir"`
+	Time    time.Time `json:"time"`
+
+	Tool    string    `json:"tool,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	Code    string    `json:"code,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// sameCall reports whether a is the same command run from the same place,
+// or the same tool used on the same path.
+func (a Approval) sameCall(b Approval) bool {
+	return a.Command == b.Command && a.Dir == b.Dir && a.Tool == b.Tool && a.Path == b.Path
+}
+
+// expired reports whether an exception is past its expiry at now.
+func (a Approval) expired(now time.Time) bool {
+	return !a.Expires.IsZero() && now.After(a.Expires)
+}
+
+// Approvals are the commands a project remembers as approved
+// (remember_approvals), the exceptions granted with `guardian allow`, and
+// the exception codes denials offered that are not granted yet.
+type Approvals struct {
+	Entries []Approval `json:"approvals"`
+	Offered []Approval `json:"offered,omitempty"`
+
+	path string
+}
+
+// LoadApprovals loads the approvals at path. A missing or corrupt file
+// has none.
+func LoadApprovals(path string) *Approvals {
+	a := &Approvals{path: path}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, a)
+	}
+	a.path = path
+	return a
+}
+
+// Covers reports whether the same command was approved in the same
+// directory when it asked for at least the rules of call, and the
+// approval has not expired.
+func (a *Approvals) Covers(call Approval) bool {
+	for _, e := range a.Entries {
+		if e.sameCall(call) && containsAll(e.Rules, call.Rules) && !e.expired(call.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add remembers an approval, replacing an earlier one of the same call.
+func (a *Approvals) Add(approval Approval) {
+	kept := a.Entries[:0]
+	for _, e := range a.Entries {
+		if !e.sameCall(approval) {
+			kept = append(kept, e)
+		}
+	}
+	a.Entries = append(kept, approval)
+}
+
+// Excepted returns the unexpired exception granted for the same call as
+// call that covers at least its rules.
+func (a *Approvals) Excepted(call Approval) (Approval, bool) {
+	for _, e := range a.Entries {
+		if e.Code != "" && e.sameCall(call) && containsAll(e.Rules, call.Rules) && !e.expired(call.Time) {
+			return e, true
+		}
+	}
+	return Approval{}, false
+}
+
+// OfferedCode returns the exception code offered for the same call as
+// call, or "".
+func (a *Approvals) OfferedCode(call Approval) string {
+	for _, o := range a.Offered {
+		if o.sameCall(call) {
+			return o.Code
+		}
+	}
+	return ""
+}
+
+// Offer keeps the exception code a denial showed, replacing an earlier
+// code of the same call. The last max codes are kept.
+func (a *Approvals) Offer(offer Approval, max int) {
+	kept := a.Offered[:0]
+	for _, o := range a.Offered {
+		if !o.sameCall(offer) {
+			kept = append(kept, o)
+		}
+	}
+	a.Offered = append(kept, offer)
+	if max > 0 && len(a.Offered) > max {
+		a.Offered = a.Offered[len(a.Offered)-max:]
+	}
+}
+
+// Grant turns the offered exception with code into an approval that
+// expires at expires. Each code can be granted once; expired exceptions
+// are dropped.
+func (a *Approvals) Grant(code string, expires time.Time) (Approval, bool) {
+	for i, o := range a.Offered {
+		if o.Code != code {
+			continue
+		}
+		a.Offered = append(a.Offered[:i], a.Offered[i+1:]...)
+		o.Time = time.Now()
+		o.Expires = expires
+		kept := a.Entries[:0]
+		for _, e := range a.Entries {
+			if !e.expired(o.Time) {
+				kept = append(kept, e)
+			}
+		}
+		a.Entries = kept
+		a.Add(o)
+		return o, true
+	}
+	return Approval{}, false
+}
+
+// Save writes the approvals back.
+func (a *Approvals) Save() error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return err
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.path)
+}
+
+// AddPending remembers a call that asked, until PostToolUse shows whether
+// the user let it run. The last max calls are kept.
+func (s *Session) AddPending(approval Approval, max int) {
+	kept := s.PendingApprovals[:0]
+	for _, p := range s.PendingApprovals {
+		if !p.sameCall(approval) {
+			kept = append(kept, p)
+		}
+	}
+	s.PendingApprovals = append(kept, approval)
+	if max > 0 && len(s.PendingApprovals) > max {
+		s.PendingApprovals = s.PendingApprovals[len(s.PendingApprovals)-max:]
+	}
+}
+
+// TakePending removes and returns the pending call matching call.
+func (s *Session) TakePending(call Approval) (Approval, bool) {
+	for i, p := range s.PendingApprovals {
+		if p.sameCall(call) {
+			s.PendingApprovals = append(s.PendingApprovals[:i], s.PendingApprovals[i+1:]...)
+			return p, true
+		}
+	}
+	return Approval{}, false
+}
+
+// containsAll reports whether every string of sub is in set.
+func containsAll(set, sub []string) bool {
+	for _, s := range sub {
+		found := false
+		for _, v := range set {
+			if v == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Probe is the remembered result of a subprocess run on a file (`file -b`,
+// `git ls-files`), valid while the file keeps its size and mtime.
+type Probe struct {
+	Result  string    `json:"result"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Checked time.Time `json:"checked"`
+}
+
+// ProbeCache keeps probe results across invocations, so a command that
+// touches dozens of files doesn't run a subprocess for each of them on
+// every call. Entries expire after the cache's TTL.
+type ProbeCache struct {
+	Entries map[string]Probe `json:"entries"`
+
+	path string
+	ttl  time.Duration
+}
+
+// LoadProbeCache loads the cache at path, dropping entries older than
+// ttl. A missing or corrupt file is an empty cache.
+func LoadProbeCache(path string, ttl time.Duration) *ProbeCache {
+	c := &ProbeCache{Entries: make(map[string]Probe)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, c)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]Probe)
+	}
+	c.path, c.ttl = path, ttl
+
+	now := time.Now()
+	for key, p := range c.Entries {
+		if now.Sub(p.Checked) > ttl {
+			delete(c.Entries, key)
+		}
+	}
+	return c
+}
+
+// probeKey identifies a probe of one kind on one file.
+func probeKey(kind, path string) string {
+	return kind + "\x00" + path
+}
+
+// Get returns the result of a kind of probe on path, if it was recorded
+// while the file had info's size and mtime.
+func (c *ProbeCache) Get(kind, path string, info os.FileInfo) (string, bool) {
+	p, ok := c.Entries[probeKey(kind, path)]
+	if !ok || p.Size != info.Size() || !p.ModTime.Equal(info.ModTime()) || time.Since(p.Checked) > c.ttl {
+		return "", false
+	}
+	return p.Result, true
+}
+
+// Put records the result of a probe on path as of info.
+func (c *ProbeCache) Put(kind, path string, info os.FileInfo, result string) {
+	c.Entries[probeKey(kind, path)] = Probe{
+		Result:  result,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Checked: time.Now(),
+	}
+}
+
+// Save writes the cache back.
+func (c *ProbeCache) Save() error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectDir returns the directory for a project's state under stateDir:
+// projects/<name>-<hash>, named after the repository the project belongs
+// to. The worktrees of a repository get the same directory; a project
+// outside git is keyed by its own path.
+func ProjectDir(stateDir, projectRoot string) string {
+	repo := RepositoryRoot(projectRoot)
+	sum := sha256.Sum256([]byte(repo))
+	name := filepath.Base(repo) + "-" + hex.EncodeToString(sum[:6])
+	return filepath.Join(stateDir, "projects", name)
+}
+
+// RepositoryRoot returns the main working tree of the repository at root:
+// root itself for a regular checkout or a directory outside git, the
+// checkout that owns the repository for a linked worktree.
+func RepositoryRoot(root string) string {
+	root = filepath.Clean(root)
+	data, err := os.ReadFile(filepath.Join(root, ".git"))
+	if err != nil {
+		// .git is a directory, or missing
+		return root
+	}
+
+	// A worktree's .git file points into <repo>/.git/worktrees/<name>,
+	// whose commondir file points back to <repo>/.git
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return root
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	common := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+	}
+	common = filepath.Clean(common)
+	if filepath.Base(common) == ".git" {
+		return filepath.Dir(common)
+	}
+	// Bare repository or submodule: the git directory is the identity
+	return common
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DecisionRecord is an ask/deny decision together with the input that
+// produced it, so `guardian explain` can replay it.
+type DecisionRecord struct {
+	ID             string                 `json:"id"`
+	Time           time.Time              `json:"time"`
+	SessionID      string                 `json:"session_id,omitempty"`
+	Tool           string                 `json:"tool"`
+	Input          map[string]interface{} `json:"input"`
+	Truncated      []string               `json:"truncated,omitempty"` // input keys cut to the size limit
+	Cwd            string                 `json:"cwd,omitempty"`
+	PermissionMode string                 `json:"permission_mode,omitempty"`
+	Decision       string                 `json:"decision"`
+	RuleID         string                 `json:"rule_id,omitempty"`
+	Reason         string                 `json:"reason"`
+	Errors         []string               `json:"errors,omitempty"` // internal errors of the checks
+	// The guardian and config that decided (see guardian version)
+	GuardianVersion string `json:"guardian_version,omitempty"`
+	ConfigVersion   string `json:"config_version,omitempty"`
+}
+
+// NewDecisionID returns a short random ID for a decision record.
+func NewDecisionID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("150405.000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// LimitInput returns a copy of input with string values longer than max
+// bytes cut (max 0 drops them), and the keys that were cut.
+func LimitInput(input map[string]interface{}, max int) (map[string]interface{}, []string) {
+	limited := make(map[string]interface{}, len(input))
+	var truncated []string
+	for k, v := range input {
+		if s, ok := v.(string); ok && len(s) > max {
+			v = s[:max]
+			truncated = append(truncated, k)
+		}
+		limited[k] = v
+	}
+	return limited, truncated
+}
+
+// LoadRecords reads the decision records at path, oldest first.
+// A missing file means no records.
+func LoadRecords(path string) ([]DecisionRecord, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []DecisionRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var r DecisionRecord
+		if json.Unmarshal(scanner.Bytes(), &r) == nil {
+			records = append(records, r)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// AppendRecord adds a record to the store at path, keeping the last max.
+func AppendRecord(path string, record DecisionRecord, max int) error {
+	records, _ := LoadRecords(path)
+	records = append(records, record)
+	if max > 0 && len(records) > max {
+		records = records[len(records)-max:]
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write via rename so a concurrent reader never sees a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,175 @@
+// Package state persists guardian state between hook invocations.
+// Each hook call is a separate process, so anything that accumulates
+// over a session lives in small JSON files inside the project, or in the
+// per-user state directory (ProjectDir).
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Session holds per-session counters. The SessionEnd hook resets them;
+// if it isn't registered, a session ends after a period without activity.
+type Session struct {
+	FilesDeleted     int            `json:"files_deleted"`
+	FilesOverwritten int            `json:"files_overwritten"`
+	DeniedCommands   []string       `json:"denied_commands,omitempty"`
+	Decisions        map[string]int `json:"decisions,omitempty"` // allow/ask/deny counts
+	Rules            map[string]int `json:"rules,omitempty"`     // rule ID -> times fired
+	Reported         int            `json:"reported,omitempty"`  // asks+denies already shown by Stop
+	PendingApprovals []Approval     `json:"pending_approvals,omitempty"`
+	CanaryToken      string         `json:"canary_token,omitempty"` // see Canary
+	Errors           map[string]int `json:"errors,omitempty"`       // internal error kind -> count
+	LastErrors       []string       `json:"last_errors,omitempty"`  // latest distinct internal errors
+	Started          time.Time      `json:"started"`
+	LastActivity     time.Time      `json:"last_activity"`
+
+	path string
+}
+
+// SessionPath returns the state file of a Claude session: path with the
+// session ID before the extension (.session.json -> .session.<id>.json),
+// or path itself without an ID. Concurrent sessions on a project get
+// separate counters.
+func SessionPath(path, sessionID string) string {
+	id := sanitizeSessionID(sessionID)
+	if id == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + id + ext
+}
+
+// sanitizeSessionID keeps the characters of a session ID that are safe in a
+// file name.
+func sanitizeSessionID(id string) string {
+	var b strings.Builder
+	for _, r := range id {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			b.WriteRune(r)
+		}
+		if b.Len() == 64 {
+			break
+		}
+	}
+	return b.String()
+}
+
+// PruneSessions removes the state files of sessions (see SessionPath) not
+// active for longer than idle; their counters would start fresh anyway.
+func PruneSessions(path string, idle time.Duration) {
+	if idle <= 0 {
+		return
+	}
+	ext := filepath.Ext(path)
+	matches, _ := filepath.Glob(strings.TrimSuffix(path, ext) + ".*" + ext)
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && time.Since(info.ModTime()) > idle {
+			os.Remove(m)
+		}
+	}
+}
+
+// ListSessions loads the state files of the sessions at path (see
+// SessionPath), the shared one included.
+func ListSessions(path string) []*Session {
+	ext := filepath.Ext(path)
+	matches, _ := filepath.Glob(strings.TrimSuffix(path, ext) + ".*" + ext)
+	var sessions []*Session
+	for _, m := range append([]string{path}, matches...) {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			continue
+		}
+		s := &Session{path: m}
+		if json.Unmarshal(data, s) == nil {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions
+}
+
+// Remove deletes the session's state file.
+func (s *Session) Remove() error {
+	err := os.Remove(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// LoadSession loads session counters from path. Counters older than idle
+// (or a missing/corrupt file) start a fresh session.
+func LoadSession(path string, idle time.Duration) *Session {
+	fresh := &Session{path: path, Started: time.Now().UTC()}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fresh
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fresh
+	}
+	if idle > 0 && time.Since(s.LastActivity) > idle {
+		return fresh
+	}
+
+	s.path = path
+	return &s
+}
+
+// AddDenied remembers a denied command, keeping the last max entries.
+func (s *Session) AddDenied(command string, max int) {
+	for _, c := range s.DeniedCommands {
+		if c == command {
+			return
+		}
+	}
+	s.DeniedCommands = append(s.DeniedCommands, command)
+	if max > 0 && len(s.DeniedCommands) > max {
+		s.DeniedCommands = s.DeniedCommands[len(s.DeniedCommands)-max:]
+	}
+}
+
+// Save writes the counters back, marking the session as active.
+func (s *Session) Save() error {
+	s.LastActivity = time.Now().UTC()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// Write via rename so a concurrent reader never sees a partial file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Canary returns the session's canary token, creating it on first use;
+// the caller saves the session. The token is added to deny guidance and
+// has no business in any tool input (canary_tokens).
+func (s *Session) Canary() string {
+	if s.CanaryToken == "" {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return ""
+		}
+		s.CanaryToken = "sgref-" + hex.EncodeToString(b)
+	}
+	return s.CanaryToken
+}
@@ -0,0 +1,163 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Summary is one finished session in the decision store.
+type Summary struct {
+	SessionID   string         `json:"session_id,omitempty"`
+	Started     time.Time      `json:"started"`
+	Ended       time.Time      `json:"ended"`
+	Reason      string         `json:"reason,omitempty"` // SessionEnd reason (clear, logout, ...)
+	Allowed     int            `json:"allowed"`
+	Asked       int            `json:"asked"`
+	Denied      int            `json:"denied"`
+	Rules       map[string]int `json:"rules,omitempty"`
+	Deleted     int            `json:"files_deleted,omitempty"`
+	Overwritten int            `json:"files_overwritten,omitempty"`
+	Errors      map[string]int `json:"errors,omitempty"` // internal errors by kind
+}
+
+// RuleCount is a rule ID with the number of times it fired.
+type RuleCount struct {
+	Rule  string
+	Count int
+}
+
+// Record counts a hook decision (allow/ask/deny) and the rule behind it.
+func (s *Session) Record(decision, ruleID string) {
+	if s.Decisions == nil {
+		s.Decisions = make(map[string]int)
+	}
+	s.Decisions[decision]++
+	if ruleID != "" {
+		if s.Rules == nil {
+			s.Rules = make(map[string]int)
+		}
+		s.Rules[ruleID]++
+	}
+}
+
+// maxLastErrors bounds the internal errors a session keeps the text of.
+const maxLastErrors = 10
+
+// RecordError counts an internal error of kind and keeps its message
+// among the latest distinct ones.
+func (s *Session) RecordError(kind, message string) {
+	if s.Errors == nil {
+		s.Errors = make(map[string]int)
+	}
+	s.Errors[kind]++
+
+	line := kind + ": " + message
+	for i, e := range s.LastErrors {
+		if e == line {
+			s.LastErrors = append(s.LastErrors[:i], s.LastErrors[i+1:]...)
+			break
+		}
+	}
+	s.LastErrors = append(s.LastErrors, line)
+	if len(s.LastErrors) > maxLastErrors {
+		s.LastErrors = s.LastErrors[len(s.LastErrors)-maxLastErrors:]
+	}
+}
+
+// Flagged returns the number of asks and denies in the session.
+func (s *Session) Flagged() int {
+	return s.Decisions["ask"] + s.Decisions["deny"]
+}
+
+// TopRules returns up to n most frequent rules, most frequent first.
+func (s *Session) TopRules(n int) []RuleCount {
+	rules := make([]RuleCount, 0, len(s.Rules))
+	for rule, count := range s.Rules {
+		rules = append(rules, RuleCount{rule, count})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+	if n > 0 && len(rules) > n {
+		rules = rules[:n]
+	}
+	return rules
+}
+
+// Summary returns the session totals.
+func (s *Session) Summary(reason string) Summary {
+	return Summary{
+		Started:     s.Started,
+		Ended:       time.Now().UTC(),
+		Reason:      reason,
+		Allowed:     s.Decisions["allow"],
+		Asked:       s.Decisions["ask"],
+		Denied:      s.Decisions["deny"],
+		Rules:       s.Rules,
+		Deleted:     s.FilesDeleted,
+		Overwritten: s.FilesOverwritten,
+		Errors:      s.Errors,
+	}
+}
+
+// Reset clears all counters, starting a new session.
+func (s *Session) Reset() {
+	*s = Session{path: s.path, Started: time.Now().UTC()}
+}
+
+// LoadSummaries reads the decision store at path, oldest first. A missing
+// file means no sessions.
+func LoadSummaries(path string) ([]Summary, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []Summary
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var s Summary
+		if json.Unmarshal(scanner.Bytes(), &s) == nil {
+			summaries = append(summaries, s)
+		}
+	}
+	return summaries, scanner.Err()
+}
+
+// AppendSummary appends a summary line to the decision store (JSON lines)
+// and syncs it to disk.
+func AppendSummary(path string, summary Summary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
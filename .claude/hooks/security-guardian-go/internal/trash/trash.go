@@ -0,0 +1,231 @@
+// Package trash moves deleted files into a per-project trash directory
+// so agent mistakes are recoverable. Each `put` creates a batch directory
+// named by timestamp with a manifest of original locations.
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+)
+
+// manifestName is the batch manifest file name.
+const manifestName = "manifest.json"
+
+// Entry maps a trashed item back to its original location.
+type Entry struct {
+	Original string `json:"original"`
+	Trashed  string `json:"trashed"` // relative to the batch directory
+}
+
+// Batch is one trash operation (one rewritten rm command).
+type Batch struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+
+	dir string
+}
+
+// Dir returns the batch directory.
+func (b *Batch) Dir() string {
+	return b.dir
+}
+
+// Put moves paths into a new batch under trashRoot. Missing paths are
+// an error unless force is set (same as rm -f).
+func Put(trashRoot string, paths []string, force bool) (*Batch, error) {
+	now := time.Now().UTC()
+	batch := &Batch{ID: now.Format("20060102-150405"), CreatedAt: now}
+
+	// Two rm calls within a second get distinct batches
+	for i := 1; ; i++ {
+		batch.dir = filepath.Join(trashRoot, batch.ID)
+		if _, err := os.Stat(batch.dir); os.IsNotExist(err) {
+			break
+		}
+		batch.ID = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), i)
+	}
+
+	var errs []error
+	for i, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := os.Lstat(abs); err != nil {
+			if !(force && os.IsNotExist(err)) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(batch.dir, 0755); err != nil {
+			return nil, err
+		}
+
+		// Numbered slot keeps same-named files from different dirs apart
+		trashed := filepath.Join(strconv.Itoa(i), filepath.Base(abs))
+		dest := filepath.Join(batch.dir, trashed)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Rename(abs, dest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		batch.Entries = append(batch.Entries, Entry{Original: abs, Trashed: trashed})
+	}
+
+	if len(batch.Entries) > 0 {
+		if err := batch.save(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return batch, errors.Join(errs...)
+}
+
+// List returns all batches, oldest first.
+func List(trashRoot string) ([]*Batch, error) {
+	dirEntries, err := os.ReadDir(trashRoot)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []*Batch
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		b, err := load(filepath.Join(trashRoot, de.Name()))
+		if err != nil {
+			continue
+		}
+		batches = append(batches, b)
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].CreatedAt.Before(batches[j].CreatedAt)
+	})
+	return batches, nil
+}
+
+// Restore moves a batch back to its original locations and removes it.
+// Empty id restores the latest batch. Existing files are never overwritten.
+func Restore(trashRoot string, id string) (*Batch, error) {
+	var b *Batch
+	if id == "" {
+		batches, err := List(trashRoot)
+		if err != nil {
+			return nil, err
+		}
+		if len(batches) == 0 {
+			return nil, errors.New("trash is empty")
+		}
+		b = batches[len(batches)-1]
+	} else {
+		var err error
+		if b, err = load(filepath.Join(trashRoot, id)); err != nil {
+			return nil, fmt.Errorf("batch %s: %w", id, err)
+		}
+	}
+
+	var errs []error
+	var remaining []Entry
+	for _, e := range b.Entries {
+		if _, err := os.Lstat(e.Original); err == nil {
+			errs = append(errs, fmt.Errorf("%s already exists, not overwriting", e.Original))
+			remaining = append(remaining, e)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Original), 0755); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, e)
+			continue
+		}
+		if err := os.Rename(filepath.Join(b.dir, e.Trashed), e.Original); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, e)
+		}
+	}
+
+	// Keep the batch while anything is left in it
+	if len(remaining) > 0 {
+		b.Entries = remaining
+		if err := b.save(); err != nil {
+			errs = append(errs, err)
+		}
+	} else if err := os.RemoveAll(b.dir); err != nil {
+		errs = append(errs, err)
+	}
+
+	return b, errors.Join(errs...)
+}
+
+// Purge permanently deletes batches older than olderThan (0 = all)
+// and returns how many were removed.
+func Purge(trashRoot string, olderThan time.Duration) (int, error) {
+	batches, err := List(trashRoot)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	for _, b := range batches {
+		if olderThan > 0 && b.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(b.dir); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// load reads a batch manifest from dir.
+func load(dir string) (*Batch, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var b Batch
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	b.dir = dir
+	return &b, nil
+}
+
+// save writes the batch manifest.
+func (b *Batch) save() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(b.dir, manifestName), data, 0644)
+}
+
+// Root returns the trash directory for a project.
+func Root(cfg *config.SecurityConfig, projectRoot string) string {
+	if filepath.IsAbs(cfg.Trash.Directory) {
+		return cfg.Trash.Directory
+	}
+	return filepath.Join(projectRoot, cfg.Trash.Directory)
+}
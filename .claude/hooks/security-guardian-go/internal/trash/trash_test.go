@@ -0,0 +1,156 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTree creates files (slash-separated paths relative to dir).
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func TestPutRestore(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, ".claude", "trash")
+	writeTree(t, dir, "build/out.bin", "a/config.json", "b/config.json")
+
+	paths := []string{filepath.Join(dir, "build"), filepath.Join(dir, "a", "config.json"), filepath.Join(dir, "b", "config.json")}
+	batch, err := Put(root, paths, false)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if len(batch.Entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(batch.Entries))
+	}
+	for _, p := range paths {
+		if exists(p) {
+			t.Errorf("%s still exists after Put", p)
+		}
+	}
+	// Same-named files keep separate slots
+	if batch.Entries[1].Trashed == batch.Entries[2].Trashed {
+		t.Errorf("same-named files share slot %s", batch.Entries[1].Trashed)
+	}
+
+	restored, err := Restore(root, "")
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.ID != batch.ID {
+		t.Errorf("restored %s, want latest %s", restored.ID, batch.ID)
+	}
+	for _, p := range paths {
+		if !exists(p) {
+			t.Errorf("%s not restored", p)
+		}
+	}
+	if exists(batch.Dir()) {
+		t.Error("batch directory left after full restore")
+	}
+}
+
+func TestPutMissing(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "trash")
+	missing := filepath.Join(dir, "missing")
+
+	if _, err := Put(root, []string{missing}, false); err == nil {
+		t.Error("Put of a missing path without -f succeeded")
+	}
+	batch, err := Put(root, []string{missing}, true)
+	if err != nil {
+		t.Fatalf("Put -f: %v", err)
+	}
+	if len(batch.Entries) != 0 || exists(batch.Dir()) {
+		t.Error("Put -f of a missing path created a batch")
+	}
+}
+
+func TestRestoreNoOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "trash")
+	writeTree(t, dir, "keep.txt", "gone.txt")
+
+	batch, err := Put(root, []string{filepath.Join(dir, "keep.txt"), filepath.Join(dir, "gone.txt")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, dir, "keep.txt")
+
+	if _, err := Restore(root, batch.ID); err == nil {
+		t.Error("Restore over an existing file succeeded")
+	}
+	if !exists(filepath.Join(dir, "gone.txt")) {
+		t.Error("gone.txt not restored")
+	}
+
+	// The conflicting entry stays in the batch
+	batches, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 || len(batches[0].Entries) != 1 || batches[0].Entries[0].Original != filepath.Join(dir, "keep.txt") {
+		t.Errorf("batches after partial restore = %+v", batches)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "trash")
+	if _, err := Restore(root, ""); err == nil {
+		t.Error("Restore of an empty trash succeeded")
+	}
+	if _, err := Restore(root, "20000101-000000"); err == nil {
+		t.Error("Restore of an unknown batch succeeded")
+	}
+}
+
+func TestListPurge(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "trash")
+	writeTree(t, dir, "one", "two")
+
+	first, err := Put(root, []string{filepath.Join(dir, "one")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Put(root, []string{filepath.Join(dir, "two")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two puts in the same second still get distinct batches
+	if first.ID == second.ID {
+		t.Fatalf("batch IDs collide: %s", first.ID)
+	}
+
+	batches, err := List(root)
+	if err != nil || len(batches) != 2 {
+		t.Fatalf("List = %d batches, %v; want 2", len(batches), err)
+	}
+
+	if n, err := Purge(root, time.Hour); err != nil || n != 0 {
+		t.Errorf("Purge(1h) = %d, %v; want 0", n, err)
+	}
+	if n, err := Purge(root, 0); err != nil || n != 2 {
+		t.Errorf("Purge(0) = %d, %v; want 2", n, err)
+	}
+	if batches, _ := List(root); len(batches) != 0 {
+		t.Errorf("List after purge = %d batches", len(batches))
+	}
+}
@@ -0,0 +1,167 @@
+// Package trust maintains trusted_scripts.yaml, a manifest of project
+// scripts the user has reviewed, keyed by path with their sha256. A script
+// whose current hash matches skips content heuristics; a changed one is
+// flagged.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/artwist-polyakov/security-guardian/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Status is the trust state of a script.
+type Status int
+
+const (
+	// Unknown means the script is not in the manifest.
+	Unknown Status = iota
+	// Trusted means the script's hash matches the manifest.
+	Trusted
+	// Changed means the script was trusted but its content changed since.
+	Changed
+)
+
+// Manifest maps project-relative script paths to sha256 hex digests.
+type Manifest struct {
+	Scripts map[string]string `yaml:"scripts"`
+
+	path string
+}
+
+// ManifestPath returns the manifest location for a project.
+func ManifestPath(cfg *config.SecurityConfig, projectRoot string) string {
+	if filepath.IsAbs(cfg.TrustedScripts.Manifest) {
+		return cfg.TrustedScripts.Manifest
+	}
+	return filepath.Join(projectRoot, cfg.TrustedScripts.Manifest)
+}
+
+// Load reads the manifest at path. A missing file yields an empty manifest.
+func Load(path string) (*Manifest, error) {
+	m := &Manifest{Scripts: make(map[string]string), path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if m.Scripts == nil {
+		m.Scripts = make(map[string]string)
+	}
+	return m, nil
+}
+
+// Save writes the manifest back to disk.
+func (m *Manifest) Save() error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	header := "# Managed by `guardian trust <file>`. Do not edit by hand.\n"
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, append([]byte(header), data...), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// Add records the current hash of file (absolute) under its path relative
+// to projectRoot. Returns the relative path and the hash.
+func (m *Manifest) Add(projectRoot string, file string) (string, string, error) {
+	rel, err := relative(projectRoot, file)
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := HashFile(file)
+	if err != nil {
+		return "", "", err
+	}
+	m.Scripts[rel] = hash
+	return rel, hash, nil
+}
+
+// Remove drops file (absolute) from the manifest. Reports whether it was present.
+func (m *Manifest) Remove(projectRoot string, file string) bool {
+	rel, err := relative(projectRoot, file)
+	if err != nil {
+		return false
+	}
+	_, ok := m.Scripts[rel]
+	delete(m.Scripts, rel)
+	return ok
+}
+
+// Lookup returns the trust status of file (absolute).
+func (m *Manifest) Lookup(projectRoot string, file string) Status {
+	rel, err := relative(projectRoot, file)
+	if err != nil {
+		return Unknown
+	}
+	want, ok := m.Scripts[rel]
+	if !ok {
+		return Unknown
+	}
+	got, err := HashFile(file)
+	if err != nil || got != want {
+		return Changed
+	}
+	return Trusted
+}
+
+// Paths returns the trusted paths, sorted.
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Scripts))
+	for p := range m.Scripts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// HashFile returns the sha256 hex digest of a file.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// relative returns file relative to projectRoot, rejecting paths outside it.
+func relative(projectRoot string, file string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(projectRoot); err == nil {
+		projectRoot = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		file = resolved
+	}
+	rel, err := filepath.Rel(projectRoot, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project", file)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,120 @@
+// Package shparse parses bash command lines into the simple commands they
+// run, for security tooling that decides on a command before it runs.
+//
+// Parse walks lists, pipelines, subshells, blocks and command and process
+// substitutions, and records for each command its words, redirections,
+// the directory preceding cd/pushd/popd leave it in, and what it is
+// nested in:
+//
+//	cmds, err := shparse.Parse(`cd build && rm -rf $(cat list) > log`, shparse.Options{})
+//
+// Words are expanded as far as they can be before the command runs
+// (quotes, ${VAR:-default}, literal $((...)), ~user and Options.Variables);
+// words holding anything else are listed in Command.Unresolved. Marshal and
+// Unmarshal convert parse results to and from JSON.
+package shparse
+
+import "strings"
+
+// Command is a simple command of a parsed command line.
+type Command struct {
+	// Command is the command name as written (after expansion).
+	Command string
+	// Args are the words after the name that don't start with "-",
+	// Flags those that do; both in order, empty words dropped.
+	Args  []string
+	Flags []string
+	// Words are the same words as written, args and flags interleaved, for
+	// checks that depend on their order (a "--" ending the options).
+	Words []string
+	// PipesTo is the next command of a pipeline.
+	PipesTo *Command
+	// Redirects are the targets of the file redirections; Redirections
+	// the same with their operators. Descriptor duplication (2>&1),
+	// heredocs and /dev/null and the like are left out.
+	Redirects    []string
+	Redirections []Redirect
+	// Subcommands are the commands of the $(...), `...`, <(...) and >(...)
+	// substitutions in this command's words and redirections. They are
+	// also in the parsed slice, so checks that ignore nesting still see
+	// them; Parent and Nesting (NestingCmdSubst, NestingProcSubst) link
+	// them back. A substitution outside any command (x=$(...), a for
+	// list) has a Nesting but no Parent.
+	Subcommands []*Command
+	Parent      *Command
+	Nesting     string
+	// VariableAsCommand is set when the command name is an expansion.
+	VariableAsCommand bool
+	// Raw is the whole command line the command was parsed from.
+	Raw string
+	// Dir is the effective directory after preceding cd/pushd/popd in the
+	// same command line (absolute, or relative to the session cwd).
+	// Empty means the session cwd. A Dir starting with "$" is only known
+	// when the command runs (see DirUnresolved).
+	Dir string
+	// PipeVia is set when stdin comes from another command through something
+	// other than | (PipeViaProcSubst, PipeViaFifo).
+	PipeVia string
+	// Unresolved are the words (command name, args, flags, redirect
+	// targets) holding an expansion only known when the command runs:
+	// $(...), $((...)) over variables, ${#VAR}, ${VAR/a/b}, ~nosuchuser,
+	// and variables not in Options.Variables.
+	Unresolved []string
+}
+
+const (
+	// PipeViaProcSubst marks the command inside a >(...) write target.
+	PipeViaProcSubst = "process_substitution"
+	// PipeViaFifo marks a command reading a named pipe. Parse doesn't set
+	// it: which files are FIFOs is up to the caller.
+	PipeViaFifo = "fifo"
+)
+
+const (
+	// NestingCmdSubst marks a command inside $(...) or `...`.
+	NestingCmdSubst = "command_substitution"
+	// NestingProcSubst marks a command inside <(...) or >(...).
+	NestingProcSubst = "process_substitution"
+)
+
+// Origin describes where a nested command runs ("inside command
+// substitution of echo"), or returns "" for a top-level command.
+func (c *Command) Origin() string {
+	if c.Parent == nil {
+		return ""
+	}
+	kind := "command substitution"
+	if c.Nesting == NestingProcSubst {
+		kind = "process substitution"
+	}
+	return "inside " + kind + " of " + c.Parent.Command
+}
+
+// DirUnresolved reports whether c runs in a directory only known when it
+// runs: a preceding cd or pushd went to $X or $(...), or its target is
+// looked up in CDPATH, or cd - went to an OLDPWD set outside the line.
+func (c *Command) DirUnresolved() bool {
+	return strings.HasPrefix(c.Dir, "$")
+}
+
+// IsUnresolved reports whether word is one of c's unresolved words, as
+// written or joined onto Dir (JoinDir), or a path in an unresolved Dir.
+func (c *Command) IsUnresolved(word string) bool {
+	for _, w := range c.Unresolved {
+		if w == word || (c.Dir != "" && JoinDir(c.Dir, w) == word) {
+			return true
+		}
+	}
+	return c.DirUnresolved() && (word == c.Dir || strings.HasPrefix(word, strings.TrimSuffix(c.Dir, "/")+"/"))
+}
+
+// Redirect is a file redirection (`> out`, `>> ~/.zshrc`, `< in`).
+type Redirect struct {
+	Op     string `json:"op"`
+	Target string `json:"target"`
+}
+
+// IsWrite reports whether the redirection writes its target.
+func (r Redirect) IsWrite() bool {
+	return r.Op != "<"
+}
@@ -0,0 +1,81 @@
+package shparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseDirs checks the directory each command runs in after the
+// cd/pushd/popd before it.
+func TestParseDirs(t *testing.T) {
+	t.Setenv("CDPATH", "")
+	tests := []struct {
+		name    string
+		command string
+		dirs    []string
+	}{
+		{"cd variable", `X=/etc; cd $X && cat passwd`, []string{"", "$X"}},
+		{"cd quoted variable", `cd "$X" && rm -rf data`, []string{"", "$X"}},
+		{"cd braced variable", `cd ${D}/sub && rm -rf home`, []string{"", "${D}/sub"}},
+		{"cd substitution", `cd $(dirname x) && cat passwd`, []string{"", "$(...)", ""}},
+		{"cd into variable in path", `cd /tmp/$X && cat passwd`, []string{"", "$PWD"}},
+		{"relative cd stays unresolved", `cd $X && cd .. && cat passwd`, []string{"", "$X", "$X/.."}},
+		{"absolute cd resolves again", `cd $X && cd /tmp && cat passwd`, []string{"", "$X", "/tmp"}},
+		{"CDPATH prefix", `CDPATH=/ cd etc && cat passwd`, []string{"", "$CDPATH/etc"}},
+		{"CDPATH statement", `CDPATH=/; cd etc; cat passwd`, []string{"", "$CDPATH/etc"}},
+		{"exported CDPATH", `export CDPATH=/; cd etc; cat passwd`, []string{"", "$CDPATH/etc"}},
+		{"CDPATH skips dot targets", `CDPATH=/ cd ./etc && cat passwd`, []string{"", "./etc"}},
+		{"unset CDPATH", `CDPATH=/; unset CDPATH; cd etc; cat passwd`, []string{"", "", "etc"}},
+		{"cd - unknown OLDPWD", `cd - && cat passwd`, []string{"", "$OLDPWD"}},
+		{"pushd variable", `pushd $X && cat passwd && popd && cat a`, []string{"", "$X", "$X", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds, err := Parse(tt.command, Options{Strict: true})
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.command, err)
+			}
+			var dirs []string
+			for _, cmd := range cmds {
+				dirs = append(dirs, cmd.Dir)
+			}
+			if !reflect.DeepEqual(dirs, tt.dirs) {
+				t.Errorf("Parse(%q) dirs = %q, want %q", tt.command, dirs, tt.dirs)
+			}
+		})
+	}
+}
+
+// TestIsUnresolvedDir checks that relative paths in a directory only known
+// at run time are unresolved, and absolute ones aren't.
+func TestIsUnresolvedDir(t *testing.T) {
+	t.Setenv("CDPATH", "")
+	tests := []struct {
+		command string
+		path    string
+		want    bool
+	}{
+		{`cd $X && cat passwd`, "passwd", true},
+		{`cd $X && cat ../../passwd`, "../../passwd", true},
+		{`cd $X && cat /etc/passwd`, "/etc/passwd", false},
+		{`CDPATH=/ cd etc && cat passwd`, "passwd", true},
+		{`cd - && cat passwd`, "passwd", true},
+		{`cd sub && cat passwd`, "passwd", false},
+		{`cd sub && cat $(x)`, "$(...)", true},
+	}
+	for _, tt := range tests {
+		cmds, err := Parse(tt.command, Options{Strict: true})
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.command, err)
+		}
+		var cmd *Command
+		for _, c := range cmds {
+			if c.Command == "cat" {
+				cmd = c
+			}
+		}
+		if got := cmd.IsUnresolved(JoinDir(cmd.Dir, tt.path)); got != tt.want {
+			t.Errorf("%q: IsUnresolved(JoinDir(%q, %q)) = %v, want %v", tt.command, cmd.Dir, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package shparse
+
+import (
+	"os"
+	"strings"
+)
+
+// simpleParse provides fallback parsing when mvdan/sh fails.
+func simpleParse(command string) []*Command {
+	var commands []*Command
+
+	// Split by pipes first
+	pipeParts := strings.Split(command, "|")
+
+	for _, part := range pipeParts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// Split by && and ; for command lists
+		for _, subpart := range splitCommandList(part) {
+			subpart = strings.TrimSpace(subpart)
+			if subpart == "" {
+				continue
+			}
+
+			tokens := tokenize(subpart)
+			if len(tokens) == 0 {
+				continue
+			}
+
+			cmdName := tokens[0]
+			var args []string
+			var flags []string
+
+			for _, token := range tokens[1:] {
+				if strings.HasPrefix(token, "-") {
+					flags = append(flags, token)
+				} else {
+					args = append(args, token)
+				}
+			}
+
+			variableAsCommand := strings.HasPrefix(cmdName, "$")
+
+			cmd := &Command{
+				Command:           cmdName,
+				Args:              args,
+				Flags:             flags,
+				Words:             tokens[1:],
+				VariableAsCommand: variableAsCommand,
+				Raw:               command,
+			}
+			commands = append(commands, cmd)
+		}
+	}
+
+	// Track directory changes in sequence
+	state := &dirState{cdpath: os.Getenv("CDPATH") != ""}
+	for _, cmd := range commands {
+		cmd.Dir = state.dir
+		if strings.HasPrefix(cmd.Command, "CDPATH=") {
+			// Tokens don't tell an assignment from a command name
+			state.cdpath = true
+		}
+		state.apply(cmd, state.cdpath)
+	}
+
+	// Link pipeline commands
+	for i := 0; i < len(commands)-1; i++ {
+		if i < len(pipeParts)-1 {
+			commands[i].PipesTo = commands[i+1]
+		}
+	}
+
+	return commands
+}
+
+// tokenize splits a command string into tokens, respecting quotes.
+func tokenize(command string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	quoteChar := byte(0)
+
+	for i := 0; i < len(command); i++ {
+		char := command[i]
+
+		if (char == '"' || char == '\'') && (i == 0 || command[i-1] != '\\') {
+			if !inQuotes {
+				inQuotes = true
+				quoteChar = char
+			} else if char == quoteChar {
+				inQuotes = false
+				quoteChar = 0
+			} else {
+				current.WriteByte(char)
+			}
+		} else if char == ' ' && !inQuotes {
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		} else {
+			current.WriteByte(char)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// splitCommandList splits command by && and ; while respecting quotes.
+func splitCommandList(command string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+	quoteChar := byte(0)
+
+	for i := 0; i < len(command); i++ {
+		char := command[i]
+
+		if (char == '"' || char == '\'') && (i == 0 || command[i-1] != '\\') {
+			if !inQuotes {
+				inQuotes = true
+				quoteChar = char
+			} else if char == quoteChar {
+				inQuotes = false
+				quoteChar = 0
+			}
+			current.WriteByte(char)
+		} else if !inQuotes {
+			if char == ';' || (char == '&' && i+1 < len(command) && command[i+1] == '&') {
+				if current.Len() > 0 {
+					parts = append(parts, current.String())
+					current.Reset()
+				}
+				if char == '&' {
+					i++ // Skip second &
+				}
+			} else {
+				current.WriteByte(char)
+			}
+		} else {
+			current.WriteByte(char)
+		}
+	}
+
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
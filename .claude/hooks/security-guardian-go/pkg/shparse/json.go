@@ -0,0 +1,118 @@
+package shparse
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// jsonCommand is the JSON form of a Command. Links to other commands are
+// indexes into the parse result, -1 for none.
+type jsonCommand struct {
+	Command           string     `json:"command"`
+	Args              []string   `json:"args,omitempty"`
+	Flags             []string   `json:"flags,omitempty"`
+	Words             []string   `json:"words,omitempty"`
+	PipesTo           int        `json:"pipes_to"`
+	Redirections      []Redirect `json:"redirections,omitempty"`
+	Subcommands       []int      `json:"subcommands,omitempty"`
+	Parent            int        `json:"parent"`
+	Nesting           string     `json:"nesting,omitempty"`
+	VariableAsCommand bool       `json:"variable_as_command,omitempty"`
+	Raw               string     `json:"raw"`
+	Dir               string     `json:"dir,omitempty"`
+	PipeVia           string     `json:"pipe_via,omitempty"`
+	Unresolved        []string   `json:"unresolved,omitempty"`
+}
+
+// Marshal encodes a parse result as a JSON array of commands. PipesTo,
+// Parent and Subcommands become indexes into the array; commands they
+// point to outside cmds are dropped (-1).
+func Marshal(cmds []*Command) ([]byte, error) {
+	index := make(map[*Command]int, len(cmds))
+	for i, cmd := range cmds {
+		index[cmd] = i
+	}
+	indexOf := func(cmd *Command) int {
+		if i, ok := index[cmd]; ok && cmd != nil {
+			return i
+		}
+		return -1
+	}
+
+	out := make([]jsonCommand, len(cmds))
+	for i, cmd := range cmds {
+		out[i] = jsonCommand{
+			Command:           cmd.Command,
+			Args:              cmd.Args,
+			Flags:             cmd.Flags,
+			Words:             cmd.Words,
+			PipesTo:           indexOf(cmd.PipesTo),
+			Redirections:      cmd.Redirections,
+			Parent:            indexOf(cmd.Parent),
+			Nesting:           cmd.Nesting,
+			VariableAsCommand: cmd.VariableAsCommand,
+			Raw:               cmd.Raw,
+			Dir:               cmd.Dir,
+			PipeVia:           cmd.PipeVia,
+			Unresolved:        cmd.Unresolved,
+		}
+		for _, sub := range cmd.Subcommands {
+			if j := indexOf(sub); j >= 0 {
+				out[i].Subcommands = append(out[i].Subcommands, j)
+			}
+		}
+	}
+	// Commands are full of & and >; keep them readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// Unmarshal decodes the output of Marshal, restoring the links between
+// commands.
+func Unmarshal(data []byte) ([]*Command, error) {
+	var in []jsonCommand
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*Command, len(in))
+	for i, c := range in {
+		cmds[i] = &Command{
+			Command:           c.Command,
+			Args:              c.Args,
+			Flags:             c.Flags,
+			Words:             c.Words,
+			Redirections:      c.Redirections,
+			Nesting:           c.Nesting,
+			VariableAsCommand: c.VariableAsCommand,
+			Raw:               c.Raw,
+			Dir:               c.Dir,
+			PipeVia:           c.PipeVia,
+			Unresolved:        c.Unresolved,
+		}
+		for _, r := range c.Redirections {
+			cmds[i].Redirects = append(cmds[i].Redirects, r.Target)
+		}
+	}
+	at := func(i int) *Command {
+		if i >= 0 && i < len(cmds) {
+			return cmds[i]
+		}
+		return nil
+	}
+	for i, c := range in {
+		cmds[i].PipesTo = at(c.PipesTo)
+		cmds[i].Parent = at(c.Parent)
+		for _, j := range c.Subcommands {
+			if sub := at(j); sub != nil {
+				cmds[i].Subcommands = append(cmds[i].Subcommands, sub)
+			}
+		}
+	}
+	return cmds, nil
+}
@@ -0,0 +1,29 @@
+package shparse
+
+import (
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Normalize returns command in canonical form, so spellings that differ
+// only in layout (spacing, line continuations, comments) compare equal.
+// Quoting is kept as written. A command that doesn't parse is only trimmed.
+func Normalize(command string) string {
+	command = strings.TrimSpace(command)
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return command
+	}
+	// Comments are not part of what runs
+	file.Last = nil
+	for _, stmt := range file.Stmts {
+		stmt.Comments = nil
+	}
+
+	var b strings.Builder
+	if err := syntax.NewPrinter().Print(&b, file); err != nil {
+		return command
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,497 @@
+package shparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Options control parsing.
+type Options struct {
+	// Strict returns the syntax error of a command the shell grammar
+	// rejects. Otherwise such commands are split on |, && and ; and
+	// tokenized on spaces, so a broken command still yields its words.
+	Strict bool
+	// Variables are the environment variables expanded with their values
+	// in the live environment. References to other variables are kept as
+	// written and make the word unresolved (see Command.Unresolved).
+	Variables []string
+}
+
+// parser holds the state of one Parse call.
+type parser struct {
+	raw  string
+	vars map[string]bool
+	// calls maps call expressions to their commands, for linking
+	// substitutions to the command they appear in
+	calls map[*syntax.CallExpr]*Command
+}
+
+// Parse parses a command line into its commands: the simple commands of
+// its lists and pipelines, in order, followed by the commands of its
+// command and process substitutions. Commands piped to are also linked by
+// PipesTo, substitutions by Parent and Subcommands.
+func Parse(command string, opts Options) ([]*Command, error) {
+	if command == "" || strings.TrimSpace(command) == "" {
+		return nil, nil
+	}
+
+	command = strings.TrimSpace(command)
+	p := &parser{
+		raw:   command,
+		vars:  make(map[string]bool),
+		calls: make(map[*syntax.CallExpr]*Command),
+	}
+	for _, name := range opts.Variables {
+		p.vars[strings.TrimPrefix(name, "$")] = true
+	}
+
+	// Try to parse with mvdan/sh
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		if opts.Strict {
+			return nil, err
+		}
+		// Fall back to simple parsing on error
+		return simpleParse(command), nil
+	}
+
+	var commands []*Command
+
+	// Directory changes carry over between top-level statements. An
+	// exported CDPATH makes cd search it for relative targets.
+	state := &dirState{cdpath: os.Getenv("CDPATH") != ""}
+	for _, stmt := range file.Stmts {
+		commands = p.appendNode(commands, stmt, state)
+	}
+
+	if len(commands) == 0 && !opts.Strict {
+		return simpleParse(command), nil
+	}
+
+	// Also extract commands from command/process substitutions.
+	// e.g. `echo $(rm -rf ../outside)` or `cat <(cat /etc/passwd)`
+	commands = append(commands, p.substitutionCommands(file)...)
+
+	return commands, nil
+}
+
+// benignRedirectTargets are devices that are safe to redirect to or from.
+var benignRedirectTargets = map[string]bool{
+	"/dev/null":   true,
+	"/dev/stdout": true,
+	"/dev/stderr": true,
+	"/dev/stdin":  true,
+	"/dev/tty":    true,
+	"/dev/fd/0":   true,
+	"/dev/fd/1":   true,
+	"/dev/fd/2":   true,
+}
+
+// fileRedirect converts a syntax redirect into a Redirect. Returns false for
+// fd duplication (2>&1), heredocs/herestrings and benign devices.
+func (p *parser) fileRedirect(redir *syntax.Redirect) (Redirect, bool) {
+	if redir.Word == nil {
+		return Redirect{}, false
+	}
+	switch redir.Op {
+	case syntax.Hdoc, syntax.DashHdoc, syntax.WordHdoc:
+		return Redirect{}, false
+	}
+
+	target := p.extractWordValue(redir.Word)
+	if target == "" || benignRedirectTargets[target] {
+		return Redirect{}, false
+	}
+	if redir.Op == syntax.DplIn || redir.Op == syntax.DplOut {
+		// >&2, <&0, 2>&- duplicate descriptors; bash's `>& file` is a file
+		if target == "-" || isNumericWord(target) {
+			return Redirect{}, false
+		}
+		if redir.Op == syntax.DplIn {
+			return Redirect{Op: "<", Target: target}, true
+		}
+		return Redirect{Op: ">", Target: target}, true
+	}
+
+	return Redirect{Op: redir.Op.String(), Target: target}, true
+}
+
+// isNumericWord reports whether s is all digits.
+func isNumericWord(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// dirState tracks directory changes while walking a command line.
+type dirState struct {
+	dir    string
+	oldDir string
+	// oldSet is false until a cd sets OLDPWD: before that, cd - goes to
+	// the shell's OLDPWD, which isn't known
+	oldSet bool
+	// cdpath is set while CDPATH is in effect
+	cdpath bool
+	stack  []string
+}
+
+// fork returns a copy for a subshell, whose cd doesn't affect the parent.
+func (s *dirState) fork() *dirState {
+	return &dirState{
+		dir:    s.dir,
+		oldDir: s.oldDir,
+		oldSet: s.oldSet,
+		cdpath: s.cdpath,
+		stack:  append([]string{}, s.stack...),
+	}
+}
+
+// apply updates state for cd/pushd/popd and unset CDPATH. cdpath is set
+// when CDPATH is in effect for cmd.
+func (s *dirState) apply(cmd *Command, cdpath bool) {
+	target := ""
+	if len(cmd.Args) > 0 {
+		target = cmd.Args[0]
+	}
+
+	switch cmd.Command {
+	case "cd":
+		switch {
+		case target != "":
+			s.oldDir, s.dir = s.dir, s.targetDir(cmd, target, cdpath)
+		case containsString(cmd.Flags, "-"):
+			if !s.oldSet {
+				s.oldDir, s.dir = s.dir, "$OLDPWD"
+			} else {
+				s.oldDir, s.dir = s.dir, s.oldDir
+			}
+		default:
+			s.oldDir, s.dir = s.dir, "~"
+		}
+		s.oldSet = true
+	case "pushd":
+		if target == "" || strings.HasPrefix(target, "+") {
+			// pushd without dir swaps the top two entries
+			if len(s.stack) > 0 {
+				top := len(s.stack) - 1
+				s.dir, s.stack[top] = s.stack[top], s.dir
+			}
+			return
+		}
+		s.stack = append(s.stack, s.dir)
+		s.dir = s.targetDir(cmd, target, cdpath)
+	case "popd":
+		if len(s.stack) > 0 {
+			top := len(s.stack) - 1
+			s.dir = s.stack[top]
+			s.stack = s.stack[:top]
+		}
+	case "unset":
+		if containsString(cmd.Args, "CDPATH") {
+			s.cdpath = false
+		}
+	}
+}
+
+// targetDir returns the directory cd/pushd target leads to. A target only
+// known when the command runs ($X, $(...), one CDPATH may redirect) gives
+// a directory starting with "$" (see Command.DirUnresolved).
+func (s *dirState) targetDir(cmd *Command, target string, cdpath bool) string {
+	switch {
+	case cmd.IsUnresolved(target) || strings.ContainsAny(target, "$`"):
+		if strings.HasPrefix(target, "$") {
+			return target
+		}
+		return "$PWD"
+	case cdpath && searchesCDPath(target):
+		return "$CDPATH/" + target
+	}
+	return joinDir(s.dir, target)
+}
+
+// searchesCDPath reports whether cd looks target up in CDPATH: relative
+// targets other than ., .. and those starting with ./ or ../.
+func searchesCDPath(target string) bool {
+	if filepath.IsAbs(target) || strings.HasPrefix(target, "~") || target == "." || target == ".." {
+		return false
+	}
+	return !strings.HasPrefix(target, "./") && !strings.HasPrefix(target, "../")
+}
+
+// setsCDPath reports whether assigns set CDPATH.
+func setsCDPath(assigns []*syntax.Assign) bool {
+	for _, as := range assigns {
+		if as.Name != nil && as.Name.Value == "CDPATH" && !as.Naked {
+			return true
+		}
+	}
+	return false
+}
+
+// joinDir resolves target relative to dir without touching the filesystem.
+// Absolute, home and variable paths replace dir entirely. Paths in a
+// directory only known at run time are appended as written: cleaning
+// "$X/../y" to "y" would drop the unknown part.
+func joinDir(dir, target string) string {
+	if dir == "" || filepath.IsAbs(target) || strings.HasPrefix(target, "~") || strings.HasPrefix(target, "$") {
+		return target
+	}
+	if strings.HasPrefix(dir, "$") {
+		return strings.TrimSuffix(dir, "/") + "/" + target
+	}
+	return filepath.Join(dir, target)
+}
+
+// JoinDir returns path as seen from the session cwd when the command runs
+// in dir (Command.Dir). Absolute, home and variable paths are unchanged.
+func JoinDir(dir, path string) string {
+	if dir == "" || path == "" {
+		return path
+	}
+	return joinDir(dir, path)
+}
+
+// containsString reports whether ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// substitutionCommands walks the AST to find command/process substitutions
+// and returns their inner commands. A substitution in the words or
+// redirections of a parsed call becomes its subcommand.
+func (p *parser) substitutionCommands(node syntax.Node) []*Command {
+	var commands []*Command
+
+	// owners[i] is the command the i-th node being walked belongs to
+	var owners []*Command
+	syntax.Walk(node, func(n syntax.Node) bool {
+		if n == nil {
+			owners = owners[:len(owners)-1]
+			return true
+		}
+		var owner *Command
+		if len(owners) > 0 {
+			owner = owners[len(owners)-1]
+		}
+
+		switch sub := n.(type) {
+		case *syntax.Stmt:
+			// Redirections belong to the statement's command
+			if call, ok := sub.Cmd.(*syntax.CallExpr); ok && p.calls[call] != nil {
+				owner = p.calls[call]
+			}
+		case *syntax.CallExpr:
+			if p.calls[sub] != nil {
+				owner = p.calls[sub]
+			}
+		case *syntax.CmdSubst:
+			// $(cmd) or `cmd`
+			for _, stmt := range sub.Stmts {
+				cmds := p.parseNode(stmt, &dirState{})
+				nestCommands(owner, cmds, NestingCmdSubst)
+				commands = append(commands, cmds...)
+			}
+			owner = nil
+		case *syntax.ProcSubst:
+			// <(cmd) or >(cmd)
+			for _, stmt := range sub.Stmts {
+				cmds := p.parseNode(stmt, &dirState{})
+				// >(cmd) is a write target: cmd reads what the outer command writes
+				if sub.Op == syntax.CmdOut && len(cmds) > 0 {
+					cmds[0].PipeVia = PipeViaProcSubst
+				}
+				nestCommands(owner, cmds, NestingProcSubst)
+				commands = append(commands, cmds...)
+			}
+			owner = nil
+		}
+		owners = append(owners, owner)
+		return true
+	})
+
+	return commands
+}
+
+// nestCommands marks cmds as substituted into parent (nil outside any
+// command, e.g. in an assignment or a for list).
+func nestCommands(parent *Command, cmds []*Command, nesting string) {
+	for _, cmd := range cmds {
+		cmd.Nesting = nesting
+		if parent != nil {
+			cmd.Parent = parent
+			parent.Subcommands = append(parent.Subcommands, cmd)
+		}
+	}
+}
+
+// parseNode parses a syntax node recursively. state tracks the effective
+// directory across cd/pushd/popd.
+func (p *parser) parseNode(node syntax.Node, state *dirState) []*Command {
+	return p.appendNode(nil, node, state)
+}
+
+// appendNode appends the commands of a node to dst. Long pipelines and
+// lists are deep trees, so the commands are built in one slice rather than
+// copied up at each level.
+func (p *parser) appendNode(dst []*Command, node syntax.Node, state *dirState) []*Command {
+	switch n := node.(type) {
+	case *syntax.Stmt:
+		if n.Cmd != nil {
+			if n.Background {
+				// Background jobs run in a subshell
+				state = state.fork()
+			}
+			start := len(dst)
+			dst = p.appendNode(dst, n.Cmd, state)
+			cmds := dst[start:]
+			// Attach redirects to the command they belong to. A simple command
+			// owns its redirects; for { ...; } > f or ( ... ) > f every command
+			// inside writes to f.
+			if len(n.Redirs) > 0 && len(cmds) > 0 {
+				var redirects []Redirect
+				var unresolved []string
+				for _, redir := range n.Redirs {
+					if r, ok := p.fileRedirect(redir); ok {
+						redirects = append(redirects, r)
+						if _, resolved := p.extractWord(redir.Word); !resolved {
+							unresolved = append(unresolved, r.Target)
+						}
+					}
+				}
+				owners := cmds
+				if _, ok := n.Cmd.(*syntax.CallExpr); ok {
+					owners = cmds[:1]
+				}
+				for _, cmd := range owners {
+					for _, r := range redirects {
+						cmd.Redirections = append(cmd.Redirections, r)
+						cmd.Redirects = append(cmd.Redirects, r.Target)
+					}
+					cmd.Unresolved = append(cmd.Unresolved, unresolved...)
+				}
+			}
+		}
+
+	case *syntax.CallExpr:
+		cmd := p.parseCallExpr(n)
+		if cmd != nil {
+			p.calls[n] = cmd
+			cmd.Dir = state.dir
+			state.apply(cmd, state.cdpath || setsCDPath(n.Assigns))
+			dst = append(dst, cmd)
+		} else if setsCDPath(n.Assigns) {
+			// CDPATH=/ on its own sets it for the rest of the line
+			state.cdpath = true
+		}
+
+	case *syntax.DeclClause:
+		// export CDPATH=/, declare CDPATH=/ and the like
+		if setsCDPath(n.Args) {
+			state.cdpath = true
+		}
+
+	case *syntax.BinaryCmd:
+		// Handle pipelines and && / || / ;
+		if n.Op == syntax.Pipe {
+			// Pipeline members run in subshells. ALL commands are kept so
+			// checks that iterate the slice (without traversing PipesTo)
+			// still see every command.
+			start := len(dst)
+			dst = p.appendNode(dst, n.X, state.fork())
+			mid := len(dst)
+			dst = p.appendNode(dst, n.Y, state.fork())
+
+			// Link pipeline commands via PipesTo chain
+			if mid > start && len(dst) > mid {
+				last := dst[mid-1]
+				for last.PipesTo != nil {
+					last = last.PipesTo
+				}
+				last.PipesTo = dst[mid]
+			}
+		} else {
+			// For && and || and ;, just collect all commands.
+			// They run in sequence, so cd on the left affects the right.
+			dst = p.appendNode(dst, n.X, state)
+			dst = p.appendNode(dst, n.Y, state)
+		}
+
+	case *syntax.Subshell:
+		// cd inside (...) doesn't leak out
+		sub := state.fork()
+		for _, stmt := range n.Stmts {
+			dst = p.appendNode(dst, stmt, sub)
+		}
+
+	case *syntax.Block:
+		for _, stmt := range n.Stmts {
+			dst = p.appendNode(dst, stmt, state)
+		}
+	}
+
+	return dst
+}
+
+// parseCallExpr parses a call expression into a Command.
+func (p *parser) parseCallExpr(call *syntax.CallExpr) *Command {
+	if len(call.Args) == 0 {
+		return nil
+	}
+
+	// Extract command name
+	cmdName, resolved := p.extractWord(call.Args[0])
+	if cmdName == "" {
+		return nil
+	}
+	var unresolved []string
+	if !resolved {
+		unresolved = append(unresolved, cmdName)
+	}
+
+	// Check if command is a variable expansion (${X:-rm} too: the shell's
+	// X may differ from ours)
+	_, paramFirst := call.Args[0].Parts[0].(*syntax.ParamExp)
+	variableAsCommand := paramFirst || strings.HasPrefix(cmdName, "$") || strings.HasPrefix(cmdName, "${")
+
+	var args, flags, words []string
+
+	// Process arguments
+	for i, arg := range call.Args[1:] {
+		_ = i
+		word, resolved := p.extractWord(arg)
+		if word == "" {
+			continue
+		}
+		if !resolved {
+			unresolved = append(unresolved, word)
+		}
+		words = append(words, word)
+		if strings.HasPrefix(word, "-") {
+			flags = append(flags, word)
+		} else {
+			args = append(args, word)
+		}
+	}
+
+	return &Command{
+		Command:           cmdName,
+		Args:              args,
+		Flags:             flags,
+		Words:             words,
+		Redirects:         nil, // Redirects are parsed at Stmt level, not needed for security checks
+		VariableAsCommand: variableAsCommand,
+		Raw:               p.raw,
+		Unresolved:        unresolved,
+	}
+}
@@ -0,0 +1,56 @@
+package shparse
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzParse checks that Parse never panics, that strict parsing only
+// fails where the fallback takes over, and that results survive a JSON
+// round trip (words intact when valid UTF-8).
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"ls -la",
+		`cd "$X" && rm -rf data`,
+		`CDPATH=/ cd etc && cat passwd`,
+		`pushd sub; pushd; popd; popd; cd -`,
+		`echo $(cat <(echo >(nc host 1)))`,
+		`cat "unterminated`,
+		`cat <<EOF` + "\n" + strings.Repeat("x\n", 5000) + "EOF\n",
+		// Every command carries the whole line as Raw: keep the JSON small
+		strings.Repeat("true && ", 500) + "rm -rf /",
+	} {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, command string, strict bool) {
+		cmds, err := Parse(command, Options{Strict: strict, Variables: []string{"HOME"}})
+		if err != nil {
+			if !strict {
+				t.Fatalf("non-strict Parse(%q) failed: %v", command, err)
+			}
+			return
+		}
+		data, err := Marshal(cmds)
+		if err != nil {
+			t.Fatalf("Marshal(Parse(%q)): %v", command, err)
+		}
+		back, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(Marshal(Parse(%q))): %v", command, err)
+		}
+		if len(back) != len(cmds) {
+			t.Fatalf("round trip of %q: %d commands, want %d", command, len(back), len(cmds))
+		}
+		if !utf8.ValidString(command) {
+			// JSON replaces invalid UTF-8
+			return
+		}
+		for i, cmd := range cmds {
+			if back[i].Command != cmd.Command || back[i].Dir != cmd.Dir {
+				t.Errorf("round trip of %q: command %d is %q in %q, want %q in %q", command, i, back[i].Command, back[i].Dir, cmd.Command, cmd.Dir)
+			}
+		}
+	})
+}
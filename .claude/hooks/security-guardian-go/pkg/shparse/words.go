@@ -0,0 +1,180 @@
+package shparse
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"mvdan.cc/sh/v3/expand"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// extractWordValue extracts the string value from a syntax.Word.
+func (p *parser) extractWordValue(word *syntax.Word) string {
+	value, _ := p.extractWord(word)
+	return value
+}
+
+// extractWord extracts the string value of a word, expanding what can be
+// known before the command runs: Options.Variables ($HOME),
+// ${VAR:-default} and the like, literal $((...)) and
+// ~user. Other variable references are kept as written. resolved is false
+// if the word holds an expansion that can't be known, other variables
+// included.
+func (p *parser) extractWord(word *syntax.Word) (value string, resolved bool) {
+	if word == nil {
+		return "", true
+	}
+
+	resolved = true
+	var parts []string
+	add := func(part string, ok bool) {
+		parts = append(parts, part)
+		resolved = resolved && ok
+	}
+	for i, part := range word.Parts {
+		switch x := part.(type) {
+		case *syntax.Lit:
+			if i == 0 && strings.HasPrefix(x.Value, "~") {
+				add(expandTildeUser(x.Value, len(word.Parts) == 1))
+			} else {
+				add(x.Value, true)
+			}
+		case *syntax.SglQuoted:
+			add(x.Value, true)
+		case *syntax.DblQuoted:
+			// Recursively extract double-quoted content
+			for _, qp := range x.Parts {
+				add(p.extractWordPart(qp))
+			}
+		default:
+			add(p.extractWordPart(part))
+		}
+	}
+
+	return strings.Join(parts, ""), resolved
+}
+
+// extractWordPart extracts an expansion inside or outside double quotes.
+func (p *parser) extractWordPart(part syntax.WordPart) (string, bool) {
+	switch x := part.(type) {
+	case *syntax.Lit:
+		return x.Value, true
+	case *syntax.ParamExp:
+		return p.paramValue(x)
+	case *syntax.ArithmExp:
+		return arithmValue(x)
+	case *syntax.CmdSubst:
+		return "$(...)", false // Placeholder for command substitution
+	}
+	return "", true
+}
+
+// paramValue expands a parameter expansion: Options.Variables get their
+// value, other references are kept ($VAR, ${VAR}) and unresolved. The
+// default, alternate and assignment forms take the word the live value
+// selects, unresolved unless the variable is in Options.Variables. Other
+// forms (${#VAR}, ${VAR%.txt}, ${VAR/a/b}, ${!VAR}, ...) are unresolved.
+func (p *parser) paramValue(pe *syntax.ParamExp) (string, bool) {
+	name := pe.Param.Value
+	ref := "${" + name + "}"
+	if pe.Short {
+		ref = "$" + name
+	}
+	if pe.Excl || pe.Length || pe.Width || pe.Index != nil || pe.Slice != nil || pe.Repl != nil || pe.Names != 0 {
+		return ref, false
+	}
+
+	known := p.vars[name]
+	value, set := os.LookupEnv(name)
+	if known {
+		ref = value
+	}
+	if pe.Exp == nil {
+		return ref, known
+	}
+
+	word := func() (string, bool) {
+		v, resolved := p.extractWord(pe.Exp.Word)
+		return v, resolved && known
+	}
+	switch pe.Exp.Op {
+	case syntax.DefaultUnset, syntax.AssignUnset:
+		if set {
+			return ref, known
+		}
+		return word()
+	case syntax.DefaultUnsetOrNull, syntax.AssignUnsetOrNull:
+		if value != "" {
+			return ref, known
+		}
+		return word()
+	case syntax.AlternateUnset:
+		if set {
+			return word()
+		}
+		return "", known
+	case syntax.AlternateUnsetOrNull:
+		if value != "" {
+			return word()
+		}
+		return "", known
+	case syntax.ErrorUnset, syntax.ErrorUnsetOrNull:
+		return ref, known
+	}
+	return ref, false
+}
+
+// arithmValue evaluates $((...)) over literal numbers. Arithmetic on
+// variables or substitutions is unresolved.
+func arithmValue(ae *syntax.ArithmExp) (string, bool) {
+	unresolved := "$((...))"
+	literal := true
+	syntax.Walk(ae.X, func(n syntax.Node) bool {
+		switch x := n.(type) {
+		case *syntax.ParamExp, *syntax.CmdSubst:
+			literal = false
+		case *syntax.Lit:
+			if syntax.ValidName(x.Value) {
+				literal = false
+			}
+		}
+		return literal
+	})
+	if !literal {
+		return unresolved, false
+	}
+	n, err := expand.Arithm(&expand.Config{Env: expand.ListEnviron()}, ae.X)
+	if err != nil {
+		return unresolved, false
+	}
+	return strconv.Itoa(n), true
+}
+
+// expandTildeUser expands a leading ~user (and ~+, the working directory)
+// in the first literal of a word; ~ and ~/ are left to the caller. whole
+// is true if the literal is the entire word.
+func expandTildeUser(lit string, whole bool) (string, bool) {
+	name, rest := lit[1:], ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		name, rest = name[:i], name[i:]
+	} else if !whole {
+		// ~$USER: bash looks up a user named "$USER"
+		return lit, false
+	}
+
+	switch name {
+	case "":
+		return lit, true
+	case "+":
+		return "." + rest, true
+	case "-":
+		return lit, false
+	}
+	u, err := user.Lookup(name)
+	if err != nil || u.HomeDir == "" {
+		return lit, false
+	}
+	return u.HomeDir + rest, true
+}
@@ -32,6 +32,16 @@ GOOS=darwin GOARCH=amd64 go build -ldflags="$LDFLAGS" -o "${BUILD_DIR}/guardian-
 echo "Building for linux/amd64..."
 GOOS=linux GOARCH=amd64 go build -ldflags="$LDFLAGS" -o "${BUILD_DIR}/guardian-linux-amd64" ./cmd/guardian
 
+# Build for Linux ARM64
+echo "Building for linux/arm64..."
+GOOS=linux GOARCH=arm64 go build -ldflags="$LDFLAGS" -o "${BUILD_DIR}/guardian-linux-arm64" ./cmd/guardian
+
+# Build for Windows AMD64 and ARM64
+echo "Building for windows/amd64..."
+GOOS=windows GOARCH=amd64 go build -ldflags="$LDFLAGS" -o "${BUILD_DIR}/guardian-windows-amd64.exe" ./cmd/guardian
+echo "Building for windows/arm64..."
+GOOS=windows GOARCH=arm64 go build -ldflags="$LDFLAGS" -o "${BUILD_DIR}/guardian-windows-arm64.exe" ./cmd/guardian
+
 echo ""
 echo "Build complete! Binaries:"
 ls -lh "$BUILD_DIR"/guardian-*
@@ -0,0 +1,30 @@
+#!/bin/bash
+# Security Guardian Go - Release manifest
+# Writes dist/release.json (the release version and the SHA-256 of each
+# binary, from GoReleaser's checksums.txt) and signs it with the release
+# key into dist/release.json.sig. `guardian self-update` verifies the
+# signature, refuses versions older than its own, and checks the binary it
+# downloads against the manifest.
+#
+# Usage: release-manifest.sh <version> <signing-key.pem> [dist-dir]
+
+set -e
+
+VERSION="${1#v}"
+KEY_FILE="$2"
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+DIST_DIR="${3:-$(dirname "$SCRIPT_DIR")/dist}"
+
+if [ -z "$VERSION" ] || [ -z "$KEY_FILE" ]; then
+    echo "usage: $0 <version> <signing-key.pem> [dist-dir]" >&2
+    exit 2
+fi
+
+{
+    printf '{"version":"%s","assets":{' "$VERSION"
+    awk '$2 ~ /^guardian-/ { printf "%s\"%s\":\"%s\"", sep, $2, $1; sep = "," }' "$DIST_DIR/checksums.txt"
+    printf '}}\n'
+} > "$DIST_DIR/release.json"
+
+openssl pkeyutl -sign -rawin -inkey "$KEY_FILE" -in "$DIST_DIR/release.json" -out "$DIST_DIR/release.json.sig"
+echo "Signed $DIST_DIR/release.json ($VERSION)"